@@ -2,16 +2,25 @@
 package thinktank
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/phrazzld/thinktank/internal/cache"
 	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/fileutil"
 	"github.com/phrazzld/thinktank/internal/logutil"
 	"github.com/phrazzld/thinktank/internal/registry"
+	"github.com/phrazzld/thinktank/internal/thinktank/orchestrator"
+	"github.com/phrazzld/thinktank/internal/thinktank/prompt"
+	"github.com/phrazzld/thinktank/internal/workflow"
+	"gopkg.in/yaml.v3"
 )
 
 // stringSliceFlag is a slice of strings that implements flag.Value interface
@@ -33,17 +42,28 @@ func (s *stringSliceFlag) Set(value string) error {
 
 // Constants referencing the config package defaults
 const (
-	defaultOutputFile      = config.DefaultOutputFile
-	defaultModel           = config.DefaultModel
-	apiKeyEnvVar           = config.APIKeyEnvVar
-	apiEndpointEnvVar      = config.APIEndpointEnvVar
-	openaiAPIKeyEnvVar     = config.OpenAIAPIKeyEnvVar
-	defaultFormat          = config.DefaultFormat
-	defaultExcludes        = config.DefaultExcludes
-	defaultExcludeNames    = config.DefaultExcludeNames
-	defaultTimeout         = config.DefaultTimeout
-	defaultDirPermissions  = config.DefaultDirPermissions
-	defaultFilePermissions = config.DefaultFilePermissions
+	defaultOutputFile          = config.DefaultOutputFile
+	defaultModel               = config.DefaultModel
+	apiKeyEnvVar               = config.APIKeyEnvVar
+	apiEndpointEnvVar          = config.APIEndpointEnvVar
+	openaiAPIKeyEnvVar         = config.OpenAIAPIKeyEnvVar
+	defaultFormat              = config.DefaultFormat
+	defaultExcludes            = config.DefaultExcludes
+	defaultExcludeNames        = config.DefaultExcludeNames
+	defaultTimeout             = config.DefaultTimeout
+	defaultPackStrategy        = config.DefaultPackStrategy
+	defaultMaxFileSize         = config.DefaultMaxFileSize
+	defaultCacheTTL            = config.DefaultCacheTTL
+	defaultRetryMaxAttempts    = config.DefaultRetryMaxAttempts
+	defaultRetryInitialBackoff = config.DefaultRetryInitialBackoff
+	defaultRetryMaxBackoff     = config.DefaultRetryMaxBackoff
+	defaultRetryJitter         = config.DefaultRetryJitter
+	defaultCritiqueRounds      = config.DefaultCritiqueRounds
+	outputFormatText           = config.OutputFormatText
+	outputFormatJSON           = config.OutputFormatJSON
+	stdinInstructions          = config.StdinInstructions
+	defaultDirPermissions      = config.DefaultDirPermissions
+	defaultFilePermissions     = config.DefaultFilePermissions
 )
 
 // ValidateInputs checks if the configuration is valid and returns an error if not
@@ -56,10 +76,22 @@ func ValidateInputs(config *config.CliConfig, logger logutil.LoggerInterface) er
 // This version takes a getenv function for easier testing
 // Note: The logger passed to this function should already have context attached
 func ValidateInputsWithEnv(config *config.CliConfig, logger logutil.LoggerInterface, getenv func(string) string) error {
-	// Check for instructions file
-	if config.InstructionsFile == "" && !config.DryRun {
-		logger.Error("The required --instructions flag is missing.")
-		return fmt.Errorf("missing required --instructions flag")
+	// Check for instructions
+	if !config.HasInstructions() && !config.DryRun {
+		logger.Error("The required --instructions flag (or --prompt) is missing.")
+		return fmt.Errorf("missing required --instructions flag or --prompt")
+	}
+
+	// Stdin can only be read once
+	stdinCount := 0
+	for _, path := range config.InstructionSources() {
+		if path == stdinInstructions {
+			stdinCount++
+		}
+	}
+	if stdinCount > 1 {
+		logger.Error("--instructions %s can only be given once.", stdinInstructions)
+		return fmt.Errorf("instructions can only be read from stdin once")
 	}
 
 	// Check for input paths
@@ -68,10 +100,84 @@ func ValidateInputsWithEnv(config *config.CliConfig, logger logutil.LoggerInterf
 		return fmt.Errorf("no paths specified")
 	}
 
+	// Check for a single set of changes to review
+	if err := config.Diff().Validate(); err != nil {
+		logger.Error("Invalid change review options: %v", err)
+		return fmt.Errorf("invalid diff options: %w", err)
+	}
+
+	// Check for a usable import depth
+	if config.ImportDepth < 0 {
+		logger.Error("Invalid --imports %d: must not be negative", config.ImportDepth)
+		return fmt.Errorf("invalid import depth: %d", config.ImportDepth)
+	}
+	if config.Imports().Enabled() && config.Diff().Enabled() {
+		logger.Error("--imports and --importers cannot be used with --diff, --staged or --since")
+		return fmt.Errorf("import expansion cannot be combined with reviewing changes")
+	}
+
+	// Check for a supported context packing strategy
+	if config.PackStrategy != "" && !fileutil.IsValidPackStrategy(config.PackStrategy) {
+		logger.Error("Invalid --pack-strategy '%s'. Supported strategies: %s", config.PackStrategy, strings.Join(fileutil.PackStrategies, ", "))
+		return fmt.Errorf("invalid pack strategy: %s", config.PackStrategy)
+	}
+
+	// Check for a built-in context format or a template
+	if _, err := prompt.ParseContextFormat(config.Format); err != nil {
+		logger.Error("Invalid --format: %v", err)
+		return fmt.Errorf("invalid format: %w", err)
+	}
+
+	// Check for a supported output format
+	if config.OutputFormat != "" && config.OutputFormat != outputFormatText && config.OutputFormat != outputFormatJSON {
+		logger.Error("Invalid --output-format '%s'. Supported formats: %s, %s", config.OutputFormat, outputFormatText, outputFormatJSON)
+		return fmt.Errorf("invalid output format: %s", config.OutputFormat)
+	}
+
+	// Check that the run to resume exists and has a manifest recording which models succeeded
+	if config.ResumeDir != "" {
+		if info, err := os.Stat(config.ResumeDir); err != nil || !info.IsDir() {
+			logger.Error("Invalid --resume %s: not an existing output directory", config.ResumeDir)
+			return fmt.Errorf("resume directory not found: %s", config.ResumeDir)
+		}
+		if _, err := os.Stat(filepath.Join(config.ResumeDir, orchestrator.ManifestFileName)); err != nil {
+			logger.Error("Invalid --resume %s: no %s recording which models succeeded", config.ResumeDir, orchestrator.ManifestFileName)
+			return fmt.Errorf("resume manifest not found in %s", config.ResumeDir)
+		}
+	}
+
+	// Check for a usable cache TTL
+	if config.CacheTTL < 0 {
+		logger.Error("Invalid --cache-ttl %s: must not be negative", config.CacheTTL)
+		return fmt.Errorf("invalid cache TTL: %s", config.CacheTTL)
+	}
+
+	// Check for a usable retry policy
+	if config.RetryMaxAttempts < 0 {
+		logger.Error("Invalid --retry-attempts %d: must not be negative", config.RetryMaxAttempts)
+		return fmt.Errorf("invalid retry attempts: %d", config.RetryMaxAttempts)
+	}
+	if config.RetryInitialBackoff < 0 || config.RetryMaxBackoff < 0 {
+		logger.Error("Invalid --retry-backoff %s or --retry-max-backoff %s: must not be negative",
+			config.RetryInitialBackoff, config.RetryMaxBackoff)
+		return fmt.Errorf("invalid retry backoff: %s, %s", config.RetryInitialBackoff, config.RetryMaxBackoff)
+	}
+	if config.RetryJitter < 0 || config.RetryJitter > 1 {
+		logger.Error("Invalid --retry-jitter %g: must be between 0 and 1", config.RetryJitter)
+		return fmt.Errorf("invalid retry jitter: %g", config.RetryJitter)
+	}
+
+	// Check for a usable number of critique-and-refine rounds
+	if config.CritiqueRefine && config.CritiqueRounds < 1 {
+		logger.Error("Invalid --critique-rounds %d: must be at least 1", config.CritiqueRounds)
+		return fmt.Errorf("invalid critique rounds: %d", config.CritiqueRounds)
+	}
+
 	// Check for API key based on model configuration
 	modelNeedsOpenAIKey := false
 	modelNeedsGeminiKey := false
 	modelNeedsOpenRouterKey := false
+	modelNeedsAnthropicKey := false
 
 	// Check models using registry if available
 	regManagerObj := getRegistryManagerForValidation(logger)
@@ -94,6 +200,8 @@ func ValidateInputsWithEnv(config *config.CliConfig, logger logutil.LoggerInterf
 					modelNeedsOpenAIKey = true
 				} else if strings.Contains(strings.ToLower(model), "openrouter") {
 					modelNeedsOpenRouterKey = true
+				} else if strings.HasPrefix(strings.ToLower(model), "claude-") {
+					modelNeedsAnthropicKey = true
 				} else {
 					// Default to Gemini for any other model
 					modelNeedsGeminiKey = true
@@ -101,12 +209,19 @@ func ValidateInputsWithEnv(config *config.CliConfig, logger logutil.LoggerInterf
 				continue
 			}
 
+			// Providers for local servers can be used without an API key
+			if !regManager.RequiresAPIKey(provider) {
+				continue
+			}
+
 			// Set flag based on provider
 			switch provider {
 			case "openai":
 				modelNeedsOpenAIKey = true
 			case "openrouter":
 				modelNeedsOpenRouterKey = true
+			case "anthropic":
+				modelNeedsAnthropicKey = true
 			case "gemini":
 				modelNeedsGeminiKey = true
 			default:
@@ -123,6 +238,8 @@ func ValidateInputsWithEnv(config *config.CliConfig, logger logutil.LoggerInterf
 				modelNeedsOpenAIKey = true
 			} else if strings.Contains(strings.ToLower(model), "openrouter") {
 				modelNeedsOpenRouterKey = true
+			} else if strings.HasPrefix(strings.ToLower(model), "claude-") {
+				modelNeedsAnthropicKey = true
 			} else {
 				// Default to Gemini for any other model
 				modelNeedsGeminiKey = true
@@ -157,6 +274,15 @@ func ValidateInputsWithEnv(config *config.CliConfig, logger logutil.LoggerInterf
 		}
 	}
 
+	// If any Anthropic model is used, check for Anthropic API key
+	if modelNeedsAnthropicKey {
+		anthropicKey := getenv("ANTHROPIC_API_KEY")
+		if anthropicKey == "" {
+			logger.Error("ANTHROPIC_API_KEY environment variable not set.")
+			return fmt.Errorf("anthropic API key not set")
+		}
+	}
+
 	// Check for model names
 	if len(config.ModelNames) == 0 && !config.DryRun {
 		logger.Error("At least one model must be specified with --model flag.")
@@ -227,16 +353,69 @@ func ParseFlagsWithEnv(flagSet *flag.FlagSet, args []string, getenv func(string)
 	cfg := config.NewDefaultCliConfig()
 
 	// Define flags
-	instructionsFileFlag := flagSet.String("instructions", "", "Path to a file containing the static instructions for the LLM.")
+	instructionsFlag := &stringSliceFlag{}
+	flagSet.Var(instructionsFlag, "instructions",
+		"Path to a file containing the static instructions for the LLM, or - for stdin (repeatable; files are concatenated in order).")
+	promptFlag := flagSet.String("prompt", "", "Instructions given inline, added after any --instructions files.")
+	varFlag := &stringSliceFlag{}
+	flagSet.Var(varFlag, "var", "Variable for an instructions template as key=value (repeatable). Instructions are templates when variables are given or a file ends in .tmpl.")
+	varsFileFlag := flagSet.String("vars-file", "", "YAML file of variables for an instructions template; --var values take precedence.")
+	systemPromptFlag := flagSet.String("system-prompt", "",
+		"Path to a file containing a system prompt for every model, overriding the system_prompt of models in models.yaml.")
 	outputDirFlag := flagSet.String("output-dir", "", "Directory path to store generated plans (one per model).")
+	resumeFlag := flagSet.String("resume", "",
+		"Output directory of a previous run to resume, which must hold its manifest: models the manifest records as failed or missing are run again, then synthesis is re-run.")
 	synthesisModelFlag := flagSet.String("synthesis-model", "", "Optional: Model to use for synthesizing results from multiple models.")
+	critiqueRefineFlag := flagSet.Bool("critique-refine", false,
+		"Have a critic model review each output against the instructions, then revise the output to address the critique.")
+	criticModelFlag := flagSet.String("critic-model", "", "Model that critiques outputs with --critique-refine (default: the model that produced the output).")
+	refineModelFlag := flagSet.String("refine-model", "", "Model that revises outputs with --critique-refine (default: the model that produced the output).")
+	critiqueRoundsFlag := flagSet.Int("critique-rounds", defaultCritiqueRounds,
+		"Maximum number of critique-and-refine rounds per output; rounds stop early when the critic finds nothing to change")
+	workflowFlag := flagSet.String("workflow", "", "Optional: Path to a YAML workflow that runs multiple steps (e.g., plan, critique, revise), each with its own models.")
 	verboseFlag := flagSet.Bool("verbose", false, "Enable verbose logging output (shorthand for --log-level=debug).")
 	logLevelFlag := flagSet.String("log-level", "info", "Set logging level (debug, info, warn, error).")
-	includeFlag := flagSet.String("include", "", "Comma-separated list of file extensions to include (e.g., .go,.md)")
-	excludeFlag := flagSet.String("exclude", defaultExcludes, "Comma-separated list of file extensions to exclude.")
-	excludeNamesFlag := flagSet.String("exclude-names", defaultExcludeNames, "Comma-separated list of file/dir names to exclude.")
-	formatFlag := flagSet.String("format", defaultFormat, "Format string for each file. Use {path} and {content}.")
+	includeFlag := flagSet.String("include", "",
+		"Comma-separated list of file extensions or glob patterns to include (e.g., .go,.md or internal/**/*.go).")
+	excludeFlag := flagSet.String("exclude", defaultExcludes,
+		"Comma-separated list of file extensions or glob patterns to exclude (e.g., *_test.go,**/mocks/**).")
+	excludeNamesFlag := flagSet.String("exclude-names", defaultExcludeNames, "Comma-separated list of file/dir names or name globs to exclude.")
+	formatFlag := flagSet.String("format", defaultFormat,
+		"Format of each context file: xml, markdown (fenced code blocks), numbered (line numbers), or a template using {path} and {content}.")
+	treeFlag := flagSet.Bool("tree", false,
+		"Show the models a directory tree of the context files, with their sizes and the directories left out, before the files.")
+	outputFormatFlag := flagSet.String("output-format", outputFormatText,
+		"What to write to stdout when the run completes: text (nothing; see the output directory) or json (a report of the run for scripts).")
 	dryRunFlag := flagSet.Bool("dry-run", false, "Show files that would be included and token count, but don't call the API.")
+	packStrategyFlag := flagSet.String("pack-strategy", defaultPackStrategy,
+		"How to rank files when the context exceeds the models' token budget (relevance, recency, size).")
+	diffFlag := flagSet.String("diff", "",
+		"Review changes: the context is the diff of HEAD since it branched from this revision (or of a range like main..feature) and the changed files.")
+	stagedFlag := flagSet.Bool("staged", false, "Review the changes staged for commit: the context is their diff and the changed files.")
+	sinceFlag := flagSet.String("since", "",
+		"Review the changes since a revision, committed or not: the context is their diff and the changed files.")
+	diffNeighborsFlag := flagSet.Int("diff-neighbors", 0,
+		"With --diff, --staged or --since, also include up to this many other files from the directory of each changed file.")
+	importsFlag := flagSet.Int("imports", 0,
+		"Add the local Go packages imported by the given Go files and packages, following imports this many levels deep (0 disables).")
+	importersFlag := flagSet.Bool("importers", false, "Add the local Go packages that import the given Go files' packages and packages.")
+	maxFileSizeFlag := flagSet.String("max-file-size", fileutil.FormatSize(defaultMaxFileSize),
+		"Largest part of each context file to include (e.g., 512KB, 2MB; 0 for no limit); larger files keep their head and tail.")
+	maxTotalSizeFlag := flagSet.String("max-total-size", "0", "Total size of the context files (e.g., 10MB; 0 for no limit); further files are skipped.")
+	includeGeneratedFlag := flagSet.Bool("include-generated", false,
+		"Include generated files (lock files, files marked as generated, minified JavaScript and CSS), which are skipped by default.")
+	streamFlag := flagSet.Bool("stream", false, "Stream model output to the output files as it is generated (and to the terminal when using a single model).")
+	noCacheFlag := flagSet.Bool("no-cache", false, "Always send requests to the providers instead of reusing cached responses.")
+	cacheTTLFlag := flagSet.Duration("cache-ttl", defaultCacheTTL,
+		"How long cached responses are reused (e.g., 30m, 24h; 0 = indefinitely)")
+	retryAttemptsFlag := flagSet.Int("retry-attempts", defaultRetryMaxAttempts,
+		"Attempts per request when a provider reports a rate limit, server or network error (1 = no retries)")
+	retryBackoffFlag := flagSet.Duration("retry-backoff", defaultRetryInitialBackoff,
+		"Delay before the first retry, doubled for each further retry")
+	retryMaxBackoffFlag := flagSet.Duration("retry-max-backoff", defaultRetryMaxBackoff,
+		"Maximum delay between retries, unless the provider asks for a longer one")
+	retryJitterFlag := flagSet.Float64("retry-jitter", defaultRetryJitter,
+		"Fraction (0-1) by which retry delays are randomized")
 	// confirm-tokens flag removed as part of T032E - token management refactoring
 	auditLogFileFlag := flagSet.String("audit-log-file", "", "Path to write structured audit logs (JSON Lines). Disabled if empty.")
 
@@ -258,21 +437,36 @@ func ParseFlagsWithEnv(flagSet *flag.FlagSet, args []string, getenv func(string)
 
 	// Define the model flag using our custom stringSliceFlag type to support multiple values
 	modelFlag := &stringSliceFlag{}
-	flagSet.Var(modelFlag, "model", fmt.Sprintf("Model to use for generation (repeatable). Can be Gemini (e.g., %s) or OpenAI (e.g., gpt-4) models. Default: %s", defaultModel, defaultModel))
+	flagSet.Var(modelFlag, "model", fmt.Sprintf("Model to use for generation (repeatable). Can be Gemini (e.g., %s) or OpenAI (e.g., gpt-4) models, "+
+		"optionally followed by comma-separated fallback models (e.g., %s,gpt-4.1). Default: %s", defaultModel, defaultModel, defaultModel))
 
 	// Set custom usage message
 	flagSet.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s --instructions <file> [options] <path1> [path2...]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s --instructions <file> | --prompt <text> [options] <path1> [path2...]\n\n", os.Args[0])
 
 		fmt.Fprintf(os.Stderr, "Arguments:\n")
-		fmt.Fprintf(os.Stderr, "  <path1> [path2...]   One or more file or directory paths for project context.\n\n")
+		fmt.Fprintf(os.Stderr, "  <path1> [path2...]   One or more file or directory paths for project context.\n")
+		fmt.Fprintf(os.Stderr, "                       With --diff, --staged or --since, the paths limit the changes (default: all).\n\n")
 
 		fmt.Fprintf(os.Stderr, "Example Commands:\n")
 		fmt.Fprintf(os.Stderr, "  %s --instructions instructions.txt ./src                        Generate plan using default model\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --instructions instructions.txt --output-dir custom-dir ./       Generate plans in custom directory\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --instructions instructions.txt --model model1 --model model2 ./  Generate plans for multiple models\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --instructions instructions.txt --model model1,model2 ./          Fall back to model2 if model1 fails\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --instructions instructions.txt --synthesis-model model3 ./       Synthesize outputs from multiple models\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --instructions instructions.txt --critique-refine ./              Critique and revise each output\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --instructions instructions.txt --workflow review.yaml ./         Run a multi-step workflow\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --instructions instructions.txt --timeout 5m ./                  Run with 5-minute timeout\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --instructions instructions.txt --stream ./                      Watch output as it is generated\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --instructions instructions.txt --no-cache ./                    Ignore cached responses\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --instructions instructions.txt --output-format json ./          Print a JSON report of the run\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --instructions style.md --instructions task.md ./             Combine several instruction files\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  generate-task | %s --instructions - ./                            Read instructions from stdin\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --prompt \"Review the error handling\" ./                       Give instructions inline\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --instructions review.md.tmpl --var focus=security ./          Fill in an instructions template\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --instructions instructions.txt --resume thinktank_20250424_152230_3721 ./  Retry the models that failed\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --prompt \"Review this branch\" --diff main                       Review the changes of a branch\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --prompt \"Explain the run loop\" --imports 1 ./internal/thinktank/orchestrator  Include imported packages\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --dry-run ./                                                     Show files without generating plan\n\n", os.Args[0])
 
 		fmt.Fprintf(os.Stderr, "Options:\n")
@@ -282,6 +476,7 @@ func ParseFlagsWithEnv(flagSet *flag.FlagSet, args []string, getenv func(string)
 		fmt.Fprintf(os.Stderr, "  %s: Required for Gemini models. Your Google AI Gemini API key.\n", apiKeyEnvVar)
 		fmt.Fprintf(os.Stderr, "  %s: Required for OpenAI models. Your OpenAI API key.\n", openaiAPIKeyEnvVar)
 		fmt.Fprintf(os.Stderr, "  OPENROUTER_API_KEY: Required for OpenRouter models. Your OpenRouter API key.\n")
+		fmt.Fprintf(os.Stderr, "  ANTHROPIC_API_KEY: Required for Anthropic models. Your Anthropic API key.\n")
 	}
 
 	// Parse the flags
@@ -290,7 +485,37 @@ func ParseFlagsWithEnv(flagSet *flag.FlagSet, args []string, getenv func(string)
 	}
 
 	// Store flag values in configuration
-	cfg.InstructionsFile = *instructionsFileFlag
+	cfg.InstructionsFiles = *instructionsFlag
+	if len(cfg.InstructionsFiles) > 0 {
+		cfg.InstructionsFile = cfg.InstructionsFiles[0]
+	}
+	cfg.Prompt = *promptFlag
+
+	// Instructions are executed as a template when variables are given or a file is a .tmpl
+	templateVars, err := parseTemplateVars(*varsFileFlag, *varFlag)
+	if err != nil {
+		return nil, err
+	}
+	cfg.TemplateVars = templateVars
+	cfg.TemplateInstructions = len(*varFlag) > 0 || *varsFileFlag != ""
+	for _, path := range cfg.InstructionsFiles {
+		if strings.HasSuffix(path, ".tmpl") {
+			cfg.TemplateInstructions = true
+		}
+	}
+	if templateDir, err := prompt.DefaultTemplateDir(); err == nil {
+		cfg.TemplateDir = templateDir
+	}
+
+	// Read the system prompt sent to every model
+	if *systemPromptFlag != "" {
+		content, err := os.ReadFile(*systemPromptFlag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read system prompt file: %w", err)
+		}
+		cfg.SystemPromptFile = *systemPromptFlag
+		cfg.SystemPrompt = strings.TrimSpace(string(content))
+	}
 
 	// Set output directory
 	cfg.OutputDir = *outputDirFlag
@@ -304,9 +529,36 @@ func ParseFlagsWithEnv(flagSet *flag.FlagSet, args []string, getenv func(string)
 	cfg.Exclude = *excludeFlag
 	cfg.ExcludeNames = *excludeNamesFlag
 	cfg.Format = *formatFlag
+	cfg.Tree = *treeFlag
+	cfg.OutputFormat = *outputFormatFlag
 	cfg.DryRun = *dryRunFlag
+	cfg.Stream = *streamFlag
+	cfg.PackStrategy = *packStrategyFlag
+	cfg.DiffRange = *diffFlag
+	cfg.DiffStaged = *stagedFlag
+	cfg.DiffSince = *sinceFlag
+	cfg.DiffNeighbors = *diffNeighborsFlag
+	cfg.ImportDepth = *importsFlag
+	cfg.Importers = *importersFlag
+	cfg.IncludeGenerated = *includeGeneratedFlag
+	cfg.NoCache = *noCacheFlag
+	cfg.CacheTTL = *cacheTTLFlag
+	cfg.RetryMaxAttempts = *retryAttemptsFlag
+	cfg.RetryInitialBackoff = *retryBackoffFlag
+	cfg.RetryMaxBackoff = *retryMaxBackoffFlag
+	cfg.RetryJitter = *retryJitterFlag
+	cfg.CritiqueRefine = *critiqueRefineFlag
+	cfg.CriticModel = *criticModelFlag
+	cfg.RefineModel = *refineModelFlag
+	cfg.CritiqueRounds = *critiqueRoundsFlag
+	if cacheDir, err := cache.DefaultDir(); err == nil {
+		cfg.CacheDir = cacheDir
+	}
 	// ConfirmTokens field assignment removed as part of T032E - token management refactoring
 	cfg.Paths = flagSet.Args()
+	if len(cfg.Paths) == 0 && cfg.Diff().Enabled() {
+		cfg.Paths = []string{"."} // Review all the changes in the repository
+	}
 
 	// Store rate limiting configuration
 	cfg.MaxConcurrentRequests = *maxConcurrentFlag
@@ -315,6 +567,14 @@ func ParseFlagsWithEnv(flagSet *flag.FlagSet, args []string, getenv func(string)
 	// Store timeout configuration
 	cfg.Timeout = *timeoutFlag
 
+	// Parse and store size limits
+	if cfg.MaxFileSize, err = fileutil.ParseSize(*maxFileSizeFlag); err != nil {
+		return nil, fmt.Errorf("invalid --max-file-size: %w", err)
+	}
+	if cfg.MaxTotalSize, err = fileutil.ParseSize(*maxTotalSizeFlag); err != nil {
+		return nil, fmt.Errorf("invalid --max-total-size: %w", err)
+	}
+
 	// Parse and store permissions
 	if dirPerm, err := parseOctalPermission(*dirPermFlag); err == nil {
 		cfg.DirPermissions = dirPerm
@@ -329,13 +589,70 @@ func ParseFlagsWithEnv(flagSet *flag.FlagSet, args []string, getenv func(string)
 	}
 
 	// Set model names from the flag, defaulting to a single default model if none provided
+	// A model may be followed by a comma-separated list of fallbacks to try in order
 	if len(*modelFlag) > 0 {
-		cfg.ModelNames = *modelFlag
+		cfg.ModelNames = nil
+		for _, spec := range *modelFlag {
+			models, err := parseModelChain(spec)
+			if err != nil {
+				return nil, err
+			}
+			cfg.ModelNames = append(cfg.ModelNames, models[0])
+			if len(models) > 1 {
+				if cfg.ModelFallbacks == nil {
+					cfg.ModelFallbacks = make(map[string][]string)
+				}
+				cfg.ModelFallbacks[models[0]] = models[1:]
+			}
+		}
 	} else {
 		// If no models were specified on the command line, use the default model
 		cfg.ModelNames = []string{defaultModel}
 	}
 
+	// A workflow declares the models of each of its steps, so the run uses all of them
+	if *workflowFlag != "" {
+		if len(*modelFlag) > 0 || cfg.SynthesisModel != "" {
+			return nil, fmt.Errorf("--model and --synthesis-model cannot be used with --workflow: the workflow sets the models of each step")
+		}
+		if cfg.CritiqueRefine {
+			return nil, fmt.Errorf("--critique-refine cannot be used with --workflow: use critique and refine steps instead")
+		}
+		if *resumeFlag != "" {
+			return nil, fmt.Errorf("--resume cannot be used with --workflow")
+		}
+		def, err := workflow.Load(*workflowFlag)
+		if err != nil {
+			return nil, err
+		}
+		cfg.WorkflowFile = *workflowFlag
+		cfg.ModelNames = def.ModelNames()
+	}
+
+	// A resumed run saves its outputs to the directory of the run being resumed and,
+	// unless --model is given, uses the models of that run
+	if *resumeFlag != "" {
+		if cfg.OutputDir != "" && filepath.Clean(cfg.OutputDir) != filepath.Clean(*resumeFlag) {
+			return nil, fmt.Errorf("--output-dir cannot be used with --resume: outputs are saved to the directory being resumed")
+		}
+		cfg.ResumeDir = *resumeFlag
+		cfg.OutputDir = *resumeFlag
+
+		if len(*modelFlag) == 0 {
+			manifest, err := orchestrator.LoadManifest(*resumeFlag)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("cannot resume %s: %w", *resumeFlag, err)
+			}
+			if manifest != nil && len(manifest.ModelNames()) > 0 {
+				cfg.ModelNames = manifest.ModelNames()
+				cfg.ModelFallbacks = manifest.ModelFallbacks()
+				if cfg.SynthesisModel == "" {
+					cfg.SynthesisModel = manifest.SynthesisModel
+				}
+			}
+		}
+	}
+
 	// Determine initial log level from flag
 	parsedLogLevel := logutil.InfoLevel // Default
 	if *logLevelFlag != "info" {
@@ -359,6 +676,46 @@ func ParseFlagsWithEnv(flagSet *flag.FlagSet, args []string, getenv func(string)
 	return cfg, nil
 }
 
+// parseTemplateVars reads the variables of an instructions template from a YAML vars file
+// (if any), then from --var values of the form key=value, which take precedence
+func parseTemplateVars(varsFile string, vars []string) (map[string]string, error) {
+	templateVars := make(map[string]string)
+	if varsFile != "" {
+		content, err := os.ReadFile(varsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vars file: %w", err)
+		}
+		if err := yaml.Unmarshal(content, &templateVars); err != nil {
+			return nil, fmt.Errorf("invalid vars file %s: %w", varsFile, err)
+		}
+		if templateVars == nil {
+			templateVars = make(map[string]string) // The file was empty
+		}
+	}
+
+	for _, v := range vars {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid --var %q: expected key=value", v)
+		}
+		templateVars[strings.TrimSpace(key)] = value
+	}
+	return templateVars, nil
+}
+
+// parseModelChain splits a --model value of the form "model,fallback1,fallback2"
+// into the model followed by its fallbacks
+func parseModelChain(spec string) ([]string, error) {
+	models := strings.Split(spec, ",")
+	for i, model := range models {
+		models[i] = strings.TrimSpace(model)
+		if models[i] == "" {
+			return nil, fmt.Errorf("invalid --model value %q: empty model name", spec)
+		}
+	}
+	return models, nil
+}
+
 // parseOctalPermission converts a string representation of an octal permission
 // to an os.FileMode
 func parseOctalPermission(permStr string) (os.FileMode, error) {
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/phrazzld/thinktank/internal/config"
 	"github.com/phrazzld/thinktank/internal/logutil"
@@ -201,6 +203,485 @@ func TestParseFlags_Timeout(t *testing.T) {
 	}
 }
 
+// TestParseFlags_Cache tests parsing of the response cache flags
+func TestParseFlags_Cache(t *testing.T) {
+	testCases := []struct {
+		name            string
+		args            []string
+		expectedNoCache bool
+		expectedTTL     string
+	}{
+		{
+			name:            "Defaults",
+			args:            []string{"--instructions=test.txt"},
+			expectedNoCache: false,
+			expectedTTL:     "24h0m0s",
+		},
+		{
+			name:            "Cache disabled",
+			args:            []string{"--no-cache"},
+			expectedNoCache: true,
+			expectedTTL:     "24h0m0s",
+		},
+		{
+			name:            "Custom TTL",
+			args:            []string{"--cache-ttl", "30m"},
+			expectedNoCache: false,
+			expectedTTL:     "30m0s",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+
+			cfg, err := ParseFlagsWithEnv(fs, tc.args, func(string) string { return "mock-value" })
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if cfg.NoCache != tc.expectedNoCache {
+				t.Errorf("Expected NoCache: %v, got: %v", tc.expectedNoCache, cfg.NoCache)
+			}
+			if cfg.CacheTTL.String() != tc.expectedTTL {
+				t.Errorf("Expected CacheTTL: %s, got: %s", tc.expectedTTL, cfg.CacheTTL.String())
+			}
+			if cfg.CacheDir == "" {
+				t.Error("Expected CacheDir to default to the user cache directory")
+			}
+		})
+	}
+}
+
+// TestParseFlags_ModelFallbacks tests parsing of fallback models in --model values
+func TestParseFlags_ModelFallbacks(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	cfg, err := ParseFlagsWithEnv(fs, []string{
+		"--model", "gemini-2.5-pro, openrouter/google/gemini-2.5-pro ,gpt-4.1",
+		"--model", "gpt-4o",
+	}, func(string) string { return "mock-value" })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if strings.Join(cfg.ModelNames, " ") != "gemini-2.5-pro gpt-4o" {
+		t.Errorf("Expected ModelNames [gemini-2.5-pro gpt-4o], got %v", cfg.ModelNames)
+	}
+	if got := strings.Join(cfg.ModelFallbacks["gemini-2.5-pro"], " "); got != "openrouter/google/gemini-2.5-pro gpt-4.1" {
+		t.Errorf("Unexpected fallbacks for gemini-2.5-pro: %v", cfg.ModelFallbacks["gemini-2.5-pro"])
+	}
+	if _, ok := cfg.ModelFallbacks["gpt-4o"]; ok {
+		t.Errorf("Expected no fallbacks for gpt-4o, got %v", cfg.ModelFallbacks["gpt-4o"])
+	}
+
+	// Empty entries are rejected
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if _, err := ParseFlagsWithEnv(fs, []string{"--model", "gemini-2.5-pro,,gpt-4.1"}, func(string) string { return "" }); err == nil {
+		t.Error("Expected an error for an empty fallback model")
+	}
+}
+
+// TestParseFlags_Workflow tests that --workflow selects the models of the workflow's steps
+func TestParseFlags_Workflow(t *testing.T) {
+	dir := t.TempDir()
+	workflowFile := filepath.Join(dir, "workflow.yaml")
+	content := "name: review\nsteps:\n" +
+		"  - id: plan\n    type: generate\n    models: [gemini-2.5-pro, gpt-4.1]\n" +
+		"  - id: critique\n    type: critique\n    models: [gpt-4.1, o4-mini]\n    needs: [plan]\n"
+	if err := os.WriteFile(workflowFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write workflow file: %v", err)
+	}
+
+	parse := func(args ...string) (*config.CliConfig, error) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		return ParseFlagsWithEnv(fs, args, func(string) string { return "" })
+	}
+
+	cfg, err := parse("--workflow", workflowFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.WorkflowFile != workflowFile {
+		t.Errorf("Expected WorkflowFile %s, got %s", workflowFile, cfg.WorkflowFile)
+	}
+	if strings.Join(cfg.ModelNames, " ") != "gemini-2.5-pro gpt-4.1 o4-mini" {
+		t.Errorf("Expected the workflow's models, got %v", cfg.ModelNames)
+	}
+
+	// The workflow sets the models, so --model and --synthesis-model are rejected
+	if _, err := parse("--workflow", workflowFile, "--model", "gpt-4o"); err == nil {
+		t.Error("Expected an error when combining --workflow with --model")
+	}
+	if _, err := parse("--workflow", workflowFile, "--synthesis-model", "gpt-4o"); err == nil {
+		t.Error("Expected an error when combining --workflow with --synthesis-model")
+	}
+
+	// Invalid workflows are reported when parsing
+	if err := os.WriteFile(workflowFile, []byte("steps: []"), 0644); err != nil {
+		t.Fatalf("Failed to write workflow file: %v", err)
+	}
+	if _, err := parse("--workflow", workflowFile); err == nil || !strings.Contains(err.Error(), "no steps defined") {
+		t.Errorf("Expected an invalid workflow error, got %v", err)
+	}
+}
+
+// TestParseFlags_Instructions tests that --instructions is repeatable and --prompt gives
+// instructions inline
+func TestParseFlags_Instructions(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	cfg, err := ParseFlagsWithEnv(fs, []string{
+		"--instructions", "style.md", "--instructions", "-", "--prompt", "Focus on errors", "./",
+	}, func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(cfg.InstructionsFiles, " ") != "style.md -" || cfg.InstructionsFile != "style.md" {
+		t.Errorf("Expected both instructions files, got %v (first %q)", cfg.InstructionsFiles, cfg.InstructionsFile)
+	}
+	if cfg.Prompt != "Focus on errors" {
+		t.Errorf("Expected the inline prompt, got %q", cfg.Prompt)
+	}
+}
+
+// TestParseFlags_SystemPrompt tests that --system-prompt reads the system prompt file
+func TestParseFlags_SystemPrompt(t *testing.T) {
+	systemPromptFile := filepath.Join(t.TempDir(), "reviewer.md")
+	if err := os.WriteFile(systemPromptFile, []byte("You are a careful reviewer.\n"), 0644); err != nil {
+		t.Fatalf("Failed to write system prompt file: %v", err)
+	}
+
+	parse := func(args ...string) (*config.CliConfig, error) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		return ParseFlagsWithEnv(fs, args, func(string) string { return "" })
+	}
+
+	cfg, err := parse("--instructions", "task.md", "--system-prompt", systemPromptFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.SystemPromptFile != systemPromptFile || cfg.SystemPrompt != "You are a careful reviewer." {
+		t.Errorf("Unexpected system prompt: %q from %q", cfg.SystemPrompt, cfg.SystemPromptFile)
+	}
+
+	if _, err := parse("--system-prompt", filepath.Join(t.TempDir(), "missing.md")); err == nil ||
+		!strings.Contains(err.Error(), "failed to read system prompt file") {
+		t.Errorf("Expected an error for a missing system prompt file, got: %v", err)
+	}
+}
+
+// TestParseFlags_Diff tests that reviewing changes covers the whole repository unless
+// paths are given
+func TestParseFlags_Diff(t *testing.T) {
+	parse := func(args ...string) (*config.CliConfig, error) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		return ParseFlagsWithEnv(fs, args, func(string) string { return "" })
+	}
+
+	cfg, err := parse("--prompt", "Review", "--diff", "main", "--diff-neighbors", "2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	diff := cfg.Diff()
+	if !diff.Enabled() || diff.Range != "main" || diff.Neighbors != 2 {
+		t.Errorf("Unexpected diff options: %+v", diff)
+	}
+	if strings.Join(cfg.Paths, ",") != "." {
+		t.Errorf("Expected the current directory as the default path, got %v", cfg.Paths)
+	}
+
+	cfg, err = parse("--prompt", "Review", "--staged", "internal")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !cfg.DiffStaged || strings.Join(cfg.Paths, ",") != "internal" {
+		t.Errorf("Expected staged changes within internal, got %+v in %v", cfg.Diff(), cfg.Paths)
+	}
+
+	cfg, err = parse("--prompt", "Review")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Diff().Enabled() || len(cfg.Paths) != 0 {
+		t.Errorf("Expected no diff and no paths by default, got %+v in %v", cfg.Diff(), cfg.Paths)
+	}
+}
+
+// TestParseFlags_SizeLimits tests parsing the size limits of context files
+func TestParseFlags_SizeLimits(t *testing.T) {
+	parse := func(args ...string) (*config.CliConfig, error) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		return ParseFlagsWithEnv(fs, args, func(string) string { return "" })
+	}
+
+	cfg, err := parse("--prompt", "Review", "./")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.MaxFileSize != config.DefaultMaxFileSize || cfg.MaxTotalSize != 0 || cfg.IncludeGenerated {
+		t.Errorf("Unexpected defaults: file=%d total=%d generated=%v", cfg.MaxFileSize, cfg.MaxTotalSize, cfg.IncludeGenerated)
+	}
+
+	cfg, err = parse("--prompt", "Review", "--max-file-size", "256KB", "--max-total-size", "8MB", "--include-generated", "./")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.MaxFileSize != 256<<10 || cfg.MaxTotalSize != 8<<20 || !cfg.IncludeGenerated {
+		t.Errorf("Unexpected limits: file=%d total=%d generated=%v", cfg.MaxFileSize, cfg.MaxTotalSize, cfg.IncludeGenerated)
+	}
+
+	if _, err := parse("--prompt", "Review", "--max-total-size", "lots", "./"); err == nil ||
+		!strings.Contains(err.Error(), "invalid --max-total-size") {
+		t.Errorf("Expected an invalid size error, got: %v", err)
+	}
+}
+
+// TestParseFlags_TemplateVars tests that variables enable instructions templates, with
+// --var taking precedence over the vars file
+func TestParseFlags_TemplateVars(t *testing.T) {
+	varsFile := filepath.Join(t.TempDir(), "vars.yaml")
+	if err := os.WriteFile(varsFile, []byte("focus: performance\nteam: platform\n"), 0644); err != nil {
+		t.Fatalf("Failed to write vars file: %v", err)
+	}
+
+	parse := func(args ...string) (*config.CliConfig, error) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		return ParseFlagsWithEnv(fs, args, func(string) string { return "" })
+	}
+
+	cfg, err := parse("--instructions", "task.md", "--vars-file", varsFile, "--var", "focus=security")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !cfg.TemplateInstructions {
+		t.Error("Expected variables to enable instructions templates")
+	}
+	if fmt.Sprint(cfg.TemplateVars) != "map[focus:security team:platform]" {
+		t.Errorf("Unexpected template variables: %v", cfg.TemplateVars)
+	}
+
+	cfg, err = parse("--instructions", "task.md")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.TemplateInstructions {
+		t.Error("Expected plain instructions without variables or a .tmpl file")
+	}
+
+	cfg, err = parse("--instructions", "review.md.tmpl")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !cfg.TemplateInstructions {
+		t.Error("Expected a .tmpl instructions file to be a template")
+	}
+
+	if _, err := parse("--var", "missing-equals"); err == nil || !strings.Contains(err.Error(), "expected key=value") {
+		t.Errorf("Expected an invalid --var error, got: %v", err)
+	}
+}
+
+// TestParseFlags_Resume tests that --resume saves to the resumed run's directory and
+// uses its models unless --model is given
+func TestParseFlags_Resume(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `{"config": {"model_fallbacks": {"gemini-2.5-pro": ["gpt-4.1"]}},
+		"models": [{"model": "gemini-2.5-pro"}, {"model": "o4-mini"}],
+		"synthesis_model": "gpt-4.1"}`
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	parse := func(args ...string) (*config.CliConfig, error) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		return ParseFlagsWithEnv(fs, args, func(string) string { return "" })
+	}
+
+	cfg, err := parse("--resume", dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.ResumeDir != dir || cfg.OutputDir != dir {
+		t.Errorf("Expected to resume and save to %s, got %q and %q", dir, cfg.ResumeDir, cfg.OutputDir)
+	}
+	if strings.Join(cfg.ModelNames, " ") != "gemini-2.5-pro o4-mini" || cfg.SynthesisModel != "gpt-4.1" {
+		t.Errorf("Expected the models of the resumed run, got %v and %q", cfg.ModelNames, cfg.SynthesisModel)
+	}
+	if fmt.Sprint(cfg.ModelFallbacks) != "map[gemini-2.5-pro:[gpt-4.1]]" {
+		t.Errorf("Expected the fallbacks of the resumed run, got %v", cfg.ModelFallbacks)
+	}
+
+	// --model replaces the models of the resumed run
+	cfg, err = parse("--resume", dir, "--model", "gpt-4o")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(cfg.ModelNames, " ") != "gpt-4o" || cfg.SynthesisModel != "" {
+		t.Errorf("Expected only the given model, got %v and %q", cfg.ModelNames, cfg.SynthesisModel)
+	}
+
+	if _, err := parse("--resume", dir, "--output-dir", t.TempDir()); err == nil {
+		t.Error("Expected an error when combining --resume with another --output-dir")
+	}
+	if _, err := parse("--resume", dir, "--workflow", "workflow.yaml"); err == nil || !strings.Contains(err.Error(), "--resume") {
+		t.Error("Expected an error when combining --resume with --workflow")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), []byte("{"), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	if _, err := parse("--resume", dir); err == nil || !strings.Contains(err.Error(), "invalid manifest") {
+		t.Errorf("Expected an invalid manifest error, got %v", err)
+	}
+}
+
+// TestParseFlags_CritiqueRefine tests parsing of the critique-and-refine flags
+func TestParseFlags_CritiqueRefine(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	cfg, err := ParseFlagsWithEnv(fs, []string{"--instructions=test.txt"}, func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.CritiqueRefine || cfg.CriticModel != "" || cfg.RefineModel != "" || cfg.CritiqueRounds != 1 {
+		t.Errorf("Unexpected critique-and-refine defaults: %v %q %q %d",
+			cfg.CritiqueRefine, cfg.CriticModel, cfg.RefineModel, cfg.CritiqueRounds)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cfg, err = ParseFlagsWithEnv(fs, []string{
+		"--instructions=test.txt", "--critique-refine", "--critic-model", "o4-mini",
+		"--refine-model", "gpt-4.1", "--critique-rounds", "3",
+	}, func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !cfg.CritiqueRefine || cfg.CriticModel != "o4-mini" || cfg.RefineModel != "gpt-4.1" || cfg.CritiqueRounds != 3 {
+		t.Errorf("Unexpected critique-and-refine configuration: %v %q %q %d",
+			cfg.CritiqueRefine, cfg.CriticModel, cfg.RefineModel, cfg.CritiqueRounds)
+	}
+}
+
+// TestParseFlags_OutputFormat tests parsing of the --output-format flag
+func TestParseFlags_OutputFormat(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	cfg, err := ParseFlagsWithEnv(fs, []string{"--instructions=test.txt"}, func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.OutputFormat != "text" {
+		t.Errorf("Expected the text output format by default, got %q", cfg.OutputFormat)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cfg, err = ParseFlagsWithEnv(fs, []string{"--instructions=test.txt", "--output-format", "json"}, func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.OutputFormat != "json" {
+		t.Errorf("Expected the json output format, got %q", cfg.OutputFormat)
+	}
+}
+
+// TestParseFlags_Tree tests parsing of the --tree flag
+func TestParseFlags_Tree(t *testing.T) {
+	for _, tt := range []struct {
+		args     []string
+		expected bool
+	}{
+		{[]string{"--instructions=test.txt"}, false},
+		{[]string{"--instructions=test.txt", "--tree"}, true},
+	} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		cfg, err := ParseFlagsWithEnv(fs, tt.args, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.Tree != tt.expected {
+			t.Errorf("Expected Tree %v for %v, got %v", tt.expected, tt.args, cfg.Tree)
+		}
+	}
+}
+
+// TestParseFlags_Retry tests parsing of the retry policy flags
+func TestParseFlags_Retry(t *testing.T) {
+	testCases := []struct {
+		name               string
+		args               []string
+		expectedAttempts   int
+		expectedBackoff    time.Duration
+		expectedMaxBackoff time.Duration
+		expectedJitter     float64
+	}{
+		{
+			name:               "Defaults",
+			args:               []string{"--instructions=test.txt"},
+			expectedAttempts:   3,
+			expectedBackoff:    2 * time.Second,
+			expectedMaxBackoff: time.Minute,
+			expectedJitter:     0.2,
+		},
+		{
+			name:               "Custom policy",
+			args:               []string{"--retry-attempts", "5", "--retry-backoff", "500ms", "--retry-max-backoff", "10s", "--retry-jitter", "0"},
+			expectedAttempts:   5,
+			expectedBackoff:    500 * time.Millisecond,
+			expectedMaxBackoff: 10 * time.Second,
+			expectedJitter:     0,
+		},
+		{
+			name:               "Retries disabled",
+			args:               []string{"--retry-attempts", "1"},
+			expectedAttempts:   1,
+			expectedBackoff:    2 * time.Second,
+			expectedMaxBackoff: time.Minute,
+			expectedJitter:     0.2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+
+			cfg, err := ParseFlagsWithEnv(fs, tc.args, func(string) string { return "mock-value" })
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if cfg.RetryMaxAttempts != tc.expectedAttempts {
+				t.Errorf("Expected RetryMaxAttempts: %d, got: %d", tc.expectedAttempts, cfg.RetryMaxAttempts)
+			}
+			if cfg.RetryInitialBackoff != tc.expectedBackoff {
+				t.Errorf("Expected RetryInitialBackoff: %s, got: %s", tc.expectedBackoff, cfg.RetryInitialBackoff)
+			}
+			if cfg.RetryMaxBackoff != tc.expectedMaxBackoff {
+				t.Errorf("Expected RetryMaxBackoff: %s, got: %s", tc.expectedMaxBackoff, cfg.RetryMaxBackoff)
+			}
+			if cfg.RetryJitter != tc.expectedJitter {
+				t.Errorf("Expected RetryJitter: %g, got: %g", tc.expectedJitter, cfg.RetryJitter)
+			}
+		})
+	}
+}
+
 // TestParseFlags_SynthesisModel tests parsing of the synthesis-model flag
 func TestParseFlags_SynthesisModel(t *testing.T) {
 	// Create a flag set
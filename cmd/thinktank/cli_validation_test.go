@@ -3,11 +3,14 @@ package thinktank
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/phrazzld/thinktank/internal/config"
 	"github.com/phrazzld/thinktank/internal/logutil"
+	"github.com/phrazzld/thinktank/internal/registry"
 )
 
 // Import constants directly from the tested file
@@ -150,6 +153,185 @@ func TestValidateInputs(t *testing.T) {
 			expectError:   true,
 			errorContains: "openAI API key not set",
 		},
+		{
+			name: "Negative cache TTL",
+			config: &config.CliConfig{
+				InstructionsFile: tempFile.Name(),
+				Paths:            []string{"testfile"},
+				APIKey:           "test-key",
+				ModelNames:       []string{"model1"},
+				CacheTTL:         -time.Minute,
+			},
+			expectError:   true,
+			errorContains: "invalid cache TTL",
+		},
+		{
+			name: "Negative retry attempts",
+			config: &config.CliConfig{
+				InstructionsFile: tempFile.Name(),
+				Paths:            []string{"testfile"},
+				APIKey:           "test-key",
+				ModelNames:       []string{"model1"},
+				RetryMaxAttempts: -1,
+			},
+			expectError:   true,
+			errorContains: "invalid retry attempts",
+		},
+		{
+			name: "Negative retry backoff",
+			config: &config.CliConfig{
+				InstructionsFile:    tempFile.Name(),
+				Paths:               []string{"testfile"},
+				APIKey:              "test-key",
+				ModelNames:          []string{"model1"},
+				RetryInitialBackoff: -time.Second,
+			},
+			expectError:   true,
+			errorContains: "invalid retry backoff",
+		},
+		{
+			name: "Retry jitter out of range",
+			config: &config.CliConfig{
+				InstructionsFile: tempFile.Name(),
+				Paths:            []string{"testfile"},
+				APIKey:           "test-key",
+				ModelNames:       []string{"model1"},
+				RetryJitter:      1.5,
+			},
+			expectError:   true,
+			errorContains: "invalid retry jitter",
+		},
+		{
+			name: "Critique-and-refine without rounds",
+			config: &config.CliConfig{
+				InstructionsFile: tempFile.Name(),
+				Paths:            []string{"testfile"},
+				APIKey:           "test-key",
+				ModelNames:       []string{"model1"},
+				CritiqueRefine:   true,
+				CritiqueRounds:   0,
+			},
+			expectError:   true,
+			errorContains: "invalid critique rounds",
+		},
+		{
+			name: "Unsupported output format",
+			config: &config.CliConfig{
+				InstructionsFile: tempFile.Name(),
+				Paths:            []string{"testfile"},
+				APIKey:           "test-key",
+				ModelNames:       []string{"model1"},
+				OutputFormat:     "yaml",
+			},
+			expectError:   true,
+			errorContains: "invalid output format",
+		},
+		{
+			name: "Inline prompt instead of an instructions file",
+			config: &config.CliConfig{
+				Prompt:     "Review the code",
+				Paths:      []string{"testfile"},
+				APIKey:     "test-key",
+				ModelNames: []string{"model1"},
+			},
+			expectError: false,
+		},
+		{
+			name: "Stdin given twice",
+			config: &config.CliConfig{
+				InstructionsFiles: []string{"-", tempFile.Name(), "-"},
+				Paths:             []string{"testfile"},
+				APIKey:            "test-key",
+				ModelNames:        []string{"model1"},
+			},
+			expectError:   true,
+			errorContains: "stdin once",
+		},
+		{
+			name: "Missing resume directory",
+			config: &config.CliConfig{
+				InstructionsFile: tempFile.Name(),
+				Paths:            []string{"testfile"},
+				APIKey:           "test-key",
+				ModelNames:       []string{"model1"},
+				ResumeDir:        "/nonexistent/thinktank_run",
+			},
+			expectError:   true,
+			errorContains: "resume directory not found",
+		},
+		{
+			name: "Resume directory without a manifest",
+			config: &config.CliConfig{
+				InstructionsFile: tempFile.Name(),
+				Paths:            []string{"testfile"},
+				APIKey:           "test-key",
+				ModelNames:       []string{"model1"},
+				ResumeDir:        t.TempDir(),
+			},
+			expectError:   true,
+			errorContains: "resume manifest not found",
+		},
+		{
+			name: "Unknown context format",
+			config: &config.CliConfig{
+				InstructionsFile: tempFile.Name(),
+				Paths:            []string{"testfile"},
+				APIKey:           "test-key",
+				ModelNames:       []string{"model1"},
+				Format:           "html",
+			},
+			expectError:   true,
+			errorContains: "unknown format",
+		},
+		{
+			name: "Context format template",
+			config: &config.CliConfig{
+				InstructionsFile: tempFile.Name(),
+				Paths:            []string{"testfile"},
+				APIKey:           "test-key",
+				ModelNames:       []string{"model1"},
+				Format:           "### {path}\\n{content}",
+			},
+			expectError: false,
+		},
+		{
+			name: "Several sets of changes to review",
+			config: &config.CliConfig{
+				InstructionsFile: tempFile.Name(),
+				Paths:            []string{"testfile"},
+				APIKey:           "test-key",
+				ModelNames:       []string{"model1"},
+				DiffRange:        "main",
+				DiffSince:        "HEAD~3",
+			},
+			expectError:   true,
+			errorContains: "invalid diff options",
+		},
+		{
+			name: "Negative import depth",
+			config: &config.CliConfig{
+				InstructionsFile: tempFile.Name(),
+				Paths:            []string{"testfile"},
+				APIKey:           "test-key",
+				ModelNames:       []string{"model1"},
+				ImportDepth:      -1,
+			},
+			expectError:   true,
+			errorContains: "invalid import depth",
+		},
+		{
+			name: "Imports with a set of changes",
+			config: &config.CliConfig{
+				InstructionsFile: tempFile.Name(),
+				Paths:            []string{"testfile"},
+				APIKey:           "test-key",
+				ModelNames:       []string{"model1"},
+				DiffStaged:       true,
+				Importers:        true,
+			},
+			expectError:   true,
+			errorContains: "import expansion cannot be combined with reviewing changes",
+		},
 		// Synthesis model validation is tested in cli_synthesis_test.go and cli_pattern_test.go
 	}
 
@@ -191,3 +373,63 @@ func TestValidateInputs(t *testing.T) {
 		})
 	}
 }
+
+// TestValidateInputsLocalModel tests that the registry decides which models need an API
+// key: models of providers for local servers need none, whatever their names
+func TestValidateInputsLocalModel(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	configDir := filepath.Join(homeDir, registry.ConfigDirName)
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, registry.ModelsConfigFileName), []byte(`api_key_sources:
+  openai: "OPENAI_API_KEY"
+providers:
+  - name: openai
+  - name: workstation
+    type: openai-compatible
+    base_url: "http://localhost:8080/v1"
+models:
+  - name: gpt-4.1
+    provider: openai
+    api_model_id: gpt-4.1
+  - name: local-coder
+    provider: workstation
+    api_model_id: qwen2.5-coder
+`), 0644); err != nil {
+		t.Fatalf("Failed to write models.yaml: %v", err)
+	}
+	manager := registry.NewManager(nil)
+	if err := manager.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize registry: %v", err)
+	}
+
+	origGetManager := getRegistryManagerForValidation
+	getRegistryManagerForValidation = func(logger logutil.LoggerInterface) interface{} {
+		return manager
+	}
+	defer func() {
+		getRegistryManagerForValidation = origGetManager
+	}()
+
+	instructionsFile := filepath.Join(t.TempDir(), "instructions.md")
+	if err := os.WriteFile(instructionsFile, []byte("Review the code"), 0644); err != nil {
+		t.Fatalf("Failed to write instructions: %v", err)
+	}
+	noKeys := func(string) string { return "" }
+
+	cfg := &config.CliConfig{
+		InstructionsFile: instructionsFile,
+		Paths:            []string{"testfile"},
+		ModelNames:       []string{"local-coder"},
+	}
+	if err := ValidateInputsWithEnv(cfg, &errorTrackingLogger{}, noKeys); err != nil {
+		t.Errorf("Expected a local model to need no API key, got: %v", err)
+	}
+
+	cfg.ModelNames = []string{"local-coder", "gpt-4.1"}
+	if err := ValidateInputsWithEnv(cfg, &errorTrackingLogger{}, noKeys); err == nil || !strings.Contains(err.Error(), "openAI API key not set") {
+		t.Errorf("Expected an OpenAI model to need an API key, got: %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
 
 	"github.com/phrazzld/thinktank/internal/auditlog"
 	"github.com/phrazzld/thinktank/internal/logutil"
@@ -27,6 +28,11 @@ func Main() {
 	// Create a base context with timeout and correlation ID
 	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
 	defer cancel() // Ensure resources are released when Main exits
+
+	// Cancel the context on interrupt so in-flight (e.g., streaming) requests stop
+	// cleanly and any partial output already written is preserved
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
 	ctx = logutil.WithCorrelationID(ctx)
 
 	// Setup logging early for error reporting with context
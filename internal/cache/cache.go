@@ -0,0 +1,181 @@
+// Package cache provides a content-addressed, on-disk cache of LLM responses,
+// so that identical requests are not sent to a provider twice
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/phrazzld/thinktank/internal/llm"
+)
+
+// DirName is the directory, relative to the user's home directory, that holds the cache
+const DirName = ".cache/thinktank"
+
+// Key identifies a cached response. Two requests with the same key are
+// expected to produce equivalent responses.
+type Key struct {
+	ModelName  string                 `json:"model_name"`
+	APIModelID string                 `json:"api_model_id"`
+	Endpoint   string                 `json:"endpoint"`
+	Params     map[string]interface{} `json:"params"`
+	PromptHash string                 `json:"prompt_hash"`
+}
+
+// NewKey creates the cache key for a request. The endpoint is the API endpoint the
+// request is sent to, so that the same model served by different servers is cached
+// separately. Only a hash of the prompt is kept, and the parameters are copied so that
+// later changes to the map don't alter the key.
+func NewKey(modelName, apiModelID, endpoint string, params map[string]interface{}, prompt string) Key {
+	paramsCopy := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		paramsCopy[name] = value
+	}
+
+	promptHash := sha256.Sum256([]byte(prompt))
+	return Key{
+		ModelName:  modelName,
+		APIModelID: apiModelID,
+		Endpoint:   endpoint,
+		Params:     paramsCopy,
+		PromptHash: hex.EncodeToString(promptHash[:]),
+	}
+}
+
+// Hash returns the content address of the key. Parameters are included in a
+// canonical form, since JSON objects are encoded with sorted keys.
+func (k Key) Hash() (string, error) {
+	data, err := json.Marshal(k)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Entry is a response stored in the cache
+type Entry struct {
+	Key       Key                `json:"key"`
+	CreatedAt time.Time          `json:"created_at"`
+	Result    llm.ProviderResult `json:"result"`
+}
+
+// ResponseCache stores provider results as JSON files named by the hash of their key
+type ResponseCache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewResponseCache creates a cache rooted at dir. Entries older than ttl are
+// treated as missing; a ttl of 0 means entries never expire.
+func NewResponseCache(dir string, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{
+		dir: dir,
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+// DefaultDir returns the default cache directory, ~/.cache/thinktank
+func DefaultDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, DirName), nil
+}
+
+// Dir returns the directory the cache is stored in
+func (c *ResponseCache) Dir() string {
+	return c.dir
+}
+
+// path returns the file used for a key hash, sharded by its first two characters
+// to keep directories small
+func (c *ResponseCache) path(hash string) string {
+	return filepath.Join(c.dir, hash[:2], hash+".json")
+}
+
+// Get returns the entry stored for a key. It reports false if there is no
+// entry or the entry has expired; an entry that cannot be read is reported
+// as missing along with the error.
+func (c *ResponseCache) Get(key Key) (*Entry, bool, error) {
+	hash, err := key.Hash()
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, err := os.ReadFile(c.path(hash))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		// Treat corrupt entries as missing; they are overwritten by the next Put
+		return nil, false, fmt.Errorf("failed to decode cache entry %s: %w", hash, err)
+	}
+
+	if c.ttl > 0 && c.now().Sub(entry.CreatedAt) > c.ttl {
+		_ = os.Remove(c.path(hash))
+		return nil, false, nil
+	}
+
+	return &entry, true, nil
+}
+
+// Put stores a result for a key, replacing any existing entry. The file is
+// written atomically so that concurrent runs never read a partial entry.
+func (c *ResponseCache) Put(key Key, result *llm.ProviderResult) error {
+	if result == nil {
+		return fmt.Errorf("cannot cache a nil result")
+	}
+
+	hash, err := key.Hash()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(Entry{
+		Key:       key,
+		CreatedAt: c.now().UTC(),
+		Result:    *result,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	path := c.path(hash)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), hash+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpFile.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		_ = os.Remove(tmpFile.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		_ = os.Remove(tmpFile.Name())
+		return fmt.Errorf("failed to store cache entry: %w", err)
+	}
+
+	return nil
+}
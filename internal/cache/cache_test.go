@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestKeyHash tests that keys address requests by model, endpoint, parameters and prompt
+func TestKeyHash(t *testing.T) {
+	base := NewKey("model", "api-model", "", map[string]interface{}{"temperature": 0.7, "top_p": 0.9}, "prompt")
+	baseHash, err := base.Hash()
+	require.NoError(t, err)
+
+	// Parameter order does not matter
+	same := NewKey("model", "api-model", "", map[string]interface{}{"top_p": 0.9, "temperature": 0.7}, "prompt")
+	sameHash, err := same.Hash()
+	require.NoError(t, err)
+	assert.Equal(t, baseHash, sameHash)
+
+	variants := map[string]Key{
+		"model name":   NewKey("other-model", "api-model", "", map[string]interface{}{"temperature": 0.7, "top_p": 0.9}, "prompt"),
+		"api model ID": NewKey("model", "other-api-model", "", map[string]interface{}{"temperature": 0.7, "top_p": 0.9}, "prompt"),
+		"endpoint":     NewKey("model", "api-model", "http://localhost:8080/v1", map[string]interface{}{"temperature": 0.7, "top_p": 0.9}, "prompt"),
+		"parameters":   NewKey("model", "api-model", "", map[string]interface{}{"temperature": 0.2, "top_p": 0.9}, "prompt"),
+		"prompt":       NewKey("model", "api-model", "", map[string]interface{}{"temperature": 0.7, "top_p": 0.9}, "other prompt"),
+	}
+	for name, key := range variants {
+		hash, err := key.Hash()
+		require.NoError(t, err)
+		assert.NotEqual(t, baseHash, hash, "Changing the %s should change the key", name)
+	}
+}
+
+// TestNewKeyCopiesParams tests that changing the parameters after creating a key does not change it
+func TestNewKeyCopiesParams(t *testing.T) {
+	params := map[string]interface{}{"temperature": 0.7}
+	key := NewKey("model", "api-model", "", params, "prompt")
+	before, err := key.Hash()
+	require.NoError(t, err)
+
+	params["temperature"] = 0.1
+	after, err := key.Hash()
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+}
+
+// TestPutGet tests storing and retrieving a result
+func TestPutGet(t *testing.T) {
+	c := NewResponseCache(t.TempDir(), time.Hour)
+	key := NewKey("model", "api-model", "", nil, "prompt")
+
+	_, ok, err := c.Get(key)
+	require.NoError(t, err)
+	assert.False(t, ok, "Expected a miss for an empty cache")
+
+	result := &llm.ProviderResult{
+		Content:      "cached content",
+		FinishReason: "stop",
+		Usage:        &llm.TokenUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}
+	require.NoError(t, c.Put(key, result))
+
+	entry, ok, err := c.Get(key)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, *result, entry.Result)
+	assert.Equal(t, key, entry.Key)
+	assert.WithinDuration(t, time.Now(), entry.CreatedAt, time.Minute)
+
+	// No temporary files are left behind
+	files, err := filepath.Glob(filepath.Join(c.Dir(), "*", "*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+// TestGetExpired tests that entries older than the TTL are treated as missing and removed
+func TestGetExpired(t *testing.T) {
+	c := NewResponseCache(t.TempDir(), time.Hour)
+	key := NewKey("model", "api-model", "", nil, "prompt")
+
+	now := time.Now()
+	c.now = func() time.Time { return now.Add(-2 * time.Hour) }
+	require.NoError(t, c.Put(key, &llm.ProviderResult{Content: "stale"}))
+	c.now = func() time.Time { return now }
+
+	_, ok, err := c.Get(key)
+	require.NoError(t, err)
+	assert.False(t, ok, "Expected an expired entry to be a miss")
+
+	hash, err := key.Hash()
+	require.NoError(t, err)
+	_, err = os.Stat(c.path(hash))
+	assert.True(t, os.IsNotExist(err), "Expected the expired entry to be removed")
+}
+
+// TestGetWithoutTTL tests that entries never expire when the TTL is zero
+func TestGetWithoutTTL(t *testing.T) {
+	c := NewResponseCache(t.TempDir(), 0)
+	key := NewKey("model", "api-model", "", nil, "prompt")
+
+	now := time.Now()
+	c.now = func() time.Time { return now.Add(-365 * 24 * time.Hour) }
+	require.NoError(t, c.Put(key, &llm.ProviderResult{Content: "old"}))
+	c.now = func() time.Time { return now }
+
+	entry, ok, err := c.Get(key)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "old", entry.Result.Content)
+}
+
+// TestGetCorruptEntry tests that an unreadable entry is reported as a miss with an error
+func TestGetCorruptEntry(t *testing.T) {
+	c := NewResponseCache(t.TempDir(), time.Hour)
+	key := NewKey("model", "api-model", "", nil, "prompt")
+	require.NoError(t, c.Put(key, &llm.ProviderResult{Content: "content"}))
+
+	hash, err := key.Hash()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(c.path(hash), []byte("{not json"), 0600))
+
+	_, ok, err := c.Get(key)
+	assert.Error(t, err)
+	assert.False(t, ok)
+
+	// The next Put replaces the corrupt entry
+	require.NoError(t, c.Put(key, &llm.ProviderResult{Content: "content"}))
+	_, ok, err = c.Get(key)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+// TestPutNilResult tests that nil results are rejected
+func TestPutNilResult(t *testing.T) {
+	c := NewResponseCache(t.TempDir(), time.Hour)
+	assert.Error(t, c.Put(NewKey("model", "", "", nil, "prompt"), nil))
+}
@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/phrazzld/thinktank/internal/auditlog"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/logutil"
+)
+
+// Client wraps an LLM client so that responses to requests seen before are
+// served from the cache instead of the provider. Successful responses from the
+// provider are stored for later runs.
+type Client struct {
+	client      llm.LLMClient
+	cache       *ResponseCache
+	modelName   string
+	apiModelID  string
+	endpoint    string
+	auditLogger auditlog.AuditLogger
+	logger      logutil.LoggerInterface
+}
+
+// NewClient creates a caching client for a model. The API model ID and the
+// endpoint the client sends requests to are part of the cache key, so that
+// remapping a model name to a different provider model or server does not
+// return stale responses.
+func NewClient(
+	client llm.LLMClient,
+	cache *ResponseCache,
+	modelName string,
+	apiModelID string,
+	endpoint string,
+	auditLogger auditlog.AuditLogger,
+	logger logutil.LoggerInterface,
+) *Client {
+	if auditLogger == nil {
+		auditLogger = auditlog.NewNoOpAuditLogger()
+	}
+	if logger == nil {
+		logger = logutil.NewLogger(logutil.InfoLevel, nil, "[cache] ")
+	}
+
+	return &Client{
+		client:      client,
+		cache:       cache,
+		modelName:   modelName,
+		apiModelID:  apiModelID,
+		endpoint:    endpoint,
+		auditLogger: auditLogger,
+		logger:      logger,
+	}
+}
+
+// GenerateContent returns the cached result for the request if there is one,
+// and otherwise calls the wrapped client and caches its result
+func (c *Client) GenerateContent(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+	key := NewKey(c.modelName, c.apiModelID, c.endpoint, params, prompt)
+	if result, ok := c.lookup(key); ok {
+		return result, nil
+	}
+
+	result, err := c.client.GenerateContent(ctx, prompt, params)
+	if err == nil {
+		c.store(key, result)
+	}
+	return result, err
+}
+
+// GenerateContentStream returns the cached result as a single chunk if there is
+// one. Otherwise it streams from the wrapped client and caches the assembled
+// result once the stream completes successfully. A stream counts as complete
+// only if it ends with a final chunk carrying a finish reason before ctx is
+// cancelled, since a stream cut short is closed without an error chunk.
+func (c *Client) GenerateContentStream(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+	key := NewKey(c.modelName, c.apiModelID, c.endpoint, params, prompt)
+	if result, ok := c.lookup(key); ok {
+		return llm.StreamFromResult(result, nil), nil
+	}
+
+	streamingClient, ok := c.client.(llm.StreamingLLMClient)
+	if !ok {
+		result, err := c.client.GenerateContent(ctx, prompt, params)
+		if err == nil {
+			c.store(key, result)
+		}
+		return llm.StreamFromResult(result, err), nil
+	}
+
+	stream, err := streamingClient.GenerateContentStream(ctx, prompt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan llm.StreamChunk)
+	go func() {
+		defer close(out)
+
+		var content []byte
+		result := &llm.ProviderResult{}
+		finished := false
+		for chunk := range stream {
+			if !llm.SendChunk(ctx, out, chunk) {
+				return
+			}
+			if chunk.Err != nil {
+				return
+			}
+
+			content = append(content, chunk.Content...)
+			finished = chunk.FinishReason != ""
+			if chunk.FinishReason != "" {
+				result.FinishReason = chunk.FinishReason
+			}
+			if chunk.Truncated {
+				result.Truncated = true
+			}
+			if len(chunk.SafetyInfo) > 0 {
+				result.SafetyInfo = chunk.SafetyInfo
+			}
+			if chunk.Usage != nil {
+				result.Usage = chunk.Usage
+			}
+		}
+
+		if !finished || ctx.Err() != nil {
+			c.logger.Debug("Not caching incomplete response for model %s", c.modelName)
+			return
+		}
+		result.Content = string(content)
+		c.store(key, result)
+	}()
+
+	return out, nil
+}
+
+// GetModelName returns the name of the wrapped client's model
+func (c *Client) GetModelName() string {
+	return c.client.GetModelName()
+}
+
+// Close releases the resources of the wrapped client
+func (c *Client) Close() error {
+	return c.client.Close()
+}
+
+// lookup returns the cached result for a key and records the hit in the audit log.
+// Cache errors are logged and treated as misses, so that a broken cache never fails a run.
+func (c *Client) lookup(key Key) (*llm.ProviderResult, bool) {
+	entry, ok, err := c.cache.Get(key)
+	if err != nil {
+		c.logger.Warn("Ignoring unreadable response cache entry for model %s: %v", c.modelName, err)
+		return nil, false
+	}
+	if !ok {
+		c.logger.Debug("No cached response for model %s", c.modelName)
+		return nil, false
+	}
+
+	age := c.cache.now().Sub(entry.CreatedAt).Round(time.Second)
+	c.logger.Info("Using cached response for model %s (cached %s ago)", c.modelName, age)
+
+	outputs := map[string]interface{}{
+		"cached_at":      entry.CreatedAt,
+		"content_length": len(entry.Result.Content),
+	}
+	if entry.Result.Usage != nil {
+		outputs["saved_prompt_tokens"] = entry.Result.Usage.PromptTokens
+		outputs["saved_completion_tokens"] = entry.Result.Usage.CompletionTokens
+	}
+	inputs := map[string]interface{}{
+		"model_name":   c.modelName,
+		"api_model_id": c.apiModelID,
+		"endpoint":     c.endpoint,
+		"prompt_hash":  key.PromptHash,
+	}
+	if logErr := c.auditLogger.LogOp("CacheHit", "Success", inputs, outputs, nil); logErr != nil {
+		c.logger.Error("Failed to write audit log: %v", logErr)
+	}
+
+	// No tokens are spent on a cache hit, so no usage is reported for it
+	result := entry.Result
+	result.Usage = nil
+	return &result, true
+}
+
+// store caches a successful result. Empty responses are not cached, since they
+// are treated as errors and a later request may succeed.
+func (c *Client) store(key Key, result *llm.ProviderResult) {
+	if result == nil || result.Content == "" {
+		return
+	}
+	if err := c.cache.Put(key, result); err != nil {
+		c.logger.Warn("Failed to cache response for model %s: %v", c.modelName, err)
+		return
+	}
+	c.logger.Debug("Cached response for model %s", c.modelName)
+}
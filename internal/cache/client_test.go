@@ -0,0 +1,237 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phrazzld/thinktank/internal/auditlog"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAuditLogger records the operations logged through LogOp
+type recordingAuditLogger struct {
+	mu      sync.Mutex
+	entries []auditlog.AuditEntry
+}
+
+func (l *recordingAuditLogger) Log(entry auditlog.AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func (l *recordingAuditLogger) LogOp(operation, status string, inputs map[string]interface{}, outputs map[string]interface{}, err error) error {
+	return l.Log(auditlog.AuditEntry{Operation: operation, Status: status, Inputs: inputs, Outputs: outputs})
+}
+
+func (l *recordingAuditLogger) Close() error {
+	return nil
+}
+
+// operations returns the names of the logged operations
+func (l *recordingAuditLogger) operations() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var ops []string
+	for _, entry := range l.entries {
+		ops = append(ops, entry.Operation)
+	}
+	return ops
+}
+
+// countingLLMClient counts calls to the provider and returns a fixed result
+type countingLLMClient struct {
+	calls  int
+	result *llm.ProviderResult
+	err    error
+}
+
+func (c *countingLLMClient) GenerateContent(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+	c.calls++
+	return c.result, c.err
+}
+
+func (c *countingLLMClient) GetModelName() string { return "model" }
+
+func (c *countingLLMClient) Close() error { return nil }
+
+// streamingCountingLLMClient adds streaming to countingLLMClient
+type streamingCountingLLMClient struct {
+	countingLLMClient
+	chunks []llm.StreamChunk
+}
+
+func (c *streamingCountingLLMClient) GenerateContentStream(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+	c.calls++
+	stream := make(chan llm.StreamChunk, len(c.chunks))
+	for _, chunk := range c.chunks {
+		stream <- chunk
+	}
+	close(stream)
+	return stream, nil
+}
+
+// TestClientGenerateContent tests that repeated requests are served from the cache
+func TestClientGenerateContent(t *testing.T) {
+	inner := &countingLLMClient{result: &llm.ProviderResult{
+		Content:      "response",
+		FinishReason: "stop",
+		Usage:        &llm.TokenUsage{PromptTokens: 100, CompletionTokens: 20, TotalTokens: 120},
+	}}
+	auditLogger := &recordingAuditLogger{}
+	responseCache := NewResponseCache(t.TempDir(), time.Hour)
+	params := map[string]interface{}{"temperature": 0.7}
+
+	first, err := NewClient(inner, responseCache, "model", "api-model", "", auditLogger, nil).GenerateContent(context.Background(), "prompt", params)
+	require.NoError(t, err)
+	assert.Equal(t, "response", first.Content)
+	require.NotNil(t, first.Usage, "A provider response reports its usage")
+	assert.Empty(t, auditLogger.operations())
+
+	// A new client in a later run reuses the stored response
+	second, err := NewClient(inner, responseCache, "model", "api-model", "", auditLogger, nil).GenerateContent(context.Background(), "prompt", params)
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.calls, "Expected the second request to be served from the cache")
+	assert.Equal(t, "response", second.Content)
+	assert.Equal(t, "stop", second.FinishReason)
+	assert.Nil(t, second.Usage, "A cache hit spends no tokens")
+
+	require.Equal(t, []string{"CacheHit"}, auditLogger.operations())
+	hit := auditLogger.entries[0]
+	assert.Equal(t, "Success", hit.Status)
+	assert.Equal(t, "model", hit.Inputs["model_name"])
+	assert.Equal(t, int32(100), hit.Outputs["saved_prompt_tokens"])
+
+	// Different parameters are a different request
+	_, err = NewClient(inner, responseCache, "model", "api-model", "", auditLogger, nil).GenerateContent(context.Background(), "prompt", map[string]interface{}{"temperature": 0.2})
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+}
+
+// TestClientDoesNotCacheFailures tests that errors and empty responses are not cached
+func TestClientDoesNotCacheFailures(t *testing.T) {
+	responseCache := NewResponseCache(t.TempDir(), time.Hour)
+
+	failing := &countingLLMClient{err: errors.New("provider error")}
+	client := NewClient(failing, responseCache, "model", "", "", nil, nil)
+	for i := 0; i < 2; i++ {
+		_, err := client.GenerateContent(context.Background(), "prompt", nil)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 2, failing.calls)
+
+	empty := &countingLLMClient{result: &llm.ProviderResult{Content: "", FinishReason: "safety"}}
+	client = NewClient(empty, responseCache, "model", "", "", nil, nil)
+	for i := 0; i < 2; i++ {
+		_, err := client.GenerateContent(context.Background(), "prompt", nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, empty.calls)
+}
+
+// TestClientGenerateContentStream tests caching of streamed responses
+func TestClientGenerateContentStream(t *testing.T) {
+	inner := &streamingCountingLLMClient{chunks: []llm.StreamChunk{
+		{Content: "Hello"},
+		{Content: " world"},
+		{FinishReason: "stop", Usage: &llm.TokenUsage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7}},
+	}}
+	responseCache := NewResponseCache(t.TempDir(), time.Hour)
+	client := NewClient(inner, responseCache, "model", "api-model", "", nil, nil)
+
+	stream, err := client.GenerateContentStream(context.Background(), "prompt", nil)
+	require.NoError(t, err)
+	var deltas []string
+	result, err := llm.CollectStream(context.Background(), stream, func(delta string) { deltas = append(deltas, delta) })
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Hello", " world"}, deltas, "Expected chunks to be passed through as they arrive")
+	assert.Equal(t, "Hello world", result.Content)
+
+	// The assembled stream is served from the cache, both streamed and not
+	stream, err = client.GenerateContentStream(context.Background(), "prompt", nil)
+	require.NoError(t, err)
+	result, err = llm.CollectStream(context.Background(), stream, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello world", result.Content)
+	assert.Equal(t, "stop", result.FinishReason)
+
+	result, err = client.GenerateContent(context.Background(), "prompt", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello world", result.Content)
+	assert.Equal(t, 1, inner.calls)
+}
+
+// TestClientGenerateContentStreamError tests that failed streams are not cached
+func TestClientGenerateContentStreamError(t *testing.T) {
+	inner := &streamingCountingLLMClient{chunks: []llm.StreamChunk{
+		{Content: "Partial"},
+		{Err: errors.New("connection reset")},
+	}}
+	client := NewClient(inner, NewResponseCache(t.TempDir(), time.Hour), "model", "", "", nil, nil)
+
+	for i := 0; i < 2; i++ {
+		stream, err := client.GenerateContentStream(context.Background(), "prompt", nil)
+		require.NoError(t, err)
+		_, err = llm.CollectStream(context.Background(), stream, nil)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 2, inner.calls)
+}
+
+// TestClientGenerateContentStreamIncomplete tests that streams cut short are not cached
+func TestClientGenerateContentStreamIncomplete(t *testing.T) {
+	t.Run("Closed without a final chunk", func(t *testing.T) {
+		// Producers close the stream without an error chunk when they are cancelled
+		inner := &streamingCountingLLMClient{chunks: []llm.StreamChunk{{Content: "Partial"}}}
+		client := NewClient(inner, NewResponseCache(t.TempDir(), time.Hour), "model", "", "", nil, nil)
+
+		for i := 0; i < 2; i++ {
+			stream, err := client.GenerateContentStream(context.Background(), "prompt", nil)
+			require.NoError(t, err)
+			_, err = llm.CollectStream(context.Background(), stream, nil)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, inner.calls)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		inner := &streamingCountingLLMClient{chunks: []llm.StreamChunk{
+			{Content: "Hello"},
+			{FinishReason: "stop"},
+		}}
+		responseCache := NewResponseCache(t.TempDir(), time.Hour)
+		client := NewClient(inner, responseCache, "model", "", "", nil, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		stream, err := client.GenerateContentStream(ctx, "prompt", nil)
+		require.NoError(t, err)
+		for range stream {
+		}
+
+		_, ok, err := responseCache.Get(NewKey("model", "", "", nil, "prompt"))
+		require.NoError(t, err)
+		assert.False(t, ok, "Expected a cancelled stream not to be cached")
+	})
+}
+
+// TestClientGenerateContentStreamWithoutStreaming tests streaming through a client that cannot stream
+func TestClientGenerateContentStreamWithoutStreaming(t *testing.T) {
+	inner := &countingLLMClient{result: &llm.ProviderResult{Content: "whole response"}}
+	client := NewClient(inner, NewResponseCache(t.TempDir(), time.Hour), "model", "", "", nil, nil)
+
+	for i := 0; i < 2; i++ {
+		stream, err := client.GenerateContentStream(context.Background(), "prompt", nil)
+		require.NoError(t, err)
+		result, err := llm.CollectStream(context.Background(), stream, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "whole response", result.Content)
+	}
+	assert.Equal(t, 1, inner.calls)
+}
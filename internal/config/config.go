@@ -7,6 +7,7 @@ package config
 
 import (
 	"fmt"
+	"github.com/phrazzld/thinktank/internal/fileutil"
 	"github.com/phrazzld/thinktank/internal/logutil"
 	"os"
 	"strings"
@@ -22,7 +23,8 @@ const (
 	APIEndpointEnvVar      = "GEMINI_API_URL"
 	OpenAIAPIKeyEnvVar     = "OPENAI_API_KEY"
 	OpenRouterAPIKeyEnvVar = "OPENROUTER_API_KEY"
-	DefaultFormat          = "<{path}>\n```\n{content}\n```\n</{path}>\n\n"
+	AnthropicAPIKeyEnvVar  = "ANTHROPIC_API_KEY"
+	DefaultFormat          = "xml" // Built-in context format wrapping each file's path in <path> tags
 
 	// Default rate limiting values
 	DefaultMaxConcurrentRequests      = 5  // Default maximum concurrent API requests
@@ -31,6 +33,29 @@ const (
 	// Default timeout value
 	DefaultTimeout = 10 * time.Minute // Default timeout for the entire operation
 
+	// Default context packing strategy used when the context exceeds the token budget
+	DefaultPackStrategy = "relevance"
+
+	// Default number of bytes read from each context file; larger files are truncated
+	DefaultMaxFileSize = 1 << 20
+
+	// Default time-to-live of cached model responses
+	DefaultCacheTTL = 24 * time.Hour
+
+	// Default retry policy for transient provider errors
+	DefaultRetryMaxAttempts    = 3                // Total attempts per request, including the first
+	DefaultRetryInitialBackoff = 2 * time.Second  // Delay before the first retry, doubled per attempt
+	DefaultRetryMaxBackoff     = 60 * time.Second // Upper bound on the delay between attempts
+	DefaultRetryJitter         = 0.2              // Fraction by which delays are randomized
+	DefaultCritiqueRounds      = 1                // Critique-and-refine rounds per model output
+
+	// StdinInstructions is the instructions file name that reads the instructions from stdin
+	StdinInstructions = "-"
+
+	// Formats of the run summary written to stdout
+	OutputFormatText = "text" // No summary; results are only written to the output directory
+	OutputFormatJSON = "json" // A single JSON document describing the run
+
 	// Default permission values
 	DefaultDirPermissions  = 0750 // Default directory permissions (rwxr-x---)
 	DefaultFilePermissions = 0640 // Default file permissions (rw-r-----)
@@ -96,12 +121,41 @@ func DefaultConfig() *AppConfig {
 // configuration parameters rather than having them parse flags directly.
 type CliConfig struct {
 	// Instructions configuration
-	InstructionsFile string
+	// The instructions are the contents of InstructionsFiles, in order, followed by Prompt.
+	// A file named StdinInstructions is read from stdin. InstructionsFile is the first of
+	// InstructionsFiles.
+	InstructionsFile  string
+	InstructionsFiles []string
+	Prompt            string // Instructions given inline with --prompt
+
+	// Instructions templates
+	// When TemplateInstructions is set, the instructions are executed as a Go text/template
+	// with TemplateVars (from --var and --vars-file) and built-in variables such as the
+	// context files, and can include the snippets in TemplateDir by file name.
+	TemplateInstructions bool
+	TemplateVars         map[string]string
+	TemplateDir          string
+
+	// System prompt
+	// SystemPrompt (read from SystemPromptFile) is sent as the system prompt to each model
+	// processing the instructions, overriding the system_prompt of the model in models.yaml.
+	SystemPromptFile string
+	SystemPrompt     string
 
 	// Output configuration
 	OutputDir    string
 	AuditLogFile string // Path to write structured audit logs (JSON Lines)
 	Format       string
+	// Tree prepends a directory tree of the context files, including the directories left
+	// out of the context, to the files in prompts
+	Tree bool
+	// OutputFormat selects what is written to stdout when the run completes: nothing for
+	// OutputFormatText, or a JSON report of the run for OutputFormatJSON.
+	OutputFormat string
+	// ResumeDir is the output directory of a previous run to resume. Models whose outputs
+	// were saved by that run are not processed again; the others are, and their outputs
+	// are saved to the same directory, which is also OutputDir.
+	ResumeDir string
 
 	// Context gathering options
 	Paths        []string
@@ -110,16 +164,79 @@ type CliConfig struct {
 	ExcludeNames string
 	DryRun       bool
 	Verbose      bool
+	// PackStrategy ranks files ("relevance", "recency" or "size") when the gathered context
+	// exceeds the token budget of the selected models; lower-ranked files are truncated or omitted.
+	PackStrategy string
+	// Change review options
+	// When one of DiffRange, DiffStaged or DiffSince is set, the context holds the unified
+	// diff of the changes (within Paths) and the current contents of the changed files,
+	// plus up to DiffNeighbors other files from the directory of each changed file.
+	DiffRange     string // Base revision or range of --diff
+	DiffStaged    bool
+	DiffSince     string
+	DiffNeighbors int
+	// Go import expansion options
+	// The Go files and packages in Paths are joined by the local packages they import, up to
+	// ImportDepth levels, and by the local packages importing them when Importers is set.
+	ImportDepth int
+	Importers   bool
+	// Size limits in bytes (0 means no limit): files larger than MaxFileSize are truncated to
+	// their head and tail, and files beyond MaxTotalSize are skipped. Generated files (lock
+	// files, files marked as generated and minified code) are skipped unless IncludeGenerated.
+	MaxFileSize      int64
+	MaxTotalSize     int64
+	IncludeGenerated bool
 
 	// API configuration
 	APIKey      string
 	APIEndpoint string
 	ModelNames  []string
+	// ModelFallbacks maps a model in ModelNames to the models to try, in order, when it fails
+	// with an error another model may not have (authentication, unknown model, quota or input
+	// limit). Fallbacks given here take precedence over those declared in models.yaml.
+	ModelFallbacks map[string][]string
 	// SynthesisModel specifies the model to use for combining (synthesizing) outputs from multiple models.
 	// When specified, all individual model outputs will be sent to this model along with original instructions,
 	// and the synthesis model will generate a consolidated result combining insights from all models.
 	// The synthesized output will be saved with the format `<synthesis-model-name>-synthesis.md`.
 	SynthesisModel string
+	// WorkflowFile is the path to a YAML workflow definition. When set, the workflow's steps
+	// are run instead of sending the prompt to each model, and ModelNames holds every model
+	// the workflow uses.
+	WorkflowFile string
+
+	// Critique-and-refine configuration
+	// When CritiqueRefine is enabled, each model output is reviewed by CriticModel against the
+	// instructions and then revised by RefineModel, for up to CritiqueRounds rounds. An empty
+	// CriticModel or RefineModel means the model that produced the output. Synthesis, if used,
+	// combines the revised outputs.
+	CritiqueRefine bool
+	CriticModel    string
+	RefineModel    string
+	CritiqueRounds int
+
+	// Streaming configuration
+	// When Stream is enabled, model responses are written to their output files as they are
+	// generated, and echoed to the terminal when only a single model is used.
+	Stream bool
+
+	// Response cache configuration
+	// Identical requests (same model, parameters and prompt) are served from the cache in
+	// CacheDir for CacheTTL instead of being sent to the provider again. The cache is disabled
+	// when NoCache is set or CacheDir is empty; a CacheTTL of 0 keeps entries indefinitely.
+	NoCache  bool
+	CacheDir string
+	CacheTTL time.Duration
+
+	// Retry configuration
+	// Requests failing with rate limit, server or network errors are retried up to
+	// RetryMaxAttempts times in total, waiting for the provider's Retry-After delay if
+	// there is one, or an exponential backoff between RetryInitialBackoff and
+	// RetryMaxBackoff randomized by RetryJitter. A RetryMaxAttempts of 1 disables retries.
+	RetryMaxAttempts    int
+	RetryInitialBackoff time.Duration
+	RetryMaxBackoff     time.Duration
+	RetryJitter         float64
 
 	// Token management field removed as part of T032E
 
@@ -145,30 +262,87 @@ type CliConfig struct {
 func NewDefaultCliConfig() *CliConfig {
 	return &CliConfig{
 		Format:                     DefaultFormat,
+		OutputFormat:               OutputFormatText,
 		Exclude:                    DefaultExcludes,
 		ExcludeNames:               DefaultExcludeNames,
+		PackStrategy:               DefaultPackStrategy,
+		MaxFileSize:                DefaultMaxFileSize,
 		ModelNames:                 []string{DefaultModel},
 		LogLevel:                   logutil.InfoLevel,
 		MaxConcurrentRequests:      DefaultMaxConcurrentRequests,
 		RateLimitRequestsPerMinute: DefaultRateLimitRequestsPerMinute,
 		Timeout:                    DefaultTimeout,
+		CacheTTL:                   DefaultCacheTTL,
+		RetryMaxAttempts:           DefaultRetryMaxAttempts,
+		RetryInitialBackoff:        DefaultRetryInitialBackoff,
+		RetryMaxBackoff:            DefaultRetryMaxBackoff,
+		RetryJitter:                DefaultRetryJitter,
+		CritiqueRounds:             DefaultCritiqueRounds,
 		DirPermissions:             DefaultDirPermissions,
 		FilePermissions:            DefaultFilePermissions,
 	}
 }
 
+// InstructionSources returns the files the instructions are read from, in order
+func (c *CliConfig) InstructionSources() []string {
+	if len(c.InstructionsFiles) > 0 {
+		return c.InstructionsFiles
+	}
+	if c.InstructionsFile != "" {
+		return []string{c.InstructionsFile}
+	}
+	return nil
+}
+
+// DescribeInstructions names the sources of the instructions, in order, for logs and reports
+func (c *CliConfig) DescribeInstructions() []string {
+	var names []string
+	for _, path := range c.InstructionSources() {
+		if path == StdinInstructions {
+			path = "stdin"
+		}
+		names = append(names, path)
+	}
+	if c.Prompt != "" {
+		names = append(names, "--prompt")
+	}
+	return names
+}
+
+// HasInstructions reports whether instructions were given in a file or inline
+func (c *CliConfig) HasInstructions() bool {
+	return len(c.InstructionSources()) > 0 || c.Prompt != ""
+}
+
+// Diff returns the changes to review selected by DiffRange, DiffStaged and DiffSince
+func (c *CliConfig) Diff() fileutil.DiffOptions {
+	return fileutil.DiffOptions{
+		Range:     c.DiffRange,
+		Staged:    c.DiffStaged,
+		Since:     c.DiffSince,
+		Neighbors: c.DiffNeighbors,
+	}
+}
+
+// Imports returns how the Go files and packages in Paths are expanded
+func (c *CliConfig) Imports() fileutil.ImportOptions {
+	return fileutil.ImportOptions{Depth: c.ImportDepth, Importers: c.Importers}
+}
+
 // ValidateConfig checks if the configuration is valid and returns an error if not.
 // It performs validation beyond simple type-checking, such as verifying that
 // required fields are present, paths exist, and values are within acceptable ranges.
 // This helps catch configuration errors early before they cause runtime failures.
+// Every model is assumed to need an API key (see ValidateConfigWithEnv).
 func ValidateConfig(config *CliConfig, logger logutil.LoggerInterface) error {
-	return ValidateConfigWithEnv(config, logger, os.Getenv)
+	return ValidateConfigWithEnv(config, logger, os.Getenv, nil)
 }
 
 // ValidateConfigWithEnv checks if the configuration is valid and returns an error if not.
 // This version takes a getenv function for easier testing by allowing environment variables
-// to be mocked.
-func ValidateConfigWithEnv(config *CliConfig, logger logutil.LoggerInterface, getenv func(string) string) error {
+// to be mocked. requiresAPIKey reports whether a model needs an API key, which callers
+// that know the models' providers decide; when it is nil, every model needs one.
+func ValidateConfigWithEnv(config *CliConfig, logger logutil.LoggerInterface, getenv func(string) string, requiresAPIKey func(model string) bool) error {
 	// Handle nil config
 	if config == nil {
 		if logger != nil {
@@ -200,25 +374,31 @@ func ValidateConfigWithEnv(config *CliConfig, logger logutil.LoggerInterface, ge
 		return fmt.Errorf("no paths specified")
 	}
 
-	// Check for instructions file (required unless in dry run mode)
-	if config.InstructionsFile == "" && !config.DryRun {
-		logError("The required --instructions flag is missing.")
-		return fmt.Errorf("missing required --instructions flag")
+	// Check for instructions (required unless in dry run mode)
+	if !config.HasInstructions() && !config.DryRun {
+		logError("The required --instructions flag (or --prompt) is missing.")
+		return fmt.Errorf("missing required --instructions flag or --prompt")
 	}
 
 	// Check for API key based on model configuration
 	modelNeedsOpenAIKey := false
 	modelNeedsGeminiKey := false
 	modelNeedsOpenRouterKey := false
+	modelNeedsAnthropicKey := false
 
-	// Check if any model is OpenAI, Gemini, or OpenRouter
+	// Check if any model is OpenAI, Gemini, OpenRouter, or Anthropic
 	for _, model := range config.ModelNames {
+		if requiresAPIKey != nil && !requiresAPIKey(model) {
+			continue
+		}
 		if strings.HasPrefix(strings.ToLower(model), "gpt-") ||
 			strings.HasPrefix(strings.ToLower(model), "text-") ||
 			strings.Contains(strings.ToLower(model), "openai") {
 			modelNeedsOpenAIKey = true
 		} else if strings.Contains(strings.ToLower(model), "openrouter") {
 			modelNeedsOpenRouterKey = true
+		} else if strings.HasPrefix(strings.ToLower(model), "claude-") {
+			modelNeedsAnthropicKey = true
 		} else {
 			// Default to Gemini for any other model
 			modelNeedsGeminiKey = true
@@ -249,6 +429,15 @@ func ValidateConfigWithEnv(config *CliConfig, logger logutil.LoggerInterface, ge
 		}
 	}
 
+	// If any Anthropic model is used, check for Anthropic API key
+	if modelNeedsAnthropicKey {
+		anthropicKey := getenv(AnthropicAPIKeyEnvVar)
+		if anthropicKey == "" {
+			logError("%s environment variable not set.", AnthropicAPIKeyEnvVar)
+			return fmt.Errorf("anthropic API key not set")
+		}
+	}
+
 	// Check for model names (required unless in dry run mode)
 	if len(config.ModelNames) == 0 && !config.DryRun {
 		logError("At least one model must be specified with --model flag.")
@@ -46,6 +46,26 @@ func TestNewDefaultCliConfig(t *testing.T) {
 		t.Errorf("Expected RateLimitRequestsPerMinute to be %d, got %d", DefaultRateLimitRequestsPerMinute, cfg.RateLimitRequestsPerMinute)
 	}
 
+	if cfg.RetryMaxAttempts != DefaultRetryMaxAttempts {
+		t.Errorf("Expected RetryMaxAttempts to be %d, got %d", DefaultRetryMaxAttempts, cfg.RetryMaxAttempts)
+	}
+
+	if cfg.RetryInitialBackoff != DefaultRetryInitialBackoff || cfg.RetryMaxBackoff != DefaultRetryMaxBackoff {
+		t.Errorf("Expected retry backoff between %s and %s, got %s and %s",
+			DefaultRetryInitialBackoff, DefaultRetryMaxBackoff, cfg.RetryInitialBackoff, cfg.RetryMaxBackoff)
+	}
+
+	if cfg.RetryJitter != DefaultRetryJitter {
+		t.Errorf("Expected RetryJitter to be %g, got %g", DefaultRetryJitter, cfg.RetryJitter)
+	}
+	if cfg.CritiqueRefine || cfg.CritiqueRounds != DefaultCritiqueRounds {
+		t.Errorf("Expected critique-and-refine to be disabled with %d round(s), got %v with %d",
+			DefaultCritiqueRounds, cfg.CritiqueRefine, cfg.CritiqueRounds)
+	}
+	if cfg.OutputFormat != OutputFormatText {
+		t.Errorf("Expected OutputFormat to be %q, got %q", OutputFormatText, cfg.OutputFormat)
+	}
+
 	// Check that uninitialized fields have zero/empty values
 	if cfg.InstructionsFile != "" {
 		t.Errorf("Expected InstructionsFile to be empty, got %q", cfg.InstructionsFile)
@@ -251,6 +271,17 @@ func TestValidateConfig(t *testing.T) {
 			expectError:   true,
 			errorContains: "missing required --instructions flag",
 		},
+		{
+			name: "Inline prompt without instructions file",
+			config: &CliConfig{
+				Prompt:     "Review the code",
+				Paths:      []string{"testfile"},
+				APIKey:     "test-key",
+				ModelNames: []string{"model1"},
+			},
+			logger:      &MockLogger{},
+			expectError: false,
+		},
 		{
 			name: "Missing paths",
 			config: &CliConfig{
@@ -582,13 +613,44 @@ func TestValidateConfigWithEnv(t *testing.T) {
 			expectError:   true,
 			errorContains: "openAI API key not set",
 		},
+		{
+			name: "Anthropic model requires Anthropic API key",
+			config: &CliConfig{
+				InstructionsFile: "instructions.md",
+				Paths:            []string{"testfile"},
+				ModelNames:       []string{"claude-sonnet-4"},
+			},
+			mockGetenv: func(key string) string {
+				if key == AnthropicAPIKeyEnvVar {
+					return ""
+				}
+				return "mock-value"
+			},
+			expectError:   true,
+			errorContains: "anthropic API key not set",
+		},
+		{
+			name: "Anthropic model with valid Anthropic API key and no Gemini key",
+			config: &CliConfig{
+				InstructionsFile: "instructions.md",
+				Paths:            []string{"testfile"},
+				ModelNames:       []string{"claude-sonnet-4"},
+			},
+			mockGetenv: func(key string) string {
+				if key == AnthropicAPIKeyEnvVar {
+					return "sk-ant-valid-key"
+				}
+				return ""
+			},
+			expectError: false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			logger := &MockLogger{}
 
-			err := ValidateConfigWithEnv(tt.config, logger, tt.mockGetenv)
+			err := ValidateConfigWithEnv(tt.config, logger, tt.mockGetenv, nil)
 
 			// Check if error matches expectation
 			if (err != nil) != tt.expectError {
@@ -614,6 +676,29 @@ func TestValidateConfigWithEnv(t *testing.T) {
 	}
 }
 
+// TestValidateConfigWithEnvRequiresAPIKey tests that models the caller reports as not
+// needing an API key are validated without one
+func TestValidateConfigWithEnvRequiresAPIKey(t *testing.T) {
+	noKeys := func(string) string { return "" }
+	local := func(model string) bool { return model != "local-coder" }
+	config := &CliConfig{
+		InstructionsFile: "instructions.md",
+		Paths:            []string{"testfile"},
+		ModelNames:       []string{"local-coder"},
+	}
+	if err := ValidateConfigWithEnv(config, &MockLogger{}, noKeys, local); err != nil {
+		t.Errorf("Expected a local model to need no API key, got: %v", err)
+	}
+	if err := ValidateConfigWithEnv(config, &MockLogger{}, noKeys, nil); err == nil {
+		t.Error("Expected every model to need an API key without requiresAPIKey")
+	}
+
+	config.ModelNames = []string{"local-coder", "gpt-4.1"}
+	if err := ValidateConfigWithEnv(config, &MockLogger{}, noKeys, local); err == nil || !strings.Contains(err.Error(), "openAI API key not set") {
+		t.Errorf("Expected an OpenAI model to need an API key, got: %v", err)
+	}
+}
+
 // TestValidateConfigWithNilConfig tests the specific case of a nil config
 func TestValidateConfigWithNilConfig(t *testing.T) {
 	logger := &MockLogger{}
@@ -636,3 +721,20 @@ func TestValidateConfigWithNilConfig(t *testing.T) {
 		t.Error("Expected error to be logged, but no error was logged")
 	}
 }
+
+// TestDescribeInstructions tests naming the sources of the instructions
+func TestDescribeInstructions(t *testing.T) {
+	cfg := &CliConfig{InstructionsFiles: []string{"style.md", StdinInstructions}, Prompt: "Review"}
+	if got := strings.Join(cfg.DescribeInstructions(), ", "); got != "style.md, stdin, --prompt" {
+		t.Errorf("Unexpected sources: %s", got)
+	}
+
+	// A configuration with only InstructionsFile set reads that file
+	cfg = &CliConfig{InstructionsFile: "task.md"}
+	if got := strings.Join(cfg.InstructionSources(), ", "); got != "task.md" || !cfg.HasInstructions() {
+		t.Errorf("Expected task.md, got %q", got)
+	}
+	if (&CliConfig{}).HasInstructions() {
+		t.Error("Expected no instructions in an empty configuration")
+	}
+}
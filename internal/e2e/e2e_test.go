@@ -442,6 +442,10 @@ func (e *TestEnv) RunThinktank(args []string, stdin io.Reader) (stdout, stderr s
 	stdoutBuf := &bytes.Buffer{}
 	stderrBuf := &bytes.Buffer{}
 
+	// Disable the response cache, since each test serves different responses
+	// for the same models and prompts from its mock server
+	args = append([]string{"--no-cache"}, args...)
+
 	// Create the command
 	cmd := exec.Command(e.BinaryPath, args...)
 
@@ -1,6 +1,7 @@
 package fileutil
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -197,7 +198,7 @@ func TestGatherProjectContext(t *testing.T) {
 			config := NewConfig(true, tt.include, tt.exclude, tt.excludeNames, "<{path}>\n{content}\n</{path}>", logger)
 
 			// Gather context
-			files, processedFiles, err := GatherProjectContext(tt.paths, config)
+			files, processedFiles, err := GatherProjectContext(context.Background(), tt.paths, config)
 			if err != nil {
 				t.Fatalf("GatherProjectContext returned error: %v", err)
 			}
@@ -265,7 +266,7 @@ func TestFileCollector(t *testing.T) {
 	config.SetFileCollector(collector)
 
 	// Gather context
-	files, processedFiles, err := GatherProjectContext([]string{testDir}, config)
+	files, processedFiles, err := GatherProjectContext(context.Background(), []string{testDir}, config)
 	if err != nil {
 		t.Fatalf("GatherProjectContext returned error: %v", err)
 	}
@@ -342,7 +343,7 @@ func TestFileMetaContent(t *testing.T) {
 	config := NewConfig(true, "", "", "", "", logger)
 
 	// Test with specific files to verify exact content
-	files, processedFiles, err := GatherProjectContext(specificFiles, config)
+	files, processedFiles, err := GatherProjectContext(context.Background(), specificFiles, config)
 	if err != nil {
 		t.Fatalf("GatherProjectContext returned error: %v", err)
 	}
@@ -399,7 +400,7 @@ func TestEmptyAndEdgeCases(t *testing.T) {
 			logger := NewMockLogger()
 			config := NewConfig(true, "", "", "", "", logger)
 
-			files, processedFiles, err := GatherProjectContext(tt.paths, config)
+			files, processedFiles, err := GatherProjectContext(context.Background(), tt.paths, config)
 			// Error should not be returned even for invalid paths
 			if err != nil {
 				t.Fatalf("GatherProjectContext returned error: %v", err)
@@ -438,7 +439,7 @@ func TestFileOrderPreservation(t *testing.T) {
 	config := NewConfig(true, "", "", "", "", logger)
 
 	// Gather context with our specific order
-	files, processedFiles, err := GatherProjectContext(orderedPaths, config)
+	files, processedFiles, err := GatherProjectContext(context.Background(), orderedPaths, config)
 	if err != nil {
 		t.Fatalf("GatherProjectContext returned error: %v", err)
 	}
@@ -488,7 +489,7 @@ func TestPathNormalization(t *testing.T) {
 	config := NewConfig(true, ".go", "", "", "", logger)
 
 	// Test with the relative path
-	files, processedFiles, err := GatherProjectContext([]string{relPath}, config)
+	files, processedFiles, err := GatherProjectContext(context.Background(), []string{relPath}, config)
 	if err != nil {
 		t.Fatalf("GatherProjectContext returned error: %v", err)
 	}
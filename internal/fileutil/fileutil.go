@@ -3,9 +3,10 @@ package fileutil
 
 import (
 	"bytes"
+	"context"
 	"log"
 	"os"
-	"os/exec"
+	"path"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -18,61 +19,62 @@ import (
 type FileMeta struct {
 	Path    string
 	Content string
+	// Size is the size of the file on disk in bytes, which exceeds the length of Content
+	// when the file was truncated (0 when the content was not read from a file)
+	Size int64
 }
 
 // Config holds file processing configuration
 type Config struct {
-	Verbose        bool
-	IncludeExts    []string
-	ExcludeExts    []string
-	ExcludeNames   []string
-	Format         string
-	Logger         logutil.LoggerInterface
-	GitAvailable   bool
-	processedFiles int
-	totalFiles     int               // For verbose logging
-	fileCollector  func(path string) // Optional callback to collect processed file paths
+	Verbose      bool
+	IncludeExts  []string
+	ExcludeExts  []string
+	ExcludeNames []string // File and directory names to skip, which may be glob patterns
+	// IncludePatterns and ExcludePatterns are the glob patterns of --include and --exclude,
+	// such as "*_test.go" (matched against file names) or "internal/**/*.go" (matched
+	// against paths relative to the current directory)
+	IncludePatterns []string
+	ExcludePatterns []string
+	Format          string
+	Logger          logutil.LoggerInterface
+	// MaxFileSize and MaxTotalSize limit the bytes read from each file and from all files
+	// (0 means no limit). Larger files are truncated to their head and tail; once the total
+	// is reached, further files are skipped.
+	MaxFileSize  int64
+	MaxTotalSize int64
+	// IncludeGenerated keeps generated files (lock files, files marked as generated and
+	// minified code), which are skipped by default
+	IncludeGenerated bool
+	processedFiles   int
+	totalFiles       int               // For verbose logging
+	fileCollector    func(path string) // Optional callback to collect processed file paths
+	ignoreRules      []*IgnoreRules    // Rules of the .thinktankignore files found so far
+	ignoreFiles      map[string]bool   // Ignore files already loaded, by path
+	gitIgnore        *gitIgnoreMatcher // Created on first use
+	totalBytes       int64             // Bytes of file content gathered so far
+	skipped          []SkippedFile     // Files skipped as generated or over the total size limit
+	truncated        []string          // Files truncated to the size limits
+	omittedDirs      []string          // Directories skipped while walking
+	workDir          string            // Current directory, which relative patterns are matched in
+	progressReporter func(read, found int)
 }
 
 // NewConfig creates a configuration with defaults.
 func NewConfig(verbose bool, include, exclude, excludeNames, format string, logger logutil.LoggerInterface) *Config {
-	// Check if git is available
-	_, gitErr := exec.LookPath("git")
-	gitAvailable := gitErr == nil
-
 	if logger == nil {
 		stdLogger := log.New(os.Stderr, "[fileutil] ", log.LstdFlags)
 		logger = logutil.NewStdLoggerAdapter(stdLogger)
 	}
 
 	cfg := &Config{
-		Verbose:      verbose,
-		Format:       format,
-		Logger:       logger,
-		GitAvailable: gitAvailable,
-	}
-
-	// Process include/exclude extensions
-	if include != "" {
-		cfg.IncludeExts = strings.Split(include, ",")
-		for i, ext := range cfg.IncludeExts {
-			ext = strings.TrimSpace(ext)
-			if !strings.HasPrefix(ext, ".") {
-				ext = "." + ext
-			}
-			cfg.IncludeExts[i] = strings.ToLower(ext)
-		}
-	}
-	if exclude != "" {
-		cfg.ExcludeExts = strings.Split(exclude, ",")
-		for i, ext := range cfg.ExcludeExts {
-			ext = strings.TrimSpace(ext)
-			if !strings.HasPrefix(ext, ".") {
-				ext = "." + ext
-			}
-			cfg.ExcludeExts[i] = strings.ToLower(ext)
-		}
+		Verbose: verbose,
+		Format:  format,
+		Logger:  logger,
 	}
+
+	// Process include/exclude extensions and patterns
+	cfg.IncludeExts, cfg.IncludePatterns = parseFilters(include)
+	cfg.ExcludeExts, cfg.ExcludePatterns = parseFilters(exclude)
 	// Process exclude names
 	if excludeNames != "" {
 		cfg.ExcludeNames = strings.Split(excludeNames, ",")
@@ -84,43 +86,182 @@ func NewConfig(verbose bool, include, exclude, excludeNames, format string, logg
 	return cfg
 }
 
+// parseFilters splits a comma-separated --include or --exclude value into file extensions
+// (normalized to lower case with a leading dot) and glob patterns
+func parseFilters(value string) (exts, patterns []string) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if isGlobPattern(entry) {
+			patterns = append(patterns, strings.TrimSuffix(entry, "/"))
+			continue
+		}
+		if !strings.HasPrefix(entry, ".") {
+			entry = "." + entry
+		}
+		exts = append(exts, strings.ToLower(entry))
+	}
+	return exts, patterns
+}
+
+// loadIgnoreFile adds the rules of the .thinktankignore file in dir, if there is one
+func (c *Config) loadIgnoreFile(dir string) {
+	ignoreFile := filepath.Join(dir, IgnoreFileName)
+	if absPath, err := filepath.Abs(ignoreFile); err == nil {
+		ignoreFile = absPath
+	}
+	if c.ignoreFiles[ignoreFile] {
+		return
+	}
+	if c.ignoreFiles == nil {
+		c.ignoreFiles = make(map[string]bool)
+	}
+	c.ignoreFiles[ignoreFile] = true
+
+	rules, err := LoadIgnoreFile(ignoreFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			c.Logger.Printf("Warning: Cannot read %s: %v\n", ignoreFile, err)
+		}
+		return
+	}
+	c.Logger.Printf("Verbose: Using ignore file %s\n", ignoreFile)
+	c.ignoreRules = append(c.ignoreRules, rules)
+}
+
+// loadIgnoreFilesFor adds the rules of the .thinktankignore files in the directories from
+// the current directory down to the directory of a file inside it
+func (c *Config) loadIgnoreFilesFor(filePath string) {
+	relPath, ok := c.workingDirPath(filePath)
+	if !ok {
+		return
+	}
+	dir := "."
+	for _, name := range strings.Split(path.Dir(relPath), "/") {
+		if name != "." {
+			dir = filepath.Join(dir, name)
+			c.loadIgnoreFile(dir)
+		}
+	}
+}
+
+// resetGathering clears the counts and the ignore rules of a previous gathering, and loads
+// the .thinktankignore file of the current directory
+func (c *Config) resetGathering() {
+	c.processedFiles = 0
+	c.totalFiles = 0
+	c.totalBytes = 0
+	c.skipped = nil
+	c.truncated = nil
+	c.omittedDirs = nil
+	c.workDir, _ = os.Getwd()
+
+	// Load the git ignore rules afresh for each run
+	c.gitIgnore = newGitIgnoreMatcher()
+
+	c.ignoreRules = nil
+	c.ignoreFiles = nil
+	c.loadIgnoreFile(".")
+}
+
+// isIgnored reports whether the .thinktankignore files loaded so far ignore a path. As
+// with gitignore, the last matching pattern decides, and the files in deeper directories
+// take precedence.
+func (c *Config) isIgnored(path string, isDir bool) bool {
+	ignored := false
+	for _, rules := range c.ignoreRules {
+		if matched, ruleIgnored := rules.Match(path, isDir); matched {
+			ignored = ruleIgnored
+		}
+	}
+	return ignored
+}
+
+// SkippedFiles returns the files of the last gathering that were skipped as generated or
+// because the total size limit was reached
+func (c *Config) SkippedFiles() []SkippedFile {
+	return c.skipped
+}
+
+// TruncatedFiles returns the files of the last gathering that were truncated to the size limits
+func (c *Config) TruncatedFiles() []string {
+	return c.truncated
+}
+
+// OmittedDirs returns the absolute paths of the directories skipped by the last gathering,
+// as excluded or ignored, leaving out .git directories
+func (c *Config) OmittedDirs() []string {
+	return c.omittedDirs
+}
+
+// skip records a file left out of the context
+func (c *Config) skip(path, reason string) {
+	c.Logger.Printf("Verbose: Skipping %s file: %s\n", reason, path)
+	c.skipped = append(c.skipped, SkippedFile{Path: path, Reason: reason})
+}
+
+// SetProgressReporter sets a callback function that will be called as each file found is
+// added or skipped, with the number of files done and the number found so far. It is
+// called from the goroutine gathering the files.
+func (c *Config) SetProgressReporter(reporter func(read, found int)) {
+	c.progressReporter = reporter
+}
+
 // SetFileCollector sets a callback function that will be called for each processed file
 func (c *Config) SetFileCollector(collector func(path string)) {
 	c.fileCollector = collector
 }
 
-// isGitIgnored checks if a file is likely ignored by git or is hidden.
-func isGitIgnored(path string, config *Config) bool {
+// GitBranch returns the name of the git branch checked out at path (a file or a
+// directory), or an empty string if path is not in a git repository or HEAD is detached.
+func GitBranch(path string) string {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	root := newGitIgnoreMatcher().repoRoot(dir)
+	if root == "" {
+		return ""
+	}
+	gitDir := resolveGitDir(root)
+	if gitDir == "" {
+		return ""
+	}
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	branch, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: refs/heads/")
+	if !ok {
+		return "" // Detached HEAD
+	}
+	return branch
+}
+
+// isGitIgnored checks if a file or directory is ignored by git or is hidden.
+func isGitIgnored(path string, isDir bool, config *Config) bool {
 	base := filepath.Base(path)
 	// Always ignore .git directory contents implicitly
 	if base == ".git" || strings.Contains(path, string(filepath.Separator)+".git"+string(filepath.Separator)) {
 		return true
 	}
 
-	// Use git check-ignore if available
-	if config.GitAvailable {
-		dir := filepath.Dir(path)
-		// Check if the directory is actually a git repo first
-		gitRepoCheck := exec.Command("git", "-C", dir, "rev-parse", "--is-inside-work-tree")
-		if gitRepoCheck.Run() == nil { // If it is a git repo
-			cmd := exec.Command("git", "-C", dir, "check-ignore", "-q", base)
-			err := cmd.Run()
-			if err == nil { // Exit code 0: file IS ignored
-				config.Logger.Printf("Verbose: Git ignored: %s\n", path)
-				return true
-			}
-			if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
-				// Exit code 1: file is NOT ignored
-				// Continue to other checks like hidden files
-			} else if err != nil {
-				// Other errors running check-ignore, log it but fall back
-				config.Logger.Printf("Verbose: Error running git check-ignore for %s: %v. Falling back.\n", path, err)
-			}
-		}
-		// If not a git repo or check-ignore failed non-fatally, proceed to hidden check
+	// Evaluate the .gitignore files, .git/info/exclude and the global excludes file
+	if config.gitIgnore == nil {
+		config.gitIgnore = newGitIgnoreMatcher()
+	}
+	if config.gitIgnore.isIgnored(path, isDir) {
+		config.Logger.Printf("Verbose: Git ignored: %s\n", path)
+		return true
 	}
 
-	// Fallback or additional check: Check if the file/directory itself is hidden
+	// Check if the file/directory itself is hidden
 	if strings.HasPrefix(base, ".") && base != "." && base != ".." {
 		config.Logger.Printf("Verbose: Hidden file/dir ignored: %s\n", path)
 		return true
@@ -159,25 +300,25 @@ func shouldProcess(path string, config *Config) bool {
 	ext := strings.ToLower(filepath.Ext(path))
 
 	// Check if explicitly excluded by name
-	if len(config.ExcludeNames) > 0 && slices.Contains(config.ExcludeNames, base) {
+	if len(config.ExcludeNames) > 0 && (slices.Contains(config.ExcludeNames, base) || config.matchesAny(config.ExcludeNames, path)) {
 		config.Logger.Printf("Verbose: Skipping excluded name: %s\n", path)
 		return false
 	}
 
 	// Check if gitignored or hidden (handles .git implicitly)
-	if isGitIgnored(path, config) {
+	if isGitIgnored(path, false, config) {
 		return false // Logging done within isGitIgnored
 	}
 
-	// Check include extensions (if specified)
-	if len(config.IncludeExts) > 0 {
-		included := false
-		for _, includeExt := range config.IncludeExts {
-			if ext == includeExt {
-				included = true
-				break
-			}
-		}
+	// Check the .thinktankignore files
+	if config.isIgnored(path, false) {
+		config.Logger.Printf("Verbose: Skipping file ignored by %s: %s\n", IgnoreFileName, path)
+		return false
+	}
+
+	// Check include extensions and patterns (if specified)
+	if len(config.IncludeExts) > 0 || len(config.IncludePatterns) > 0 {
+		included := slices.Contains(config.IncludeExts, ext) || config.matchesAny(config.IncludePatterns, path)
 		if !included {
 			config.Logger.Printf("Verbose: Skipping non-included extension: %s (%s)\n", path, ext)
 			return false
@@ -194,74 +335,42 @@ func shouldProcess(path string, config *Config) bool {
 		}
 	}
 
-	return true
-}
-
-// processFile reads, checks, and adds a file to the FileMeta slice.
-func processFile(path string, files *[]FileMeta, config *Config) {
-	config.totalFiles++ // Increment total count when we attempt to process
-
-	// Run all checks first
-	if !shouldProcess(path, config) {
-		return // Already logged why it was skipped
-	}
-
-	content, err := os.ReadFile(path)
-	if err != nil {
-		config.Logger.Printf("Warning: Cannot read file %s: %v\n", path, err)
-		return
-	}
-
-	if isBinaryFile(content) {
-		config.Logger.Printf("Verbose: Skipping binary file: %s\n", path)
-		return
-	}
-
-	// If all checks pass, process it
-	config.processedFiles++
-	config.Logger.Printf("Verbose: Processing file (%d/%d): %s (size: %d bytes)\n",
-		config.processedFiles, config.totalFiles, path, len(content))
-
-	// If a file collector is set, call it
-	if config.fileCollector != nil {
-		config.fileCollector(path)
-	}
-
-	// Convert to absolute path if it's not already
-	absPath := path
-	if !filepath.IsAbs(path) {
-		// If this fails, just use the original path
-		if abs, err := filepath.Abs(path); err == nil {
-			absPath = abs
-		} else {
-			config.Logger.Printf("Warning: Could not convert %s to absolute path: %v\n", path, err)
-		}
+	// Check exclude patterns
+	if config.matchesAny(config.ExcludePatterns, path) {
+		config.Logger.Printf("Verbose: Skipping excluded pattern: %s\n", path)
+		return false
 	}
 
-	// Create a FileMeta and add it to the slice
-	*files = append(*files, FileMeta{
-		Path:    absPath,
-		Content: string(content),
-	})
+	return true
 }
 
-// GatherProjectContext walks paths and gathers files into a slice of FileMeta.
-func GatherProjectContext(paths []string, config *Config) ([]FileMeta, int, error) {
-	var files []FileMeta
-
-	config.processedFiles = 0
-	config.totalFiles = 0
+// GatherProjectContext walks paths and gathers files into a slice of FileMeta, in the
+// order they are walked. Files are read concurrently as the walk goes on (see
+// gatherFiles). If ctx is cancelled, the walk stops and ctx.Err() is returned.
+func GatherProjectContext(ctx context.Context, paths []string, config *Config) ([]FileMeta, int, error) {
+	// Apply the .thinktankignore file of the current directory, then those of the
+	// directories walked
+	config.resetGathering()
+
+	files, err := config.gatherFiles(ctx, func(emit func(path string) bool) {
+		for _, p := range paths {
+			info, err := os.Stat(p)
+			if err != nil {
+				config.Logger.Printf("Warning: Cannot stat path %s: %v. Skipping.\n", p, err)
+				continue
+			}
 
-	for _, p := range paths {
-		info, err := os.Stat(p)
-		if err != nil {
-			config.Logger.Printf("Warning: Cannot stat path %s: %v. Skipping.\n", p, err)
-			continue
-		}
+			if !info.IsDir() {
+				// It's a single file
+				if !emit(p) {
+					return
+				}
+				continue
+			}
 
-		if info.IsDir() {
 			// Walk the directory
-			err := filepath.WalkDir(p, func(subPath string, d os.DirEntry, err error) error {
+			stopped := false
+			err = filepath.WalkDir(p, func(subPath string, d os.DirEntry, err error) error {
 				if err != nil {
 					config.Logger.Printf("Warning: Error accessing path %s during walk: %v\n", subPath, err)
 					return err // Report error up
@@ -269,28 +378,43 @@ func GatherProjectContext(paths []string, config *Config) ([]FileMeta, int, erro
 
 				// Check if the directory itself should be skipped (e.g., .git, node_modules)
 				if d.IsDir() {
-					if isGitIgnored(subPath, config) || slices.Contains(config.ExcludeNames, d.Name()) {
+					if ctx.Err() != nil {
+						stopped = true
+						return filepath.SkipAll
+					}
+					if isGitIgnored(subPath, true, config) || slices.Contains(config.ExcludeNames, d.Name()) ||
+						config.matchesAnyDir(config.ExcludeNames, subPath) || config.matchesAnyDir(config.ExcludePatterns, subPath) ||
+						config.isIgnored(subPath, true) {
 						config.Logger.Printf("Verbose: Skipping directory: %s\n", subPath)
+						if d.Name() != ".git" {
+							if absPath, err := filepath.Abs(subPath); err == nil {
+								config.omittedDirs = append(config.omittedDirs, absPath)
+							}
+						}
 						return filepath.SkipDir // Skip this whole directory
 					}
+					config.loadIgnoreFile(subPath)
 					return nil // Continue walking into directory
 				}
 
 				// It's a file, process it
-				if !d.IsDir() {
-					processFile(subPath, &files, config)
+				if !emit(subPath) {
+					stopped = true
+					return filepath.SkipAll
 				}
-
 				return nil // Continue walking
 			})
 			if err != nil {
 				config.Logger.Printf("Error walking directory %s: %v\n", p, err)
 				// Continue with other paths if possible
 			}
-		} else {
-			// It's a single file
-			processFile(p, &files, config)
+			if stopped {
+				return
+			}
 		}
+	})
+	if err != nil {
+		return nil, 0, err
 	}
 
 	return files, config.processedFiles, nil
@@ -0,0 +1,297 @@
+// internal/fileutil/gather.go
+package fileutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// candidate is a file found while walking that passed the filters, numbered in the
+// order it was found
+type candidate struct {
+	index   int    // Position among the candidates
+	attempt int    // Number of files found so far, including this one, for logging
+	path    string // Path as walked
+}
+
+// fileRead is what a reader found out about a candidate
+type fileRead struct {
+	candidate
+	size      int64  // Size of the file on disk
+	lockFile  bool   // Skipped as a lock file without reading it
+	loaded    bool   // Whether content was read, with limit
+	limit     int64  // Size limit content was read with (see readFileLimited)
+	content   []byte // Content, truncated to limit
+	truncated bool   // Whether content was truncated
+	binary    bool   // Whether content is binary
+	generated string // Why the file is generated, if it is (see generatedReason)
+	err       error  // Error reading the file
+}
+
+// gatherReaders returns the number of files read concurrently. Reading is bound by I/O
+// rather than CPU, so there are at least a few readers even on a single CPU.
+func gatherReaders() int {
+	return min(max(runtime.GOMAXPROCS(0), 4), 16)
+}
+
+// gatherFiles gathers the files that walk passes to emit. walk runs in its own goroutine
+// and must stop when emit returns false. The files that pass the filters are read by a
+// bounded pool of readers and added with the checks of addFile in the order they were
+// emitted, so that the result does not depend on the order in which reads complete.
+// ctx.Err() is returned if ctx is cancelled before every file is gathered.
+func (c *Config) gatherFiles(ctx context.Context, walk func(emit func(path string) bool)) ([]FileMeta, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	candidates := make(chan candidate)
+	results := make(chan fileRead)
+	walked := make(chan struct{})
+	var found atomic.Int64 // Candidates sent to the readers
+	var full atomic.Bool   // Set once the total size limit is reached, so files need not be read
+
+	// Walk the paths, filtering the files found. The walker alone uses the ignore rules.
+	go func() {
+		defer close(walked)
+		defer close(candidates)
+		walk(func(path string) bool {
+			if ctx.Err() != nil {
+				return false
+			}
+			c.totalFiles++
+			if !shouldProcess(path, c) {
+				return true
+			}
+			index := int(found.Add(1)) - 1 // Counted before it can be read
+			select {
+			case candidates <- candidate{index: index, attempt: c.totalFiles, path: path}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	// Read the files found with a bounded pool of readers
+	var readers sync.WaitGroup
+	for range gatherReaders() {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for cand := range candidates {
+				select {
+				case results <- c.readFile(cand, !full.Load()):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		readers.Wait()
+		close(results)
+	}()
+
+	// Add the files in the order they were found, as soon as those before them are added
+	var files []FileMeta
+	pending := make(map[int]fileRead)
+	next := 0
+	for result := range results {
+		pending[result.index] = result
+		for {
+			read, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+
+			c.addFile(read, &files)
+			if c.MaxTotalSize > 0 && c.totalBytes >= c.MaxTotalSize {
+				full.Store(true)
+			}
+			if c.progressReporter != nil {
+				c.progressReporter(next, int(found.Load()))
+			}
+		}
+	}
+	<-walked
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// processFile checks, reads and adds a single file to files, as gatherFiles does for each
+// file emitted
+func processFile(path string, files *[]FileMeta, config *Config) {
+	config.totalFiles++ // Increment total count when we attempt to process
+
+	// Run all checks first
+	if !shouldProcess(path, config) {
+		return // Already logged why it was skipped
+	}
+	config.addFile(config.readFile(candidate{attempt: config.totalFiles, path: path}, true), files)
+}
+
+// readFile finds out the size of a candidate and, unless it is a lock file to skip or
+// load is false, reads it up to MaxFileSize. It only reads the configuration, so that
+// readers can run concurrently.
+func (c *Config) readFile(cand candidate, load bool) fileRead {
+	read := fileRead{candidate: cand}
+	info, err := os.Stat(cand.path)
+	if err != nil {
+		read.err = err
+		return read
+	}
+	read.size = info.Size()
+
+	// Lock files are known to be generated without reading them
+	if !c.IncludeGenerated && isLockFile(filepath.Base(cand.path)) {
+		read.lockFile = true
+		return read
+	}
+
+	if load {
+		read.load(c.MaxFileSize, c.IncludeGenerated)
+	}
+	return read
+}
+
+// load reads the file up to limit and checks whether it is binary or generated
+func (r *fileRead) load(limit int64, includeGenerated bool) {
+	r.content, r.truncated, r.err = readFileLimited(r.path, r.size, limit)
+	r.loaded, r.limit = true, limit
+	if r.err != nil {
+		return
+	}
+
+	r.binary = isBinaryFile(r.content)
+	r.generated = ""
+	if !r.binary && !includeGenerated {
+		r.generated = generatedReason(r.path, r.content)
+	}
+}
+
+// effectiveLimit returns the size a file of the given size is truncated to by limit, or
+// 0 if it is read whole
+func effectiveLimit(limit, size int64) int64 {
+	if limit <= 0 || size <= limit {
+		return 0
+	}
+	return limit
+}
+
+// addFile adds a file that was read to files, unless it is skipped as a lock file, as
+// binary or generated, or because the total size limit is reached. Files are added in
+// the order they were found, which decides which files fit the total size limit.
+func (c *Config) addFile(read fileRead, files *[]FileMeta) {
+	if read.err != nil {
+		c.Logger.Printf("Warning: Cannot read file %s: %v\n", read.path, read.err)
+		return
+	}
+	if read.lockFile {
+		c.skip(read.path, "lock file")
+		return
+	}
+
+	// Only read as much of the file as the size limits allow
+	limit := c.MaxFileSize
+	if c.MaxTotalSize > 0 {
+		remaining := c.MaxTotalSize - c.totalBytes
+		if remaining < min(read.size, minTruncatedSize) {
+			c.skip(read.path, "total size limit reached")
+			return
+		}
+		if limit <= 0 || remaining < limit {
+			limit = remaining
+		}
+	}
+
+	// Read the file again if the room left is less than it was read with
+	if !read.loaded || effectiveLimit(limit, read.size) != effectiveLimit(read.limit, read.size) {
+		read.load(limit, c.IncludeGenerated)
+		if read.err != nil {
+			c.Logger.Printf("Warning: Cannot read file %s: %v\n", read.path, read.err)
+			return
+		}
+	}
+
+	if read.binary {
+		c.Logger.Printf("Verbose: Skipping binary file: %s\n", read.path)
+		return
+	}
+	if read.generated != "" {
+		c.skip(read.path, read.generated)
+		return
+	}
+
+	if read.truncated {
+		c.Logger.Printf("Verbose: Truncating file %s (%s) to %s\n", read.path, FormatSize(read.size), FormatSize(limit))
+		c.truncated = append(c.truncated, read.path)
+	}
+	c.totalBytes += int64(len(read.content))
+
+	// If all checks pass, process it
+	c.processedFiles++
+	c.Logger.Printf("Verbose: Processing file (%d/%d): %s (size: %d bytes)\n",
+		c.processedFiles, read.attempt, read.path, len(read.content))
+
+	// If a file collector is set, call it
+	if c.fileCollector != nil {
+		c.fileCollector(read.path)
+	}
+
+	// Convert to absolute path if it's not already
+	absPath := read.path
+	if !filepath.IsAbs(read.path) {
+		// If this fails, just use the original path
+		if abs, err := filepath.Abs(read.path); err == nil {
+			absPath = abs
+		} else {
+			c.Logger.Printf("Warning: Could not convert %s to absolute path: %v\n", read.path, err)
+		}
+	}
+
+	// Create a FileMeta and add it to the slice
+	*files = append(*files, FileMeta{
+		Path:    absPath,
+		Content: string(read.content),
+		Size:    read.size,
+	})
+}
+
+// addContent adds content that is not read from a file, such as a diff, to files with the
+// size limits of addFile, truncating it to the room left. It counts towards the total size
+// but not as a processed file.
+func (c *Config) addContent(path string, content []byte, files *[]FileMeta) {
+	size := int64(len(content))
+	limit := c.MaxFileSize
+	if c.MaxTotalSize > 0 {
+		remaining := c.MaxTotalSize - c.totalBytes
+		if remaining < min(size, minTruncatedSize) {
+			c.skip(path, "total size limit reached")
+			return
+		}
+		if limit <= 0 || remaining < limit {
+			limit = remaining
+		}
+	}
+
+	if effectiveLimit(limit, size) > 0 {
+		c.Logger.Printf("Verbose: Truncating %s (%s) to %s\n", path, FormatSize(size), FormatSize(limit))
+		c.truncated = append(c.truncated, path)
+		content = truncateContent(content, limit)
+	}
+	c.totalBytes += int64(len(content))
+
+	*files = append(*files, FileMeta{
+		Path:    path,
+		Content: string(content),
+		Size:    size,
+	})
+}
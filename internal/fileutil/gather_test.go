@@ -0,0 +1,102 @@
+package fileutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestGatherProjectContextConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	contents := make(map[string]string)
+	var expected []string
+	for i := 0; i < 200; i++ {
+		// Alternate large and small files, so that reads complete out of order
+		name := fmt.Sprintf("pkg%d/file%03d.go", i%7, i)
+		contents[name] = fmt.Sprintf("package pkg // %d\n", i) + strings.Repeat("// padding\n", (i%2)*500)
+	}
+	contents["pkg3/data.bin"] = "\x00\x01\x02\x03"
+	writeRepoFiles(t, dir, contents)
+
+	var walked []string
+	config := NewConfig(false, "", "", "", "", NewMockLogger())
+	config.SetFileCollector(func(path string) { walked = append(walked, path) })
+	var progress [][2]int
+	config.SetProgressReporter(func(read, found int) { progress = append(progress, [2]int{read, found}) })
+
+	files, processed, err := GatherProjectContext(context.Background(), []string{dir}, config)
+	if err != nil {
+		t.Fatalf("GatherProjectContext returned an error: %v", err)
+	}
+
+	// Files are gathered in walk order, whatever order they were read in
+	for name := range contents {
+		if !strings.HasSuffix(name, ".bin") {
+			expected = append(expected, filepath.Join(dir, filepath.FromSlash(name)))
+		}
+	}
+	sort.Strings(expected) // The walk order, as names sort like their paths
+	if processed != len(expected) || len(files) != len(expected) {
+		t.Fatalf("Expected %d files, got %d (%d processed)", len(expected), len(files), processed)
+	}
+	for i, file := range files {
+		if file.Path != expected[i] || walked[i] != expected[i] {
+			t.Fatalf("Expected file %d to be %s, got %s (collected %s)", i, expected[i], file.Path, walked[i])
+		}
+		if file.Content != contents[filepath.ToSlash(strings.TrimPrefix(file.Path, dir+string(filepath.Separator)))] {
+			t.Errorf("Unexpected content for %s", file.Path)
+		}
+	}
+
+	// Progress is reported for every file found that passed the filters, binary or not
+	if len(progress) != len(contents) {
+		t.Fatalf("Expected progress for %d files, got %d reports", len(contents), len(progress))
+	}
+	for i, report := range progress {
+		if report[0] != i+1 || report[1] < report[0] {
+			t.Errorf("Unexpected progress report %d: %v", i, report)
+		}
+	}
+	if last := progress[len(progress)-1]; last[1] != len(contents) {
+		t.Errorf("Expected %d files found by the end, got %d", len(contents), last[1])
+	}
+}
+
+func TestGatherProjectContextCancelled(t *testing.T) {
+	dir := t.TempDir()
+	contents := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		contents[fmt.Sprintf("file%03d.txt", i)] = "content\n"
+	}
+	writeRepoFiles(t, dir, contents)
+
+	t.Run("Before gathering", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		files, _, err := GatherProjectContext(ctx, []string{dir}, NewConfig(false, "", "", "", "", NewMockLogger()))
+		if !errors.Is(err, context.Canceled) || files != nil {
+			t.Errorf("Expected a cancellation error and no files, got %d files and error %v", len(files), err)
+		}
+	})
+
+	t.Run("While gathering", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		config := NewConfig(false, "", "", "", "", NewMockLogger())
+		config.SetProgressReporter(func(read, found int) {
+			if read == 10 {
+				cancel()
+			}
+		})
+		files, _, err := GatherProjectContext(ctx, []string{dir}, config)
+		if !errors.Is(err, context.Canceled) || files != nil {
+			t.Errorf("Expected a cancellation error and no files, got %d files and error %v", len(files), err)
+		}
+	})
+}
@@ -2,8 +2,10 @@
 package fileutil
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 )
@@ -13,11 +15,17 @@ func TestIsGitIgnored(t *testing.T) {
 	logger := NewMockLogger()
 	logger.SetVerbose(true)
 
-	// Basic configuration - git not available
-	configNoGit := &Config{
-		Logger:       logger,
-		GitAvailable: false,
+	// Run outside any git repository so that no ignore rules apply
+	workDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
 	}
+	defer func() { _ = os.Chdir(workDir) }()
+
+	configNoGit := &Config{Logger: logger}
 
 	// Test cases that don't depend on git
 	basicTests := []struct {
@@ -120,7 +128,7 @@ func TestIsGitIgnored(t *testing.T) {
 	for _, tt := range basicTests {
 		t.Run(tt.name, func(t *testing.T) {
 			logger.ClearMessages()
-			result := isGitIgnored(tt.path, tt.config)
+			result := isGitIgnored(tt.path, false, tt.config)
 
 			if result != tt.expected {
 				t.Errorf("isGitIgnored(%q) = %v, want %v", tt.path, result, tt.expected)
@@ -138,289 +146,175 @@ func TestIsGitIgnored(t *testing.T) {
 	}
 }
 
-// TestIsGitIgnoredWithMockGit tests the isGitIgnored function with a mock git environment
-func TestIsGitIgnoredWithMockGit(t *testing.T) {
-	// Skip this test if the OS doesn't support executable permissions
-	if os.Getenv("SKIP_GIT_MOCK_TESTS") != "" {
-		t.Skip("Skipping tests that require git command mocking")
-	}
-
-	logger := NewMockLogger()
-	logger.SetVerbose(true)
-
-	// Create a temporary directory to act as a git repo
-	tempDir := t.TempDir()
-
-	// Create a mock git executable script in the temp directory
-	mockGitPath := filepath.Join(tempDir, "git")
-	if isWindows() {
-		mockGitPath += ".bat"
-	}
-
-	// Create a bash script that simulates git behavior
-	mockGitScript := `#!/bin/sh
-if [ "$1" = "-C" ] && [ "$3" = "rev-parse" ] && [ "$4" = "--is-inside-work-tree" ]; then
-  # Simulate being in a git repo
-  exit 0
-elif [ "$1" = "-C" ] && [ "$3" = "check-ignore" ] && [ "$4" = "-q" ]; then
-  # Check if the file should be ignored
-  filename="$5"
-
-  # Files to ignore
-  if [ "$filename" = "ignored.txt" ] || [ "$filename" = "build.log" ] || [ "$filename" = "node_modules" ]; then
-    exit 0  # Exit code 0: file IS ignored
-  else
-    exit 1  # Exit code 1: file is NOT ignored
-  fi
-else
-  # Unknown git command, return error
-  exit 2
-fi
-`
-
-	// For Windows, create a batch script instead
-	if isWindows() {
-		mockGitScript = `@echo off
-if "%1"=="-C" (
-  if "%3"=="rev-parse" (
-    if "%4"=="--is-inside-work-tree" (
-      exit /b 0
-    )
-  )
-  if "%3"=="check-ignore" (
-    if "%4"=="-q" (
-      set filename=%5
-      if "%filename%"=="ignored.txt" exit /b 0
-      if "%filename%"=="build.log" exit /b 0
-      if "%filename%"=="node_modules" exit /b 0
-      exit /b 1
-    )
-  )
-)
-exit /b 2
-`
+// writeRepoFiles creates files with their content under dir
+func writeRepoFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for relPath, content := range files {
+		fullPath := filepath.Join(dir, relPath)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
 	}
+}
 
-	// Write the mock git script
-	err := os.WriteFile(mockGitPath, []byte(mockGitScript), 0755)
-	if err != nil {
-		t.Fatalf("Failed to write mock git script: %v", err)
-	}
+// TestIsGitIgnoredInRepository tests evaluating the ignore rules of a repository without git
+func TestIsGitIgnoredInRepository(t *testing.T) {
+	// Keep the user's global excludes file out of the test
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("HOME", t.TempDir())
+	writeRepoFiles(t, configHome, map[string]string{
+		"git/ignore": "*.swp\n",
+	})
 
-	// Add tempDir to PATH temporarily for this test
-	origPath := os.Getenv("PATH")
-	t.Cleanup(func() {
-		_ = os.Setenv("PATH", origPath) // Restore original PATH
+	repo := t.TempDir()
+	writeRepoFiles(t, repo, map[string]string{
+		".git/HEAD":         "ref: refs/heads/main\n",
+		".git/info/exclude": "scratch.txt\n",
+		".gitignore":        "*.log\n!keep.log\nbuild/\n/vendor\n",
+		"sub/.gitignore":    "generated.go\n!*.swp\n",
 	})
-	_ = os.Setenv("PATH", tempDir+string(filepath.ListSeparator)+origPath)
 
-	// Create a config with git available
-	config := &Config{
-		Logger:       logger,
-		GitAvailable: true,
-	}
+	logger := NewMockLogger()
+	logger.SetVerbose(true)
+	config := &Config{Logger: logger}
 
-	// Test cases
 	tests := []struct {
 		name     string
 		path     string
+		isDir    bool
 		expected bool
 	}{
-		{
-			name:     "File that should be ignored by git",
-			path:     "ignored.txt",
-			expected: true,
-		},
-		{
-			name:     "Another ignored file",
-			path:     "build.log",
-			expected: true,
-		},
-		{
-			name:     "Ignored directory",
-			path:     "node_modules",
-			expected: true,
-		},
-		{
-			name:     "File that should not be ignored",
-			path:     "README.md",
-			expected: false,
-		},
-		{
-			name:     "Source file",
-			path:     "main.go",
-			expected: false,
-		},
+		{"Ignored by extension", "debug.log", false, true},
+		{"Re-included by negation", "keep.log", false, false},
+		{"Ignored in a subdirectory", "sub/debug.log", false, true},
+		{"Ignored directory", "build", true, true},
+		{"File in an ignored directory", "build/out/app.go", false, true},
+		{"Directory pattern does not match files", "sub/build", false, false},
+		{"Anchored pattern", "vendor", true, true},
+		{"Anchored pattern in a subdirectory", "sub/vendor", true, false},
+		{"Nested .gitignore", "sub/generated.go", false, true},
+		{"Nested .gitignore does not apply above it", "generated.go", false, false},
+		{".git/info/exclude", "scratch.txt", false, true},
+		{"Global excludes", "main.go.swp", false, true},
+		{"Global excludes overridden by .gitignore", "sub/main.go.swp", false, false},
+		{"Regular file", "main.go", false, false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			logger.ClearMessages()
-			result := isGitIgnored(tt.path, config)
-
-			if result != tt.expected {
-				t.Errorf("isGitIgnored(%q) with mock git = %v, want %v", tt.path, result, tt.expected)
+			path := filepath.Join(repo, filepath.FromSlash(tt.path))
+			if result := isGitIgnored(path, tt.isDir, config); result != tt.expected {
+				t.Errorf("isGitIgnored(%q) = %v, want %v", tt.path, result, tt.expected)
 			}
-
-			// Check for log messages
-			if tt.expected && !logger.ContainsMessage("Git ignored") && !logger.ContainsMessage("Hidden file/dir ignored") {
+			if tt.expected && !logger.ContainsMessage("Git ignored") {
 				t.Errorf("Expected log message about git ignored for path %s", tt.path)
 			}
 		})
 	}
-}
-
-// TestGitErrorHandling tests how isGitIgnored handles various git command errors
-func TestGitErrorHandling(t *testing.T) {
-	// Skip this test if the OS doesn't support executable permissions
-	if os.Getenv("SKIP_GIT_MOCK_TESTS") != "" {
-		t.Skip("Skipping tests that require git command mocking")
-	}
 
-	logger := NewMockLogger()
-	logger.SetVerbose(true)
+	t.Run("Outside a repository", func(t *testing.T) {
+		dir := t.TempDir()
+		writeRepoFiles(t, dir, map[string]string{".gitignore": "*.log\n"})
+		if isGitIgnored(filepath.Join(dir, "debug.log"), false, config) {
+			t.Error("Expected .gitignore files outside a repository to be ignored")
+		}
+	})
+}
 
-	// Create a temporary directory for the mock git
-	tempDir := t.TempDir()
+// TestIsGitIgnoredGitFile tests repositories whose .git is a file pointing to the git
+// directory, as in worktrees and submodules
+func TestIsGitIgnoredGitFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
 
-	// Create a mock git executable that always fails
-	mockGitPath := filepath.Join(tempDir, "git")
-	if isWindows() {
-		mockGitPath += ".bat"
-	}
-
-	// Create a bash script that simulates different git error scenarios
-	mockGitScript := `#!/bin/sh
-if [ "$1" = "-C" ] && [ "$3" = "rev-parse" ] && [ "$4" = "--is-inside-work-tree" ]; then
-  # Simulate not being in a git repo
-  if [ "$2" = "not-a-repo" ]; then
-    exit 128
-  else
-    exit 0  # Is a git repo
-  fi
-elif [ "$1" = "-C" ] && [ "$3" = "check-ignore" ] && [ "$4" = "-q" ]; then
-  # Simulate various check-ignore errors
-  if [ "$2" = "error-repo" ]; then
-    exit 128  # Fatal error
-  elif [ "$5" = "error-file.txt" ]; then
-    exit 2    # Other error code
-  elif [ "$5" = "ignored.txt" ]; then
-    exit 0    # File is ignored
-  else
-    exit 1    # File is not ignored
-  fi
-else
-  # Unknown git command
-  exit 2
-fi
-`
+	gitDir := t.TempDir()
+	writeRepoFiles(t, gitDir, map[string]string{
+		"HEAD":         "ref: refs/heads/feature/worktree\n",
+		"info/exclude": "*.tmp\n",
+	})
+	repo := t.TempDir()
+	writeRepoFiles(t, repo, map[string]string{
+		".git": "gitdir: " + gitDir + "\n",
+	})
 
-	// For Windows, create a batch script instead
-	if isWindows() {
-		mockGitScript = `@echo off
-if "%1"=="-C" (
-  if "%3"=="rev-parse" (
-    if "%4"=="--is-inside-work-tree" (
-      if "%2"=="not-a-repo" (
-        exit /b 128
-      ) else (
-        exit /b 0
-      )
-    )
-  )
-  if "%3"=="check-ignore" (
-    if "%4"=="-q" (
-      if "%2"=="error-repo" (
-        exit /b 128
-      )
-      if "%5"=="error-file.txt" (
-        exit /b 2
-      )
-      if "%5"=="ignored.txt" (
-        exit /b 0
-      )
-      exit /b 1
-    )
-  )
-)
-exit /b 2
-`
+	config := &Config{Logger: NewMockLogger()}
+	if !isGitIgnored(filepath.Join(repo, "notes.tmp"), false, config) {
+		t.Error("Expected the exclude file of the linked git directory to apply")
 	}
-
-	// Write the mock git script
-	err := os.WriteFile(mockGitPath, []byte(mockGitScript), 0755)
-	if err != nil {
-		t.Fatalf("Failed to write mock git script: %v", err)
+	if branch := GitBranch(repo); branch != "feature/worktree" {
+		t.Errorf("Expected branch feature/worktree, got %q", branch)
 	}
+}
 
-	// Add tempDir to PATH temporarily for this test
-	origPath := os.Getenv("PATH")
-	t.Cleanup(func() {
-		_ = os.Setenv("PATH", origPath) // Restore original PATH
+// TestGatherProjectContextGitIgnore tests that gathering skips files ignored by git
+func TestGatherProjectContextGitIgnore(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	repo := t.TempDir()
+	writeRepoFiles(t, repo, map[string]string{
+		".git/HEAD":          "ref: refs/heads/main\n",
+		".gitignore":         "dist/\n*.out\n",
+		"main.go":            "package main",
+		"report.out":         "output",
+		"dist/bundle.js":     "bundle",
+		"pkg/.gitignore":     "!trace.out\n",
+		"pkg/trace.out":      "trace",
+		"pkg/lib.go":         "package pkg",
+		"pkg/dist/extra.txt": "extra",
 	})
-	_ = os.Setenv("PATH", tempDir+string(filepath.ListSeparator)+origPath)
 
-	// Create a config with git available
-	config := &Config{
-		Logger:       logger,
-		GitAvailable: true,
+	config := NewConfig(false, "", "", "", "", NewMockLogger())
+	files, _, err := GatherProjectContext(context.Background(), []string{repo}, config)
+	if err != nil {
+		t.Fatalf("GatherProjectContext returned an error: %v", err)
 	}
 
-	// Test cases for error handling
-	tests := []struct {
-		name     string
-		dirPath  string // Directory part of the path (for -C argument)
-		filePath string // File part of the path (basename)
-		expected bool
-	}{
-		{
-			name:     "Not in a git repository",
-			dirPath:  "not-a-repo",
-			filePath: "some-file.txt",
-			expected: false, // Falls back to hidden check, which is false
-		},
-		{
-			name:     "Error running check-ignore",
-			dirPath:  "error-repo",
-			filePath: "some-file.txt",
-			expected: false, // Falls back to hidden check, which is false
-		},
-		{
-			name:     "File with check-ignore error",
-			dirPath:  "normal-repo",
-			filePath: "error-file.txt",
-			expected: false, // Falls back to hidden check, which is false
-		},
-		{
-			name:     "Regular ignored file",
-			dirPath:  "normal-repo",
-			filePath: "ignored.txt",
-			expected: true,
-		},
-		{
-			name:     "Hidden file with git error",
-			dirPath:  "error-repo",
-			filePath: ".config",
-			expected: true, // Hidden file is still ignored even if git errors out
-		},
+	var relPaths []string
+	for _, file := range files {
+		relPath, err := filepath.Rel(repo, file.Path)
+		if err != nil {
+			t.Fatalf("Failed to get relative path: %v", err)
+		}
+		relPaths = append(relPaths, filepath.ToSlash(relPath))
 	}
+	sort.Strings(relPaths)
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			logger.ClearMessages()
-			// Construct the full path
-			fullPath := filepath.Join(tt.dirPath, tt.filePath)
+	expected := "main.go,pkg/lib.go,pkg/trace.out"
+	if strings.Join(relPaths, ",") != expected {
+		t.Errorf("Expected files %s, got %s", expected, strings.Join(relPaths, ","))
+	}
+}
 
-			result := isGitIgnored(fullPath, config)
+// TestGitBranch tests reading the checked out branch of a repository
+func TestGitBranch(t *testing.T) {
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		".git/HEAD": "ref: refs/heads/feature/templates\n",
+		"main.go":   "package main",
+	})
+	file := filepath.Join(dir, "main.go")
 
-			if result != tt.expected {
-				t.Errorf("isGitIgnored(%q) with error conditions = %v, want %v", fullPath, result, tt.expected)
-			}
+	if branch := GitBranch(dir); branch != "feature/templates" {
+		t.Errorf("Expected branch feature/templates, got %q", branch)
+	}
+	if branch := GitBranch(file); branch != "feature/templates" {
+		t.Errorf("Expected the branch of the file's repository, got %q", branch)
+	}
+	if branch := GitBranch(t.TempDir()); branch != "" {
+		t.Errorf("Expected no branch outside a repository, got %q", branch)
+	}
 
-			// Check for expected error messages
-			if tt.dirPath == "error-repo" && !logger.ContainsMessage("Error running git check-ignore") {
-				t.Errorf("Expected error message about git check-ignore for %s", fullPath)
-			}
-		})
+	detached := t.TempDir()
+	writeRepoFiles(t, detached, map[string]string{
+		".git/HEAD": "0123456789abcdef0123456789abcdef01234567\n",
+	})
+	if branch := GitBranch(detached); branch != "" {
+		t.Errorf("Expected no branch with a detached HEAD, got %q", branch)
 	}
 }
@@ -0,0 +1,340 @@
+// internal/fileutil/gitdiff.go
+package fileutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DiffOptions selects a set of changes to review. When one of Range, Staged or Since is
+// set, the context holds the unified diff of the changes and the current contents of the
+// changed files, instead of every file under the context paths.
+type DiffOptions struct {
+	// Range is a base revision, for the changes on HEAD since it branched from base, or a
+	// range such as "base..head" or "base...head", passed to git diff as given
+	Range string
+	// Staged selects the changes staged for commit
+	Staged bool
+	// Since is a revision, for the changes in the working tree since that revision,
+	// committed or not
+	Since string
+	// Neighbors is the number of other files to include from the directory (package) of
+	// each changed file, nearest to it by name
+	Neighbors int
+}
+
+// Enabled reports whether a set of changes is selected
+func (o DiffOptions) Enabled() bool {
+	return o.Range != "" || o.Staged || o.Since != ""
+}
+
+// Validate checks that at most one set of changes is selected
+func (o DiffOptions) Validate() error {
+	selected := 0
+	for _, set := range []bool{o.Range != "", o.Staged, o.Since != ""} {
+		if set {
+			selected++
+		}
+	}
+	if selected > 1 {
+		return fmt.Errorf("only one of --diff, --staged and --since can be used")
+	}
+	if o.Neighbors < 0 {
+		return fmt.Errorf("invalid number of neighboring files: %d", o.Neighbors)
+	}
+	return nil
+}
+
+// diffArgs returns the git diff arguments selecting the changes
+func (o DiffOptions) diffArgs() []string {
+	switch {
+	case o.Staged:
+		return []string{"--cached"}
+	case o.Since != "":
+		return []string{o.Since}
+	case strings.Contains(o.Range, ".."):
+		return []string{o.Range}
+	default:
+		return []string{o.Range + "...HEAD"}
+	}
+}
+
+// Describe returns the git command showing the changes
+func (o DiffOptions) Describe() string {
+	return "git diff " + strings.Join(o.diffArgs(), " ")
+}
+
+// ChangeSet holds the context gathered for a set of changes
+type ChangeSet struct {
+	Files        []FileMeta // The diff, followed by the changed files and their neighboring files
+	ChangedPaths []string   // Paths of the diff and the changed files in Files
+	Processed    int        // Number of changed and neighboring files processed
+}
+
+// GatherChangedFiles gathers the changes selected by opts within paths: the unified diff,
+// as a file named after the git diff command, followed by the changed files that pass the
+// filters of config and their neighboring files. Deleted files only appear in the diff.
+// Files are read concurrently, as by GatherProjectContext. git runs in the repository of
+// the first path, and the other paths must be in the same repository. It returns an error
+// if git is not available or fails, or ctx.Err() if ctx is cancelled.
+func GatherChangedFiles(ctx context.Context, paths []string, opts DiffOptions, config *Config) (*ChangeSet, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("reviewing changes requires git: %w", err)
+	}
+	config.resetGathering()
+
+	// git runs in the repository of the paths, which need not be the current directory
+	dir := gitDir(paths)
+	root, err := runGit(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root = strings.TrimSpace(root)
+
+	args := slices.Concat([]string{"diff", "--no-color", "--no-ext-diff"}, opts.diffArgs())
+	pathspec := []string{"--"}
+	for _, path := range paths {
+		absPath, err := repoPath(root, path)
+		if err != nil {
+			return nil, err
+		}
+		pathspec = append(pathspec, absPath)
+	}
+
+	diff, err := runGit(ctx, dir, slices.Concat(args, pathspec)...)
+	if err != nil {
+		return nil, err
+	}
+	changes := &ChangeSet{}
+	if strings.TrimSpace(diff) == "" {
+		config.Logger.Printf("Warning: No changes found for %s\n", opts.Describe())
+		return changes, nil
+	}
+
+	// Leave out the changes to files that do not pass the filters or are generated
+	diffNames, err := runGit(ctx, dir, slices.Concat(args, []string{"--name-only", "-z"}, pathspec)...)
+	if err != nil {
+		return nil, err
+	}
+	diff = config.filterDiff(diff, splitNames(root, diffNames))
+	if strings.TrimSpace(diff) == "" {
+		config.Logger.Printf("Warning: No changes to files passing the filters found for %s\n", opts.Describe())
+		return changes, nil
+	}
+
+	names, err := runGit(ctx, dir, slices.Concat(args, []string{"--name-only", "-z", "--diff-filter=d"}, pathspec)...)
+	if err != nil {
+		return nil, err
+	}
+	changedPaths := splitNames(root, names)
+	changed := make(map[string]bool)
+	for _, path := range changedPaths {
+		changed[path] = true
+	}
+
+	// The diff comes first, so it has the first claim on the total size limit
+	var files []FileMeta
+	config.addContent(opts.Describe(), []byte(diff), &files)
+
+	gathered, err := config.gatherFiles(ctx, func(emit func(path string) bool) {
+		for _, path := range changedPaths {
+			config.loadIgnoreFilesFor(path)
+		}
+		for _, path := range slices.Concat(changedPaths, neighborFiles(changedPaths, changed, opts.Neighbors)) {
+			if !emit(path) {
+				return
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changes.Files = append(files, gathered...)
+	for _, file := range changes.Files {
+		if file.Path == opts.Describe() || changed[file.Path] {
+			changes.ChangedPaths = append(changes.ChangedPaths, file.Path)
+		}
+	}
+	changes.Processed = config.processedFiles
+	return changes, nil
+}
+
+// gitDir returns the directory to run git in for paths: the first path, or its directory
+// if it is not a directory. It returns "", for the current directory, without paths.
+func gitDir(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	if info, err := os.Stat(paths[0]); err == nil && info.IsDir() {
+		return paths[0]
+	}
+	return filepath.Dir(paths[0])
+}
+
+// repoPath returns the absolute path of a path in the git repository at root, or an error
+// if it is outside the repository
+func repoPath(root, path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve %s: %w", path, err)
+	}
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		absPath = resolved
+	}
+	relPath, err := filepath.Rel(root, absPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the git repository at %s; changes can only be reviewed within one repository", path, root)
+	}
+	return absPath, nil
+}
+
+// splitNames returns the paths of the NUL-separated file names output by git, which are
+// relative to the root of the repository
+func splitNames(root, names string) []string {
+	var paths []string
+	for _, name := range strings.Split(names, "\x00") {
+		if name != "" {
+			paths = append(paths, filepath.Join(root, filepath.FromSlash(name)))
+		}
+	}
+	return paths
+}
+
+// filterDiff removes from a unified diff the sections of the files that do not pass the
+// filters of config or, unless generated files are included, are generated. paths are
+// the files of the sections, in the order of the diff. The diff is returned whole if its
+// sections cannot be matched to paths.
+func (c *Config) filterDiff(diff string, paths []string) string {
+	sections := diffSections(diff)
+	if len(sections) != len(paths) {
+		c.Logger.Printf("Warning: Cannot match the %d sections of the diff to %d changed files, so it is not filtered\n",
+			len(sections), len(paths))
+		return diff
+	}
+
+	var sb strings.Builder
+	for i, section := range sections {
+		c.loadIgnoreFilesFor(paths[i])
+		if !shouldProcess(paths[i], c) {
+			continue
+		}
+		if reason := c.changeGeneratedReason(paths[i]); reason != "" {
+			c.Logger.Printf("Verbose: Leaving the changes to %s out of the diff (%s)\n", paths[i], reason)
+			continue
+		}
+		sb.WriteString(section)
+	}
+	return sb.String()
+}
+
+// changeGeneratedReason returns why a changed file is generated, as generatedReason does
+// from the start of its content, or "" if it is not or generated files are included.
+// Files deleted by the changes are only checked by name.
+func (c *Config) changeGeneratedReason(path string) string {
+	if c.IncludeGenerated {
+		return ""
+	}
+
+	var head []byte
+	if file, err := os.Open(path); err == nil {
+		head = make([]byte, generatedHeaderSize)
+		n, _ := io.ReadFull(file, head)
+		head = head[:n]
+		_ = file.Close()
+	}
+	if isBinaryFile(head) {
+		return ""
+	}
+	return generatedReason(path, head)
+}
+
+// diffSections splits a unified diff into the sections of each file, which start with a
+// "diff --git" line
+func diffSections(diff string) []string {
+	var sections []string
+	start := 0
+	for {
+		next := strings.Index(diff[start:], "\ndiff --git ")
+		if next < 0 {
+			break
+		}
+		end := start + next + 1
+		sections = append(sections, diff[start:end])
+		start = end
+	}
+	return append(sections, diff[start:])
+}
+
+// neighborFiles returns up to n files from the directory of each changed file, nearest to
+// it in name order, leaving out changed files and returning each file once
+func neighborFiles(changedPaths []string, changed map[string]bool, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+
+	var neighbors []string
+	seen := make(map[string]bool)
+	for _, path := range changedPaths {
+		dir := filepath.Dir(path)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		var candidates []string
+		for _, entry := range entries {
+			candidate := filepath.Join(dir, entry.Name())
+			if entry.Type().IsRegular() && (candidate == path || !changed[candidate]) {
+				candidates = append(candidates, candidate)
+			}
+		}
+		index := slices.Index(candidates, path)
+		if index < 0 {
+			continue
+		}
+
+		// Alternate between the files after and before the changed file
+		added := 0
+		for distance := 1; added < n && (index-distance >= 0 || index+distance < len(candidates)); distance++ {
+			for _, i := range []int{index + distance, index - distance} {
+				if added < n && i >= 0 && i < len(candidates) && !seen[candidates[i]] {
+					seen[candidates[i]] = true
+					neighbors = append(neighbors, candidates[i])
+					added++
+				}
+			}
+		}
+	}
+	return neighbors
+}
+
+// runGit runs git in dir, or the current directory if dir is "", and returns its output.
+// git is killed if ctx is cancelled.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git %s failed: %s", args[0], message)
+		}
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
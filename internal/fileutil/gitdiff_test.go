@@ -0,0 +1,301 @@
+package fileutil
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupDiffRepo creates a git repository with a main branch and a feature branch checked
+// out, and changes the current directory to it until the test ends
+func setupDiffRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	repo := t.TempDir()
+	if resolved, err := filepath.EvalSymlinks(repo); err == nil {
+		repo = resolved
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v: %s", strings.Join(args, " "), err, output)
+		}
+	}
+
+	git("init", "-q", "-b", "main")
+	writeRepoFiles(t, repo, map[string]string{
+		"store/a.go":      "package store // a",
+		"store/b.go":      "package store // b",
+		"store/c.go":      "package store // c",
+		"store/d.go":      "package store // d",
+		"store/legacy.go": "package store // legacy",
+		"api/api.go":      "package api",
+		"README.md":       "# Project",
+	})
+	git("add", ".")
+	git("commit", "-q", "-m", "Initial commit")
+
+	git("checkout", "-q", "-b", "feature")
+	writeRepoFiles(t, repo, map[string]string{
+		"store/c.go": "package store // c changed",
+		"api/new.go": "package api // new",
+	})
+	if err := os.Remove(filepath.Join(repo, "store", "legacy.go")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	git("add", "-A")
+	git("commit", "-q", "-m", "Change the store")
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(workDir) })
+	return repo
+}
+
+// relativePaths returns the paths of files relative to dir, leaving other paths as they are
+func relativePaths(dir string, files []FileMeta) []string {
+	var paths []string
+	for _, file := range files {
+		if relPath, err := filepath.Rel(dir, file.Path); err == nil && filepath.IsAbs(file.Path) {
+			paths = append(paths, filepath.ToSlash(relPath))
+		} else {
+			paths = append(paths, file.Path)
+		}
+	}
+	return paths
+}
+
+func TestGatherChangedFiles(t *testing.T) {
+	repo := setupDiffRepo(t)
+
+	// Uncommitted changes: one staged, one not
+	writeRepoFiles(t, repo, map[string]string{
+		"README.md":  "# Project\n\nStaged.",
+		"api/api.go": "package api // unstaged",
+	})
+	if output, err := exec.Command("git", "add", "README.md").CombinedOutput(); err != nil {
+		t.Fatalf("git add failed: %v: %s", err, output)
+	}
+
+	tests := []struct {
+		name          string
+		paths         []string
+		opts          DiffOptions
+		exclude       string
+		expectedFiles string
+		expectedDiff  []string
+		excludedDiff  []string
+	}{
+		{
+			name:          "Branch since it left the base",
+			paths:         []string{"."},
+			opts:          DiffOptions{Range: "main"},
+			expectedFiles: "git diff main...HEAD,api/new.go,store/c.go",
+			expectedDiff:  []string{"+package store // c changed", "-package store // legacy"},
+		},
+		{
+			name:          "Range",
+			paths:         []string{"."},
+			opts:          DiffOptions{Range: "main..feature"},
+			expectedFiles: "git diff main..feature,api/new.go,store/c.go",
+		},
+		{
+			name:          "Limited to paths",
+			paths:         []string{"store"},
+			opts:          DiffOptions{Range: "main"},
+			expectedFiles: "git diff main...HEAD,store/c.go",
+		},
+		{
+			name:          "Neighboring files",
+			paths:         []string{"store"},
+			opts:          DiffOptions{Range: "main", Neighbors: 2},
+			expectedFiles: "git diff main...HEAD,store/c.go,store/d.go,store/b.go",
+		},
+		{
+			name:          "Filtered",
+			paths:         []string{"."},
+			opts:          DiffOptions{Range: "main"},
+			exclude:       "api/**",
+			expectedFiles: "git diff main...HEAD,store/c.go",
+			expectedDiff:  []string{"+package store // c changed"},
+			excludedDiff:  []string{"api/new.go"},
+		},
+		{
+			name:          "Staged",
+			paths:         []string{"."},
+			opts:          DiffOptions{Staged: true},
+			expectedFiles: "git diff --cached,README.md",
+			expectedDiff:  []string{"+Staged."},
+		},
+		{
+			name:          "Since a revision, including uncommitted changes",
+			paths:         []string{"."},
+			opts:          DiffOptions{Since: "HEAD"},
+			expectedFiles: "git diff HEAD,README.md,api/api.go",
+			expectedDiff:  []string{"+package api // unstaged"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := NewConfig(false, "", tt.exclude, "", "", NewMockLogger())
+			changes, err := GatherChangedFiles(context.Background(), tt.paths, tt.opts, config)
+			if err != nil {
+				t.Fatalf("GatherChangedFiles returned an error: %v", err)
+			}
+
+			if got := strings.Join(relativePaths(repo, changes.Files), ","); got != tt.expectedFiles {
+				t.Errorf("Expected files %s, got %s", tt.expectedFiles, got)
+			}
+			if changes.Processed != len(changes.Files)-1 {
+				t.Errorf("Expected %d processed files, got %d", len(changes.Files)-1, changes.Processed)
+			}
+			for _, line := range tt.expectedDiff {
+				if !strings.Contains(changes.Files[0].Content, line) {
+					t.Errorf("Expected the diff to contain %q, got:\n%s", line, changes.Files[0].Content)
+				}
+			}
+			for _, line := range tt.excludedDiff {
+				if strings.Contains(changes.Files[0].Content, line) {
+					t.Errorf("Expected the diff not to contain %q, got:\n%s", line, changes.Files[0].Content)
+				}
+			}
+			for _, path := range changes.ChangedPaths {
+				if strings.HasSuffix(path, "d.go") || strings.HasSuffix(path, "b.go") {
+					t.Errorf("Expected neighboring file %s not to be among the changed paths", path)
+				}
+			}
+		})
+	}
+
+	t.Run("Size limits", func(t *testing.T) {
+		config := NewConfig(false, "", "", "", "", NewMockLogger())
+		config.MaxFileSize = 200
+		changes, err := GatherChangedFiles(context.Background(), []string{"."}, DiffOptions{Range: "main"}, config)
+		if err != nil {
+			t.Fatalf("GatherChangedFiles returned an error: %v", err)
+		}
+
+		if got := strings.Join(relativePaths(repo, changes.Files), ","); got != "git diff main...HEAD,api/new.go,store/c.go" {
+			t.Errorf("Expected the diff and the changed files, got %s", got)
+		}
+		if !strings.Contains(changes.Files[0].Content, "[truncated:") {
+			t.Errorf("Expected the diff to be truncated, got:\n%s", changes.Files[0].Content)
+		}
+		if len(config.truncated) != 1 || config.truncated[0] != "git diff main...HEAD" {
+			t.Errorf("Expected the diff to be reported as truncated, got %v", config.truncated)
+		}
+
+		// The diff counts towards the total size
+		var total int64
+		for _, file := range changes.Files {
+			total += int64(len(file.Content))
+		}
+		if config.totalBytes != total {
+			t.Errorf("Expected a total size of %d bytes, got %d", total, config.totalBytes)
+		}
+	})
+
+	t.Run("Generated files", func(t *testing.T) {
+		writeRepoFiles(t, repo, map[string]string{
+			"package-lock.json": `{"lockfileVersion": 3}`,
+			"api/gen.go":        "// Code generated by stringer. DO NOT EDIT.\n\npackage api",
+		})
+		if output, err := exec.Command("git", "add", "package-lock.json", "api/gen.go").CombinedOutput(); err != nil {
+			t.Fatalf("git add failed: %v: %s", err, output)
+		}
+
+		config := NewConfig(false, "", "", "", "", NewMockLogger())
+		changes, err := GatherChangedFiles(context.Background(), []string{"."}, DiffOptions{Staged: true}, config)
+		if err != nil {
+			t.Fatalf("GatherChangedFiles returned an error: %v", err)
+		}
+		if got := strings.Join(relativePaths(repo, changes.Files), ","); got != "git diff --cached,README.md" {
+			t.Errorf("Expected the diff and README.md, got %s", got)
+		}
+		diff := changes.Files[0].Content
+		if !strings.Contains(diff, "+Staged.") || strings.Contains(diff, "lockfileVersion") || strings.Contains(diff, "Code generated") {
+			t.Errorf("Expected the diff to leave out the generated files, got:\n%s", diff)
+		}
+	})
+
+	t.Run("No changes", func(t *testing.T) {
+		config := NewConfig(false, "", "", "", "", NewMockLogger())
+		changes, err := GatherChangedFiles(context.Background(), []string{"."}, DiffOptions{Range: "feature..feature"}, config)
+		if err != nil {
+			t.Fatalf("GatherChangedFiles returned an error: %v", err)
+		}
+		if len(changes.Files) != 0 {
+			t.Errorf("Expected no files, got %v", relativePaths(repo, changes.Files))
+		}
+	})
+
+	t.Run("Unknown revision", func(t *testing.T) {
+		config := NewConfig(false, "", "", "", "", NewMockLogger())
+		_, err := GatherChangedFiles(context.Background(), []string{"."}, DiffOptions{Range: "no-such-branch"}, config)
+		if err == nil || !strings.Contains(err.Error(), "git diff failed") {
+			t.Errorf("Expected a git diff error, got: %v", err)
+		}
+	})
+
+	t.Run("Repository of the paths", func(t *testing.T) {
+		// Run from outside the repository
+		if err := os.Chdir(t.TempDir()); err != nil {
+			t.Fatalf("Failed to change directory: %v", err)
+		}
+		defer func() { _ = os.Chdir(repo) }()
+
+		config := NewConfig(false, "", "", "", "", NewMockLogger())
+		changes, err := GatherChangedFiles(context.Background(), []string{filepath.Join(repo, "store")}, DiffOptions{Range: "main"}, config)
+		if err != nil {
+			t.Fatalf("GatherChangedFiles returned an error: %v", err)
+		}
+		if got := strings.Join(relativePaths(repo, changes.Files), ","); got != "git diff main...HEAD,store/c.go" {
+			t.Errorf("Expected the changes in the store, got %s", got)
+		}
+
+		_, err = GatherChangedFiles(context.Background(), []string{repo, t.TempDir()}, DiffOptions{Range: "main"}, config)
+		if err == nil || !strings.Contains(err.Error(), "outside the git repository") {
+			t.Errorf("Expected an error for a path outside the repository, got: %v", err)
+		}
+	})
+}
+
+func TestDiffOptionsValidate(t *testing.T) {
+	tests := []struct {
+		opts          DiffOptions
+		errorContains string
+	}{
+		{DiffOptions{}, ""},
+		{DiffOptions{Range: "main", Neighbors: 3}, ""},
+		{DiffOptions{Range: "main", Staged: true}, "only one of"},
+		{DiffOptions{Staged: true, Since: "HEAD~1"}, "only one of"},
+		{DiffOptions{Staged: true, Neighbors: -1}, "neighboring files"},
+	}
+
+	for _, tt := range tests {
+		err := tt.opts.Validate()
+		if tt.errorContains == "" && err != nil {
+			t.Errorf("Validate(%+v) returned an error: %v", tt.opts, err)
+		}
+		if tt.errorContains != "" && (err == nil || !strings.Contains(err.Error(), tt.errorContains)) {
+			t.Errorf("Validate(%+v) = %v, want an error containing %q", tt.opts, err, tt.errorContains)
+		}
+	}
+}
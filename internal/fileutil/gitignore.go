@@ -0,0 +1,258 @@
+package fileutil
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// gitIgnoreMatcher decides whether paths are ignored by git without running git. For each
+// repository it loads the global excludes file, .git/info/exclude and the .gitignore files
+// of the directories involved, once, and caches the decisions for directories. A path is
+// ignored when the last pattern matching it ignores it, with the global excludes taking
+// the lowest precedence and the .gitignore files of deeper directories the highest, or
+// when a directory containing it is ignored. It is safe for concurrent use.
+type gitIgnoreMatcher struct {
+	mu sync.Mutex
+
+	globalExcludes *string                   // content of the global excludes file, once loaded
+	repoRoots      map[string]string         // repository root by directory ("" outside a repository)
+	repoRules      map[string][]*IgnoreRules // global and .git/info/exclude rules by repository root
+	dirRules       map[string]*IgnoreRules   // rules of the .gitignore file by directory (nil if none)
+	ignoredDirs    map[string]bool           // cached decisions for directories
+}
+
+// newGitIgnoreMatcher creates a matcher with empty caches
+func newGitIgnoreMatcher() *gitIgnoreMatcher {
+	return &gitIgnoreMatcher{
+		repoRoots:   make(map[string]string),
+		repoRules:   make(map[string][]*IgnoreRules),
+		dirRules:    make(map[string]*IgnoreRules),
+		ignoredDirs: make(map[string]bool),
+	}
+}
+
+// isIgnored reports whether git ignores path, which is a directory if isDir is set.
+// Paths outside a git repository are never ignored.
+func (m *gitIgnoreMatcher) isIgnored(path string, isDir bool) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	root := m.repoRoot(filepath.Dir(absPath))
+	if root == "" || absPath == root {
+		return false
+	}
+	if isDir {
+		return m.isDirIgnored(root, absPath)
+	}
+	if parent := filepath.Dir(absPath); parent != root && m.isDirIgnored(root, parent) {
+		return true
+	}
+	return m.matchRules(root, absPath, false)
+}
+
+// isDirIgnored reports whether the directory dir of the repository at root is ignored,
+// either by a pattern or because a directory containing it is ignored
+func (m *gitIgnoreMatcher) isDirIgnored(root, dir string) bool {
+	if ignored, ok := m.ignoredDirs[dir]; ok {
+		return ignored
+	}
+	ignored := false
+	if parent := filepath.Dir(dir); parent != root && parent != dir {
+		ignored = m.isDirIgnored(root, parent)
+	}
+	if !ignored {
+		ignored = m.matchRules(root, dir, true)
+	}
+	m.ignoredDirs[dir] = ignored
+	return ignored
+}
+
+// matchRules applies the rules of the repository at root and of the .gitignore files from
+// the root down to the directory containing path; the last matching pattern decides
+func (m *gitIgnoreMatcher) matchRules(root, path string, isDir bool) bool {
+	ignored := false
+	apply := func(rules *IgnoreRules) {
+		if rules == nil {
+			return
+		}
+		if matched, ruleIgnored := rules.Match(path, isDir); matched {
+			ignored = ruleIgnored
+		}
+	}
+
+	for _, rules := range m.rulesOfRepo(root) {
+		apply(rules)
+	}
+
+	// The .gitignore files from the root down to the directory containing path
+	var dirs []string
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if dir == root || dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		apply(m.gitIgnoreRules(dirs[i]))
+	}
+	return ignored
+}
+
+// repoRoot returns the root of the git repository containing dir (the nearest directory
+// with a .git directory or file), or "" if dir is not in a repository
+func (m *gitIgnoreMatcher) repoRoot(dir string) string {
+	if root, ok := m.repoRoots[dir]; ok {
+		return root
+	}
+	root := ""
+	if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+		root = dir
+	} else if parent := filepath.Dir(dir); parent != dir {
+		root = m.repoRoot(parent)
+	}
+	m.repoRoots[dir] = root
+	return root
+}
+
+// rulesOfRepo returns the rules that apply to the whole repository at root: the global
+// excludes file and .git/info/exclude
+func (m *gitIgnoreMatcher) rulesOfRepo(root string) []*IgnoreRules {
+	if rules, ok := m.repoRules[root]; ok {
+		return rules
+	}
+
+	if m.globalExcludes == nil {
+		content := ""
+		if path := globalExcludesFile(); path != "" {
+			if data, err := os.ReadFile(path); err == nil {
+				content = string(data)
+			}
+		}
+		m.globalExcludes = &content
+	}
+
+	var rules []*IgnoreRules
+	if *m.globalExcludes != "" {
+		rules = append(rules, ParseIgnoreRules(root, *m.globalExcludes))
+	}
+	if gitDir := resolveGitDir(root); gitDir != "" {
+		if data, err := os.ReadFile(filepath.Join(gitDir, "info", "exclude")); err == nil {
+			rules = append(rules, ParseIgnoreRules(root, string(data)))
+		}
+	}
+	m.repoRules[root] = rules
+	return rules
+}
+
+// gitIgnoreRules returns the rules of the .gitignore file in dir, or nil if there is none
+func (m *gitIgnoreMatcher) gitIgnoreRules(dir string) *IgnoreRules {
+	if rules, ok := m.dirRules[dir]; ok {
+		return rules
+	}
+	rules, err := LoadIgnoreFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		rules = nil
+	}
+	m.dirRules[dir] = rules
+	return rules
+}
+
+// resolveGitDir returns the git directory of the repository at root: root/.git, or the
+// directory named by a .git file (as in worktrees and submodules). It returns "" when
+// neither exists.
+func resolveGitDir(root string) string {
+	gitPath := filepath.Join(root, ".git")
+	info, err := os.Stat(gitPath)
+	if err != nil {
+		return ""
+	}
+	if info.IsDir() {
+		return gitPath
+	}
+
+	content, err := os.ReadFile(gitPath)
+	if err != nil {
+		return ""
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+	if !ok {
+		return ""
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(root, gitDir)
+	}
+	return gitDir
+}
+
+// globalExcludesFile returns the path of git's global excludes file: core.excludesFile
+// from the user's git configuration, or git's default location
+func globalExcludesFile() string {
+	homeDir, _ := os.UserHomeDir()
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" && homeDir != "" {
+		configHome = filepath.Join(homeDir, ".config")
+	}
+
+	var configFiles []string
+	if configHome != "" {
+		configFiles = append(configFiles, filepath.Join(configHome, "git", "config"))
+	}
+	if homeDir != "" {
+		configFiles = append(configFiles, filepath.Join(homeDir, ".gitconfig")) // Takes precedence
+	}
+
+	excludesFile := ""
+	for _, configFile := range configFiles {
+		if value := readCoreExcludesFile(configFile); value != "" {
+			excludesFile = value
+		}
+	}
+	if excludesFile == "" {
+		if configHome == "" {
+			return ""
+		}
+		return filepath.Join(configHome, "git", "ignore")
+	}
+	if rest, ok := strings.CutPrefix(excludesFile, "~/"); ok && homeDir != "" {
+		excludesFile = filepath.Join(homeDir, rest)
+	}
+	return excludesFile
+}
+
+// readCoreExcludesFile returns the value of core.excludesFile in a git configuration
+// file, or "" if it is not set
+func readCoreExcludesFile(configFile string) string {
+	file, err := os.Open(configFile)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = file.Close() }()
+
+	value := ""
+	inCore := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			inCore = strings.EqualFold(strings.Trim(line, "[] \t"), "core")
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		if inCore && ok && strings.EqualFold(strings.TrimSpace(key), "excludesfile") {
+			value = strings.Trim(strings.TrimSpace(val), `"`)
+		}
+	}
+	return value
+}
@@ -0,0 +1,276 @@
+// internal/fileutil/goimports.go
+package fileutil
+
+import (
+	"bufio"
+	"context"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ImportOptions controls how the Go files and packages given as context paths are
+// expanded with the local packages they are connected to
+type ImportOptions struct {
+	// Depth is the number of levels of local imports to follow from the given packages:
+	// 1 adds the packages they import, 2 also the packages those import, and so on
+	Depth int
+	// Importers adds the local packages that import the given packages
+	Importers bool
+}
+
+// Enabled reports whether the context paths are expanded
+func (o ImportOptions) Enabled() bool {
+	return o.Depth > 0 || o.Importers
+}
+
+// goModule holds the import graph of the packages of a Go module, as far as it is known
+type goModule struct {
+	root    string              // Directory containing go.mod
+	path    string              // Module path declared in go.mod
+	imports map[string][]string // Directories of the local packages imported, by file or package directory
+}
+
+// ExpandGoImports returns the Go files of the local packages connected to the Go files
+// and packages in paths, as selected by opts: the packages they import, transitively up
+// to opts.Depth levels, and the packages that import them. Packages are local when their
+// import path is within the module path declared in go.mod. A directory in paths stands
+// for every package under it. Only non-test files are returned, leaving out the packages
+// already covered by paths; build constraints are not evaluated. ctx.Err() is returned if
+// ctx is cancelled while the packages are parsed.
+func ExpandGoImports(ctx context.Context, paths []string, opts ImportOptions, config *Config) ([]string, error) {
+	if !opts.Enabled() {
+		return nil, nil
+	}
+
+	modules := make(map[string]*goModule) // By root, including nil for directories outside modules
+	covered := make(map[string]bool)      // Package directories already in the context
+	var starts []string                   // Files and package directories given
+	var startPackages []string
+
+	for _, p := range paths {
+		absPath, err := filepath.Abs(p)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(absPath)
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() {
+			if strings.HasSuffix(absPath, ".go") {
+				starts = append(starts, absPath)
+				startPackages = append(startPackages, filepath.Dir(absPath))
+			}
+			continue
+		}
+		for _, dir := range goPackageDirs(absPath) {
+			covered[dir] = true
+			starts = append(starts, dir)
+			startPackages = append(startPackages, dir)
+		}
+	}
+
+	var added []string
+	add := func(dir string) bool {
+		if covered[dir] {
+			return false
+		}
+		covered[dir] = true
+		added = append(added, dir)
+		return true
+	}
+
+	// Follow the imports level by level, starting with those of the files and packages given
+	frontier := starts
+	for level := 0; level < opts.Depth && len(frontier) > 0; level++ {
+		var next []string
+		for _, item := range frontier {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			module := findGoModule(modules, item)
+			if module == nil {
+				continue
+			}
+			for _, dir := range module.localImports(item) {
+				if add(dir) {
+					config.Logger.Printf("Verbose: Including imported package %s\n", dir)
+					next = append(next, dir)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	// Add the packages importing the packages given
+	if opts.Importers {
+		imported := make(map[string]bool)
+		for _, dir := range startPackages {
+			imported[dir] = true
+		}
+		scanned := make(map[string]bool)
+		for _, dir := range startPackages {
+			module := findGoModule(modules, dir)
+			if module == nil || scanned[module.root] {
+				continue
+			}
+			scanned[module.root] = true
+			for _, pkg := range goPackageDirs(module.root) {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				for _, dep := range module.localImports(pkg) {
+					if imported[dep] && add(pkg) {
+						config.Logger.Printf("Verbose: Including importing package %s\n", pkg)
+						break
+					}
+				}
+			}
+		}
+	}
+
+	var files []string
+	for _, dir := range added {
+		files = append(files, goSourceFiles(dir)...)
+	}
+	return files, nil
+}
+
+// findGoModule returns the module containing a file or directory, or nil if it is not
+// in a Go module. Modules are cached by root directory.
+func findGoModule(modules map[string]*goModule, path string) *goModule {
+	dir := path
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	for {
+		if module, ok := modules[dir]; ok {
+			return module
+		}
+		if modulePath := readModulePath(filepath.Join(dir, "go.mod")); modulePath != "" {
+			module := &goModule{root: dir, path: modulePath, imports: make(map[string][]string)}
+			modules[dir] = module
+			return module
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// readModulePath returns the module path declared in a go.mod file, or "" if the file
+// cannot be read or declares none
+func readModulePath(goModPath string) string {
+	file, err := os.Open(goModPath)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if modulePath, ok := strings.CutPrefix(line, "module "); ok {
+			modulePath = strings.TrimSpace(modulePath)
+			if unquoted, err := strconv.Unquote(modulePath); err == nil {
+				modulePath = unquoted
+			}
+			return modulePath
+		}
+	}
+	return ""
+}
+
+// localImports returns the directories of the packages of the module imported by a Go
+// file, or by the non-test files of a package directory
+func (m *goModule) localImports(path string) []string {
+	if dirs, ok := m.imports[path]; ok {
+		return dirs
+	}
+
+	var files []string
+	if strings.HasSuffix(path, ".go") {
+		files = []string{path}
+	} else {
+		files = goSourceFiles(path)
+	}
+
+	var dirs []string
+	fset := token.NewFileSet()
+	for _, file := range files {
+		parsed, err := parser.ParseFile(fset, file, nil, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		for _, spec := range parsed.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			var dir string
+			if importPath == m.path {
+				dir = m.root
+			} else if rest, ok := strings.CutPrefix(importPath, m.path+"/"); ok {
+				dir = filepath.Join(m.root, filepath.FromSlash(rest))
+			} else {
+				continue
+			}
+			if !slices.Contains(dirs, dir) {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	slices.Sort(dirs)
+
+	m.imports[path] = dirs
+	return dirs
+}
+
+// goPackageDirs returns the directories under root containing Go source files, skipping
+// hidden directories, testdata, vendor and nested modules
+func goPackageDirs(root string) []string {
+	var dirs []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != root {
+			name := d.Name()
+			if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor" {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+		if len(goSourceFiles(path)) > 0 {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	return dirs
+}
+
+// goSourceFiles returns the Go files of a package directory, leaving out tests
+func goSourceFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	return files
+}
@@ -0,0 +1,113 @@
+package fileutil
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandGoImports(t *testing.T) {
+	module := t.TempDir()
+	writeRepoFiles(t, module, map[string]string{
+		"go.mod":                         "module example.com/app\n\ngo 1.23\n",
+		"main.go":                        "package main\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/app/internal/server\"\n)\n",
+		"internal/server/server.go":      "package server\n\nimport \"example.com/app/internal/store\"\n",
+		"internal/server/server_test.go": "package server\n\nimport \"example.com/app/internal/testutil\"\n",
+		"internal/store/store.go":        "package store\n\nimport m \"example.com/app/internal/model\"\n",
+		"internal/store/query.go":        "package store\n",
+		"internal/model/model.go":        "package model\n",
+		"internal/testutil/util.go":      "package testutil\n",
+		"cmd/tool/tool.go":               "package main\n\nimport \"example.com/app/internal/store\"\n",
+		"nested/go.mod":                  "module example.com/nested\n",
+		"nested/x.go":                    "package nested\n\nimport \"example.com/app/internal/store\"\n",
+	})
+
+	tests := []struct {
+		name     string
+		paths    []string
+		opts     ImportOptions
+		expected string
+	}{
+		{
+			name:     "Disabled",
+			paths:    []string{"internal/server"},
+			opts:     ImportOptions{},
+			expected: "",
+		},
+		{
+			name:     "Direct imports of a package",
+			paths:    []string{"internal/server"},
+			opts:     ImportOptions{Depth: 1},
+			expected: "internal/store/query.go,internal/store/store.go",
+		},
+		{
+			name:     "Transitive imports",
+			paths:    []string{"internal/server"},
+			opts:     ImportOptions{Depth: 2},
+			expected: "internal/store/query.go,internal/store/store.go,internal/model/model.go",
+		},
+		{
+			name:     "Imports of a single file",
+			paths:    []string{"main.go"},
+			opts:     ImportOptions{Depth: 1},
+			expected: "internal/server/server.go",
+		},
+		{
+			name:     "Importers",
+			paths:    []string{"internal/store"},
+			opts:     ImportOptions{Importers: true},
+			expected: "cmd/tool/tool.go,internal/server/server.go",
+		},
+		{
+			name:     "Packages under a directory are already covered",
+			paths:    []string{"internal"},
+			opts:     ImportOptions{Depth: 3, Importers: true},
+			expected: "main.go,cmd/tool/tool.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			for _, p := range tt.paths {
+				paths = append(paths, filepath.Join(module, p))
+			}
+
+			files, err := ExpandGoImports(context.Background(), paths, tt.opts, NewConfig(false, "", "", "", "", NewMockLogger()))
+			if err != nil {
+				t.Fatalf("ExpandGoImports returned an error: %v", err)
+			}
+			var relPaths []string
+			for _, file := range files {
+				relPath, err := filepath.Rel(module, file)
+				if err != nil {
+					t.Fatalf("Failed to get relative path: %v", err)
+				}
+				relPaths = append(relPaths, filepath.ToSlash(relPath))
+			}
+			if got := strings.Join(relPaths, ","); got != tt.expected {
+				t.Errorf("Expected files %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("Outside a module", func(t *testing.T) {
+		dir := t.TempDir()
+		writeRepoFiles(t, dir, map[string]string{"main.go": "package main\n\nimport \"example.com/app/internal/store\"\n"})
+		files, err := ExpandGoImports(context.Background(), []string{dir}, ImportOptions{Depth: 1, Importers: true}, NewConfig(false, "", "", "", "", NewMockLogger()))
+		if err != nil || len(files) != 0 {
+			t.Errorf("Expected no files outside a module, got %v (error %v)", files, err)
+		}
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		files, err := ExpandGoImports(ctx, []string{filepath.Join(module, "main.go")}, ImportOptions{Depth: 3, Importers: true}, NewConfig(false, "", "", "", "", NewMockLogger()))
+		if !errors.Is(err, context.Canceled) || files != nil {
+			t.Errorf("Expected a cancellation error and no files, got %v (error %v)", files, err)
+		}
+	})
+}
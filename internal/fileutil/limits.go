@@ -0,0 +1,209 @@
+// internal/fileutil/limits.go
+package fileutil
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SkippedFile describes a file left out of the context, and why
+type SkippedFile struct {
+	Path   string
+	Reason string
+}
+
+// String formats the file and the reason it was skipped for logs
+func (f SkippedFile) String() string {
+	return fmt.Sprintf("%s (%s)", f.Path, f.Reason)
+}
+
+const (
+	// minTruncatedSize is the smallest remaining total size worth filling with a truncated file
+	minTruncatedSize = 1024
+
+	// generatedHeaderSize is how much of the start of a file is searched for generated code markers
+	generatedHeaderSize = 2048
+
+	// minifiedLineLength is the line length from which JavaScript and CSS are considered minified
+	minifiedLineLength = 1000
+)
+
+// sizeUnits are the units accepted by ParseSize, in powers of 1024
+var sizeUnits = map[string]int64{
+	"": 1, "b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gib": 1 << 30,
+}
+
+// ParseSize parses a size such as "512KB", "1.5MB" or "2048" (bytes). Units are powers
+// of 1024 and case-insensitive.
+func ParseSize(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	number := strings.TrimRightFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	unit := strings.ToLower(strings.TrimSpace(value[len(number):]))
+
+	multiplier, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", value, unit)
+	}
+	size, err := strconv.ParseFloat(number, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return int64(size * float64(multiplier)), nil
+}
+
+// FormatSize formats a number of bytes for people, e.g. "40.0 MB"
+func FormatSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+// readFileLimited reads a file of the given size. If it is larger than limit (and limit is
+// positive), only its head and tail are read, cut at line boundaries, and joined by a
+// marker saying how much was left out; truncated is then set.
+func readFileLimited(path string, size, limit int64) (content []byte, truncated bool, err error) {
+	if limit <= 0 || size <= limit {
+		content, err = os.ReadFile(path)
+		return content, false, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() { _ = file.Close() }()
+
+	head := make([]byte, limit/2)
+	if _, err := io.ReadFull(file, head); err != nil {
+		return nil, false, err
+	}
+	tail := make([]byte, limit-int64(len(head)))
+	if _, err := file.ReadAt(tail, size-int64(len(tail))); err != nil && err != io.EOF {
+		return nil, false, err
+	}
+	return joinTruncated(head, tail, size), true, nil
+}
+
+// truncateContent truncates content larger than limit to its head and tail, as
+// readFileLimited does for files
+func truncateContent(content []byte, limit int64) []byte {
+	size := int64(len(content))
+	if limit <= 0 || size <= limit {
+		return content
+	}
+	head := limit / 2
+	return joinTruncated(content[:head], content[size-(limit-head):], size)
+}
+
+// joinTruncated joins the head and tail of content of the given size, cut at line
+// boundaries, with a marker saying how much was left out
+func joinTruncated(head, tail []byte, size int64) []byte {
+	// Keep whole lines where possible
+	if i := bytes.LastIndexByte(head, '\n'); i > 0 {
+		head = head[:i+1]
+	}
+	if i := bytes.IndexByte(tail, '\n'); i >= 0 && i < len(tail)-1 {
+		tail = tail[i+1:]
+	}
+
+	omitted := size - int64(len(head)) - int64(len(tail))
+	marker := fmt.Sprintf("\n... [truncated: %s omitted from the middle of this %s file] ...\n\n",
+		FormatSize(omitted), FormatSize(size))
+	return slices.Concat(head, []byte(marker), tail)
+}
+
+// lockFileNames are dependency lock files, which are generated by package managers
+var lockFileNames = map[string]bool{
+	"package-lock.json": true, "npm-shrinkwrap.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"bun.lockb": true, "Cargo.lock": true, "Gemfile.lock": true, "poetry.lock": true, "Pipfile.lock": true,
+	"uv.lock": true, "composer.lock": true, "go.sum": true, "mix.lock": true, "Podfile.lock": true,
+	"packages.lock.json": true, "flake.lock": true, "pubspec.lock": true, "gradle.lockfile": true,
+}
+
+// generatedReason returns why a file appears to be generated rather than written by
+// hand, or "" if it does not: a dependency lock file, a comment near the start marking
+// it as generated, or minified JavaScript or CSS
+func generatedReason(path string, content []byte) string {
+	base := filepath.Base(path)
+	if isLockFile(base) {
+		return "lock file"
+	}
+
+	header := string(content[:min(len(content), generatedHeaderSize)])
+	for _, line := range strings.Split(header, "\n") {
+		if isGeneratedMarker(strings.TrimSpace(line)) {
+			return "generated code"
+		}
+	}
+
+	switch strings.ToLower(filepath.Ext(base)) {
+	case ".js", ".mjs", ".cjs", ".css":
+		if strings.Contains(base, ".min.") || hasLongLine(content, minifiedLineLength) {
+			return "minified"
+		}
+	}
+	return ""
+}
+
+// isLockFile reports whether a file name is that of a dependency lock file
+func isLockFile(name string) bool {
+	return lockFileNames[name] || strings.HasSuffix(name, ".lock") || strings.HasSuffix(name, "-lock.json") ||
+		strings.HasSuffix(name, "-lock.yaml")
+}
+
+// isGeneratedMarker reports whether a line is a comment marking its file as generated:
+// Go's "Code generated ... DO NOT EDIT.", an "@generated" tag, or a note that the file
+// was automatically generated and must not be edited
+func isGeneratedMarker(line string) bool {
+	isComment := false
+	for _, prefix := range []string{"//", "#", "/*", "*", "<!--", "--", ";", "%"} {
+		if strings.HasPrefix(line, prefix) {
+			isComment = true
+			break
+		}
+	}
+	if !isComment {
+		return false
+	}
+	if strings.Contains(line, "@generated") || (strings.Contains(line, "Code generated") && strings.Contains(line, "DO NOT EDIT")) {
+		return true
+	}
+
+	lower := strings.ToLower(line)
+	automatic := strings.Contains(lower, "automatically generated") || strings.Contains(lower, "auto-generated") ||
+		strings.Contains(lower, "autogenerated")
+	return automatic && (strings.Contains(lower, "do not edit") || strings.Contains(lower, "do not modify"))
+}
+
+// hasLongLine reports whether content has a line longer than length
+func hasLongLine(content []byte, length int) bool {
+	for len(content) > 0 {
+		end := bytes.IndexByte(content, '\n')
+		if end < 0 {
+			end = len(content)
+		}
+		if end > length {
+			return true
+		}
+		content = content[min(end+1, len(content)):]
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package fileutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+		wantErr  bool
+	}{
+		{"0", 0, false},
+		{"2048", 2048, false},
+		{"512KB", 512 << 10, false},
+		{"1.5mb", 3 << 19, false},
+		{"2 GiB", 2 << 30, false},
+		{"1.0 MB", 1 << 20, false}, // As formatted by FormatSize
+		{"10TB", 0, true},
+		{"MB", 0, true},
+		{"-1KB", 0, true},
+	}
+
+	for _, tt := range tests {
+		size, err := ParseSize(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSize(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if size != tt.expected {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.value, size, tt.expected)
+		}
+	}
+
+	if got := FormatSize(40 << 20); got != "40.0 MB" {
+		t.Errorf("FormatSize(40MB) = %q", got)
+	}
+	if got := FormatSize(300); got != "300 B" {
+		t.Errorf("FormatSize(300) = %q", got)
+	}
+}
+
+func TestGeneratedReason(t *testing.T) {
+	tests := []struct {
+		path     string
+		content  string
+		expected string
+	}{
+		{"api/service.pb.go", "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n", "generated code"},
+		{"schema.ts", "/**\n * This file was automatically generated. Do not modify it by hand.\n */\n", "generated code"},
+		{"Foo.java", "// @generated by the build\nclass Foo {}\n", "generated code"},
+		{"web/yarn.lock", "# yarn lockfile v1\n", "lock file"},
+		{"deps/custom.lock", "pinned\n", "lock file"},
+		{"static/app.min.js", "var a=1;\n", "minified"},
+		{"static/bundle.css", strings.Repeat("a{color:red}", 100), "minified"},
+		{"config.py", "# auto-generated from schema.yaml, do not edit\n", "generated code"},
+		{"main.go", "package main\n\n// The generated report is written to disk; do not edit it here.\n", ""},
+		{"README.md", "Generated files are skipped.\n\nDo not edit generated files.\n", ""},
+		{"gen_test.go", "var marker = \"// Code generated by x. DO NOT EDIT.\"\n", ""},
+		{"app.js", "function main() {\n  return 1;\n}\n", ""},
+	}
+
+	for _, tt := range tests {
+		if got := generatedReason(tt.path, []byte(tt.content)); got != tt.expected {
+			t.Errorf("generatedReason(%q) = %q, want %q", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestReadFileLimited(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.txt")
+	var lines []string
+	for i := 0; i < 1000; i++ {
+		lines = append(lines, strings.Repeat("x", 30)+" line")
+	}
+	content := strings.Join(lines, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	size := int64(len(content))
+
+	read, truncated, err := readFileLimited(path, size, 0)
+	if err != nil || truncated || string(read) != content {
+		t.Fatalf("Expected the whole file without a limit, got truncated=%v err=%v", truncated, err)
+	}
+
+	read, truncated, err = readFileLimited(path, size, 1000)
+	if err != nil {
+		t.Fatalf("readFileLimited returned an error: %v", err)
+	}
+	if !truncated {
+		t.Fatal("Expected the file to be truncated")
+	}
+	text := string(read)
+	if !strings.HasPrefix(text, lines[0]+"\n") || !strings.HasSuffix(text, lines[len(lines)-1]+"\n") {
+		t.Errorf("Expected the head and tail of the file, got:\n%s", text)
+	}
+	if !strings.Contains(text, "[truncated:") || !strings.Contains(text, "of this "+FormatSize(size)+" file") {
+		t.Errorf("Expected a truncation marker, got:\n%s", text)
+	}
+	for _, part := range strings.Split(text, "\n") {
+		if part != "" && !strings.HasSuffix(part, " line") && !strings.Contains(part, "[truncated:") {
+			t.Errorf("Expected whole lines only, got %q", part)
+		}
+	}
+}
+
+func TestGatherProjectContextLimits(t *testing.T) {
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		"a.go":           "package a\n",
+		"b.go":           strings.Repeat("// comment\n", 400),
+		"c.go":           strings.Repeat("// comment\n", 400),
+		"d.go":           "package d\n",
+		"gen.pb.go":      "// Code generated by protoc-gen-go. DO NOT EDIT.\npackage a\n",
+		"pnpm-lock.yaml": "lockfileVersion: 6.0\n",
+	})
+
+	tests := []struct {
+		name              string
+		maxFileSize       int64
+		maxTotalSize      int64
+		includeGenerated  bool
+		expectedFiles     string
+		expectedSkipped   string
+		expectedTruncated string
+	}{
+		{
+			name:            "Generated files are skipped",
+			expectedFiles:   "a.go,b.go,c.go,d.go",
+			expectedSkipped: "gen.pb.go (generated code),pnpm-lock.yaml (lock file)",
+		},
+		{
+			name:             "Generated files can be included",
+			includeGenerated: true,
+			expectedFiles:    "a.go,b.go,c.go,d.go,gen.pb.go,pnpm-lock.yaml",
+		},
+		{
+			name:              "Large files are truncated",
+			maxFileSize:       2000,
+			expectedFiles:     "a.go,b.go,c.go,d.go",
+			expectedSkipped:   "gen.pb.go (generated code),pnpm-lock.yaml (lock file)",
+			expectedTruncated: "b.go,c.go",
+		},
+		{
+			name:              "Files beyond the total size are skipped",
+			maxTotalSize:      6000,
+			expectedFiles:     "a.go,b.go,c.go",
+			expectedSkipped:   "d.go (total size limit reached),gen.pb.go (total size limit reached),pnpm-lock.yaml (lock file)",
+			expectedTruncated: "c.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := NewConfig(false, "", "", "", "", NewMockLogger())
+			config.MaxFileSize = tt.maxFileSize
+			config.MaxTotalSize = tt.maxTotalSize
+			config.IncludeGenerated = tt.includeGenerated
+
+			files, _, err := GatherProjectContext(context.Background(), []string{dir}, config)
+			if err != nil {
+				t.Fatalf("GatherProjectContext returned an error: %v", err)
+			}
+
+			base := func(paths []string) string {
+				var names []string
+				for _, path := range paths {
+					names = append(names, filepath.Base(path))
+				}
+				sort.Strings(names)
+				return strings.Join(names, ",")
+			}
+			var paths, skipped []string
+			for _, file := range files {
+				paths = append(paths, file.Path)
+			}
+			for _, file := range config.SkippedFiles() {
+				skipped = append(skipped, filepath.Base(file.Path)+" ("+file.Reason+")")
+			}
+			sort.Strings(skipped)
+
+			if got := base(paths); got != tt.expectedFiles {
+				t.Errorf("Expected files %s, got %s", tt.expectedFiles, got)
+			}
+			if got := strings.Join(skipped, ","); got != tt.expectedSkipped {
+				t.Errorf("Expected skipped files %s, got %s", tt.expectedSkipped, got)
+			}
+			if got := base(config.TruncatedFiles()); got != tt.expectedTruncated {
+				t.Errorf("Expected truncated files %s, got %s", tt.expectedTruncated, got)
+			}
+		})
+	}
+}
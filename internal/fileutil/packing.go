@@ -0,0 +1,252 @@
+// internal/fileutil/packing.go
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Packing strategies used to rank files that are not explicitly requested
+// when the gathered context exceeds the token budget.
+const (
+	// PackByRelevance ranks files by how many instruction terms appear in their path and content
+	PackByRelevance = "relevance"
+	// PackByRecency ranks the most recently modified files first
+	PackByRecency = "recency"
+	// PackBySize ranks the smallest files first, fitting as many files as possible
+	PackBySize = "size"
+)
+
+// PackStrategies lists the supported packing strategies
+var PackStrategies = []string{PackByRelevance, PackByRecency, PackBySize}
+
+const (
+	// charsPerToken is the approximate number of characters per token for source code and prose
+	charsPerToken = 4
+
+	// fileOverheadTokens approximates the tokens used by the path tags around each file in the prompt
+	fileOverheadTokens = 8
+
+	// minTruncatedTokens is the smallest remaining budget worth filling with a truncated file
+	minTruncatedTokens = 256
+)
+
+// PackOptions controls how gathered files are fitted into a token budget
+type PackOptions struct {
+	Budget        int      // Maximum estimated tokens for all file content (<= 0 disables packing)
+	Strategy      string   // Ranking strategy for files that were not explicitly requested
+	ExplicitPaths []string // Paths named directly by the user; matching files are always ranked first
+	Instructions  string   // Instructions used to rank files by relevance
+
+	// FileTokens estimates the tokens a file adds to the prompt as it is rendered there.
+	// When nil, the file's content and path are estimated with a fixed allowance for tags.
+	FileTokens func(file FileMeta) int
+}
+
+// fileTokens returns the estimator of the tokens a file adds to the prompt
+func (o PackOptions) fileTokens() func(file FileMeta) int {
+	if o.FileTokens != nil {
+		return o.FileTokens
+	}
+	return estimateFileTokens
+}
+
+// OmittedFile describes a file dropped from the context to fit the token budget
+type OmittedFile struct {
+	Path   string
+	Tokens int // Estimated tokens the file would have used
+}
+
+// PackResult holds the outcome of fitting files into a token budget
+type PackResult struct {
+	Files           []FileMeta    // Files kept, in their original order; some may be truncated
+	Omitted         []OmittedFile // Files dropped entirely, in ranked order
+	Truncated       []string      // Paths of kept files whose content was truncated
+	EstimatedTokens int           // Estimated tokens used by the kept files
+}
+
+// IsValidPackStrategy reports whether strategy names a supported packing strategy
+func IsValidPackStrategy(strategy string) bool {
+	for _, s := range PackStrategies {
+		if s == strategy {
+			return true
+		}
+	}
+	return false
+}
+
+// EstimateTokens approximates the number of tokens in text without calling a provider.
+// It takes the larger of the whitespace word count and a characters-per-token estimate,
+// since whitespace counting alone badly underestimates dense source code.
+func EstimateTokens(text string) int {
+	return max(estimateTokenCount(text), (len(text)+charsPerToken-1)/charsPerToken)
+}
+
+// estimateFileTokens approximates the tokens a file adds to the prompt, including its path tags
+func estimateFileTokens(file FileMeta) int {
+	return EstimateTokens(file.Content) + EstimateTokens(file.Path) + fileOverheadTokens
+}
+
+// PackFiles fits files into the token budget in opts. Files are ranked with explicitly
+// requested files first, followed by the rest ordered by the chosen strategy. Files are
+// kept in rank order while they fit; when a file does not fit, it is truncated to fill the
+// remaining budget if enough is left, and otherwise omitted. Smaller lower-ranked files
+// may still be kept after a larger file is omitted.
+func PackFiles(files []FileMeta, opts PackOptions) PackResult {
+	fileTokens := opts.fileTokens()
+	tokens := make([]int, len(files))
+	total := 0
+	for i, file := range files {
+		tokens[i] = fileTokens(file)
+		total += tokens[i]
+	}
+
+	// Everything fits (or there is no budget): keep all files unchanged
+	if opts.Budget <= 0 || total <= opts.Budget {
+		return PackResult{Files: files, EstimatedTokens: total}
+	}
+
+	order := rankFiles(files, tokens, opts)
+
+	kept := make(map[int]FileMeta, len(files))
+	var result PackResult
+	remaining := opts.Budget
+	for _, i := range order {
+		if tokens[i] <= remaining {
+			kept[i] = files[i]
+			remaining -= tokens[i]
+			continue
+		}
+
+		if remaining >= minTruncatedTokens {
+			truncated, used := truncateFile(files[i], remaining, fileTokens)
+			kept[i] = truncated
+			remaining -= used
+			result.Truncated = append(result.Truncated, files[i].Path)
+			continue
+		}
+
+		result.Omitted = append(result.Omitted, OmittedFile{Path: files[i].Path, Tokens: tokens[i]})
+	}
+
+	// Preserve the original gathering order for the kept files
+	for i := range files {
+		if file, ok := kept[i]; ok {
+			result.Files = append(result.Files, file)
+		}
+	}
+	result.EstimatedTokens = opts.Budget - remaining
+	return result
+}
+
+// truncateFile keeps as many leading lines of file as fit within budget tokens, as estimated
+// by fileTokens, and appends a marker noting how much was dropped. It returns the truncated
+// file and its estimated tokens.
+func truncateFile(file FileMeta, budget int, fileTokens func(file FileMeta) int) (FileMeta, int) {
+	lines := strings.SplitAfter(file.Content, "\n")
+	truncate := func(keptLines int) FileMeta {
+		content := strings.Join(lines[:keptLines], "") + truncationMarker(len(lines)-keptLines)
+		return FileMeta{Path: file.Path, Content: content, Size: file.Size}
+	}
+
+	// Find the most lines that fit, as the estimate grows with the lines kept
+	keptLines := sort.Search(len(lines), func(n int) bool {
+		return fileTokens(truncate(n+1)) > budget
+	})
+	truncated := truncate(keptLines)
+	return truncated, fileTokens(truncated)
+}
+
+// truncationMarker is appended to truncated files so the model knows content is missing
+func truncationMarker(omittedLines int) string {
+	return fmt.Sprintf("\n... [truncated: %d lines omitted to fit the token budget]", omittedLines)
+}
+
+// rankFiles returns the indexes of files in priority order: explicitly requested files
+// first, then the remaining files ordered by the packing strategy
+func rankFiles(files []FileMeta, tokens []int, opts PackOptions) []int {
+	explicit := make(map[string]bool, len(opts.ExplicitPaths))
+	for _, p := range opts.ExplicitPaths {
+		if abs, err := filepath.Abs(p); err == nil {
+			explicit[abs] = true
+		}
+		explicit[p] = true
+	}
+
+	order := make([]int, len(files))
+	for i := range files {
+		order[i] = i
+	}
+
+	var less func(a, b int) bool
+	switch opts.Strategy {
+	case PackByRecency:
+		modTimes := make([]time.Time, len(files))
+		for i, file := range files {
+			if info, err := os.Stat(file.Path); err == nil {
+				modTimes[i] = info.ModTime()
+			}
+		}
+		less = func(a, b int) bool { return modTimes[a].After(modTimes[b]) }
+	case PackBySize:
+		less = func(a, b int) bool { return tokens[a] < tokens[b] }
+	default:
+		terms := instructionTerms(opts.Instructions)
+		scores := make([]int, len(files))
+		for i, file := range files {
+			scores[i] = relevanceScore(file, terms)
+		}
+		less = func(a, b int) bool {
+			if scores[a] != scores[b] {
+				return scores[a] > scores[b]
+			}
+			return tokens[a] < tokens[b]
+		}
+	}
+
+	sort.SliceStable(order, func(x, y int) bool {
+		a, b := order[x], order[y]
+		if explicit[files[a].Path] != explicit[files[b].Path] {
+			return explicit[files[a].Path]
+		}
+		return less(a, b)
+	})
+	return order
+}
+
+// instructionTerms extracts the distinct lowercase words of at least three characters from instructions
+func instructionTerms(instructions string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(instructions), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		if len(word) >= 3 && !seen[word] {
+			seen[word] = true
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// relevanceScore counts the instruction terms found in a file, weighting matches
+// in the file path above matches in the content
+func relevanceScore(file FileMeta, terms []string) int {
+	path := strings.ToLower(file.Path)
+	content := strings.ToLower(file.Content)
+	score := 0
+	for _, term := range terms {
+		if strings.Contains(path, term) {
+			score += 3
+		}
+		if strings.Contains(content, term) {
+			score++
+		}
+	}
+	return score
+}
@@ -0,0 +1,196 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fileOfTokens creates a FileMeta whose content is estimated at roughly the given number of tokens
+func fileOfTokens(path string, tokens int) FileMeta {
+	return FileMeta{Path: path, Content: strings.Repeat("abc\n", tokens)}
+}
+
+// keptPaths returns the paths of the files kept by a pack result
+func keptPaths(result PackResult) []string {
+	paths := make([]string, 0, len(result.Files))
+	for _, file := range result.Files {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{"Empty", "", 0},
+		{"Words dominate", "a b c d e f g h", 8},
+		{"Characters dominate", "func(x,y)=>{return x*y;}", 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EstimateTokens(tt.text); got != tt.expected {
+				t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPackFilesWithinBudget(t *testing.T) {
+	files := []FileMeta{fileOfTokens("/a.go", 10), fileOfTokens("/b.go", 10)}
+
+	result := PackFiles(files, PackOptions{Budget: 1000})
+	if len(result.Files) != 2 || len(result.Omitted) != 0 || len(result.Truncated) != 0 {
+		t.Errorf("Expected all files kept unchanged, got %+v", result)
+	}
+
+	// A zero budget disables packing
+	result = PackFiles(files, PackOptions{Budget: 0})
+	if len(result.Files) != 2 {
+		t.Errorf("Expected all files kept without a budget, got %d", len(result.Files))
+	}
+	if result.EstimatedTokens != estimateFileTokens(files[0])+estimateFileTokens(files[1]) {
+		t.Errorf("Unexpected estimated tokens: %d", result.EstimatedTokens)
+	}
+}
+
+func TestPackFilesExplicitPathsFirst(t *testing.T) {
+	files := []FileMeta{
+		fileOfTokens("/src/small.go", 50),
+		fileOfTokens("/src/explicit.go", 500),
+		fileOfTokens("/src/other.go", 60),
+	}
+
+	result := PackFiles(files, PackOptions{
+		Budget:        600,
+		Strategy:      PackBySize,
+		ExplicitPaths: []string{"/src/explicit.go"},
+	})
+
+	kept := keptPaths(result)
+	if len(kept) != 2 || kept[0] != "/src/small.go" || kept[1] != "/src/explicit.go" {
+		t.Errorf("Expected explicit file and smallest file kept in original order, got %v", kept)
+	}
+	if len(result.Omitted) != 1 || result.Omitted[0].Path != "/src/other.go" {
+		t.Errorf("Expected other.go to be omitted, got %+v", result.Omitted)
+	}
+	if result.EstimatedTokens > 600 {
+		t.Errorf("Estimated tokens %d exceed budget", result.EstimatedTokens)
+	}
+}
+
+func TestPackFilesTruncatesWhenBudgetRemains(t *testing.T) {
+	files := []FileMeta{
+		fileOfTokens("/first.go", 100),
+		fileOfTokens("/second.go", 2000),
+	}
+
+	result := PackFiles(files, PackOptions{Budget: 1000, Strategy: PackBySize})
+
+	if len(result.Files) != 2 || len(result.Omitted) != 0 {
+		t.Fatalf("Expected both files kept, got kept=%v omitted=%+v", keptPaths(result), result.Omitted)
+	}
+	if len(result.Truncated) != 1 || result.Truncated[0] != "/second.go" {
+		t.Errorf("Expected second.go to be truncated, got %v", result.Truncated)
+	}
+	if !strings.Contains(result.Files[1].Content, "lines omitted to fit the token budget") {
+		t.Error("Expected truncated file to contain a truncation marker")
+	}
+	if result.EstimatedTokens > 1000 {
+		t.Errorf("Estimated tokens %d exceed budget", result.EstimatedTokens)
+	}
+	if files[1].Content == result.Files[1].Content {
+		t.Error("Expected truncated content to differ from the original")
+	}
+}
+
+func TestPackFilesByRelevance(t *testing.T) {
+	files := []FileMeta{
+		{Path: "/docs/readme.md", Content: strings.Repeat("general notes\n", 100)},
+		{Path: "/auth/login.go", Content: strings.Repeat("func login() { session() }\n", 100)},
+		{Path: "/util/strings.go", Content: strings.Repeat("func trim() {}\n", 100)},
+	}
+	budget := estimateFileTokens(files[1]) + 10
+
+	result := PackFiles(files, PackOptions{
+		Budget:       budget,
+		Strategy:     PackByRelevance,
+		Instructions: "Fix the login session bug",
+	})
+
+	kept := keptPaths(result)
+	if len(kept) != 1 || kept[0] != "/auth/login.go" {
+		t.Errorf("Expected only the relevant file to be kept, got %v", kept)
+	}
+	if len(result.Omitted) != 2 {
+		t.Errorf("Expected 2 omitted files, got %+v", result.Omitted)
+	}
+}
+
+func TestPackFilesByRecency(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.go")
+	newPath := filepath.Join(dir, "new.go")
+	content := strings.Repeat("abc\n", 300)
+	for _, path := range []string{oldPath, newPath} {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	past := time.Now().Add(-24 * time.Hour)
+	if err := os.Chtimes(oldPath, past, past); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	files := []FileMeta{{Path: oldPath, Content: content}, {Path: newPath, Content: content}}
+	result := PackFiles(files, PackOptions{Budget: estimateFileTokens(files[1]) + 10, Strategy: PackByRecency})
+
+	kept := keptPaths(result)
+	if len(kept) != 1 || kept[0] != newPath {
+		t.Errorf("Expected only the most recent file to be kept, got %v", kept)
+	}
+}
+
+// TestPackFilesWithFileTokens tests that files are packed by their tokens as rendered in
+// the prompt, when an estimator is given
+func TestPackFilesWithFileTokens(t *testing.T) {
+	// Each line costs an extra 5 tokens, as with line numbers
+	fileTokens := func(file FileMeta) int {
+		return EstimateTokens(file.Content) + 5*strings.Count(file.Content, "\n")
+	}
+	files := []FileMeta{
+		fileOfTokens("/first.go", 100),
+		fileOfTokens("/second.go", 400),
+	}
+
+	// Both files fit by the default estimate, but not as rendered
+	result := PackFiles(files, PackOptions{Budget: 1000, Strategy: PackBySize, FileTokens: fileTokens})
+
+	if len(result.Files) != 2 || len(result.Truncated) != 1 || result.Truncated[0] != "/second.go" {
+		t.Fatalf("Expected second.go to be truncated, got kept=%v truncated=%v", keptPaths(result), result.Truncated)
+	}
+	used := 0
+	for _, file := range result.Files {
+		used += fileTokens(file)
+	}
+	if used > 1000 || result.EstimatedTokens != used {
+		t.Errorf("Expected the rendered files to fit the budget, got %d tokens (estimated %d)", used, result.EstimatedTokens)
+	}
+}
+
+func TestIsValidPackStrategy(t *testing.T) {
+	for _, strategy := range PackStrategies {
+		if !IsValidPackStrategy(strategy) {
+			t.Errorf("Expected %q to be valid", strategy)
+		}
+	}
+	if IsValidPackStrategy("random") {
+		t.Error("Expected unknown strategy to be invalid")
+	}
+}
@@ -0,0 +1,217 @@
+package fileutil
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the name of the files listing paths to leave out of the context, in
+// gitignore syntax. Patterns are relative to the directory containing the file.
+const IgnoreFileName = ".thinktankignore"
+
+// isGlobPattern reports whether an --include or --exclude entry is a glob or path pattern
+// (e.g. "*_test.go" or "docs/*.md") rather than a file extension
+func isGlobPattern(entry string) bool {
+	return strings.ContainsAny(entry, "*?[/")
+}
+
+// matchGlob reports whether a slash-separated path matches a glob pattern. Besides the
+// path.Match syntax within a path segment, a "**" segment matches any number of segments,
+// including none, so that "internal/**/*_test.go" matches "internal/a_test.go" and
+// "internal/x/y/b_test.go". As in gitignore, a trailing "**" matches at least one segment:
+// "vendor/**" matches everything inside vendor, but not vendor itself.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments matches the segments of a path against the segments of a glob pattern
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			if len(pattern) == 1 {
+				return len(name) > 0
+			}
+			// Try the rest of the pattern at every remaining position
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// matchPattern reports whether a file matches an --include or --exclude pattern. Patterns
+// without a slash match the file name at any depth; patterns with a slash match the path
+// relative to the current directory, or the absolute path of files outside it (so that
+// patterns starting with "**/" match anywhere).
+func (c *Config) matchPattern(pattern, filePath string) bool {
+	if !strings.Contains(pattern, "/") {
+		return matchGlob(pattern, filepath.Base(filePath))
+	}
+	relPath, ok := c.workingDirPath(filePath)
+	if !ok {
+		absPath, err := filepath.Abs(filePath)
+		if err != nil {
+			return false
+		}
+		relPath = filepath.ToSlash(absPath)
+	}
+	return matchGlob(strings.TrimPrefix(pattern, "./"), relPath)
+}
+
+// matchesAny reports whether a file matches any of the patterns (see matchPattern)
+func (c *Config) matchesAny(patterns []string, filePath string) bool {
+	for _, pattern := range patterns {
+		if c.matchPattern(pattern, filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAnyDir reports whether a directory matches any of the patterns, or everything
+// inside it does, as for "vendor/**", so that the directory can be skipped as a whole
+func (c *Config) matchesAnyDir(patterns []string, dirPath string) bool {
+	for _, pattern := range patterns {
+		if c.matchPattern(pattern, dirPath) {
+			return true
+		}
+		if dirPattern, ok := strings.CutSuffix(pattern, "/**"); ok && dirPattern != "" {
+			// Keep the pattern relative to the current directory
+			if !strings.Contains(dirPattern, "/") {
+				dirPattern = "./" + dirPattern
+			}
+			if c.matchPattern(dirPattern, dirPath) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// workingDirPath returns the slash-separated path of a file relative to the current
+// directory, as it was when the gathering started, or false if the file is outside it
+func (c *Config) workingDirPath(filePath string) (string, bool) {
+	if c.workDir == "" {
+		c.workDir, _ = os.Getwd()
+		if c.workDir == "" {
+			return "", false
+		}
+	}
+	if !filepath.IsAbs(filePath) {
+		filePath = filepath.Join(c.workDir, filePath)
+	}
+	return relativeTo(c.workDir, filePath)
+}
+
+// relativeTo returns the slash-separated path of a file relative to dir, or false if the
+// file is outside dir
+func relativeTo(dir, filePath string) (string, bool) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", false
+	}
+	relPath, err := filepath.Rel(dir, absPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(relPath), true
+}
+
+// ignorePattern is a single pattern of an ignore file
+type ignorePattern struct {
+	glob    string // slash-separated glob, relative to the directory of the ignore file
+	negate  bool   // the pattern started with "!", re-including what earlier patterns ignored
+	dirOnly bool   // the pattern ended with "/", matching only directories
+}
+
+// IgnoreRules holds the patterns of an ignore file in gitignore syntax
+type IgnoreRules struct {
+	dir      string // absolute directory the patterns are relative to
+	patterns []ignorePattern
+}
+
+// ParseIgnoreRules parses the content of an ignore file in gitignore syntax, whose
+// patterns are relative to dir. Blank lines and lines starting with "#" are skipped;
+// "!" negates a pattern, a trailing "/" matches only directories, and a pattern without
+// a slash (other than a trailing one) matches a name at any depth.
+func ParseIgnoreRules(dir, content string) *IgnoreRules {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		absDir = dir
+	}
+	rules := &IgnoreRules{dir: absDir}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var p ignorePattern
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:] // Escaped leading "#" or "!"
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		if strings.Contains(line, "/") {
+			p.glob = strings.TrimPrefix(line, "/")
+		} else {
+			p.glob = "**/" + line
+		}
+		rules.patterns = append(rules.patterns, p)
+	}
+	return rules
+}
+
+// LoadIgnoreFile reads an ignore file in gitignore syntax; its patterns are relative to
+// the directory containing it
+func LoadIgnoreFile(filePath string) (*IgnoreRules, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseIgnoreRules(filepath.Dir(filePath), string(content)), nil
+}
+
+// Match reports whether a pattern matches the path, and if so, whether the last matching
+// pattern ignores the path (rather than re-including it). Paths outside the directory of
+// the rules never match.
+func (r *IgnoreRules) Match(filePath string, isDir bool) (matched, ignored bool) {
+	relPath, ok := relativeTo(r.dir, filePath)
+	if !ok || relPath == "." {
+		return false, false
+	}
+	for _, p := range r.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if matchGlob(p.glob, relPath) {
+			matched, ignored = true, !p.negate
+		}
+	}
+	return matched, ignored
+}
@@ -0,0 +1,152 @@
+package fileutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"docs/*.md", "docs/README.md", true},
+		{"docs/*.md", "docs/api/README.md", false},
+		{"internal/**/*_test.go", "internal/a_test.go", true},
+		{"internal/**/*_test.go", "internal/x/y/b_test.go", true},
+		{"internal/**/*_test.go", "internal/x/b.go", false},
+		{"internal/**/*_test.go", "cmd/a_test.go", false},
+		{"**/mocks/**", "mocks", false}, // Everything inside mocks, not mocks itself
+		{"vendor/**", "vendor", false},
+		{"vendor/**", "vendor/lib/lib.go", true},
+		{"**/mocks/**", "internal/mocks/client.go", true},
+		{"**/mocks/**", "internal/mockserver/client.go", false},
+		{"**", "any/path/at/all", true},
+		{"~$*", "~$notes.md", true},
+		{"[", "[", false}, // Malformed pattern
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.expected {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestParseFilters(t *testing.T) {
+	exts, patterns := parseFilters("go, .MD,*_test.go,docs/,internal/**/*.go")
+	if strings.Join(exts, ",") != ".go,.md" {
+		t.Errorf("Unexpected extensions: %v", exts)
+	}
+	if strings.Join(patterns, ",") != "*_test.go,docs,internal/**/*.go" {
+		t.Errorf("Unexpected patterns: %v", patterns)
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	dir := t.TempDir()
+	rules := ParseIgnoreRules(dir, `
+# Generated code
+*.pb.go
+!keep.pb.go
+/build
+testdata/
+docs/**/*.png
+\#notes.md
+cache/**
+!cache/keep.txt
+`)
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"api/service.pb.go", false, true},
+		{"api/keep.pb.go", false, false},
+		{"build", true, true},
+		{"cmd/build", true, false}, // Anchored to the directory of the rules
+		{"pkg/testdata", true, true},
+		{"pkg/testdata", false, false}, // Only directories
+		{"docs/images/logo.png", false, true},
+		{"#notes.md", false, true},
+		{"cache", true, false}, // Only what is inside, so that files can be re-included
+		{"cache/data.bin", false, true},
+		{"cache/keep.txt", false, false},
+		{"main.go", false, false},
+	}
+
+	for _, tt := range tests {
+		_, ignored := rules.Match(filepath.Join(dir, tt.path), tt.isDir)
+		if ignored != tt.expected {
+			t.Errorf("Match(%q, %v) ignored = %v, want %v", tt.path, tt.isDir, ignored, tt.expected)
+		}
+	}
+
+	if matched, _ := rules.Match(filepath.Join(filepath.Dir(dir), "other.pb.go"), false); matched {
+		t.Error("Expected paths outside the directory of the rules not to match")
+	}
+}
+
+func TestGatherProjectContextIgnoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		IgnoreFileName:                     "*.gen.go\n/scratch/\n",
+		"main.go":                          "package main",
+		"api.gen.go":                       "package main",
+		"scratch/notes.go":                 "package scratch",
+		"internal/store/store.go":          "package store",
+		"internal/store/store_test.go":     "package store",
+		"internal/store/" + IgnoreFileName: "fixtures.go\n!api.gen.go\n",
+		"internal/store/fixtures.go":       "package store",
+		"internal/store/api.gen.go":        "package store",
+		"docs/guide.md":                    "# Guide",
+	}
+	for relPath, content := range files {
+		fullPath := filepath.Join(dir, relPath)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	// Patterns with a slash and the ignore file of the current directory are relative to it
+	workDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer func() { _ = os.Chdir(workDir) }()
+
+	config := NewConfig(false, ".go,docs/*.md", "internal/**/*_test.go", "", "", NewMockLogger())
+	gathered, _, err := GatherProjectContext(context.Background(), []string{"."}, config)
+	if err != nil {
+		t.Fatalf("GatherProjectContext returned an error: %v", err)
+	}
+
+	var relPaths []string
+	for _, file := range gathered {
+		relPath, err := filepath.Rel(dir, file.Path)
+		if err != nil {
+			t.Fatalf("Failed to get relative path: %v", err)
+		}
+		relPaths = append(relPaths, filepath.ToSlash(relPath))
+	}
+	sort.Strings(relPaths)
+
+	expected := "docs/guide.md,internal/store/api.gen.go,internal/store/store.go,main.go"
+	if strings.Join(relPaths, ",") != expected {
+		t.Errorf("Expected files %s, got %s", expected, strings.Join(relPaths, ","))
+	}
+}
@@ -2,6 +2,7 @@
 package fileutil
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -54,7 +55,7 @@ func TestGatherProjectContextErrors(t *testing.T) {
 			logger.ClearMessages()
 
 			// Call GatherProjectContext
-			files, count, err := GatherProjectContext(tt.paths, config)
+			files, count, err := GatherProjectContext(context.Background(), tt.paths, config)
 
 			// No fatal errors are expected (library handles errors internally)
 			if err != nil {
@@ -142,7 +143,7 @@ func TestGatherProjectContextWalkErrors(t *testing.T) {
 
 	// Run GatherProjectContext
 	logger.ClearMessages()
-	_, count, err := GatherProjectContext([]string{tempDir}, config)
+	_, count, err := GatherProjectContext(context.Background(), []string{tempDir}, config)
 
 	// Verify there was no fatal error
 	if err != nil {
@@ -230,7 +231,7 @@ func TestWalkDirectoryErrorHandling(t *testing.T) {
 			ExcludeNames: []string{"node_modules"},
 		}
 
-		files, _, err := GatherProjectContext([]string{tempDir}, config)
+		files, _, err := GatherProjectContext(context.Background(), []string{tempDir}, config)
 		if err != nil {
 			t.Errorf("Unexpected error: %v", err)
 		}
@@ -284,7 +285,7 @@ func TestWalkDirectoryErrorHandling(t *testing.T) {
 			}
 
 			// Attempt to process just this directory (should fail with permission denied)
-			_, _, _ = GatherProjectContext([]string{badDir}, config)
+			_, _, _ = GatherProjectContext(context.Background(), []string{badDir}, config)
 
 			// Check for error message
 			if !logger.ContainsMessage("Error walking directory") && !logger.ContainsMessage("Cannot stat path") {
@@ -297,12 +298,9 @@ func TestWalkDirectoryErrorHandling(t *testing.T) {
 	// Test 3: Skip a directory that should be git-ignored
 	t.Run("Git-Ignored Directory", func(t *testing.T) {
 		logger.ClearMessages()
-		config := &Config{
-			Logger:       logger,
-			GitAvailable: true,
-		}
+		config := &Config{Logger: logger}
 
-		files, _, _ := GatherProjectContext([]string{tempDir}, config)
+		files, _, _ := GatherProjectContext(context.Background(), []string{tempDir}, config)
 
 		// Verify .git directory contents were not included
 		for _, file := range files {
@@ -427,6 +425,35 @@ func TestGatherProjectContextFiltering(t *testing.T) {
 			},
 			expectedProcessedCount: 6, // Should match only the included extensions while respecting the exclusions
 		},
+		{
+			name: "Glob Patterns",
+			fileContents: map[string]string{
+				"main.go":                   "package main\n\nfunc main() {}\n",
+				"main_test.go":              "package main\n",
+				"internal/store/store.go":   "package store\n",
+				"internal/store/mock_db.go": "package store\n",
+				"internal/mocks/client.go":  "package mocks\n",
+				"scripts/build.py":          "print('build')",
+				"scripts/build.pyc":         "compiled",
+				"docs/~$notes.md":           "lock file",
+			},
+			includeFilter: "*.go,*.py,*.pyc,*.md",
+			excludeFilter: "*_test.go,mock_*.go,**/mocks/**",
+			excludeNames:  "*.pyc,~$*",
+			expectedIncludedFiles: []string{
+				"main.go",
+				"internal/store/store.go",
+				"scripts/build.py",
+			},
+			expectedExcludedFiles: []string{
+				"main_test.go",
+				"internal/store/mock_db.go",
+				"internal/mocks/client.go",
+				"scripts/build.pyc",
+				"docs/~$notes.md",
+			},
+			expectedProcessedCount: 3, // Should match the include patterns while skipping the excluded patterns and names
+		},
 	}
 
 	for _, tc := range tests {
@@ -459,7 +486,7 @@ func TestGatherProjectContextFiltering(t *testing.T) {
 			config := NewConfig(true, tc.includeFilter, tc.excludeFilter, tc.excludeNames, "format", logger)
 
 			// Call GatherProjectContext directly
-			files, processedCount, err := GatherProjectContext([]string{tempDir}, config)
+			files, processedCount, err := GatherProjectContext(context.Background(), []string{tempDir}, config)
 			if err != nil {
 				t.Fatalf("GatherProjectContext returned an error: %v", err)
 			}
@@ -510,3 +537,35 @@ func TestGatherProjectContextFiltering(t *testing.T) {
 		})
 	}
 }
+
+func TestGatherProjectContextOmittedDirs(t *testing.T) {
+	dir := t.TempDir()
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	writeRepoFiles(t, dir, map[string]string{
+		"main.go":                   "package main\n",
+		"node_modules/lib/index.js": "module.exports = {}\n",
+		"build/out.txt":             "output\n",
+		"vendor/lib/lib.go":         "package lib\n",
+		".git/HEAD":                 "ref: refs/heads/main\n",
+	})
+
+	// A directory whose contents are all excluded is omitted as a whole
+	config := NewConfig(false, "", "**/vendor/**", "node_modules,build", "", NewMockLogger())
+	files, _, err := GatherProjectContext(context.Background(), []string{dir}, config)
+	if err != nil {
+		t.Fatalf("GatherProjectContext returned an error: %v", err)
+	}
+
+	if len(files) != 1 || files[0].Size != int64(len("package main\n")) {
+		t.Errorf("Expected main.go with its size, got %+v", files)
+	}
+	var omitted []string
+	for _, path := range config.OmittedDirs() {
+		omitted = append(omitted, filepath.Base(path))
+	}
+	if got := strings.Join(omitted, ","); got != "build,node_modules,vendor" {
+		t.Errorf("Expected the build, node_modules and vendor directories to be omitted, got %s", got)
+	}
+}
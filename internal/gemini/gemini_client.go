@@ -10,6 +10,7 @@ import (
 	"github.com/phrazzld/thinktank/internal/logutil"
 
 	genai "github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
@@ -95,60 +96,7 @@ func (c *geminiClient) GenerateContent(ctx context.Context, prompt string, param
 		)
 	}
 
-	// Apply parameters if provided
-	if params != nil {
-		// Temperature
-		if temp, ok := params["temperature"]; ok {
-			switch v := temp.(type) {
-			case float64:
-				c.model.SetTemperature(float32(v))
-			case float32:
-				c.model.SetTemperature(v)
-			case int:
-				c.model.SetTemperature(float32(v))
-			}
-		}
-
-		// TopP
-		if topP, ok := params["top_p"]; ok {
-			switch v := topP.(type) {
-			case float64:
-				c.model.SetTopP(float32(v))
-			case float32:
-				c.model.SetTopP(v)
-			case int:
-				c.model.SetTopP(float32(v))
-			}
-		}
-
-		// TopK
-		if topK, ok := params["top_k"]; ok {
-			switch v := topK.(type) {
-			case int:
-				c.model.SetTopK(int32(v))
-			case int32:
-				c.model.SetTopK(v)
-			case int64:
-				c.model.SetTopK(int32(v))
-			case float64:
-				c.model.SetTopK(int32(v))
-			}
-		}
-
-		// MaxOutputTokens
-		if maxTokens, ok := params["max_output_tokens"]; ok {
-			switch v := maxTokens.(type) {
-			case int:
-				c.model.SetMaxOutputTokens(int32(v))
-			case int32:
-				c.model.SetMaxOutputTokens(v)
-			case int64:
-				c.model.SetMaxOutputTokens(int32(v))
-			case float64:
-				c.model.SetMaxOutputTokens(int32(v))
-			}
-		}
-	}
+	c.applyParams(params)
 
 	// Generate content
 	resp, err := c.model.GenerateContent(ctx, genai.Text(prompt))
@@ -188,6 +136,7 @@ func (c *geminiClient) GenerateContent(ctx context.Context, prompt string, param
 			FinishReason: string(candidate.FinishReason),
 			SafetyInfo:   toProviderSafety(mapSafetyRatings(candidate.SafetyRatings)),
 			Truncated:    candidate.FinishReason == genai.FinishReasonMaxTokens,
+			Usage:        toProviderUsage(resp.UsageMetadata),
 		}, nil
 	}
 
@@ -205,11 +154,185 @@ func (c *geminiClient) GenerateContent(ctx context.Context, prompt string, param
 		FinishReason: string(candidate.FinishReason),
 		Truncated:    candidate.FinishReason == genai.FinishReasonMaxTokens,
 		SafetyInfo:   toProviderSafety(mapSafetyRatings(candidate.SafetyRatings)),
+		Usage:        toProviderUsage(resp.UsageMetadata),
 	}
 
 	return result, nil
 }
 
+// applyParams applies request parameters to the underlying generative model
+func (c *geminiClient) applyParams(params map[string]interface{}) {
+	// The system prompt is set (or cleared) for each request, since the model is reused
+	c.model.SystemInstruction = nil
+	if systemPrompt := llm.SystemPrompt(params); systemPrompt != "" {
+		c.model.SystemInstruction = genai.NewUserContent(genai.Text(systemPrompt))
+	}
+
+	if params == nil {
+		return
+	}
+
+	// Temperature
+	if temp, ok := params["temperature"]; ok {
+		switch v := temp.(type) {
+		case float64:
+			c.model.SetTemperature(float32(v))
+		case float32:
+			c.model.SetTemperature(v)
+		case int:
+			c.model.SetTemperature(float32(v))
+		}
+	}
+
+	// TopP
+	if topP, ok := params["top_p"]; ok {
+		switch v := topP.(type) {
+		case float64:
+			c.model.SetTopP(float32(v))
+		case float32:
+			c.model.SetTopP(v)
+		case int:
+			c.model.SetTopP(float32(v))
+		}
+	}
+
+	// TopK
+	if topK, ok := params["top_k"]; ok {
+		switch v := topK.(type) {
+		case int:
+			c.model.SetTopK(int32(v))
+		case int32:
+			c.model.SetTopK(v)
+		case int64:
+			c.model.SetTopK(int32(v))
+		case float64:
+			c.model.SetTopK(int32(v))
+		}
+	}
+
+	// MaxOutputTokens
+	if maxTokens, ok := params["max_output_tokens"]; ok {
+		switch v := maxTokens.(type) {
+		case int:
+			c.model.SetMaxOutputTokens(int32(v))
+		case int32:
+			c.model.SetMaxOutputTokens(v)
+		case int64:
+			c.model.SetMaxOutputTokens(int32(v))
+		case float64:
+			c.model.SetMaxOutputTokens(int32(v))
+		}
+	}
+}
+
+// GenerateContentStream implements the llm.StreamingLLMClient interface.
+// Errors that occur before the first response arrives are returned directly;
+// later failures arrive as a terminal chunk.
+func (c *geminiClient) GenerateContentStream(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+	if prompt == "" {
+		return nil, CreateAPIError(
+			llm.CategoryInvalidRequest,
+			"Cannot generate content with an empty prompt",
+			errors.New("prompt cannot be empty"),
+			"Provide a task description using the --instructions flag",
+		)
+	}
+
+	c.applyParams(params)
+
+	iter := c.model.GenerateContentStream(ctx, genai.Text(prompt))
+
+	// Fetch the first response synchronously so request-level failures surface as a returned error
+	first, err := iter.Next()
+	if err != nil && !errors.Is(err, iterator.Done) {
+		apiErr := FormatAPIError(err, 0)
+		c.logger.Debug("Gemini API Error: %s", apiErr.DebugInfo())
+		return nil, apiErr
+	}
+
+	primed := true
+	next := func() (*genai.GenerateContentResponse, error) {
+		if primed {
+			primed = false
+			return first, err
+		}
+		return iter.Next()
+	}
+
+	stream := make(chan llm.StreamChunk)
+	go func() {
+		defer close(stream)
+		c.forwardStream(ctx, next, stream)
+	}()
+
+	return stream, nil
+}
+
+// forwardStream reads responses from next until the iterator is exhausted and
+// forwards their text to the stream. The final chunk carries the finish reason
+// and safety information of the last candidate, along with the token usage.
+func (c *geminiClient) forwardStream(ctx context.Context, next func() (*genai.GenerateContentResponse, error), stream chan<- llm.StreamChunk) {
+	var finishReason genai.FinishReason
+	var safetyInfo []llm.Safety
+	var usage *llm.TokenUsage
+
+	for {
+		resp, err := next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			apiErr := FormatAPIError(err, 0)
+			c.logger.Debug("Gemini API Error: %s", apiErr.DebugInfo())
+			llm.SendChunk(ctx, stream, llm.StreamChunk{Err: apiErr})
+			return
+		}
+		if resp == nil {
+			continue
+		}
+		// Usage metadata is cumulative, so the last reported value is the total
+		if resp.UsageMetadata != nil {
+			usage = toProviderUsage(resp.UsageMetadata)
+		}
+		if len(resp.Candidates) == 0 {
+			continue
+		}
+
+		candidate := resp.Candidates[0]
+		if candidate.FinishReason != genai.FinishReasonUnspecified {
+			finishReason = candidate.FinishReason
+		}
+		if len(candidate.SafetyRatings) > 0 {
+			safetyInfo = toProviderSafety(mapSafetyRatings(candidate.SafetyRatings))
+		}
+		if candidate.Content == nil {
+			continue
+		}
+
+		for _, part := range candidate.Content.Parts {
+			textPart, ok := part.(genai.Text)
+			if !ok || textPart == "" {
+				continue
+			}
+			if !llm.SendChunk(ctx, stream, llm.StreamChunk{Content: string(textPart)}) {
+				return
+			}
+		}
+	}
+
+	var reason string
+	if finishReason != genai.FinishReasonUnspecified {
+		reason = finishReason.String()
+	}
+
+	llm.SendChunk(ctx, stream, llm.StreamChunk{
+		FinishReason: reason,
+		Truncated:    finishReason == genai.FinishReasonMaxTokens,
+		SafetyInfo:   safetyInfo,
+		Usage:        usage,
+	})
+}
+
 // Close implements the llm.LLMClient interface by releasing resources
 func (c *geminiClient) Close() error {
 	if c.client != nil {
@@ -250,6 +373,19 @@ func mapSafetyRatings(ratings []*genai.SafetyRating) []SafetyRating {
 	return result
 }
 
+// toProviderUsage converts Gemini usage metadata to provider-agnostic token usage
+func toProviderUsage(metadata *genai.UsageMetadata) *llm.TokenUsage {
+	if metadata == nil {
+		return nil
+	}
+
+	return &llm.TokenUsage{
+		PromptTokens:     metadata.PromptTokenCount,
+		CompletionTokens: metadata.CandidatesTokenCount,
+		TotalTokens:      metadata.TotalTokenCount,
+	}
+}
+
 // toProviderSafety converts Gemini safety ratings to provider-agnostic safety info
 func toProviderSafety(ratings []SafetyRating) []llm.Safety {
 	if ratings == nil {
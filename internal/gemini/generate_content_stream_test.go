@@ -0,0 +1,162 @@
+// internal/gemini/generate_content_stream_test.go
+// Tests for the GenerateContentStream method
+package gemini
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	genai "github.com/google/generative-ai-go/genai"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"google.golang.org/api/iterator"
+)
+
+// scriptedResponses returns a next function that yields the given responses and then iterator.Done,
+// or finalErr instead of iterator.Done when it is non-nil
+func scriptedResponses(finalErr error, responses ...*genai.GenerateContentResponse) func() (*genai.GenerateContentResponse, error) {
+	index := 0
+	return func() (*genai.GenerateContentResponse, error) {
+		if index >= len(responses) {
+			if finalErr != nil {
+				return nil, finalErr
+			}
+			return nil, iterator.Done
+		}
+		resp := responses[index]
+		index++
+		return resp, nil
+	}
+}
+
+// textResponse creates a single-candidate response containing the given text
+func textResponse(text string, finishReason genai.FinishReason) *genai.GenerateContentResponse {
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{
+			{
+				Content:      &genai.Content{Parts: []genai.Part{genai.Text(text)}},
+				FinishReason: finishReason,
+			},
+		},
+	}
+}
+
+func TestGenerateContentStream(t *testing.T) {
+	t.Run("Empty prompt validation", func(t *testing.T) {
+		client := &geminiClient{
+			apiKey:    "test-key",
+			modelName: "test-model",
+			logger:    getTestLogger(),
+		}
+
+		stream, err := client.GenerateContentStream(context.Background(), "", nil)
+		if err == nil {
+			t.Fatal("Expected error for empty prompt, got nil")
+		}
+		if stream != nil {
+			t.Error("Expected nil stream for empty prompt")
+		}
+		if !strings.Contains(err.Error(), "empty prompt") {
+			t.Errorf("Expected error message to mention empty prompt, got: %v", err)
+		}
+	})
+
+	t.Run("Forwards text and final finish reason", func(t *testing.T) {
+		client := &geminiClient{modelName: "test-model", logger: getTestLogger()}
+
+		final := textResponse(" world", genai.FinishReasonMaxTokens)
+		final.UsageMetadata = &genai.UsageMetadata{PromptTokenCount: 12, CandidatesTokenCount: 2, TotalTokenCount: 14}
+		next := scriptedResponses(nil,
+			textResponse("Hello", genai.FinishReasonUnspecified),
+			final,
+		)
+
+		stream := make(chan llm.StreamChunk)
+		go func() {
+			defer close(stream)
+			client.forwardStream(context.Background(), next, stream)
+		}()
+
+		var deltas []string
+		result, err := llm.CollectStream(context.Background(), stream, func(delta string) {
+			deltas = append(deltas, delta)
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if len(deltas) != 2 {
+			t.Errorf("Expected 2 deltas, got %d: %v", len(deltas), deltas)
+		}
+		if result.Content != "Hello world" {
+			t.Errorf("Expected content 'Hello world', got %q", result.Content)
+		}
+		if result.FinishReason != genai.FinishReasonMaxTokens.String() {
+			t.Errorf("Expected finish reason %q, got %q", genai.FinishReasonMaxTokens.String(), result.FinishReason)
+		}
+		if !result.Truncated {
+			t.Error("Expected result to be marked as truncated")
+		}
+		if result.Usage == nil || result.Usage.PromptTokens != 12 || result.Usage.CompletionTokens != 2 || result.Usage.TotalTokens != 14 {
+			t.Errorf("Expected usage {12 2 14}, got %+v", result.Usage)
+		}
+	})
+
+	t.Run("Mid-stream error is terminal", func(t *testing.T) {
+		client := &geminiClient{modelName: "test-model", logger: getTestLogger()}
+
+		next := scriptedResponses(errors.New("stream interrupted"),
+			textResponse("partial", genai.FinishReasonUnspecified),
+		)
+
+		stream := make(chan llm.StreamChunk)
+		go func() {
+			defer close(stream)
+			client.forwardStream(context.Background(), next, stream)
+		}()
+
+		result, err := llm.CollectStream(context.Background(), stream, nil)
+		if err == nil {
+			t.Fatal("Expected error from interrupted stream, got nil")
+		}
+		if _, ok := IsGeminiError(err); !ok {
+			t.Errorf("Expected LLMError from Gemini, got %T", err)
+		}
+		if result.Content != "partial" {
+			t.Errorf("Expected partial content to be preserved, got %q", result.Content)
+		}
+	})
+
+	t.Run("Stops when context is cancelled", func(t *testing.T) {
+		client := &geminiClient{modelName: "test-model", logger: getTestLogger()}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		next := scriptedResponses(nil, textResponse("never delivered", genai.FinishReasonStop))
+
+		// Unbuffered and never read: forwardStream must return rather than block
+		stream := make(chan llm.StreamChunk)
+		done := make(chan struct{})
+		go func() {
+			client.forwardStream(ctx, next, stream)
+			close(done)
+		}()
+		<-done
+	})
+}
+
+func TestToProviderUsage(t *testing.T) {
+	if usage := toProviderUsage(nil); usage != nil {
+		t.Errorf("Expected nil usage for nil metadata, got %+v", usage)
+	}
+
+	usage := toProviderUsage(&genai.UsageMetadata{PromptTokenCount: 100, CandidatesTokenCount: 50, TotalTokenCount: 150})
+	if usage == nil {
+		t.Fatal("Expected usage, got nil")
+	}
+	if usage.PromptTokens != 100 || usage.CompletionTokens != 50 || usage.TotalTokens != 150 {
+		t.Errorf("Unexpected usage conversion: %+v", usage)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	genai "github.com/google/generative-ai-go/genai"
 	"github.com/phrazzld/thinktank/internal/llm"
 )
 
@@ -217,3 +218,20 @@ func TestGenerateContent(t *testing.T) {
 		}
 	})
 }
+
+// TestApplyParamsSystemPrompt verifies that the system prompt of a request is set as the
+// model's system instruction, and cleared for requests without one
+func TestApplyParamsSystemPrompt(t *testing.T) {
+	client := &geminiClient{model: &genai.GenerativeModel{}, modelName: "test-model", logger: getTestLogger()}
+
+	client.applyParams(map[string]interface{}{llm.SystemPromptParam: "You are a careful reviewer."})
+	if client.model.SystemInstruction == nil || len(client.model.SystemInstruction.Parts) != 1 ||
+		client.model.SystemInstruction.Parts[0] != genai.Text("You are a careful reviewer.") {
+		t.Errorf("Expected the system prompt as the system instruction, got %+v", client.model.SystemInstruction)
+	}
+
+	client.applyParams(nil)
+	if client.model.SystemInstruction != nil {
+		t.Errorf("Expected no system instruction, got %+v", client.model.SystemInstruction)
+	}
+}
@@ -345,6 +345,12 @@ func (s *BoundaryAPIService) GetModelDefinition(modelName string) (*registry.Mod
 	}, nil
 }
 
+// GetProviderDefinition retrieves the provider definition
+func (s *BoundaryAPIService) GetProviderDefinition(providerName string) (*registry.ProviderDefinition, error) {
+	// Providers use their default base URL
+	return &registry.ProviderDefinition{Name: providerName}, nil
+}
+
 // getProviderFromModelName determines the provider based on the model name
 func getProviderFromModelName(modelName string) string {
 	modelLower := strings.ToLower(modelName)
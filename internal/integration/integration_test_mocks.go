@@ -20,6 +20,7 @@ type MockAPIService struct {
 	GetModelParametersFunc     func(modelName string) (map[string]interface{}, error)
 	ValidateModelParameterFunc func(modelName, paramName string, value interface{}) (bool, error)
 	GetModelDefinitionFunc     func(modelName string) (*registry.ModelDefinition, error)
+	GetProviderDefinitionFunc  func(providerName string) (*registry.ProviderDefinition, error)
 	GetModelTokenLimitsFunc    func(modelName string) (contextWindow, maxOutputTokens int32, err error)
 	ProcessLLMResponseFunc     func(result *llm.ProviderResult) (string, error)
 	IsEmptyResponseErrorFunc   func(err error) bool
@@ -55,6 +56,13 @@ func (m *MockAPIService) GetModelDefinition(modelName string) (*registry.ModelDe
 	return nil, nil
 }
 
+func (m *MockAPIService) GetProviderDefinition(providerName string) (*registry.ProviderDefinition, error) {
+	if m.GetProviderDefinitionFunc != nil {
+		return m.GetProviderDefinitionFunc(providerName)
+	}
+	return nil, nil
+}
+
 func (m *MockAPIService) GetModelTokenLimits(modelName string) (contextWindow, maxOutputTokens int32, err error) {
 	if m.GetModelTokenLimitsFunc != nil {
 		return m.GetModelTokenLimitsFunc(modelName)
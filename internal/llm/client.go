@@ -7,10 +7,18 @@ import (
 
 // ProviderResult holds the response from a content generation call
 type ProviderResult struct {
-	Content      string   // The generated content
-	FinishReason string   // Why generation stopped, e.g., "stop", "length", "safety"
-	Truncated    bool     // Whether the response was truncated
-	SafetyInfo   []Safety // Optional safety information
+	Content      string      // The generated content
+	FinishReason string      // Why generation stopped, e.g., "stop", "length", "safety"
+	Truncated    bool        // Whether the response was truncated
+	SafetyInfo   []Safety    // Optional safety information
+	Usage        *TokenUsage // Token usage reported by the provider (nil if unavailable)
+}
+
+// TokenUsage holds the token counts reported by a provider for a single request
+type TokenUsage struct {
+	PromptTokens     int32 // Tokens in the prompt (input)
+	CompletionTokens int32 // Tokens in the generated content (output)
+	TotalTokens      int32 // Total tokens billed for the request
 }
 
 // Safety represents content safety evaluation information
@@ -20,10 +28,23 @@ type Safety struct {
 	Score    float32 // Severity score (provider-specific scale)
 }
 
+// SystemPromptParam is the request parameter holding the system prompt, which sets the
+// model's persona and ground rules separately from the prompt itself. Each provider
+// sends it through its native mechanism (e.g., an OpenAI system message or a Gemini
+// system instruction).
+const SystemPromptParam = "system"
+
+// SystemPrompt returns the system prompt in params, or "" when there is none
+func SystemPrompt(params map[string]interface{}) string {
+	systemPrompt, _ := params[SystemPromptParam].(string)
+	return systemPrompt
+}
+
 // LLMClient defines the interface for interacting with any LLM provider
 type LLMClient interface {
 	// GenerateContent sends a text prompt to the LLM and returns the generated content
-	// If params is provided, these parameters will override the default model parameters
+	// If params is provided, these parameters will override the default model parameters;
+	// params[SystemPromptParam] is the system prompt
 	GenerateContent(ctx context.Context, prompt string, params map[string]interface{}) (*ProviderResult, error)
 
 	// GetModelName returns the name of the model being used
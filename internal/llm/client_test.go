@@ -68,3 +68,16 @@ func TestMockLLMClientCustom(t *testing.T) {
 		t.Errorf("Expected model name to be 'custom-model', got '%s'", modelName)
 	}
 }
+
+// TestSystemPrompt tests reading the system prompt from request parameters
+func TestSystemPrompt(t *testing.T) {
+	if got := SystemPrompt(map[string]interface{}{SystemPromptParam: "Be concise."}); got != "Be concise." {
+		t.Errorf("Expected the system prompt, got %q", got)
+	}
+	if got := SystemPrompt(map[string]interface{}{SystemPromptParam: 42}); got != "" {
+		t.Errorf("Expected no system prompt for a non-string value, got %q", got)
+	}
+	if got := SystemPrompt(nil); got != "" {
+		t.Errorf("Expected no system prompt for nil params, got %q", got)
+	}
+}
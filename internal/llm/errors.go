@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Define sentinel errors for common LLM-related error conditions
@@ -136,6 +138,10 @@ type LLMError struct {
 
 	// Details contains additional error details
 	Details string
+
+	// RetryAfter is how long the provider asked clients to wait before retrying,
+	// taken from the Retry-After response header (zero if not provided)
+	RetryAfter time.Duration
 }
 
 // Error implements the error interface
@@ -204,6 +210,10 @@ func (e *LLMError) DebugInfo() string {
 		sb.WriteString(fmt.Sprintf("Details: %s\n", e.Details))
 	}
 
+	if e.RetryAfter > 0 {
+		sb.WriteString(fmt.Sprintf("Retry After: %s\n", e.RetryAfter))
+	}
+
 	if e.Suggestion != "" {
 		sb.WriteString(fmt.Sprintf("Suggestion: %s\n", e.Suggestion))
 	}
@@ -254,6 +264,40 @@ func Wrap(err error, provider string, message string, category ErrorCategory) *L
 	}
 }
 
+// ParseRetryAfter parses the value of a Retry-After HTTP header, which is either
+// a number of seconds or an HTTP date. It returns zero if the header is empty,
+// invalid, or refers to a time that has already passed.
+func ParseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
+
+// GetRetryAfter returns the Retry-After delay carried by an error, if any
+func GetRetryAfter(err error) time.Duration {
+	var llmErr *LLMError
+	if errors.As(err, &llmErr) {
+		return llmErr.RetryAfter
+	}
+	return 0
+}
+
 // IsCategory checks if an error belongs to a specific category
 func IsCategory(err error, category ErrorCategory) bool {
 	if err == nil {
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 )
 
 // Test the String method of ErrorCategory
@@ -330,6 +331,49 @@ func TestGetErrorCategoryFromStatusCode(t *testing.T) {
 	}
 }
 
+// Test ParseRetryAfter
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		header   string
+		expected time.Duration
+	}{
+		{"Empty", "", 0},
+		{"Seconds", "30", 30 * time.Second},
+		{"Seconds with whitespace", " 5 ", 5 * time.Second},
+		{"Zero seconds", "0", 0},
+		{"Negative seconds", "-3", 0},
+		{"HTTP date", "Thu, 01 May 2025 12:01:30 GMT", 90 * time.Second},
+		{"HTTP date in the past", "Thu, 01 May 2025 11:59:00 GMT", 0},
+		{"Invalid", "soon", 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := ParseRetryAfter(tc.header, now); result != tc.expected {
+				t.Errorf("Expected ParseRetryAfter(%q) = %v, got %v", tc.header, tc.expected, result)
+			}
+		})
+	}
+}
+
+// Test GetRetryAfter
+func TestGetRetryAfter(t *testing.T) {
+	llmErr := &LLMError{Message: "rate limited", ErrorCategory: CategoryRateLimit, RetryAfter: 10 * time.Second}
+
+	if result := GetRetryAfter(fmt.Errorf("wrapped: %w", llmErr)); result != 10*time.Second {
+		t.Errorf("Expected a wrapped LLMError's RetryAfter, got %v", result)
+	}
+	if result := GetRetryAfter(errors.New("plain error")); result != 0 {
+		t.Errorf("Expected no RetryAfter for a plain error, got %v", result)
+	}
+	if !strings.Contains(llmErr.DebugInfo(), "Retry After: 10s") {
+		t.Errorf("Expected DebugInfo to include the retry delay, got: %s", llmErr.DebugInfo())
+	}
+}
+
 // Test GetErrorCategoryFromMessage
 func TestGetErrorCategoryFromMessage(t *testing.T) {
 	testCases := []struct {
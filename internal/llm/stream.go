@@ -0,0 +1,120 @@
+// internal/llm/stream.go
+package llm
+
+import (
+	"context"
+	"strings"
+)
+
+// StreamChunk holds a single incremental piece of a streamed generation.
+// Providers send zero or more content chunks followed by a final chunk that
+// carries the finish reason (and safety and usage information, if any). A chunk with a
+// non-nil Err is terminal: no further chunks follow it.
+type StreamChunk struct {
+	Content      string      // Incremental text delta
+	FinishReason string      // Set on the final chunk, e.g., "stop", "length", "safety"
+	Truncated    bool        // Whether the response was truncated
+	SafetyInfo   []Safety    // Optional safety information
+	Usage        *TokenUsage // Token usage, usually only set on the final chunk
+	Err          error       // Terminal error, if the stream failed
+}
+
+// StreamingLLMClient is implemented by clients that can deliver generated
+// content incrementally. It is an optional extension of LLMClient; callers
+// should detect it with a type assertion and fall back to GenerateContent.
+type StreamingLLMClient interface {
+	LLMClient
+
+	// GenerateContentStream sends a text prompt to the LLM and returns a channel
+	// of incremental chunks. The channel is closed once generation finishes,
+	// fails, or the context is cancelled.
+	GenerateContentStream(ctx context.Context, prompt string, params map[string]interface{}) (<-chan StreamChunk, error)
+}
+
+// CollectStream drains a stream, calling onDelta (if non-nil) for every
+// non-empty content delta, and assembles the chunks into a single ProviderResult.
+// If the stream ends with an error, the partial result is returned with that error.
+// The same goes for ctx.Err() when ctx is cancelled before the stream is complete,
+// since producers cannot deliver a terminal chunk to a cancelled stream.
+func CollectStream(ctx context.Context, stream <-chan StreamChunk, onDelta func(delta string)) (*ProviderResult, error) {
+	var content strings.Builder
+	result := &ProviderResult{}
+
+	for {
+		var chunk StreamChunk
+		var ok bool
+		select {
+		case chunk, ok = <-stream:
+		case <-ctx.Done():
+			result.Content = content.String()
+			return result, ctx.Err()
+		}
+		if !ok {
+			break
+		}
+
+		if chunk.Err != nil {
+			result.Content = content.String()
+			return result, chunk.Err
+		}
+
+		if chunk.Content != "" {
+			content.WriteString(chunk.Content)
+			if onDelta != nil {
+				onDelta(chunk.Content)
+			}
+		}
+
+		if chunk.FinishReason != "" {
+			result.FinishReason = chunk.FinishReason
+		}
+		if chunk.Truncated {
+			result.Truncated = true
+		}
+		if len(chunk.SafetyInfo) > 0 {
+			result.SafetyInfo = chunk.SafetyInfo
+		}
+		if chunk.Usage != nil {
+			result.Usage = chunk.Usage
+		}
+	}
+
+	// The producer may have seen the cancellation first and closed the stream early
+	result.Content = content.String()
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// StreamFromResult converts a blocking GenerateContent outcome into a stream
+// containing a single chunk. It lets wrappers offer GenerateContentStream even
+// when the client they wrap cannot stream.
+func StreamFromResult(result *ProviderResult, err error) <-chan StreamChunk {
+	stream := make(chan StreamChunk, 1)
+	if err != nil {
+		stream <- StreamChunk{Err: err}
+	} else if result != nil {
+		stream <- StreamChunk{
+			Content:      result.Content,
+			FinishReason: result.FinishReason,
+			Truncated:    result.Truncated,
+			SafetyInfo:   result.SafetyInfo,
+			Usage:        result.Usage,
+		}
+	}
+	close(stream)
+	return stream
+}
+
+// SendChunk delivers a chunk to a stream unless the context is cancelled first.
+// It returns false if the chunk could not be delivered, in which case the
+// producer should stop generating.
+func SendChunk(ctx context.Context, stream chan<- StreamChunk, chunk StreamChunk) bool {
+	select {
+	case stream <- chunk:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestCollectStream verifies that stream chunks are assembled into a single result
+func TestCollectStream(t *testing.T) {
+	stream := make(chan StreamChunk, 4)
+	stream <- StreamChunk{Content: "Hello"}
+	stream <- StreamChunk{Content: ""}
+	stream <- StreamChunk{Content: ", world"}
+	stream <- StreamChunk{FinishReason: "length", Truncated: true, SafetyInfo: []Safety{{Category: "HARM", Score: 0.1}}}
+	close(stream)
+
+	var deltas []string
+	result, err := CollectStream(context.Background(), stream, func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	if err != nil {
+		t.Fatalf("CollectStream returned unexpected error: %v", err)
+	}
+
+	if result.Content != "Hello, world" {
+		t.Errorf("Expected content 'Hello, world', got '%s'", result.Content)
+	}
+	if len(deltas) != 2 {
+		t.Errorf("Expected 2 non-empty deltas, got %d", len(deltas))
+	}
+	if result.FinishReason != "length" || !result.Truncated {
+		t.Errorf("Expected truncated result with finish reason 'length', got %q (truncated=%v)", result.FinishReason, result.Truncated)
+	}
+	if len(result.SafetyInfo) != 1 {
+		t.Errorf("Expected safety info to be carried over, got %v", result.SafetyInfo)
+	}
+}
+
+// TestCollectStreamError verifies that a terminal error returns the partial content
+func TestCollectStreamError(t *testing.T) {
+	expectedErr := errors.New("stream failed")
+	stream := make(chan StreamChunk, 2)
+	stream <- StreamChunk{Content: "partial"}
+	stream <- StreamChunk{Err: expectedErr}
+	close(stream)
+
+	result, err := CollectStream(context.Background(), stream, nil)
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("Expected error %v, got %v", expectedErr, err)
+	}
+	if result == nil || result.Content != "partial" {
+		t.Errorf("Expected partial content to be returned, got %+v", result)
+	}
+}
+
+// TestCollectStreamCancelled verifies that a stream cut short by cancellation is an error,
+// even though the producer closes the stream without a terminal chunk
+func TestCollectStreamCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A producer that stops sending once the context is cancelled, as providers do
+	stream := make(chan StreamChunk)
+	go func() {
+		defer close(stream)
+		for _, content := range []string{"partial", " output", " never delivered"} {
+			if !SendChunk(ctx, stream, StreamChunk{Content: content}) {
+				return
+			}
+		}
+		SendChunk(ctx, stream, StreamChunk{FinishReason: "stop"})
+	}()
+
+	result, err := CollectStream(ctx, stream, func(delta string) {
+		if delta == " output" {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a cancellation error, got %v", err)
+	}
+	if result == nil || result.Content != "partial output" || result.FinishReason != "" {
+		t.Errorf("Expected the partial content without a finish reason, got %+v", result)
+	}
+}
+
+// TestStreamFromResult verifies conversion of a blocking result into a stream
+func TestStreamFromResult(t *testing.T) {
+	result, err := CollectStream(context.Background(), StreamFromResult(&ProviderResult{Content: "done", FinishReason: "stop"}, nil), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "done" || result.FinishReason != "stop" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	expectedErr := errors.New("generation failed")
+	_, err = CollectStream(context.Background(), StreamFromResult(nil, expectedErr), nil)
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("Expected error %v, got %v", expectedErr, err)
+	}
+}
+
+// TestSendChunkCancelled verifies that SendChunk gives up when the context is cancelled
+func TestSendChunkCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Unbuffered channel with no reader: only cancellation can unblock the send
+	stream := make(chan StreamChunk)
+	if SendChunk(ctx, stream, StreamChunk{Content: "x"}) {
+		t.Error("Expected SendChunk to report failure for a cancelled context")
+	}
+}
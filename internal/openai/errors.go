@@ -3,7 +3,9 @@ package openai
 
 import (
 	"errors"
+	"time"
 
+	"github.com/openai/openai-go"
 	"github.com/phrazzld/thinktank/internal/llm"
 )
 
@@ -28,11 +30,25 @@ func FormatAPIError(err error, statusCode int) *llm.LLMError {
 		return llmErr
 	}
 
+	// Use the status code and Retry-After header of errors returned by the SDK
+	retryAfter := time.Duration(0)
+	var sdkErr *openai.Error
+	if errors.As(err, &sdkErr) {
+		if statusCode == 0 {
+			statusCode = sdkErr.StatusCode
+		}
+		if sdkErr.Response != nil {
+			retryAfter = llm.ParseRetryAfter(sdkErr.Response.Header.Get("Retry-After"), time.Now())
+		}
+	}
+
 	// Get error category from the shared library
 	category := llm.DetectErrorCategory(err, statusCode)
 
 	// Create a formatted error with OpenAI-specific suggestions
 	llmError := llm.CreateStandardErrorWithMessage("openai", category, err, "")
+	llmError.StatusCode = statusCode
+	llmError.RetryAfter = retryAfter
 
 	// Add OpenAI-specific suggestions for certain error types
 	switch category {
@@ -2,9 +2,13 @@ package openai
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/openai/openai-go"
 	"github.com/phrazzld/thinktank/internal/llm"
 )
 
@@ -156,6 +160,31 @@ func TestFormatAPIError(t *testing.T) {
 	}
 }
 
+// Test that the status code and Retry-After header of SDK errors are preserved
+func TestFormatAPIErrorSDKError(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "https://api.openai.com/v1/chat/completions", nil)
+	sdkErr := &openai.Error{
+		StatusCode: http.StatusTooManyRequests,
+		Request:    request,
+		Response: &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Retry-After": []string{"20"}},
+		},
+	}
+
+	result := FormatAPIError(fmt.Errorf("request failed: %w", sdkErr), 0)
+
+	if result.ErrorCategory != llm.CategoryRateLimit {
+		t.Errorf("Expected category %v, got %v", llm.CategoryRateLimit, result.ErrorCategory)
+	}
+	if result.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected status code %d, got %d", http.StatusTooManyRequests, result.StatusCode)
+	}
+	if result.RetryAfter != 20*time.Second {
+		t.Errorf("Expected RetryAfter 20s, got %v", result.RetryAfter)
+	}
+}
+
 // Test MockAPIErrorResponse for backward compatibility
 func TestMockAPIErrorResponse(t *testing.T) {
 	const (
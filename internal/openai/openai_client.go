@@ -5,7 +5,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
@@ -16,6 +15,15 @@ import (
 type openaiAPI interface {
 	createChatCompletion(ctx context.Context, model string, prompt string, systemPrompt string) (*openai.ChatCompletion, error)
 	createChatCompletionWithParams(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error)
+	createChatCompletionStream(ctx context.Context, params openai.ChatCompletionNewParams) chatCompletionStream
+}
+
+// chatCompletionStream defines the operations we need from a streaming chat completion
+type chatCompletionStream interface {
+	Next() bool
+	Current() openai.ChatCompletionChunk
+	Err() error
+	Close() error
 }
 
 // openaiClient implements the llm.LLMClient interface for OpenAI
@@ -70,6 +78,14 @@ func (api *realOpenAIAPI) createChatCompletionWithParams(ctx context.Context, pa
 	return completion, nil
 }
 
+// createChatCompletionStream starts a streaming API call with specific parameters
+func (api *realOpenAIAPI) createChatCompletionStream(ctx context.Context, params openai.ChatCompletionNewParams) chatCompletionStream {
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{
+		IncludeUsage: openai.Bool(true),
+	}
+	return api.client.Chat.Completions.NewStreaming(ctx, params)
+}
+
 // NewClient creates a new OpenAI client that implements the llm.LLMClient interface
 func NewClient(apiKey, modelName, apiBase string) (llm.LLMClient, error) {
 	if apiKey == "" {
@@ -81,8 +97,11 @@ func NewClient(apiKey, modelName, apiBase string) (llm.LLMClient, error) {
 	}
 
 	// Create a list of client options
+	// Transient errors are retried by the caller's retry policy, so the SDK's
+	// own retries are disabled to avoid multiplying the attempts
 	clientOptions := []option.RequestOption{
 		option.WithAPIKey(apiKey),
+		option.WithMaxRetries(0),
 	}
 
 	// Add custom base URL if provided
@@ -107,8 +126,112 @@ func NewClient(apiKey, modelName, apiBase string) (llm.LLMClient, error) {
 
 // GenerateContent implements the LLMClient interface
 func (c *openaiClient) GenerateContent(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+	requestParams, err := c.buildRequestParams(prompt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the API call
+	completion, err := c.api.createChatCompletionWithParams(ctx, requestParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle empty choices
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("no completions returned from OpenAI API")
+	}
+
+	// Extract content
+	choice := completion.Choices[0]
+	content := choice.Message.Content
+
+	// Get finish reason
+	finishReason := choice.FinishReason
+
+	// Build result
+	result := &llm.ProviderResult{
+		Content:      content,
+		FinishReason: finishReason,
+		Truncated:    finishReason == "length",
+		Usage:        toProviderUsage(completion.Usage),
+	}
+
+	return result, nil
+}
+
+// GenerateContentStream implements the llm.StreamingLLMClient interface.
+// Errors that occur before the first chunk arrives (authentication, rate limits,
+// invalid requests) are returned directly; later failures arrive as a terminal chunk.
+func (c *openaiClient) GenerateContentStream(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+	requestParams, err := c.buildRequestParams(prompt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := c.api.createChatCompletionStream(ctx, requestParams)
+
+	// Prime the stream so request-level failures surface as a returned error
+	if !stream.Next() {
+		err := stream.Err()
+		_ = stream.Close()
+		if err != nil {
+			return nil, FormatAPIError(err, 0)
+		}
+		// The stream ended without producing anything; report an empty completion
+		return llm.StreamFromResult(&llm.ProviderResult{}, nil), nil
+	}
+
+	chunks := make(chan llm.StreamChunk)
+	go func() {
+		defer close(chunks)
+		defer func() { _ = stream.Close() }()
+
+		var finishReason string
+		var usage *llm.TokenUsage
+		for {
+			chunk := stream.Current()
+			// With usage reporting enabled, the final chunk carries usage and no choices
+			if chunkUsage := toProviderUsage(chunk.Usage); chunkUsage != nil {
+				usage = chunkUsage
+			}
+			for _, choice := range chunk.Choices {
+				if choice.FinishReason != "" {
+					finishReason = choice.FinishReason
+				}
+				if choice.Delta.Content == "" {
+					continue
+				}
+				if !llm.SendChunk(ctx, chunks, llm.StreamChunk{Content: choice.Delta.Content}) {
+					return
+				}
+			}
+
+			if !stream.Next() {
+				break
+			}
+		}
+
+		if err := stream.Err(); err != nil {
+			llm.SendChunk(ctx, chunks, llm.StreamChunk{Err: FormatAPIError(err, 0)})
+			return
+		}
+
+		llm.SendChunk(ctx, chunks, llm.StreamChunk{
+			FinishReason: finishReason,
+			Truncated:    finishReason == "length",
+			Usage:        usage,
+		})
+	}()
+
+	return chunks, nil
+}
+
+// buildRequestParams converts a prompt and parameter map into OpenAI request parameters,
+// applying client-level defaults first and then per-request overrides
+func (c *openaiClient) buildRequestParams(prompt string, params map[string]interface{}) (openai.ChatCompletionNewParams, error) {
 	if prompt == "" {
-		return nil, CreateAPIError(
+		return openai.ChatCompletionNewParams{}, CreateAPIError(
 			llm.CategoryInvalidRequest,
 			"Empty prompt",
 			errors.New("prompt cannot be empty"),
@@ -116,22 +239,9 @@ func (c *openaiClient) GenerateContent(ctx context.Context, prompt string, param
 		)
 	}
 
-	// Extract system prompt if present
-	systemPrompt := ""
-	if strings.Contains(prompt, "<system>") && strings.Contains(prompt, "</system>") {
-		systemPrompt = extractTag(prompt, "system")
-		if systemPrompt != "" {
-			// Remove system content from prompt
-			prompt = removeTag(prompt, "system")
-		}
-	} else if strings.Contains(prompt, "<s>") && strings.Contains(prompt, "</s>") {
-		// For backward compatibility with <s> tags
-		systemPrompt = extractTag(prompt, "s")
-		if systemPrompt != "" {
-			// Remove system content from prompt
-			prompt = removeTag(prompt, "s")
-		}
-	}
+	// Use the system prompt of the request. The prompt is sent as it is: it holds the
+	// context files, whose contents must not be read as instructions.
+	systemPrompt := llm.SystemPrompt(params)
 
 	// Build the messages array
 	messages := []openai.ChatCompletionMessageParamUnion{}
@@ -178,32 +288,21 @@ func (c *openaiClient) GenerateContent(ctx context.Context, prompt string, param
 		applyOpenAIParameters(&requestParams, params)
 	}
 
-	// Make the API call
-	completion, err := c.api.createChatCompletionWithParams(ctx, requestParams)
-	if err != nil {
-		return nil, err
-	}
+	return requestParams, nil
+}
 
-	// Handle empty choices
-	if len(completion.Choices) == 0 {
-		return nil, fmt.Errorf("no completions returned from OpenAI API")
+// toProviderUsage converts OpenAI usage information to provider-agnostic token usage.
+// It returns nil when the response carried no usage data.
+func toProviderUsage(usage openai.CompletionUsage) *llm.TokenUsage {
+	if usage.TotalTokens == 0 && usage.PromptTokens == 0 && usage.CompletionTokens == 0 {
+		return nil
 	}
 
-	// Extract content
-	choice := completion.Choices[0]
-	content := choice.Message.Content
-
-	// Get finish reason
-	finishReason := choice.FinishReason
-
-	// Build result
-	result := &llm.ProviderResult{
-		Content:      content,
-		FinishReason: finishReason,
-		Truncated:    finishReason == "length",
+	return &llm.TokenUsage{
+		PromptTokens:     int32(usage.PromptTokens),
+		CompletionTokens: int32(usage.CompletionTokens),
+		TotalTokens:      int32(usage.TotalTokens),
 	}
-
-	return result, nil
 }
 
 // GetModelName returns the model name
@@ -241,44 +340,6 @@ func (c *openaiClient) SetPresencePenalty(penalty float32) {
 	c.presencePenalty = &penalty
 }
 
-// Helper function to extract content between XML tags
-func extractTag(text, tag string) string {
-	startTag := fmt.Sprintf("<%s>", tag)
-	endTag := fmt.Sprintf("</%s>", tag)
-
-	startIdx := strings.Index(text, startTag)
-	if startIdx == -1 {
-		return ""
-	}
-	startIdx += len(startTag)
-
-	endIdx := strings.Index(text[startIdx:], endTag)
-	if endIdx == -1 {
-		return ""
-	}
-
-	return text[startIdx : startIdx+endIdx]
-}
-
-// Helper function to remove content between XML tags including the tags
-func removeTag(text, tag string) string {
-	startTag := fmt.Sprintf("<%s>", tag)
-	endTag := fmt.Sprintf("</%s>", tag)
-
-	startIdx := strings.Index(text, startTag)
-	if startIdx == -1 {
-		return text
-	}
-
-	endIdx := strings.Index(text[startIdx:], endTag)
-	if endIdx == -1 {
-		return text
-	}
-	endIdx = startIdx + endIdx + len(endTag)
-
-	return text[:startIdx] + text[endIdx:]
-}
-
 // Apply parameters from map to OpenAI request parameters
 func applyOpenAIParameters(params *openai.ChatCompletionNewParams, customParams map[string]interface{}) {
 	// Apply standard parameters
@@ -16,6 +16,7 @@ import (
 type mockOpenAIAPI struct {
 	createChatCompletionFunc           func(ctx context.Context, model string, prompt string, systemPrompt string) (*openai.ChatCompletion, error)
 	createChatCompletionWithParamsFunc func(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error)
+	createChatCompletionStreamFunc     func(ctx context.Context, params openai.ChatCompletionNewParams) chatCompletionStream
 }
 
 func (m *mockOpenAIAPI) createChatCompletion(ctx context.Context, model string, prompt string, systemPrompt string) (*openai.ChatCompletion, error) {
@@ -32,51 +33,11 @@ func (m *mockOpenAIAPI) createChatCompletionWithParams(ctx context.Context, para
 	return nil, errors.New("not implemented")
 }
 
-// TestExtractTag verifies the XML tag extraction function
-func TestExtractTag(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		tag      string
-		expected string
-	}{
-		{"Basic tag", "<system>Hello</system>", "system", "Hello"},
-		{"S tag", "<s>Hello</s>", "s", "Hello"},
-		{"Nested content", "<system>Hello <world></system>", "system", "Hello <world>"},
-		{"No matching tag", "Hello world", "system", ""},
-		{"Incomplete tag", "<system>Hello", "system", ""},
-		{"Multiple tags", "<system>First</system><system>Second</system>", "system", "First"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := extractTag(tt.input, tt.tag)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
-// TestRemoveTag verifies the XML tag removal function
-func TestRemoveTag(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		tag      string
-		expected string
-	}{
-		{"Basic tag", "<system>Hello</system>Content", "system", "Content"},
-		{"S tag", "<s>Hello</s>Content", "s", "Content"},
-		{"No matching tag", "Hello world", "system", "Hello world"},
-		{"Incomplete tag", "<system>Hello", "system", "<system>Hello"},
-		{"Multiple tags", "<system>First</system><system>Second</system>", "system", "<system>Second</system>"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := removeTag(tt.input, tt.tag)
-			assert.Equal(t, tt.expected, result)
-		})
+func (m *mockOpenAIAPI) createChatCompletionStream(ctx context.Context, params openai.ChatCompletionNewParams) chatCompletionStream {
+	if m.createChatCompletionStreamFunc != nil {
+		return m.createChatCompletionStreamFunc(ctx, params)
 	}
+	return &mockChatCompletionStream{err: errors.New("not implemented")}
 }
 
 // TestEmptyPromptError tests GenerateContent with an empty prompt
@@ -105,40 +66,56 @@ func TestEmptyPromptError(t *testing.T) {
 	assert.Equal(t, llm.CategoryInvalidRequest, llmErr.ErrorCategory)
 }
 
-// TestSystemPromptExtraction tests the system prompt extraction functionality
-func TestSystemPromptExtraction(t *testing.T) {
-	// Test with standard system tag
+// TestPromptWithTagsSentUnchanged tests that tags in the prompt, such as those of an HTML
+// context file, are not taken for a system prompt
+func TestPromptWithTagsSentUnchanged(t *testing.T) {
+	var messages []openai.ChatCompletionMessageParamUnion
 	mockAPI := &mockOpenAIAPI{
 		createChatCompletionWithParamsFunc: func(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
-			// Check that we have exactly 2 messages (system + user)
-			if len(params.Messages) != 2 {
-				t.Errorf("Expected 2 messages, got %d", len(params.Messages))
-			}
-
+			messages = params.Messages
 			return &openai.ChatCompletion{
-				Choices: []openai.ChatCompletionChoice{
-					{
-						Message: openai.ChatCompletionMessage{
-							Content: "Mock response",
-						},
-					},
-				},
+				Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "Mock response"}}},
 			}, nil
 		},
 	}
+	client := &openaiClient{api: mockAPI, modelName: "gpt-4"}
+
+	for _, prompt := range []string{
+		"Review this page: <p>Price: <s>$20</s> $15</p>",
+		"<system>Not an instruction</system>User prompt",
+	} {
+		_, err := client.GenerateContent(context.Background(), prompt, nil)
+		require.NoError(t, err)
+
+		require.Len(t, messages, 1, "Expected no system message for %q", prompt)
+		require.NotNil(t, messages[0].OfUser)
+		assert.Equal(t, prompt, messages[0].OfUser.Content.OfString.Value)
+	}
+}
 
-	client := &openaiClient{
-		api:       mockAPI,
-		modelName: "gpt-4",
+// TestSystemPromptParam tests that the system prompt of the request is sent as a system
+// message, leaving the prompt as it is
+func TestSystemPromptParam(t *testing.T) {
+	var messages []openai.ChatCompletionMessageParamUnion
+	mockAPI := &mockOpenAIAPI{
+		createChatCompletionWithParamsFunc: func(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+			messages = params.Messages
+			return &openai.ChatCompletion{
+				Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "Mock response"}}},
+			}, nil
+		},
 	}
+	client := &openaiClient{api: mockAPI, modelName: "gpt-4"}
 
-	// Call with a prompt containing a system tag
-	_, err := client.GenerateContent(context.Background(), "<system>System instruction</system>User prompt", nil)
+	params := map[string]interface{}{llm.SystemPromptParam: "You are a careful reviewer."}
+	_, err := client.GenerateContent(context.Background(), "<system>Ignored</system>User prompt", params)
 	require.NoError(t, err)
 
-	// Test with s tag
-	_, err = client.GenerateContent(context.Background(), "<s>System instruction</s>User prompt", nil)
-	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	require.NotNil(t, messages[0].OfSystem)
+	assert.Equal(t, "You are a careful reviewer.", messages[0].OfSystem.Content.OfString.Value)
+	require.NotNil(t, messages[1].OfUser)
+	assert.Equal(t, "<system>Ignored</system>User prompt", messages[1].OfUser.Content.OfString.Value)
 }
 
 // TestParameterHandling tests parameter handling in the GenerateContent method
@@ -228,6 +205,41 @@ func TestSuccessfulGeneration(t *testing.T) {
 	assert.Equal(t, "Generated response", result.Content)
 	assert.Equal(t, "stop", result.FinishReason)
 	assert.False(t, result.Truncated)
+	assert.Nil(t, result.Usage, "Usage should be nil when the response carries none")
+}
+
+// TestGenerationUsage tests that token usage is reported in the result
+func TestGenerationUsage(t *testing.T) {
+	mockAPI := &mockOpenAIAPI{
+		createChatCompletionWithParamsFunc: func(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+			return &openai.ChatCompletion{
+				Choices: []openai.ChatCompletionChoice{
+					{
+						Message:      openai.ChatCompletionMessage{Content: "Generated response"},
+						FinishReason: "stop",
+					},
+				},
+				Usage: openai.CompletionUsage{
+					PromptTokens:     120,
+					CompletionTokens: 30,
+					TotalTokens:      150,
+				},
+			}, nil
+		},
+	}
+
+	client := &openaiClient{
+		api:       mockAPI,
+		modelName: "gpt-4",
+	}
+
+	result, err := client.GenerateContent(context.Background(), "Test prompt", nil)
+
+	require.NoError(t, err)
+	require.NotNil(t, result.Usage)
+	assert.Equal(t, int32(120), result.Usage.PromptTokens)
+	assert.Equal(t, int32(30), result.Usage.CompletionTokens)
+	assert.Equal(t, int32(150), result.Usage.TotalTokens)
 }
 
 // TestTruncatedResponse tests response truncation detection
@@ -0,0 +1,561 @@
+// Package anthropic provides the implementation of the Anthropic LLM provider
+package anthropic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/logutil"
+)
+
+const (
+	// DefaultAPIEndpoint is the base URL of the Anthropic API
+	DefaultAPIEndpoint = "https://api.anthropic.com/v1"
+
+	// APIVersion is the Anthropic API version sent with every request
+	APIVersion = "2023-06-01"
+
+	// DefaultMaxTokens is used when neither the model parameters nor the request
+	// set max_tokens, which the Messages API requires
+	DefaultMaxTokens int32 = 8192
+)
+
+// anthropicClient implements the llm.LLMClient interface for the Anthropic Messages API
+type anthropicClient struct {
+	apiKey      string
+	modelID     string
+	apiEndpoint string
+	httpClient  *http.Client
+	logger      logutil.LoggerInterface
+
+	// Optional request parameters
+	temperature *float32
+	topP        *float32
+	topK        *int32
+	maxTokens   *int32
+}
+
+// NewClient creates a new Anthropic client that implements the llm.LLMClient interface
+func NewClient(apiKey string, modelID string, apiEndpoint string, logger logutil.LoggerInterface) (*anthropicClient, error) {
+	// Validate required parameters
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key cannot be empty")
+	}
+
+	if modelID == "" {
+		return nil, fmt.Errorf("model ID cannot be empty")
+	}
+
+	// Set default API endpoint if not provided
+	if apiEndpoint == "" {
+		apiEndpoint = DefaultAPIEndpoint
+	}
+
+	// Create HTTP client with reasonable timeout
+	httpClient := &http.Client{
+		Timeout: 10 * time.Minute, // Long generations with large max_tokens can take several minutes
+	}
+
+	return &anthropicClient{
+		apiKey:      apiKey,
+		modelID:     modelID,
+		apiEndpoint: strings.TrimSuffix(apiEndpoint, "/"),
+		httpClient:  httpClient,
+		logger:      logger,
+	}, nil
+}
+
+// Message represents a message in the Anthropic Messages API format
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// MessagesRequest represents the request structure for the Anthropic Messages API
+type MessagesRequest struct {
+	Model       string    `json:"model"`
+	System      string    `json:"system,omitempty"`
+	Messages    []Message `json:"messages"`
+	MaxTokens   int32     `json:"max_tokens"`
+	Temperature *float32  `json:"temperature,omitempty"`
+	TopP        *float32  `json:"top_p,omitempty"`
+	TopK        *int32    `json:"top_k,omitempty"`
+	Stream      bool      `json:"stream,omitempty"`
+}
+
+// ContentBlock represents a block of content in a Messages API response
+type ContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+// Usage represents token usage information in a Messages API response
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// toProviderUsage converts Anthropic usage information to provider-agnostic token usage.
+// It returns nil when the response carried no usage data.
+func (u Usage) toProviderUsage() *llm.TokenUsage {
+	if u.InputTokens == 0 && u.OutputTokens == 0 {
+		return nil
+	}
+
+	return &llm.TokenUsage{
+		PromptTokens:     int32(u.InputTokens),
+		CompletionTokens: int32(u.OutputTokens),
+		TotalTokens:      int32(u.InputTokens + u.OutputTokens),
+	}
+}
+
+// MessagesResponse represents the response structure from the Anthropic Messages API
+type MessagesResponse struct {
+	ID         string         `json:"id"`
+	Type       string         `json:"type"`
+	Role       string         `json:"role"`
+	Model      string         `json:"model"`
+	Content    []ContentBlock `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      Usage          `json:"usage"`
+}
+
+// StreamEvent represents a single server-sent event payload from the Messages API
+// when streaming is enabled. Only the fields used by the client are decoded.
+type StreamEvent struct {
+	Type    string            `json:"type"`
+	Message *MessagesResponse `json:"message,omitempty"`
+	Delta   *StreamDelta      `json:"delta,omitempty"`
+	Usage   *Usage            `json:"usage,omitempty"`
+	Error   *APIErrorDetail   `json:"error,omitempty"`
+}
+
+// StreamDelta holds the incremental content of content_block_delta events and
+// the stop reason of message_delta events
+type StreamDelta struct {
+	Type       string `json:"type"`
+	Text       string `json:"text,omitempty"`
+	StopReason string `json:"stop_reason,omitempty"`
+}
+
+// mapStopReason converts an Anthropic stop_reason to the finish reasons used by
+// the other providers, and reports whether the response was truncated
+func mapStopReason(stopReason string) (string, bool) {
+	switch stopReason {
+	case "end_turn", "stop_sequence":
+		return "stop", false
+	case "max_tokens":
+		return "length", true
+	case "tool_use":
+		return "tool_calls", false
+	case "refusal":
+		return "safety", false
+	default:
+		return stopReason, false
+	}
+}
+
+// GenerateContent sends a prompt to the LLM and returns the generated content
+func (c *anthropicClient) GenerateContent(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+	resp, err := c.sendRequest(ctx, c.buildRequest(prompt, params, false))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil && c.logger != nil {
+			c.logger.Warn("Failed to close response body: %v", closeErr)
+		}
+	}()
+
+	// Read the response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, CreateAPIError(
+			llm.CategoryNetwork,
+			"Failed to read response from Anthropic API",
+			err,
+			fmt.Sprintf("Response read error: %v", err),
+		)
+	}
+
+	// Parse the response
+	var messagesResponse MessagesResponse
+	if err := json.Unmarshal(body, &messagesResponse); err != nil {
+		return nil, CreateAPIError(
+			llm.CategoryServer,
+			"Failed to parse response from Anthropic API",
+			err,
+			fmt.Sprintf("JSON unmarshal error: %v, Body: %s", err, truncateString(string(body), 200)),
+		)
+	}
+
+	// Concatenate the text blocks of the response
+	var content strings.Builder
+	for _, block := range messagesResponse.Content {
+		if block.Type == "text" {
+			content.WriteString(block.Text)
+		}
+	}
+
+	finishReason, truncated := mapStopReason(messagesResponse.StopReason)
+
+	return &llm.ProviderResult{
+		Content:      content.String(),
+		FinishReason: finishReason,
+		Truncated:    truncated,
+		SafetyInfo:   []llm.Safety{},
+		Usage:        messagesResponse.Usage.toProviderUsage(),
+	}, nil
+}
+
+// GenerateContentStream sends a prompt to the LLM with streaming enabled and
+// returns a channel that receives content deltas as server-sent events arrive
+func (c *anthropicClient) GenerateContentStream(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+	resp, err := c.sendRequest(ctx, c.buildRequest(prompt, params, true))
+	if err != nil {
+		return nil, err
+	}
+
+	stream := make(chan llm.StreamChunk)
+	go func() {
+		defer close(stream)
+		defer func() {
+			if closeErr := resp.Body.Close(); closeErr != nil && c.logger != nil {
+				c.logger.Warn("Failed to close response body: %v", closeErr)
+			}
+		}()
+		c.readStream(ctx, resp.Body, stream)
+	}()
+
+	return stream, nil
+}
+
+// readStream parses server-sent events from the response body and forwards
+// text deltas to the stream. The final chunk carries the finish reason and usage.
+func (c *anthropicClient) readStream(ctx context.Context, body io.Reader, stream chan<- llm.StreamChunk) {
+	var stopReason string
+	var usage Usage
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Event names are repeated in the payload's type field, so only data lines matter
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+
+		var event StreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			llm.SendChunk(ctx, stream, llm.StreamChunk{Err: CreateAPIError(
+				llm.CategoryServer,
+				"Failed to parse streaming response from Anthropic API",
+				err,
+				fmt.Sprintf("JSON unmarshal error: %v, Event: %s", err, truncateString(data, 200)),
+			)})
+			return
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				usage = event.Message.Usage
+			}
+		case "content_block_delta":
+			if event.Delta == nil || event.Delta.Type != "text_delta" || event.Delta.Text == "" {
+				continue
+			}
+			if !llm.SendChunk(ctx, stream, llm.StreamChunk{Content: event.Delta.Text}) {
+				return
+			}
+		case "message_delta":
+			if event.Delta != nil && event.Delta.StopReason != "" {
+				stopReason = event.Delta.StopReason
+			}
+			// message_delta usage is cumulative for output tokens
+			if event.Usage != nil {
+				usage.OutputTokens = event.Usage.OutputTokens
+			}
+		case "error":
+			// Errors after the response has started (e.g. overloaded_error) arrive as events
+			errorMessage := "unknown streaming error"
+			if event.Error != nil {
+				errorMessage = event.Error.Message
+			}
+			llm.SendChunk(ctx, stream, llm.StreamChunk{Err: FormatAPIError(
+				fmt.Errorf("Anthropic API streaming error: %s", errorMessage),
+				0,
+				[]byte(data),
+			)})
+			return
+		case "message_stop":
+			finishReason, truncated := mapStopReason(stopReason)
+			llm.SendChunk(ctx, stream, llm.StreamChunk{
+				FinishReason: finishReason,
+				Truncated:    truncated,
+				Usage:        usage.toProviderUsage(),
+			})
+			return
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		category := llm.CategoryNetwork
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			category = llm.CategoryCancelled
+		}
+		llm.SendChunk(ctx, stream, llm.StreamChunk{Err: CreateAPIError(
+			category,
+			"Streaming response from Anthropic API was interrupted",
+			err,
+			fmt.Sprintf("Stream read error: %v", err),
+		)})
+		return
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	// The stream ended without a message_stop event
+	llm.SendChunk(ctx, stream, llm.StreamChunk{Err: CreateAPIError(
+		llm.CategoryNetwork,
+		"Streaming response from Anthropic API ended unexpectedly",
+		io.ErrUnexpectedEOF,
+		"Stream closed before the message_stop event",
+	)})
+}
+
+// sendRequest marshals and sends a Messages API request, returning the HTTP
+// response on success. Non-200 responses are converted into categorized errors.
+// The caller is responsible for closing the response body.
+func (c *anthropicClient) sendRequest(ctx context.Context, requestBody MessagesRequest) (*http.Response, error) {
+	// Convert request to JSON
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, CreateAPIError(
+			llm.CategoryInvalidRequest,
+			"Failed to prepare request to Anthropic API",
+			err,
+			fmt.Sprintf("JSON marshal error: %v", err),
+		)
+	}
+
+	// Construct the API URL
+	apiURL := fmt.Sprintf("%s/messages", c.apiEndpoint)
+
+	// Create the HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, CreateAPIError(
+			llm.CategoryNetwork,
+			"Failed to create HTTP request to Anthropic API",
+			err,
+			fmt.Sprintf("Request creation error: %v", err),
+		)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", APIVersion)
+	if requestBody.Stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	// Execute the request
+	if c.logger != nil {
+		c.logger.Debug("Sending request to Anthropic API: %s", apiURL)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// Check for context cancellation
+		category := llm.CategoryNetwork
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			category = llm.CategoryCancelled
+		}
+
+		return nil, CreateAPIError(
+			category,
+			"Failed to connect to Anthropic API",
+			err,
+			fmt.Sprintf("HTTP error: %v", err),
+		)
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+
+	// Handle non-200 status codes
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil && c.logger != nil {
+			c.logger.Warn("Failed to close response body: %v", closeErr)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, CreateAPIError(
+			llm.CategoryNetwork,
+			"Failed to read response from Anthropic API",
+			err,
+			fmt.Sprintf("Response read error: %v", err),
+		)
+	}
+
+	apiErr := FormatAPIError(
+		fmt.Errorf("Anthropic API returned non-200 status code: %d", resp.StatusCode),
+		resp.StatusCode,
+		body,
+	)
+	apiErr.RequestID = resp.Header.Get("request-id")
+	apiErr.RetryAfter = llm.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+	return nil, apiErr
+}
+
+// buildRequest assembles a Messages API request from the client defaults
+// and the per-request parameters
+func (c *anthropicClient) buildRequest(prompt string, params map[string]interface{}, stream bool) MessagesRequest {
+	request := MessagesRequest{
+		Model:     c.modelID,
+		Messages:  []Message{{Role: "user", Content: prompt}},
+		MaxTokens: DefaultMaxTokens,
+		Stream:    stream,
+	}
+
+	// Copy receiver defaults instead of sharing pointers with the client
+	if c.temperature != nil {
+		temp := *c.temperature
+		request.Temperature = &temp
+	}
+	if c.topP != nil {
+		tp := *c.topP
+		request.TopP = &tp
+	}
+	if c.topK != nil {
+		tk := *c.topK
+		request.TopK = &tk
+	}
+	if c.maxTokens != nil {
+		request.MaxTokens = *c.maxTokens
+	}
+
+	// Apply parameters from method argument if provided
+	if v, ok := toFloat32(params["temperature"]); ok {
+		request.Temperature = &v
+	}
+	if v, ok := toFloat32(params["top_p"]); ok {
+		request.TopP = &v
+	}
+	if v, ok := toInt32(params["top_k"]); ok {
+		request.TopK = &v
+	}
+
+	// Max tokens - accept both OpenAI-style and Gemini-style parameter names
+	if v, ok := toInt32(params["max_tokens"]); ok && v > 0 {
+		request.MaxTokens = v
+	} else if v, ok := toInt32(params["max_output_tokens"]); ok && v > 0 {
+		request.MaxTokens = v
+	}
+
+	// The system prompt is a top-level field rather than a message
+	request.System = llm.SystemPrompt(params)
+
+	return request
+}
+
+// toFloat32 converts a numeric parameter value to float32
+func toFloat32(value interface{}) (float32, bool) {
+	switch v := value.(type) {
+	case float32:
+		return v, true
+	case float64:
+		return float32(v), true
+	case int:
+		return float32(v), true
+	default:
+		return 0, false
+	}
+}
+
+// toInt32 converts a numeric parameter value to int32
+func toInt32(value interface{}) (int32, bool) {
+	switch v := value.(type) {
+	case int32:
+		return v, true
+	case int:
+		return int32(v), true
+	case int64:
+		return int32(v), true
+	case float64:
+		return int32(v), true
+	default:
+		return 0, false
+	}
+}
+
+// GetModelName returns the name of the model being used
+func (c *anthropicClient) GetModelName() string {
+	return c.modelID
+}
+
+// Close releases resources used by the client
+func (c *anthropicClient) Close() error {
+	// For a standard HTTP client, no explicit cleanup is required
+	return nil
+}
+
+// Helper methods for parameter configuration
+
+// SetTemperature sets the default temperature parameter
+// Note: This setting only applies as a default. Request-specific parameters
+// passed to GenerateContent will override this value without modifying it.
+func (c *anthropicClient) SetTemperature(temp float32) {
+	localTemp := temp
+	c.temperature = &localTemp
+}
+
+// SetTopP sets the default top_p parameter
+// Note: This setting only applies as a default. Request-specific parameters
+// passed to GenerateContent will override this value without modifying it.
+func (c *anthropicClient) SetTopP(topP float32) {
+	localTopP := topP
+	c.topP = &localTopP
+}
+
+// SetTopK sets the default top_k parameter
+// Note: This setting only applies as a default. Request-specific parameters
+// passed to GenerateContent will override this value without modifying it.
+func (c *anthropicClient) SetTopK(topK int32) {
+	localTopK := topK
+	c.topK = &localTopK
+}
+
+// SetMaxTokens sets the default max_tokens parameter
+// Note: This setting only applies as a default. Request-specific parameters
+// passed to GenerateContent will override this value without modifying it.
+func (c *anthropicClient) SetMaxTokens(tokens int32) {
+	localTokens := tokens
+	c.maxTokens = &localTokens
+}
+
+// truncateString truncates a string to the specified length and adds an ellipsis
+func truncateString(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen] + "..."
+}
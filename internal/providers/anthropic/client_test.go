@@ -0,0 +1,253 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient creates a client that sends requests to the given test server
+func newTestClient(t *testing.T, server *httptest.Server) *anthropicClient {
+	t.Helper()
+	client, err := NewClient("sk-ant-test-key", "claude-test", server.URL, nil)
+	require.NoError(t, err)
+	return client
+}
+
+// TestGenerateContent tests a successful Messages API round trip
+func TestGenerateContent(t *testing.T) {
+	var captured MessagesRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/messages", r.URL.Path)
+		assert.Equal(t, "sk-ant-test-key", r.Header.Get("x-api-key"))
+		assert.Equal(t, APIVersion, r.Header.Get("anthropic-version"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &captured))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{
+			"id": "msg_1",
+			"type": "message",
+			"role": "assistant",
+			"content": [{"type": "text", "text": "Hello, "}, {"type": "text", "text": "world"}],
+			"stop_reason": "end_turn",
+			"usage": {"input_tokens": 12, "output_tokens": 5}
+		}`)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	client.SetTemperature(0.2)
+
+	result, err := client.GenerateContent(context.Background(), "Say hello", map[string]interface{}{
+		"max_tokens": 1024,
+		"top_k":      40,
+		"system":     "You are a careful reviewer.",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hello, world", result.Content)
+	assert.Equal(t, "stop", result.FinishReason)
+	assert.False(t, result.Truncated)
+	require.NotNil(t, result.Usage)
+	assert.Equal(t, int32(12), result.Usage.PromptTokens)
+	assert.Equal(t, int32(5), result.Usage.CompletionTokens)
+	assert.Equal(t, int32(17), result.Usage.TotalTokens)
+
+	assert.Equal(t, "claude-test", captured.Model)
+	assert.Equal(t, "You are a careful reviewer.", captured.System)
+	assert.Equal(t, int32(1024), captured.MaxTokens)
+	require.NotNil(t, captured.Temperature)
+	assert.Equal(t, float32(0.2), *captured.Temperature)
+	require.NotNil(t, captured.TopK)
+	assert.Equal(t, int32(40), *captured.TopK)
+	require.Len(t, captured.Messages, 1)
+	assert.Equal(t, "user", captured.Messages[0].Role)
+	assert.Equal(t, "Say hello", captured.Messages[0].Content)
+}
+
+// TestBuildRequestDefaultMaxTokens tests that max_tokens is always set, since the API requires it
+func TestBuildRequestDefaultMaxTokens(t *testing.T) {
+	client, err := NewClient("sk-ant-test-key", "claude-test", "", nil)
+	require.NoError(t, err)
+
+	request := client.buildRequest("prompt", nil, false)
+	assert.Equal(t, DefaultMaxTokens, request.MaxTokens)
+	assert.Empty(t, request.System)
+
+	request = client.buildRequest("prompt", map[string]interface{}{"max_output_tokens": 2048.0}, false)
+	assert.Equal(t, int32(2048), request.MaxTokens)
+
+	client.SetMaxTokens(4096)
+	request = client.buildRequest("prompt", nil, false)
+	assert.Equal(t, int32(4096), request.MaxTokens)
+}
+
+// TestMapStopReason tests the mapping of Anthropic stop reasons to finish reasons
+func TestMapStopReason(t *testing.T) {
+	tests := []struct {
+		stopReason    string
+		finishReason  string
+		wantTruncated bool
+	}{
+		{"end_turn", "stop", false},
+		{"stop_sequence", "stop", false},
+		{"max_tokens", "length", true},
+		{"tool_use", "tool_calls", false},
+		{"refusal", "safety", false},
+		{"pause_turn", "pause_turn", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stopReason, func(t *testing.T) {
+			finishReason, truncated := mapStopReason(tt.stopReason)
+			assert.Equal(t, tt.finishReason, finishReason)
+			assert.Equal(t, tt.wantTruncated, truncated)
+		})
+	}
+}
+
+// TestGenerateContentErrors tests that HTTP errors are mapped to error categories
+func TestGenerateContentErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		body         string
+		wantCategory llm.ErrorCategory
+	}{
+		{"Authentication", http.StatusUnauthorized, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`, llm.CategoryAuth},
+		{"Rate limit", http.StatusTooManyRequests, `{"type":"error","error":{"type":"rate_limit_error","message":"Number of requests has exceeded your rate limit"}}`, llm.CategoryRateLimit},
+		{"Overloaded", StatusOverloaded, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`, llm.CategoryServer},
+		{"Prompt too long", http.StatusBadRequest, `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`, llm.CategoryInputLimit},
+		{"Invalid request", http.StatusBadRequest, `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: field required"}}`, llm.CategoryInvalidRequest},
+		{"Request too large", http.StatusRequestEntityTooLarge, `{"type":"error","error":{"type":"request_too_large","message":"Request exceeds the maximum allowed number of bytes"}}`, llm.CategoryInputLimit},
+		{"Unparseable body", http.StatusServiceUnavailable, `upstream unavailable`, llm.CategoryServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("request-id", "req_123")
+				w.WriteHeader(tt.statusCode)
+				_, _ = fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			_, err := newTestClient(t, server).GenerateContent(context.Background(), "prompt", nil)
+			require.Error(t, err)
+
+			llmErr, ok := IsAnthropicError(err)
+			require.True(t, ok, "Expected an Anthropic LLMError, got %T", err)
+			assert.Equal(t, tt.wantCategory, llmErr.Category())
+			assert.Equal(t, tt.statusCode, llmErr.StatusCode)
+			assert.Equal(t, "req_123", llmErr.RequestID)
+		})
+	}
+}
+
+// TestGenerateContentRetryAfter tests that the Retry-After header of an error response is preserved
+func TestGenerateContentRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "15")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = fmt.Fprint(w, `{"type":"error","error":{"type":"rate_limit_error","message":"rate limited"}}`)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(t, server).GenerateContent(context.Background(), "prompt", nil)
+	require.Error(t, err)
+	assert.True(t, llm.IsRateLimit(err))
+	assert.Equal(t, 15*time.Second, llm.GetRetryAfter(err))
+}
+
+// TestGenerateContentStream tests parsing of a streamed Messages API response
+func TestGenerateContentStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request MessagesRequest
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &request))
+		assert.True(t, request.Stream)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		events := []string{
+			`event: message_start` + "\n" + `data: {"type":"message_start","message":{"id":"msg_1","usage":{"input_tokens":25,"output_tokens":1}}}`,
+			`event: content_block_start` + "\n" + `data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`event: ping` + "\n" + `data: {"type":"ping"}`,
+			`event: content_block_delta` + "\n" + `data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}`,
+			`event: content_block_delta` + "\n" + `data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" there"}}`,
+			`event: content_block_stop` + "\n" + `data: {"type":"content_block_stop","index":0}`,
+			`event: message_delta` + "\n" + `data: {"type":"message_delta","delta":{"stop_reason":"max_tokens"},"usage":{"output_tokens":15}}`,
+			`event: message_stop` + "\n" + `data: {"type":"message_stop"}`,
+		}
+		for _, event := range events {
+			_, _ = fmt.Fprintf(w, "%s\n\n", event)
+		}
+	}))
+	defer server.Close()
+
+	stream, err := newTestClient(t, server).GenerateContentStream(context.Background(), "prompt", nil)
+	require.NoError(t, err)
+
+	var deltas []string
+	result, err := llm.CollectStream(context.Background(), stream, func(delta string) { deltas = append(deltas, delta) })
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Hello", " there"}, deltas)
+	assert.Equal(t, "Hello there", result.Content)
+	assert.Equal(t, "length", result.FinishReason)
+	assert.True(t, result.Truncated)
+	require.NotNil(t, result.Usage)
+	assert.Equal(t, int32(25), result.Usage.PromptTokens)
+	assert.Equal(t, int32(15), result.Usage.CompletionTokens)
+}
+
+// TestGenerateContentStreamErrorEvent tests that an error event ends the stream with a categorized error
+func TestGenerateContentStreamErrorEvent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Partial\"}}\n\n")
+		_, _ = fmt.Fprint(w, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+	}))
+	defer server.Close()
+
+	stream, err := newTestClient(t, server).GenerateContentStream(context.Background(), "prompt", nil)
+	require.NoError(t, err)
+
+	result, err := llm.CollectStream(context.Background(), stream, nil)
+	require.Error(t, err)
+	assert.Equal(t, "Partial", result.Content)
+
+	llmErr, ok := IsAnthropicError(err)
+	require.True(t, ok)
+	assert.Equal(t, llm.CategoryServer, llmErr.Category())
+	assert.Contains(t, llmErr.Suggestion, "overloaded")
+}
+
+// TestGenerateContentStreamUnexpectedEOF tests that a stream closed before message_stop reports an error
+func TestGenerateContentStreamUnexpectedEOF(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Partial\"}}\n\n")
+	}))
+	defer server.Close()
+
+	stream, err := newTestClient(t, server).GenerateContentStream(context.Background(), "prompt", nil)
+	require.NoError(t, err)
+
+	_, err = llm.CollectStream(context.Background(), stream, nil)
+	require.Error(t, err)
+	llmErr, ok := IsAnthropicError(err)
+	require.True(t, ok)
+	assert.Equal(t, llm.CategoryNetwork, llmErr.Category())
+}
@@ -0,0 +1,167 @@
+// Package anthropic provides the implementation of the Anthropic LLM provider
+package anthropic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/phrazzld/thinktank/internal/llm"
+)
+
+// StatusOverloaded is the non-standard HTTP status code the Anthropic API returns
+// when it is temporarily overloaded
+const StatusOverloaded = 529
+
+// APIErrorResponse represents the error structure returned by the Anthropic Messages API
+type APIErrorResponse struct {
+	Type  string         `json:"type"`
+	Error APIErrorDetail `json:"error"`
+}
+
+// APIErrorDetail contains the details of an API error returned by Anthropic
+type APIErrorDetail struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// IsAnthropicError checks if an error is an llm.LLMError originating from Anthropic
+func IsAnthropicError(err error) (*llm.LLMError, bool) {
+	var llmErr *llm.LLMError
+	if errors.As(err, &llmErr) && llmErr.Provider == "anthropic" {
+		return llmErr, true
+	}
+	return nil, false
+}
+
+// ParseErrorResponse parses the Anthropic API error response body and returns
+// the error message and error type
+func ParseErrorResponse(responseBody []byte) (string, string) {
+	if len(responseBody) == 0 {
+		return "", ""
+	}
+
+	var apiErrorResp APIErrorResponse
+	if err := json.Unmarshal(responseBody, &apiErrorResp); err != nil {
+		return "", ""
+	}
+
+	return apiErrorResp.Error.Message, apiErrorResp.Error.Type
+}
+
+// categoryForErrorType maps an Anthropic error type to an error category.
+// It returns llm.CategoryUnknown for types it does not recognize.
+func categoryForErrorType(errorType, errorMessage string) llm.ErrorCategory {
+	switch errorType {
+	case "authentication_error", "permission_error":
+		return llm.CategoryAuth
+	case "rate_limit_error":
+		return llm.CategoryRateLimit
+	case "not_found_error":
+		return llm.CategoryNotFound
+	case "request_too_large":
+		return llm.CategoryInputLimit
+	case "overloaded_error", "api_error":
+		return llm.CategoryServer
+	case "billing_error":
+		return llm.CategoryInsufficientCredits
+	case "invalid_request_error":
+		// Prompts that exceed the context window are reported as invalid requests
+		if strings.Contains(strings.ToLower(errorMessage), "prompt is too long") {
+			return llm.CategoryInputLimit
+		}
+		return llm.CategoryInvalidRequest
+	default:
+		return llm.CategoryUnknown
+	}
+}
+
+// FormatAPIError creates a standardized LLMError from an Anthropic API error
+func FormatAPIError(err error, statusCode int, responseBody []byte) *llm.LLMError {
+	if err == nil {
+		return nil
+	}
+
+	// Check if it's already an LLMError
+	var llmErr *llm.LLMError
+	if errors.As(err, &llmErr) {
+		return llmErr
+	}
+
+	errorMessage, errorType := ParseErrorResponse(responseBody)
+	details := ""
+	if errorMessage != "" {
+		details = fmt.Sprintf("API Error: %s", errorMessage)
+		if errorType != "" {
+			details += fmt.Sprintf(" (Type: %s)", errorType)
+		}
+	}
+
+	// Prefer the Anthropic error type, then the status code and message
+	category := categoryForErrorType(errorType, errorMessage)
+	if category == llm.CategoryUnknown {
+		category = llm.DetectErrorCategory(err, statusCode)
+	}
+
+	llmError := llm.CreateStandardErrorWithMessage("anthropic", category, err, details)
+	llmError.StatusCode = statusCode
+	llmError.Code = errorType
+	llmError.Suggestion = suggestionFor(category, statusCode == StatusOverloaded || errorType == "overloaded_error")
+
+	return llmError
+}
+
+// CreateAPIError creates a new LLMError with Anthropic-specific settings
+func CreateAPIError(category llm.ErrorCategory, errMsg string, originalErr error, details string) *llm.LLMError {
+	llmError := llm.New(
+		"anthropic", // Provider
+		"",          // Code
+		0,           // StatusCode
+		errMsg,      // Message
+		"",          // RequestID
+		originalErr, // Original error
+		category,    // Error category
+	)
+
+	// Add details if provided
+	if details != "" {
+		llmError.Details = details
+	}
+
+	llmError.Suggestion = suggestionFor(category, false)
+
+	return llmError
+}
+
+// suggestionFor returns an Anthropic-specific suggestion for an error category.
+// Overloaded errors get their own suggestion since they are expected to clear quickly.
+func suggestionFor(category llm.ErrorCategory, overloaded bool) string {
+	switch category {
+	case llm.CategoryAuth:
+		return "Check that your Anthropic API key is valid and has not expired. Ensure the ANTHROPIC_API_KEY environment variable is set correctly."
+	case llm.CategoryRateLimit:
+		return "Wait and try again later. Consider adjusting the --max-concurrent and --rate-limit flags to limit request rate."
+	case llm.CategoryInsufficientCredits:
+		return "Check your Anthropic account's billing settings and credit balance."
+	case llm.CategoryInvalidRequest:
+		return "Check the prompt format and parameters. Ensure they comply with the API requirements."
+	case llm.CategoryNotFound:
+		return "Verify that the api_model_id in models.yaml names a model available to your Anthropic account."
+	case llm.CategoryServer:
+		if overloaded {
+			return "The Anthropic API is temporarily overloaded. Wait a few moments and try again."
+		}
+		return "This is typically a temporary issue with the Anthropic API. Wait a few moments and try again."
+	case llm.CategoryNetwork:
+		return "Check your internet connection and try again. If persistent, there may be connectivity issues to Anthropic's servers."
+	case llm.CategoryCancelled:
+		return "The operation was interrupted. Try again with a longer timeout if needed."
+	case llm.CategoryInputLimit:
+		return "Reduce the input size by using --include, --exclude, or --exclude-names flags to filter the context."
+	case llm.CategoryContentFiltered:
+		return "Your prompt or content may have triggered safety filters. Review and modify your input to comply with content policies."
+	default:
+		return "Check the logs for more details or try again."
+	}
+}
@@ -0,0 +1,79 @@
+// Package anthropic provides the implementation of the Anthropic LLM provider
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/logutil"
+	"github.com/phrazzld/thinktank/internal/providers"
+)
+
+// AnthropicProvider implements the Provider interface for Anthropic models.
+type AnthropicProvider struct {
+	logger logutil.LoggerInterface
+}
+
+// NewProvider creates a new instance of AnthropicProvider.
+func NewProvider(logger logutil.LoggerInterface) providers.Provider {
+	// If no logger provided, create a default one
+	if logger == nil {
+		logger = logutil.NewLogger(logutil.InfoLevel, nil, "[anthropic-provider] ")
+	}
+
+	return &AnthropicProvider{
+		logger: logger,
+	}
+}
+
+// CreateClient implements the Provider interface.
+func (p *AnthropicProvider) CreateClient(
+	ctx context.Context,
+	apiKey string,
+	modelID string,
+	apiEndpoint string,
+) (llm.LLMClient, error) {
+	p.logger.Debug("Creating Anthropic client for model: %s", modelID)
+
+	// Initialize API key from provided argument or environment variable
+	effectiveAPIKey := apiKey
+	if effectiveAPIKey != "" {
+		p.logger.Debug("Using provided API key (length: %d)", len(effectiveAPIKey))
+	} else {
+		// Fall back to the ANTHROPIC_API_KEY environment variable
+		effectiveAPIKey = os.Getenv("ANTHROPIC_API_KEY")
+		if effectiveAPIKey == "" {
+			return nil, fmt.Errorf("no Anthropic API key provided and ANTHROPIC_API_KEY environment variable not set")
+		}
+		p.logger.Debug("Using API key from ANTHROPIC_API_KEY environment variable")
+	}
+
+	// Anthropic keys start with 'sk-ant-'; other keys will fail authentication
+	if !strings.HasPrefix(effectiveAPIKey, "sk-ant-") {
+		p.logger.Warn("Anthropic API key does not have the expected 'sk-ant-' prefix. This will likely fail.")
+	}
+
+	// Validate modelID
+	if modelID == "" {
+		return nil, fmt.Errorf("model ID cannot be empty")
+	}
+
+	// Set default API endpoint if none provided
+	effectiveAPIEndpoint := apiEndpoint
+	if effectiveAPIEndpoint == "" {
+		effectiveAPIEndpoint = DefaultAPIEndpoint
+		p.logger.Debug("Using default Anthropic API endpoint")
+	} else {
+		p.logger.Debug("Using custom Anthropic API endpoint: %s", effectiveAPIEndpoint)
+	}
+
+	client, err := NewClient(effectiveAPIKey, modelID, effectiveAPIEndpoint, p.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Anthropic client: %w", err)
+	}
+
+	return client, nil
+}
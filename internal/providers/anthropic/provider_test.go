@@ -0,0 +1,77 @@
+package anthropic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/logutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCreateClient tests client creation through the provider
+func TestCreateClient(t *testing.T) {
+	provider := NewProvider(logutil.NewLogger(logutil.InfoLevel, nil, "[test] "))
+
+	tests := []struct {
+		name        string
+		apiKey      string
+		envKey      string
+		modelID     string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:    "Provided API key",
+			apiKey:  "sk-ant-test-key",
+			modelID: "claude-sonnet-4-20250514",
+		},
+		{
+			name:    "API key from environment",
+			envKey:  "sk-ant-env-key",
+			modelID: "claude-sonnet-4-20250514",
+		},
+		{
+			name:        "No API key",
+			modelID:     "claude-sonnet-4-20250514",
+			wantErr:     true,
+			errContains: "ANTHROPIC_API_KEY environment variable not set",
+		},
+		{
+			name:        "Empty model ID",
+			apiKey:      "sk-ant-test-key",
+			wantErr:     true,
+			errContains: "model ID cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ANTHROPIC_API_KEY", tt.envKey)
+
+			client, err := provider.CreateClient(context.Background(), tt.apiKey, tt.modelID, "")
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.modelID, client.GetModelName())
+			_, streams := client.(llm.StreamingLLMClient)
+			assert.True(t, streams, "Expected the Anthropic client to support streaming")
+		})
+	}
+}
+
+// TestProviderInitialization tests the provider initialization
+func TestProviderInitialization(t *testing.T) {
+	assert.NotNil(t, NewProvider(nil), "Provider should not be nil even with nil logger")
+}
+
+// TestFormatAPIErrorPassesThroughLLMError tests that existing LLM errors are not rewrapped
+func TestFormatAPIErrorPassesThroughLLMError(t *testing.T) {
+	original := CreateAPIError(llm.CategoryNetwork, "network down", nil, "")
+	assert.Same(t, original, FormatAPIError(original, 500, nil))
+	assert.Nil(t, FormatAPIError(nil, 500, nil))
+}
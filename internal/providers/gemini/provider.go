@@ -96,6 +96,29 @@ func (a *GeminiClientAdapter) GenerateContent(ctx context.Context, prompt string
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	a.applyParameters(params)
+
+	// Call the underlying client's implementation with combined parameters
+	return a.client.GenerateContent(ctx, prompt, a.params)
+}
+
+// GenerateContentStream implements the llm.StreamingLLMClient interface and applies parameters.
+// If the wrapped client cannot stream, its complete response is delivered as a single chunk.
+func (a *GeminiClientAdapter) GenerateContentStream(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.applyParameters(params)
+
+	if client, ok := a.client.(llm.StreamingLLMClient); ok {
+		return client.GenerateContentStream(ctx, prompt, a.params)
+	}
+	return llm.StreamFromResult(a.client.GenerateContent(ctx, prompt, a.params)), nil
+}
+
+// applyParameters stores the request parameters and applies them to the underlying client.
+// The caller must hold a.mu.
+func (a *GeminiClientAdapter) applyParameters(params map[string]interface{}) {
 	// Apply parameters if provided
 	if len(params) > 0 {
 		a.params = params
@@ -137,9 +160,6 @@ func (a *GeminiClientAdapter) GenerateContent(ctx context.Context, prompt string
 			client.SetMaxOutputTokens(maxTokens)
 		}
 	}
-
-	// Call the underlying client's implementation with combined parameters
-	return a.client.GenerateContent(ctx, prompt, a.params)
 }
 
 // getFloatParam safely extracts a float parameter
@@ -204,6 +204,79 @@ func TestGeminiClientAdapter(t *testing.T) {
 	assert.True(t, closeCalled, "Close was not called on the underlying client")
 }
 
+// mockStreamingLLMClient extends MockLLMClient with streaming support
+type mockStreamingLLMClient struct {
+	*MockLLMClient
+	streamParams map[string]interface{}
+}
+
+// GenerateContentStream implements the llm.StreamingLLMClient interface for testing
+func (m *mockStreamingLLMClient) GenerateContentStream(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+	m.lastPrompt = prompt
+	m.streamParams = params
+	stream := make(chan llm.StreamChunk, 3)
+	stream <- llm.StreamChunk{Content: "Streamed "}
+	stream <- llm.StreamChunk{Content: "response"}
+	stream <- llm.StreamChunk{FinishReason: "STOP"}
+	close(stream)
+	return stream, nil
+}
+
+// TestGeminiClientAdapterStreaming verifies that GeminiClientAdapter streams through the wrapped client
+func TestGeminiClientAdapterStreaming(t *testing.T) {
+	t.Run("delegates to streaming client", func(t *testing.T) {
+		mockClient := &mockStreamingLLMClient{MockLLMClient: &MockLLMClient{}}
+		adapter := NewGeminiClientAdapter(mockClient)
+
+		var _ llm.StreamingLLMClient = adapter
+
+		params := map[string]interface{}{"temperature": 0.4}
+		stream, err := adapter.GenerateContentStream(context.Background(), "stream prompt", params)
+		require.NoError(t, err)
+
+		result, err := llm.CollectStream(context.Background(), stream, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Streamed response", result.Content)
+		assert.Equal(t, "STOP", result.FinishReason)
+		assert.Equal(t, "stream prompt", mockClient.lastPrompt)
+		assert.Equal(t, params, mockClient.streamParams, "Parameters were not passed to the streaming client")
+		assert.InDelta(t, 0.4, mockClient.temperature, 0.001, "Temperature was not applied before streaming")
+	})
+
+	t.Run("falls back to a single chunk", func(t *testing.T) {
+		mockClient := &MockLLMClient{
+			GenerateContentFunc: func(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+				return &llm.ProviderResult{Content: "Whole response", FinishReason: "STOP"}, nil
+			},
+		}
+		adapter := NewGeminiClientAdapter(mockClient)
+
+		stream, err := adapter.GenerateContentStream(context.Background(), "prompt", nil)
+		require.NoError(t, err)
+
+		result, err := llm.CollectStream(context.Background(), stream, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Whole response", result.Content)
+		assert.Equal(t, "STOP", result.FinishReason)
+	})
+
+	t.Run("fallback propagates errors as a terminal chunk", func(t *testing.T) {
+		mockClient := &MockLLMClient{
+			GenerateContentFunc: func(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+				return nil, errors.New("mock error")
+			},
+		}
+		adapter := NewGeminiClientAdapter(mockClient)
+
+		stream, err := adapter.GenerateContentStream(context.Background(), "prompt", nil)
+		require.NoError(t, err)
+
+		_, err = llm.CollectStream(context.Background(), stream, nil)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "mock error")
+	})
+}
+
 // TestParamTypeConversion tests the parameter type conversion functions
 func TestParamTypeConversion(t *testing.T) {
 	adapter := &GeminiClientAdapter{
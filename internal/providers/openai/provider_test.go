@@ -182,6 +182,59 @@ func TestAdapterParameterHandling(t *testing.T) {
 	assert.Equal(t, newParams, adapter.params, "Parameters were not overridden correctly")
 }
 
+// mockStreamingLLMClient extends MockLLMClient with streaming support
+type mockStreamingLLMClient struct {
+	*MockLLMClient
+}
+
+// GenerateContentStream implements the llm.StreamingLLMClient interface for testing
+func (m *mockStreamingLLMClient) GenerateContentStream(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+	m.lastPrompt = prompt
+	m.lastParams = params
+	stream := make(chan llm.StreamChunk, 2)
+	stream <- llm.StreamChunk{Content: "Streamed response"}
+	stream <- llm.StreamChunk{FinishReason: "stop"}
+	close(stream)
+	return stream, nil
+}
+
+func TestAdapterStreaming(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("delegates to streaming client", func(t *testing.T) {
+		mockClient := &mockStreamingLLMClient{MockLLMClient: &MockLLMClient{modelName: "gpt-4"}}
+		adapter := NewOpenAIClientAdapter(mockClient)
+
+		params := map[string]interface{}{"temperature": 0.3, "max_tokens": 50}
+		stream, err := adapter.GenerateContentStream(ctx, "Stream prompt", params)
+		require.NoError(t, err)
+
+		result, err := llm.CollectStream(context.Background(), stream, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Streamed response", result.Content)
+		assert.Equal(t, "stop", result.FinishReason)
+		assert.Equal(t, "Stream prompt", mockClient.lastPrompt)
+		assert.Equal(t, params, mockClient.lastParams)
+		assert.Equal(t, int32(50), mockClient.maxTokens, "max_tokens was not applied before streaming")
+	})
+
+	t.Run("falls back to a single chunk", func(t *testing.T) {
+		mockClient := &MockLLMClient{
+			modelName:  "gpt-4",
+			mockResult: &llm.ProviderResult{Content: "Whole response", FinishReason: "stop"},
+		}
+		adapter := NewOpenAIClientAdapter(mockClient)
+
+		stream, err := adapter.GenerateContentStream(ctx, "Prompt", nil)
+		require.NoError(t, err)
+
+		result, err := llm.CollectStream(context.Background(), stream, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Whole response", result.Content)
+		assert.Equal(t, "stop", result.FinishReason)
+	})
+}
+
 func TestGetFloatParam(t *testing.T) {
 	adapter := &OpenAIClientAdapter{
 		params: map[string]interface{}{
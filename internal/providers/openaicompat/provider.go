@@ -0,0 +1,89 @@
+// Package openaicompat provides a provider for local and self-hosted servers that
+// implement the OpenAI chat completions API, such as Ollama and the llama.cpp server
+package openaicompat
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/logutil"
+	"github.com/phrazzld/thinktank/internal/openai"
+	"github.com/phrazzld/thinktank/internal/providers"
+	openaiprovider "github.com/phrazzld/thinktank/internal/providers/openai"
+)
+
+const (
+	// OllamaEndpoint is the default OpenAI-compatible endpoint of a local Ollama server
+	OllamaEndpoint = "http://localhost:11434/v1"
+
+	// placeholderAPIKey is sent when no API key is configured. Local servers ignore
+	// the Authorization header, but the OpenAI client requires a non-empty key.
+	placeholderAPIKey = "no-api-key"
+)
+
+// Provider implements the Provider interface for OpenAI-compatible servers.
+// Unlike the hosted providers, it does not require an API key.
+type Provider struct {
+	logger          logutil.LoggerInterface
+	defaultEndpoint string
+}
+
+// NewProvider creates a new OpenAI-compatible provider. If defaultEndpoint is empty,
+// every provider using this implementation must configure a base_url.
+func NewProvider(logger logutil.LoggerInterface, defaultEndpoint string) providers.Provider {
+	// If no logger provided, create a default one
+	if logger == nil {
+		logger = logutil.NewLogger(logutil.InfoLevel, nil, "[openai-compatible-provider] ")
+	}
+
+	return &Provider{
+		logger:          logger,
+		defaultEndpoint: defaultEndpoint,
+	}
+}
+
+// RequiresAPIKey reports that OpenAI-compatible servers can be used without an API key
+func (p *Provider) RequiresAPIKey() bool {
+	return false
+}
+
+// CreateClient implements the Provider interface.
+func (p *Provider) CreateClient(
+	ctx context.Context,
+	apiKey string,
+	modelID string,
+	apiEndpoint string,
+) (llm.LLMClient, error) {
+	p.logger.Debug("Creating OpenAI-compatible client for model: %s", modelID)
+
+	// Validate modelID
+	if modelID == "" {
+		return nil, fmt.Errorf("model ID cannot be empty")
+	}
+
+	// Determine the server endpoint
+	effectiveAPIEndpoint := apiEndpoint
+	if effectiveAPIEndpoint == "" {
+		if p.defaultEndpoint == "" {
+			return nil, fmt.Errorf("no base_url configured for OpenAI-compatible model '%s'", modelID)
+		}
+		effectiveAPIEndpoint = p.defaultEndpoint
+	}
+	p.logger.Debug("Using OpenAI-compatible endpoint: %s", effectiveAPIEndpoint)
+
+	// An API key is optional; some servers are started with one for access control
+	effectiveAPIKey := apiKey
+	if effectiveAPIKey == "" {
+		effectiveAPIKey = placeholderAPIKey
+		p.logger.Debug("No API key configured, sending placeholder key")
+	}
+
+	baseClient, err := openai.NewClient(effectiveAPIKey, modelID, effectiveAPIEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpenAI-compatible client: %w", err)
+	}
+
+	// Reuse the OpenAI adapter so registry parameters are applied the same way
+	return openaiprovider.NewOpenAIClientAdapter(baseClient), nil
+}
@@ -0,0 +1,96 @@
+package openaicompat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newChatServer starts a stand-in for a local OpenAI-compatible server and records
+// the Authorization header and model of each request
+func newChatServer(t *testing.T, authHeader, model *string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		*authHeader = r.Header.Get("Authorization")
+
+		var request map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		*model, _ = request["model"].(string)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{
+			"id": "chatcmpl-1",
+			"object": "chat.completion",
+			"created": 1700000000,
+			"model": "llama3.3",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "Local response"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 10, "completion_tokens": 2, "total_tokens": 12}
+		}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestCreateClientWithoutAPIKey tests that a local server can be used without an API key
+func TestCreateClientWithoutAPIKey(t *testing.T) {
+	var authHeader, model string
+	server := newChatServer(t, &authHeader, &model)
+
+	provider := NewProvider(nil, "")
+	client, err := provider.CreateClient(context.Background(), "", "llama3.3", server.URL+"/v1")
+	require.NoError(t, err)
+
+	result, err := client.GenerateContent(context.Background(), "Hello", map[string]interface{}{"temperature": 0.2})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Local response", result.Content)
+	assert.Equal(t, "stop", result.FinishReason)
+	require.NotNil(t, result.Usage)
+	assert.Equal(t, int32(12), result.Usage.TotalTokens)
+	assert.Equal(t, "llama3.3", model)
+	assert.Equal(t, "Bearer "+placeholderAPIKey, authHeader)
+}
+
+// TestCreateClientWithAPIKey tests that a configured API key is passed to the server
+func TestCreateClientWithAPIKey(t *testing.T) {
+	var authHeader, model string
+	server := newChatServer(t, &authHeader, &model)
+
+	client, err := NewProvider(nil, "").CreateClient(context.Background(), "server-secret", "qwen", server.URL+"/v1")
+	require.NoError(t, err)
+
+	_, err = client.GenerateContent(context.Background(), "Hello", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer server-secret", authHeader)
+}
+
+// TestCreateClientEndpoint tests the default endpoint handling
+func TestCreateClientEndpoint(t *testing.T) {
+	// A provider with a default endpoint does not need a base_url
+	client, err := NewProvider(nil, OllamaEndpoint).CreateClient(context.Background(), "", "llama3.3", "")
+	require.NoError(t, err)
+	assert.Equal(t, "llama3.3", client.GetModelName())
+
+	// A generic provider requires a base_url
+	_, err = NewProvider(nil, "").CreateClient(context.Background(), "", "llama3.3", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no base_url configured")
+
+	// The model ID is always required
+	_, err = NewProvider(nil, OllamaEndpoint).CreateClient(context.Background(), "", "", "")
+	require.Error(t, err)
+}
+
+// TestRequiresAPIKey tests that the provider advertises that no API key is needed
+func TestRequiresAPIKey(t *testing.T) {
+	provider, ok := NewProvider(nil, OllamaEndpoint).(interface{ RequiresAPIKey() bool })
+	require.True(t, ok)
+	assert.False(t, provider.RequiresAPIKey())
+}
@@ -2,6 +2,7 @@
 package openrouter
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -87,6 +88,12 @@ type ChatCompletionRequest struct {
 	PresencePenalty  *float32                `json:"presence_penalty,omitempty"`
 	MaxTokens        *int32                  `json:"max_tokens,omitempty"`
 	Stream           bool                    `json:"stream,omitempty"`
+	Usage            *UsageOptions           `json:"usage,omitempty"`
+}
+
+// UsageOptions controls usage accounting in OpenRouter responses
+type UsageOptions struct {
+	Include bool `json:"include"`
 }
 
 // ChatCompletionChoice represents a choice in the OpenRouter chat completion response
@@ -103,6 +110,20 @@ type ChatCompletionUsage struct {
 	TotalTokens      int `json:"total_tokens"`
 }
 
+// toProviderUsage converts OpenRouter usage information to provider-agnostic token usage.
+// It returns nil when the response carried no usage data.
+func (u ChatCompletionUsage) toProviderUsage() *llm.TokenUsage {
+	if u.TotalTokens == 0 && u.PromptTokens == 0 && u.CompletionTokens == 0 {
+		return nil
+	}
+
+	return &llm.TokenUsage{
+		PromptTokens:     int32(u.PromptTokens),
+		CompletionTokens: int32(u.CompletionTokens),
+		TotalTokens:      int32(u.TotalTokens),
+	}
+}
+
 // ChatCompletionResponse represents the response structure from the OpenRouter chat API
 type ChatCompletionResponse struct {
 	ID      string                 `json:"id"`
@@ -113,8 +134,317 @@ type ChatCompletionResponse struct {
 	Usage   ChatCompletionUsage    `json:"usage"`
 }
 
+// ChatCompletionStreamDelta represents the incremental message content in a streamed chunk
+type ChatCompletionStreamDelta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content"`
+}
+
+// ChatCompletionStreamChoice represents a choice in a streamed chat completion chunk
+type ChatCompletionStreamChoice struct {
+	Index        int                       `json:"index"`
+	Delta        ChatCompletionStreamDelta `json:"delta"`
+	FinishReason *string                   `json:"finish_reason"`
+}
+
+// ChatCompletionStreamResponse represents a single server-sent event payload
+// from the OpenRouter chat API when streaming is enabled
+type ChatCompletionStreamResponse struct {
+	ID      string                       `json:"id"`
+	Object  string                       `json:"object"`
+	Created int64                        `json:"created"`
+	Model   string                       `json:"model"`
+	Choices []ChatCompletionStreamChoice `json:"choices"`
+	Usage   *ChatCompletionUsage         `json:"usage,omitempty"`
+}
+
 // GenerateContent sends a prompt to the LLM and returns the generated content
 func (c *openrouterClient) GenerateContent(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+	resp, err := c.sendRequest(ctx, c.buildRequest(prompt, params, false))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil && c.logger != nil {
+			c.logger.Warn("Failed to close response body: %v", closeErr)
+		}
+	}()
+
+	// Read the response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, CreateAPIError(
+			llm.CategoryNetwork,
+			"Failed to read response from OpenRouter API",
+			err,
+			fmt.Sprintf("Response read error: %v", err),
+		)
+	}
+
+	// Parse the response
+	var completionResponse ChatCompletionResponse
+	if err := json.Unmarshal(body, &completionResponse); err != nil {
+		return nil, CreateAPIError(
+			llm.CategoryServer,
+			"Failed to parse response from OpenRouter API",
+			err,
+			fmt.Sprintf("JSON unmarshal error: %v, Body: %s", err, truncateString(string(body), 200)),
+		)
+	}
+
+	// Validate response structure
+	if len(completionResponse.Choices) == 0 {
+		return nil, CreateAPIError(
+			llm.CategoryServer,
+			"OpenRouter API returned an empty response",
+			fmt.Errorf("no completion choices in response"),
+			fmt.Sprintf("Response contained zero choices: %s", truncateString(string(body), 200)),
+		)
+	}
+
+	// Extract the content and other fields
+	content := completionResponse.Choices[0].Message.Content
+	finishReason := completionResponse.Choices[0].FinishReason
+
+	// Build and return the result
+	return &llm.ProviderResult{
+		Content:      content,
+		FinishReason: finishReason,
+		Truncated:    finishReason == "length",
+		// OpenRouter doesn't provide safety info in the same format as Gemini,
+		// so we leave SafetyInfo empty for now
+		SafetyInfo: []llm.Safety{},
+		Usage:      completionResponse.Usage.toProviderUsage(),
+	}, nil
+}
+
+// GenerateContentStream sends a prompt to the LLM with streaming enabled and
+// returns a channel that receives content deltas as server-sent events arrive
+func (c *openrouterClient) GenerateContentStream(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+	resp, err := c.sendRequest(ctx, c.buildRequest(prompt, params, true))
+	if err != nil {
+		return nil, err
+	}
+
+	stream := make(chan llm.StreamChunk)
+	go func() {
+		defer close(stream)
+		defer func() {
+			if closeErr := resp.Body.Close(); closeErr != nil && c.logger != nil {
+				c.logger.Warn("Failed to close response body: %v", closeErr)
+			}
+		}()
+		c.readStream(ctx, resp.Body, stream)
+	}()
+
+	return stream, nil
+}
+
+// readStream parses server-sent events from the response body and forwards
+// content deltas to the stream. The final chunk carries the finish reason.
+func (c *openrouterClient) readStream(ctx context.Context, body io.Reader, stream chan<- llm.StreamChunk) {
+	var finishReason string
+	var usage *llm.TokenUsage
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip blank separators and SSE comments (OpenRouter sends ": OPENROUTER PROCESSING" keep-alives)
+		if line == "" || strings.HasPrefix(line, ":") || !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var event ChatCompletionStreamResponse
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			llm.SendChunk(ctx, stream, llm.StreamChunk{Err: CreateAPIError(
+				llm.CategoryServer,
+				"Failed to parse streaming response from OpenRouter API",
+				err,
+				fmt.Sprintf("JSON unmarshal error: %v, Event: %s", err, truncateString(data, 200)),
+			)})
+			return
+		}
+
+		// Usage is reported on the final event when usage accounting is requested
+		if event.Usage != nil {
+			usage = event.Usage.toProviderUsage()
+		}
+
+		for _, choice := range event.Choices {
+			if choice.FinishReason != nil && *choice.FinishReason != "" {
+				finishReason = *choice.FinishReason
+			}
+			if choice.Delta.Content == "" {
+				continue
+			}
+			if !llm.SendChunk(ctx, stream, llm.StreamChunk{Content: choice.Delta.Content}) {
+				return
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		category := llm.CategoryNetwork
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			category = llm.CategoryCancelled
+		}
+		llm.SendChunk(ctx, stream, llm.StreamChunk{Err: CreateAPIError(
+			category,
+			"Streaming response from OpenRouter API was interrupted",
+			err,
+			fmt.Sprintf("Stream read error: %v", err),
+		)})
+		return
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	llm.SendChunk(ctx, stream, llm.StreamChunk{
+		FinishReason: finishReason,
+		Truncated:    finishReason == "length",
+		Usage:        usage,
+	})
+}
+
+// sendRequest marshals and sends a chat completion request, returning the HTTP
+// response on success. Non-200 responses are converted into categorized errors.
+// The caller is responsible for closing the response body.
+func (c *openrouterClient) sendRequest(ctx context.Context, requestBody ChatCompletionRequest) (*http.Response, error) {
+	// Convert request to JSON
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		// Create a categorized error for request marshaling failures
+		return nil, CreateAPIError(
+			llm.CategoryInvalidRequest,
+			"Failed to prepare request to OpenRouter API",
+			err,
+			fmt.Sprintf("JSON marshal error: %v", err),
+		)
+	}
+
+	// Construct the API URL
+	apiURL := fmt.Sprintf("%s/chat/completions", c.apiEndpoint)
+
+	// Create the HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		// Create a categorized error for HTTP request creation failures
+		return nil, CreateAPIError(
+			llm.CategoryNetwork,
+			"Failed to create HTTP request to OpenRouter API",
+			err,
+			fmt.Sprintf("Request creation error: %v", err),
+		)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	if requestBody.Stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	// Execute the request
+	if c.logger != nil {
+		c.logger.Debug("Sending request to OpenRouter API: %s", sanitizeURLBasic(apiURL))
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// Check for context cancellation
+		category := llm.CategoryNetwork
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			category = llm.CategoryCancelled
+		}
+
+		// Create a categorized error for HTTP execution failures
+		return nil, CreateAPIError(
+			category,
+			"Failed to connect to OpenRouter API",
+			err,
+			fmt.Sprintf("HTTP error: %v", err),
+		)
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+
+	// Handle non-200 status codes
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil && c.logger != nil {
+			c.logger.Warn("Failed to close response body: %v", closeErr)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, CreateAPIError(
+			llm.CategoryNetwork,
+			"Failed to read response from OpenRouter API",
+			err,
+			fmt.Sprintf("Response read error: %v", err),
+		)
+	}
+
+	// Create a categorized API error using the FormatAPIError function
+	apiErr := FormatAPIError(
+		fmt.Errorf("OpenRouter API returned non-200 status code: %d", resp.StatusCode),
+		resp.StatusCode,
+		body,
+	)
+	apiErr.RetryAfter = llm.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+	// Try to parse the response for any additional information
+	var usageInfo *ChatCompletionUsage
+
+	// Attempt to extract usage information and possibly finish reason if available
+	var errorResponse map[string]interface{}
+	if err := json.Unmarshal(body, &errorResponse); err == nil {
+		// Check if there's usage information
+		if usage, ok := errorResponse["usage"].(map[string]interface{}); ok {
+			usageInfo = &ChatCompletionUsage{}
+			if promptTokens, ok := usage["prompt_tokens"].(float64); ok {
+				usageInfo.PromptTokens = int(promptTokens)
+			}
+			if completionTokens, ok := usage["completion_tokens"].(float64); ok {
+				usageInfo.CompletionTokens = int(completionTokens)
+			}
+			if totalTokens, ok := usage["total_tokens"].(float64); ok {
+				usageInfo.TotalTokens = int(totalTokens)
+			}
+		}
+
+		// Check if there's a finish reason and add to error details if found
+		if choices, ok := errorResponse["choices"].([]interface{}); ok && len(choices) > 0 {
+			if choice, ok := choices[0].(map[string]interface{}); ok {
+				if reason, ok := choice["finish_reason"].(string); ok && apiErr != nil {
+					apiErr.Details += fmt.Sprintf(" (Finish reason: %s)", reason)
+				}
+			}
+		}
+	}
+
+	// If we have token usage information, include it in debug details
+	if usageInfo != nil && apiErr != nil {
+		apiErr.Details += fmt.Sprintf(" (Token usage: %d prompt, %d completion, %d total)",
+			usageInfo.PromptTokens, usageInfo.CompletionTokens, usageInfo.TotalTokens)
+	}
+
+	return nil, apiErr
+}
+
+// buildRequest assembles a chat completion request from the client defaults
+// and the per-request parameters
+func (c *openrouterClient) buildRequest(prompt string, params map[string]interface{}, stream bool) ChatCompletionRequest {
 	// Create local variables for request parameters instead of modifying receiver fields
 	var temperature, topP, presencePenalty, frequencyPenalty *float32
 	var maxTokens *int32
@@ -232,16 +562,27 @@ func (c *openrouterClient) GenerateContent(ctx context.Context, prompt string, p
 		}
 	}
 
-	// Create chat completion request
-	messages := []ChatCompletionMessage{
-		{
-			Role:    "user",
-			Content: prompt,
-		},
+	// Create chat completion request, with the system prompt as a system message
+	var messages []ChatCompletionMessage
+	if systemPrompt := llm.SystemPrompt(params); systemPrompt != "" {
+		messages = append(messages, ChatCompletionMessage{
+			Role:    "system",
+			Content: systemPrompt,
+		})
+	}
+	messages = append(messages, ChatCompletionMessage{
+		Role:    "user",
+		Content: prompt,
+	})
+
+	// Streamed responses only include token usage when explicitly requested
+	var usageOptions *UsageOptions
+	if stream {
+		usageOptions = &UsageOptions{Include: true}
 	}
 
 	// Build the request body using local variables instead of receiver fields
-	requestBody := ChatCompletionRequest{
+	return ChatCompletionRequest{
 		Model:            c.modelID,
 		Messages:         messages,
 		Temperature:      temperature,
@@ -249,159 +590,9 @@ func (c *openrouterClient) GenerateContent(ctx context.Context, prompt string, p
 		FrequencyPenalty: frequencyPenalty,
 		PresencePenalty:  presencePenalty,
 		MaxTokens:        maxTokens,
-		Stream:           false, // Non-streaming implementation for initial version
-	}
-
-	// Convert request to JSON
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		// Create a categorized error for request marshaling failures
-		return nil, CreateAPIError(
-			llm.CategoryInvalidRequest,
-			"Failed to prepare request to OpenRouter API",
-			err,
-			fmt.Sprintf("JSON marshal error: %v", err),
-		)
-	}
-
-	// Construct the API URL
-	apiURL := fmt.Sprintf("%s/chat/completions", c.apiEndpoint)
-
-	// Create the HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		// Create a categorized error for HTTP request creation failures
-		return nil, CreateAPIError(
-			llm.CategoryNetwork,
-			"Failed to create HTTP request to OpenRouter API",
-			err,
-			fmt.Sprintf("Request creation error: %v", err),
-		)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
-
-	// Execute the request
-	if c.logger != nil {
-		c.logger.Debug("Sending request to OpenRouter API: %s", sanitizeURLBasic(apiURL))
-	}
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		// Check for context cancellation
-		category := llm.CategoryNetwork
-		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-			category = llm.CategoryCancelled
-		}
-
-		// Create a categorized error for HTTP execution failures
-		return nil, CreateAPIError(
-			category,
-			"Failed to connect to OpenRouter API",
-			err,
-			fmt.Sprintf("HTTP error: %v", err),
-		)
-	}
-	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil && c.logger != nil {
-			c.logger.Warn("Failed to close response body: %v", closeErr)
-		}
-	}()
-
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, CreateAPIError(
-			llm.CategoryNetwork,
-			"Failed to read response from OpenRouter API",
-			err,
-			fmt.Sprintf("Response read error: %v", err),
-		)
-	}
-
-	// Handle non-200 status codes
-	if resp.StatusCode != http.StatusOK {
-		// Create a categorized API error using the FormatAPIError function
-		apiErr := FormatAPIError(
-			fmt.Errorf("OpenRouter API returned non-200 status code: %d", resp.StatusCode),
-			resp.StatusCode,
-			body,
-		)
-
-		// Try to parse the response for any additional information
-		var usageInfo *ChatCompletionUsage
-
-		// Attempt to extract usage information and possibly finish reason if available
-		var errorResponse map[string]interface{}
-		if err := json.Unmarshal(body, &errorResponse); err == nil {
-			// Check if there's usage information
-			if usage, ok := errorResponse["usage"].(map[string]interface{}); ok {
-				usageInfo = &ChatCompletionUsage{}
-				if promptTokens, ok := usage["prompt_tokens"].(float64); ok {
-					usageInfo.PromptTokens = int(promptTokens)
-				}
-				if completionTokens, ok := usage["completion_tokens"].(float64); ok {
-					usageInfo.CompletionTokens = int(completionTokens)
-				}
-				if totalTokens, ok := usage["total_tokens"].(float64); ok {
-					usageInfo.TotalTokens = int(totalTokens)
-				}
-			}
-
-			// Check if there's a finish reason and add to error details if found
-			if choices, ok := errorResponse["choices"].([]interface{}); ok && len(choices) > 0 {
-				if choice, ok := choices[0].(map[string]interface{}); ok {
-					if reason, ok := choice["finish_reason"].(string); ok && apiErr != nil {
-						apiErr.Details += fmt.Sprintf(" (Finish reason: %s)", reason)
-					}
-				}
-			}
-		}
-
-		// If we have token usage information, include it in debug details
-		if usageInfo != nil && apiErr != nil {
-			apiErr.Details += fmt.Sprintf(" (Token usage: %d prompt, %d completion, %d total)",
-				usageInfo.PromptTokens, usageInfo.CompletionTokens, usageInfo.TotalTokens)
-		}
-
-		return nil, apiErr
-	}
-
-	// Parse the response
-	var completionResponse ChatCompletionResponse
-	if err := json.Unmarshal(body, &completionResponse); err != nil {
-		return nil, CreateAPIError(
-			llm.CategoryServer,
-			"Failed to parse response from OpenRouter API",
-			err,
-			fmt.Sprintf("JSON unmarshal error: %v, Body: %s", err, truncateString(string(body), 200)),
-		)
+		Stream:           stream,
+		Usage:            usageOptions,
 	}
-
-	// Validate response structure
-	if len(completionResponse.Choices) == 0 {
-		return nil, CreateAPIError(
-			llm.CategoryServer,
-			"OpenRouter API returned an empty response",
-			fmt.Errorf("no completion choices in response"),
-			fmt.Sprintf("Response contained zero choices: %s", truncateString(string(body), 200)),
-		)
-	}
-
-	// Extract the content and other fields
-	content := completionResponse.Choices[0].Message.Content
-	finishReason := completionResponse.Choices[0].FinishReason
-
-	// Build and return the result
-	return &llm.ProviderResult{
-		Content:      content,
-		FinishReason: finishReason,
-		Truncated:    finishReason == "length",
-		// OpenRouter doesn't provide safety info in the same format as Gemini,
-		// so we leave SafetyInfo empty for now
-		SafetyInfo: []llm.Safety{},
-	}, nil
 }
 
 // GetModelName returns the name of the model being used
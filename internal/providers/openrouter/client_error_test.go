@@ -177,6 +177,29 @@ func TestClientHTTPErrors(t *testing.T) {
 	}
 }
 
+// TestClientRetryAfter tests that the Retry-After header of an error response is preserved
+func TestClientRetryAfter(t *testing.T) {
+	client, err := NewClient("test-api-key", "anthropic/claude-3-opus", "", logutil.NewLogger(logutil.DebugLevel, nil, "[test] "))
+	require.NoError(t, err)
+
+	client.httpClient = &http.Client{
+		Transport: &ErrorMockRoundTripper{
+			requestHandler: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusTooManyRequests,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"error":{"message":"Rate limit exceeded","type":"rate_limit"}}`)),
+					Header:     http.Header{"Retry-After": []string{"12"}},
+				}, nil
+			},
+		},
+	}
+
+	_, err = client.GenerateContent(context.Background(), "test prompt", nil)
+	require.Error(t, err)
+	assert.True(t, llm.IsRateLimit(err))
+	assert.Equal(t, 12*time.Second, llm.GetRetryAfter(err))
+}
+
 // TestContextCancellation tests handling of context cancellation
 func TestContextCancellation(t *testing.T) {
 	tests := []struct {
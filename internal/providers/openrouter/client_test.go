@@ -11,6 +11,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/phrazzld/thinktank/internal/llm"
 	"github.com/phrazzld/thinktank/internal/logutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -355,6 +356,9 @@ func TestConcurrentGenerateContent(t *testing.T) {
 				assert.NoError(t, err)
 				assert.NotNil(t, result)
 				assert.Contains(t, result.Content, testCase.expectResp)
+				if assert.NotNil(t, result.Usage) {
+					assert.Equal(t, int32(30), result.Usage.TotalTokens)
+				}
 			}()
 		}
 	}
@@ -362,3 +366,121 @@ func TestConcurrentGenerateContent(t *testing.T) {
 	// Wait for all goroutines to complete
 	wg.Wait()
 }
+
+// sseRoundTripper returns a fixed server-sent event body and records the request payload
+type sseRoundTripper struct {
+	statusCode  int
+	body        string
+	requestBody map[string]interface{}
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (s *sseRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		bodyBytes, _ := io.ReadAll(req.Body)
+		_ = json.Unmarshal(bodyBytes, &s.requestBody)
+	}
+	return &http.Response{
+		StatusCode: s.statusCode,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       io.NopCloser(bytes.NewBufferString(s.body)),
+	}, nil
+}
+
+// TestGenerateContentStream tests streaming responses from the OpenRouter API
+func TestGenerateContentStream(t *testing.T) {
+	logger := logutil.NewLogger(logutil.DebugLevel, nil, "[test] ")
+
+	newStreamingClient := func(t *testing.T, rt *sseRoundTripper) *openrouterClient {
+		client, err := NewClient("sk-or-test-api-key", "anthropic/claude-3-opus", "", logger)
+		require.NoError(t, err)
+		client.httpClient = &http.Client{Transport: rt}
+		return client
+	}
+
+	t.Run("Parses deltas and finish reason", func(t *testing.T) {
+		rt := &sseRoundTripper{
+			statusCode: http.StatusOK,
+			body: ": OPENROUTER PROCESSING\n\n" +
+				`data: {"id":"1","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"},"finish_reason":null}]}` + "\n\n" +
+				`data: {"id":"1","choices":[{"index":0,"delta":{"content":" there"},"finish_reason":null}]}` + "\n\n" +
+				`data: {"id":"1","choices":[{"index":0,"delta":{"content":""},"finish_reason":"length"}]}` + "\n\n" +
+				`data: {"id":"1","choices":[],"usage":{"prompt_tokens":42,"completion_tokens":2,"total_tokens":44}}` + "\n\n" +
+				"data: [DONE]\n\n",
+		}
+		client := newStreamingClient(t, rt)
+
+		stream, err := client.GenerateContentStream(context.Background(), "Test prompt", map[string]interface{}{
+			"temperature": 0.2,
+		})
+		require.NoError(t, err)
+
+		var deltas []string
+		result, err := llm.CollectStream(context.Background(), stream, func(delta string) {
+			deltas = append(deltas, delta)
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"Hello", " there"}, deltas)
+		assert.Equal(t, "Hello there", result.Content)
+		assert.Equal(t, "length", result.FinishReason)
+		assert.True(t, result.Truncated)
+		require.NotNil(t, result.Usage)
+		assert.Equal(t, int32(42), result.Usage.PromptTokens)
+		assert.Equal(t, int32(2), result.Usage.CompletionTokens)
+		assert.Equal(t, int32(44), result.Usage.TotalTokens)
+		assert.Equal(t, true, rt.requestBody["stream"], "Request should enable streaming")
+		assert.Equal(t, map[string]interface{}{"include": true}, rt.requestBody["usage"], "Request should ask for usage accounting")
+	})
+
+	t.Run("Non-200 status is returned as an error", func(t *testing.T) {
+		rt := &sseRoundTripper{
+			statusCode: http.StatusUnauthorized,
+			body:       `{"error":{"message":"Invalid API key","code":401}}`,
+		}
+		client := newStreamingClient(t, rt)
+
+		stream, err := client.GenerateContentStream(context.Background(), "Test prompt", nil)
+		assert.Error(t, err)
+		assert.Nil(t, stream)
+
+		var llmErr *llm.LLMError
+		require.ErrorAs(t, err, &llmErr)
+		assert.Equal(t, llm.CategoryAuth, llmErr.Category())
+	})
+
+	t.Run("Malformed event is a terminal error", func(t *testing.T) {
+		rt := &sseRoundTripper{
+			statusCode: http.StatusOK,
+			body: `data: {"id":"1","choices":[{"index":0,"delta":{"content":"partial"}}]}` + "\n\n" +
+				"data: {\"invalid json\n\n",
+		}
+		client := newStreamingClient(t, rt)
+
+		stream, err := client.GenerateContentStream(context.Background(), "Test prompt", nil)
+		require.NoError(t, err)
+
+		result, err := llm.CollectStream(context.Background(), stream, nil)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "Failed to parse streaming response")
+		assert.Equal(t, "partial", result.Content)
+	})
+}
+
+// TestBuildRequestSystemPrompt tests that the system prompt is sent as a system message
+// before the prompt
+func TestBuildRequestSystemPrompt(t *testing.T) {
+	client, err := NewClient("sk-or-test-api-key", "anthropic/claude-3-opus", "", logutil.NewLogger(logutil.DebugLevel, nil, "[test] "))
+	require.NoError(t, err)
+
+	request := client.buildRequest("Test prompt", map[string]interface{}{
+		llm.SystemPromptParam: "You are a careful reviewer.",
+	}, false)
+	assert.Equal(t, []ChatCompletionMessage{
+		{Role: "system", Content: "You are a careful reviewer."},
+		{Role: "user", Content: "Test prompt"},
+	}, request.Messages)
+
+	request = client.buildRequest("Test prompt", nil, false)
+	assert.Equal(t, []ChatCompletionMessage{{Role: "user", Content: "Test prompt"}}, request.Messages)
+}
@@ -21,3 +21,12 @@ type Provider interface {
 	//   - An error if client creation fails
 	CreateClient(ctx context.Context, apiKey string, modelID string, apiEndpoint string) (llm.LLMClient, error)
 }
+
+// RequiresAPIKey reports whether a provider needs an API key to create clients.
+// Providers opt out by implementing RequiresAPIKey() bool; all others require a key.
+func RequiresAPIKey(provider Provider) bool {
+	if p, ok := provider.(interface{ RequiresAPIKey() bool }); ok {
+		return p.RequiresAPIKey()
+	}
+	return true
+}
@@ -56,7 +56,7 @@ func (c *ConfigLoader) Load() (*ModelsConfig, error) {
 	}
 
 	// Log the configuration file path being used
-	fmt.Printf("Loading model configuration from: %s\n", configPath)
+	fmt.Fprintf(os.Stderr, "Loading model configuration from: %s\n", configPath)
 
 	// Read the configuration file
 	data, err := os.ReadFile(configPath)
@@ -71,7 +71,7 @@ func (c *ConfigLoader) Load() (*ModelsConfig, error) {
 	}
 
 	// Log configuration file size
-	fmt.Printf("Read configuration file (%d bytes)\n", len(data))
+	fmt.Fprintf(os.Stderr, "Read configuration file (%d bytes)\n", len(data))
 
 	// Parse the YAML
 	var config ModelsConfig
@@ -81,7 +81,7 @@ func (c *ConfigLoader) Load() (*ModelsConfig, error) {
 	}
 
 	// Log successful parsing
-	fmt.Printf("Successfully parsed YAML configuration\n")
+	fmt.Fprintf(os.Stderr, "Successfully parsed YAML configuration\n")
 
 	// Validate the configuration
 	if err := c.validate(&config); err != nil {
@@ -89,7 +89,7 @@ func (c *ConfigLoader) Load() (*ModelsConfig, error) {
 	}
 
 	// Log validation success
-	fmt.Printf("Configuration validated successfully: %d providers, %d models defined\n",
+	fmt.Fprintf(os.Stderr, "Configuration validated successfully: %d providers, %d models defined\n",
 		len(config.Providers), len(config.Models))
 
 	return &config, nil
@@ -101,16 +101,16 @@ func (c *ConfigLoader) validate(config *ModelsConfig) error {
 	if len(config.APIKeySources) == 0 {
 		return fmt.Errorf("configuration must include api_key_sources")
 	}
-	fmt.Printf("Validated API key sources: %d sources defined\n", len(config.APIKeySources))
+	fmt.Fprintf(os.Stderr, "Validated API key sources: %d sources defined\n", len(config.APIKeySources))
 
 	// Display found API key sources for debugging
 	for provider, envVar := range config.APIKeySources {
 		// Check if the environment variable exists (without revealing its value)
 		_, exists := os.LookupEnv(envVar)
 		if exists {
-			fmt.Printf("✓ API key for provider '%s' found in environment variable %s\n", provider, envVar)
+			fmt.Fprintf(os.Stderr, "✓ API key for provider '%s' found in environment variable %s\n", provider, envVar)
 		} else {
-			fmt.Printf("⚠ API key for provider '%s' not found in environment variable %s\n", provider, envVar)
+			fmt.Fprintf(os.Stderr, "⚠ API key for provider '%s' not found in environment variable %s\n", provider, envVar)
 		}
 	}
 
@@ -118,7 +118,7 @@ func (c *ConfigLoader) validate(config *ModelsConfig) error {
 	if len(config.Providers) == 0 {
 		return fmt.Errorf("configuration must include at least one provider")
 	}
-	fmt.Printf("Validating %d providers...\n", len(config.Providers))
+	fmt.Fprintf(os.Stderr, "Validating %d providers...\n", len(config.Providers))
 
 	// Check for provider name uniqueness
 	providerNames := make(map[string]bool)
@@ -131,11 +131,16 @@ func (c *ConfigLoader) validate(config *ModelsConfig) error {
 		}
 		providerNames[provider.Name] = true
 
+		// Generic OpenAI-compatible providers have no default endpoint
+		if provider.ImplementationName() == "openai-compatible" && provider.BaseURL == "" {
+			return fmt.Errorf("provider '%s' of type 'openai-compatible' must set base_url", provider.Name)
+		}
+
 		// Log provider details
 		if provider.BaseURL != "" {
-			fmt.Printf("Provider '%s' configured with custom base URL: %s\n", provider.Name, provider.BaseURL)
+			fmt.Fprintf(os.Stderr, "Provider '%s' configured with custom base URL: %s\n", provider.Name, provider.BaseURL)
 		} else {
-			fmt.Printf("Provider '%s' configured with default base URL\n", provider.Name)
+			fmt.Fprintf(os.Stderr, "Provider '%s' configured with default base URL\n", provider.Name)
 		}
 	}
 
@@ -143,7 +148,7 @@ func (c *ConfigLoader) validate(config *ModelsConfig) error {
 	if len(config.Models) == 0 {
 		return fmt.Errorf("configuration must include at least one model")
 	}
-	fmt.Printf("Validating %d models...\n", len(config.Models))
+	fmt.Fprintf(os.Stderr, "Validating %d models...\n", len(config.Models))
 
 	// Check for model name uniqueness
 	modelNames := make(map[string]bool)
@@ -171,28 +176,39 @@ func (c *ConfigLoader) validate(config *ModelsConfig) error {
 			return fmt.Errorf("model '%s' is missing api_model_id", model.Name)
 		}
 
-		// Token validation removed as part of T036C
-		// The token validation logic has been removed since the token-related fields
-		// have been removed from the ModelDefinition struct.
-		// Token handling is now the responsibility of each provider.
+		// Validate pricing if provided
+		if model.Pricing != nil && (model.Pricing.InputPerMillion < 0 || model.Pricing.OutputPerMillion < 0) {
+			return fmt.Errorf("model '%s' has negative pricing", model.Name)
+		}
+
+		// Validate token limits if provided
+		if model.ContextWindow < 0 || model.MaxOutputTokens < 0 {
+			return fmt.Errorf("model '%s' has negative token limits", model.Name)
+		}
+		if model.ContextWindow > 0 && model.MaxOutputTokens > model.ContextWindow {
+			return fmt.Errorf("model '%s' has max_output_tokens (%d) greater than context_window (%d)",
+				model.Name, model.MaxOutputTokens, model.ContextWindow)
+		}
 
-		// Token warnings removed as part of T036C
-		// These warnings have been removed since token-related fields were removed.
+		// Token warnings
+		if model.ContextWindow == 0 {
+			fmt.Fprintf(os.Stderr, "⚠ Warning: Model '%s' has no context_window defined; prompts will not be checked against its limits\n", model.Name)
+		}
 
 		// Parameter validation
 		if len(model.Parameters) == 0 {
-			fmt.Printf("⚠ Warning: Model '%s' has no parameters defined\n", model.Name)
+			fmt.Fprintf(os.Stderr, "⚠ Warning: Model '%s' has no parameters defined\n", model.Name)
 		} else {
 			// Log parameters with invalid/suspicious values
 			for paramName, paramDef := range model.Parameters {
 				// Validate parameter type
 				if paramDef.Type == "" {
-					fmt.Printf("⚠ Warning: Parameter '%s' for model '%s' is missing type\n", paramName, model.Name)
+					fmt.Fprintf(os.Stderr, "⚠ Warning: Parameter '%s' for model '%s' is missing type\n", paramName, model.Name)
 				}
 
 				// Check for default value presence
 				if paramDef.Default == nil {
-					fmt.Printf("⚠ Warning: Parameter '%s' for model '%s' has no default value\n", paramName, model.Name)
+					fmt.Fprintf(os.Stderr, "⚠ Warning: Parameter '%s' for model '%s' has no default value\n", paramName, model.Name)
 				}
 
 				// Check numeric constraints for consistency
@@ -202,7 +218,7 @@ func (c *ConfigLoader) validate(config *ModelsConfig) error {
 					minFloat, minOk := paramDef.Min.(float64)
 					maxFloat, maxOk := paramDef.Max.(float64)
 					if minOk && maxOk && minFloat > maxFloat {
-						fmt.Printf("⚠ Warning: Parameter '%s' for model '%s' has min (%v) > max (%v)\n",
+						fmt.Fprintf(os.Stderr, "⚠ Warning: Parameter '%s' for model '%s' has min (%v) > max (%v)\n",
 							paramName, model.Name, paramDef.Min, paramDef.Max)
 					}
 				}
@@ -210,9 +226,21 @@ func (c *ConfigLoader) validate(config *ModelsConfig) error {
 		}
 
 		// Log successful model validation
-		fmt.Printf("✓ Validated model '%s' (provider: '%s')\n",
+		fmt.Fprintf(os.Stderr, "✓ Validated model '%s' (provider: '%s')\n",
 			model.Name, model.Provider)
 	}
 
+	// Check fallbacks once all model names are known, since they may refer to later models
+	for _, model := range config.Models {
+		for _, fallback := range model.Fallbacks {
+			if fallback == model.Name {
+				return fmt.Errorf("model '%s' lists itself as a fallback", model.Name)
+			}
+			if !modelNames[fallback] {
+				return fmt.Errorf("model '%s' has unknown fallback model '%s'", model.Name, fallback)
+			}
+		}
+	}
+
 	return nil
 }
@@ -161,8 +161,8 @@ func TestValidateConfig(t *testing.T) {
 		t.Error("Expected error with invalid model (unknown provider), got nil")
 	}
 
-	// Test with valid config
-	validConfig := &ModelsConfig{
+	// Test with invalid model (negative pricing)
+	configNegativePricing := &ModelsConfig{
 		APIKeySources: map[string]string{"test": "TEST_API_KEY"},
 		Providers:     []ProviderDefinition{{Name: "test"}},
 		Models: []ModelDefinition{
@@ -170,6 +170,107 @@ func TestValidateConfig(t *testing.T) {
 				Name:       "test-model",
 				Provider:   "test",
 				APIModelID: "test-model-id",
+				Pricing:    &PricingDefinition{InputPerMillion: -1, OutputPerMillion: 2},
+			},
+		},
+	}
+	err = loader.validate(configNegativePricing)
+	if err == nil {
+		t.Error("Expected error with invalid model (negative pricing), got nil")
+	}
+
+	// Test with invalid provider (openai-compatible without base_url)
+	configCompatibleWithoutURL := &ModelsConfig{
+		APIKeySources: map[string]string{"test": "TEST_API_KEY"},
+		Providers:     []ProviderDefinition{{Name: "local", Type: "openai-compatible"}},
+		Models: []ModelDefinition{
+			{Name: "test-model", Provider: "local", APIModelID: "test-model-id"},
+		},
+	}
+	err = loader.validate(configCompatibleWithoutURL)
+	if err == nil {
+		t.Error("Expected error with openai-compatible provider missing base_url, got nil")
+	}
+
+	// Test with invalid model (max_output_tokens exceeds context_window)
+	configOutputExceedsWindow := &ModelsConfig{
+		APIKeySources: map[string]string{"test": "TEST_API_KEY"},
+		Providers:     []ProviderDefinition{{Name: "test"}},
+		Models: []ModelDefinition{
+			{
+				Name:            "test-model",
+				Provider:        "test",
+				APIModelID:      "test-model-id",
+				ContextWindow:   4096,
+				MaxOutputTokens: 8192,
+			},
+		},
+	}
+	err = loader.validate(configOutputExceedsWindow)
+	if err == nil {
+		t.Error("Expected error with invalid model (max_output_tokens > context_window), got nil")
+	} else if !strings.Contains(err.Error(), "greater than context_window") {
+		t.Errorf("Unexpected error for max_output_tokens > context_window: %v", err)
+	}
+
+	// Test with invalid model (negative token limits)
+	configNegativeTokens := &ModelsConfig{
+		APIKeySources: map[string]string{"test": "TEST_API_KEY"},
+		Providers:     []ProviderDefinition{{Name: "test"}},
+		Models: []ModelDefinition{
+			{
+				Name:          "test-model",
+				Provider:      "test",
+				APIModelID:    "test-model-id",
+				ContextWindow: -1,
+			},
+		},
+	}
+	err = loader.validate(configNegativeTokens)
+	if err == nil {
+		t.Error("Expected error with invalid model (negative token limits), got nil")
+	}
+
+	// Test with invalid fallbacks (unknown model, or the model itself)
+	for _, fallback := range []string{"missing-model", "test-model"} {
+		configInvalidFallback := &ModelsConfig{
+			APIKeySources: map[string]string{"test": "TEST_API_KEY"},
+			Providers:     []ProviderDefinition{{Name: "test"}},
+			Models: []ModelDefinition{
+				{Name: "test-model", Provider: "test", APIModelID: "test-model-id", Fallbacks: []string{fallback}},
+			},
+		}
+		err = loader.validate(configInvalidFallback)
+		if err == nil || !strings.Contains(err.Error(), "fallback") {
+			t.Errorf("Expected fallback error for fallback '%s', got: %v", fallback, err)
+		}
+	}
+
+	// Test with a fallback declared before the model it refers to
+	configForwardFallback := &ModelsConfig{
+		APIKeySources: map[string]string{"test": "TEST_API_KEY"},
+		Providers:     []ProviderDefinition{{Name: "test"}},
+		Models: []ModelDefinition{
+			{Name: "preview-model", Provider: "test", APIModelID: "preview-model-id", Fallbacks: []string{"stable-model"}},
+			{Name: "stable-model", Provider: "test", APIModelID: "stable-model-id"},
+		},
+	}
+	err = loader.validate(configForwardFallback)
+	if err != nil {
+		t.Errorf("Expected no error with a fallback declared later in the config, got: %v", err)
+	}
+
+	// Test with valid config
+	validConfig := &ModelsConfig{
+		APIKeySources: map[string]string{"test": "TEST_API_KEY"},
+		Providers:     []ProviderDefinition{{Name: "test"}},
+		Models: []ModelDefinition{
+			{
+				Name:            "test-model",
+				Provider:        "test",
+				APIModelID:      "test-model-id",
+				ContextWindow:   8192,
+				MaxOutputTokens: 8192,
 			},
 		},
 	}
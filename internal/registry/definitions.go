@@ -12,6 +12,18 @@ type ProviderDefinition struct {
 	// BaseURL is the optional API endpoint base URL
 	// If not provided, the default URL for the provider will be used
 	BaseURL string `yaml:"base_url,omitempty" json:"base_url,omitempty"`
+
+	// Type is the optional provider implementation to use (e.g., "openai-compatible")
+	// If not provided, the implementation with the same name as the provider is used
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+}
+
+// ImplementationName returns the name of the provider implementation used by this provider
+func (p ProviderDefinition) ImplementationName() string {
+	if p.Type != "" {
+		return p.Type
+	}
+	return p.Name
 }
 
 // ModelDefinition represents a model entry from the configuration.
@@ -27,9 +39,47 @@ type ModelDefinition struct {
 	// APIModelID is the actual ID used in API calls (e.g., "gpt-4-turbo")
 	APIModelID string `yaml:"api_model_id" json:"api_model_id"`
 
+	// ContextWindow is the maximum number of tokens (input + output) the model accepts
+	ContextWindow int32 `yaml:"context_window,omitempty" json:"context_window,omitempty"`
+
+	// MaxOutputTokens is the maximum number of tokens the model can generate
+	MaxOutputTokens int32 `yaml:"max_output_tokens,omitempty" json:"max_output_tokens,omitempty"`
+
 	// Parameters is a map defining supported parameters for the model
 	// (e.g., temperature, top_p, reasoning_effort)
 	Parameters map[string]ParameterDefinition `yaml:"parameters" json:"parameters"`
+
+	// Pricing is the optional cost of using the model, used for cost reporting
+	Pricing *PricingDefinition `yaml:"pricing,omitempty" json:"pricing,omitempty"`
+
+	// Fallbacks is an optional ordered list of models to try instead when this
+	// model is unavailable (e.g., a retired preview model or exhausted quota)
+	Fallbacks []string `yaml:"fallbacks,omitempty" json:"fallbacks,omitempty"`
+
+	// SystemPrompt is an optional system prompt sent with every request to this model,
+	// unless --system-prompt is given
+	SystemPrompt string `yaml:"system_prompt,omitempty" json:"system_prompt,omitempty"`
+}
+
+// PricingDefinition represents the cost of using a model,
+// expressed in US dollars per million tokens.
+type PricingDefinition struct {
+	// InputPerMillion is the cost per million prompt (input) tokens
+	InputPerMillion float64 `yaml:"input_per_million" json:"input_per_million"`
+
+	// OutputPerMillion is the cost per million completion (output) tokens
+	OutputPerMillion float64 `yaml:"output_per_million" json:"output_per_million"`
+}
+
+// EstimateCost calculates the cost in US dollars of a request with the given
+// token counts. The second return value is false if the model has no pricing.
+func (m ModelDefinition) EstimateCost(promptTokens, completionTokens int32) (float64, bool) {
+	if m.Pricing == nil {
+		return 0, false
+	}
+	cost := float64(promptTokens)*m.Pricing.InputPerMillion/1_000_000 +
+		float64(completionTokens)*m.Pricing.OutputPerMillion/1_000_000
+	return cost, true
 }
 
 // ParameterDefinition represents a parameter definition from the configuration.
@@ -271,3 +271,23 @@ func TestMarshalingAndUnmarshaling(t *testing.T) {
 			newNumericParam.Min, newNumericParam.Max, origNumericParam.Min, origNumericParam.Max)
 	}
 }
+
+// TestEstimateCost verifies cost estimation from per-million token pricing
+func TestEstimateCost(t *testing.T) {
+	model := ModelDefinition{
+		Name:    "priced-model",
+		Pricing: &PricingDefinition{InputPerMillion: 1.25, OutputPerMillion: 10.0},
+	}
+
+	cost, ok := model.EstimateCost(2_000_000, 100_000)
+	if !ok {
+		t.Fatal("Expected cost to be known for a model with pricing")
+	}
+	if cost != 3.5 {
+		t.Errorf("Expected cost 3.5, got %f", cost)
+	}
+
+	if _, ok := (ModelDefinition{Name: "unpriced-model"}).EstimateCost(1000, 1000); ok {
+		t.Error("Expected cost to be unknown for a model without pricing")
+	}
+}
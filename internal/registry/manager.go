@@ -11,8 +11,11 @@ import (
 	"sync"
 
 	"github.com/phrazzld/thinktank/internal/logutil"
+	"github.com/phrazzld/thinktank/internal/providers"
+	"github.com/phrazzld/thinktank/internal/providers/anthropic"
 	"github.com/phrazzld/thinktank/internal/providers/gemini"
 	"github.com/phrazzld/thinktank/internal/providers/openai"
+	"github.com/phrazzld/thinktank/internal/providers/openaicompat"
 	"github.com/phrazzld/thinktank/internal/providers/openrouter"
 )
 
@@ -121,31 +124,44 @@ func (m *Manager) GetRegistry() *Registry {
 }
 
 // registerProviders registers the provider implementations with the registry.
+// Each provider defined in the configuration gets the implementation named by its
+// type (or its own name), so configuration files written before a provider was
+// added keep working, and several providers can share an implementation.
 func (m *Manager) registerProviders() error {
-	// Register Gemini provider implementation
-	geminiProvider := gemini.NewProvider(m.logger)
-	if err := m.registry.RegisterProviderImplementation("gemini", geminiProvider); err != nil {
-		return fmt.Errorf("failed to register Gemini provider: %w", err)
+	factories := map[string]func() providers.Provider{
+		"gemini":            func() providers.Provider { return gemini.NewProvider(m.logger) },
+		"openai":            func() providers.Provider { return openai.NewProvider(m.logger) },
+		"openrouter":        func() providers.Provider { return openrouter.NewProvider(m.logger) },
+		"anthropic":         func() providers.Provider { return anthropic.NewProvider(m.logger) },
+		"ollama":            func() providers.Provider { return openaicompat.NewProvider(m.logger, openaicompat.OllamaEndpoint) },
+		"openai-compatible": func() providers.Provider { return openaicompat.NewProvider(m.logger, "") },
 	}
-	m.logger.Debug("Registered Gemini provider implementation")
 
-	// Register OpenAI provider implementation
-	openaiProvider := openai.NewProvider(m.logger)
-	if err := m.registry.RegisterProviderImplementation("openai", openaiProvider); err != nil {
-		return fmt.Errorf("failed to register OpenAI provider: %w", err)
-	}
-	m.logger.Debug("Registered OpenAI provider implementation")
-
-	// Register OpenRouter provider implementation
-	openrouterProvider := openrouter.NewProvider(m.logger)
-	if err := m.registry.RegisterProviderImplementation("openrouter", openrouterProvider); err != nil {
-		return fmt.Errorf("failed to register OpenRouter provider: %w", err)
+	for _, provider := range m.registry.providerDefinitions() {
+		factory, ok := factories[provider.ImplementationName()]
+		if !ok {
+			m.logger.Warn("No implementation available for provider '%s' (type '%s')", provider.Name, provider.ImplementationName())
+			continue
+		}
+		if err := m.registry.RegisterProviderImplementation(provider.Name, factory()); err != nil {
+			return fmt.Errorf("failed to register provider '%s': %w", provider.Name, err)
+		}
+		m.logger.Debug("Registered '%s' implementation for provider '%s'", provider.ImplementationName(), provider.Name)
 	}
-	m.logger.Debug("Registered OpenRouter provider implementation")
 
 	return nil
 }
 
+// RequiresAPIKey reports whether models of the given provider need an API key.
+// Providers for local servers, such as Ollama, can be used without one.
+func (m *Manager) RequiresAPIKey(providerName string) bool {
+	impl, err := m.registry.GetProviderImplementation(providerName)
+	if err != nil {
+		return true
+	}
+	return providers.RequiresAPIKey(impl)
+}
+
 // installDefaultConfig creates the config directory and copies the default models.yaml file.
 func (m *Manager) installDefaultConfig() error {
 	// Get home directory
@@ -26,3 +26,62 @@ func TestGetGlobalManager(t *testing.T) {
 		t.Error("Expected the same manager instance")
 	}
 }
+
+// TestRegisterProvidersSkipsUndefinedProviders tests that configurations defining only
+// some providers still initialize, registering implementations for the defined ones
+func TestRegisterProvidersSkipsUndefinedProviders(t *testing.T) {
+	logger := logutil.NewLogger(logutil.InfoLevel, nil, "[test] ")
+	manager := NewManager(logger)
+	manager.registry.providers["gemini"] = ProviderDefinition{Name: "gemini"}
+	manager.registry.providers["openai"] = ProviderDefinition{Name: "openai"}
+
+	if err := manager.registerProviders(); err != nil {
+		t.Fatalf("Expected no error registering providers, got: %v", err)
+	}
+
+	for _, name := range []string{"gemini", "openai"} {
+		if _, err := manager.registry.GetProviderImplementation(name); err != nil {
+			t.Errorf("Expected implementation for %s to be registered: %v", name, err)
+		}
+	}
+	if _, err := manager.registry.GetProviderImplementation("anthropic"); err == nil {
+		t.Error("Expected no implementation for a provider missing from the configuration")
+	}
+}
+
+// TestRegisterProvidersByType tests that providers use the implementation named by their type
+func TestRegisterProvidersByType(t *testing.T) {
+	logger := logutil.NewLogger(logutil.InfoLevel, nil, "[test] ")
+	manager := NewManager(logger)
+	manager.registry.providers["openai"] = ProviderDefinition{Name: "openai"}
+	manager.registry.providers["ollama"] = ProviderDefinition{Name: "ollama"}
+	manager.registry.providers["workstation"] = ProviderDefinition{
+		Name:    "workstation",
+		Type:    "openai-compatible",
+		BaseURL: "http://10.0.0.5:8080/v1",
+	}
+	manager.registry.providers["mystery"] = ProviderDefinition{Name: "mystery"}
+
+	if err := manager.registerProviders(); err != nil {
+		t.Fatalf("Expected no error registering providers, got: %v", err)
+	}
+
+	for _, name := range []string{"openai", "ollama", "workstation"} {
+		if _, err := manager.registry.GetProviderImplementation(name); err != nil {
+			t.Errorf("Expected implementation for %s to be registered: %v", name, err)
+		}
+	}
+	if _, err := manager.registry.GetProviderImplementation("mystery"); err == nil {
+		t.Error("Expected no implementation for a provider with an unknown type")
+	}
+
+	if !manager.RequiresAPIKey("openai") {
+		t.Error("Expected the openai provider to require an API key")
+	}
+	if manager.RequiresAPIKey("ollama") || manager.RequiresAPIKey("workstation") {
+		t.Error("Expected local providers not to require an API key")
+	}
+	if !manager.RequiresAPIKey("mystery") {
+		t.Error("Expected providers without an implementation to require an API key")
+	}
+}
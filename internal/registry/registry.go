@@ -6,6 +6,7 @@ package registry
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
@@ -174,6 +175,19 @@ func (r *Registry) GetProvider(name string) (*ProviderDefinition, error) {
 	return &provider, nil
 }
 
+// providerDefinitions returns the providers defined in the configuration, sorted by name
+func (r *Registry) providerDefinitions() []ProviderDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	definitions := make([]ProviderDefinition, 0, len(r.providers))
+	for _, provider := range r.providers {
+		definitions = append(definitions, provider)
+	}
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Name < definitions[j].Name })
+	return definitions
+}
+
 // getAvailableProvidersList returns a comma-separated list of available providers
 func (r *Registry) getAvailableProvidersList() string {
 	if len(r.providers) == 0 {
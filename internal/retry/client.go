@@ -0,0 +1,193 @@
+package retry
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/phrazzld/thinktank/internal/auditlog"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/logutil"
+)
+
+// Client wraps an LLM client so that requests failing with transient errors are
+// retried according to a Policy. A Retry-After delay reported by the provider
+// takes precedence over the computed backoff. Every attempt is recorded in the
+// audit log.
+type Client struct {
+	client      llm.LLMClient
+	policy      Policy
+	modelName   string
+	auditLogger auditlog.AuditLogger
+	logger      logutil.LoggerInterface
+
+	// random and sleep are replaceable for testing
+	random func() float64
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// NewClient creates a retrying client for a model
+func NewClient(
+	client llm.LLMClient,
+	policy Policy,
+	modelName string,
+	auditLogger auditlog.AuditLogger,
+	logger logutil.LoggerInterface,
+) *Client {
+	if auditLogger == nil {
+		auditLogger = auditlog.NewNoOpAuditLogger()
+	}
+	if logger == nil {
+		logger = logutil.NewLogger(logutil.InfoLevel, nil, "[retry] ")
+	}
+
+	return &Client{
+		client:      client,
+		policy:      policy,
+		modelName:   modelName,
+		auditLogger: auditLogger,
+		logger:      logger,
+		random:      rand.Float64,
+		sleep:       sleepContext,
+	}
+}
+
+// GenerateContent calls the wrapped client, retrying transient failures
+func (c *Client) GenerateContent(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := c.client.GenerateContent(ctx, prompt, params)
+
+		delay, retry := c.nextDelay(ctx, attempt, err)
+		c.logAttempt("GenerateContentAttempt", attempt, retry, delay, err)
+		if !retry {
+			return result, err
+		}
+
+		if waitErr := c.sleep(ctx, delay); waitErr != nil {
+			return nil, err
+		}
+	}
+}
+
+// GenerateContentStream streams from the wrapped client, retrying transient failures
+// that occur before any chunk has been delivered. Once output has been passed on,
+// a failure ends the stream, since the consumer has already seen part of the response.
+func (c *Client) GenerateContentStream(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+	streamingClient, ok := c.client.(llm.StreamingLLMClient)
+	if !ok {
+		return llm.StreamFromResult(c.GenerateContent(ctx, prompt, params)), nil
+	}
+
+	out := make(chan llm.StreamChunk)
+	go func() {
+		defer close(out)
+
+		for attempt := 1; ; attempt++ {
+			forwarded := false
+			stream, err := streamingClient.GenerateContentStream(ctx, prompt, params)
+			if err == nil {
+				for chunk := range stream {
+					if chunk.Err != nil && !forwarded {
+						err = chunk.Err
+						break
+					}
+					if !llm.SendChunk(ctx, out, chunk) {
+						return
+					}
+					forwarded = true
+					if chunk.Err != nil {
+						c.logAttempt("GenerateContentStreamAttempt", attempt, false, 0, chunk.Err)
+						return
+					}
+				}
+			}
+
+			delay, retry := c.nextDelay(ctx, attempt, err)
+			c.logAttempt("GenerateContentStreamAttempt", attempt, retry, delay, err)
+			if err == nil {
+				return
+			}
+			if !retry {
+				llm.SendChunk(ctx, out, llm.StreamChunk{Err: err})
+				return
+			}
+
+			if waitErr := c.sleep(ctx, delay); waitErr != nil {
+				llm.SendChunk(ctx, out, llm.StreamChunk{Err: err})
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// GetModelName returns the name of the wrapped client's model
+func (c *Client) GetModelName() string {
+	return c.client.GetModelName()
+}
+
+// Close releases the resources of the wrapped client
+func (c *Client) Close() error {
+	return c.client.Close()
+}
+
+// nextDelay decides whether a failed attempt should be retried and how long to
+// wait first. Requests are not retried once the attempts are exhausted, when the
+// error is not transient, or when the delay would outlast the context's deadline.
+func (c *Client) nextDelay(ctx context.Context, attempt int, err error) (time.Duration, bool) {
+	if err == nil || attempt >= c.policy.MaxAttempts || !IsRetryable(err) || ctx.Err() != nil {
+		return 0, false
+	}
+
+	delay := llm.GetRetryAfter(err)
+	if delay <= 0 {
+		delay = c.policy.Backoff(attempt, c.random)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		c.logger.Warn("Not retrying model %s: waiting %s would exceed the deadline", c.modelName, delay)
+		return 0, false
+	}
+	return delay, true
+}
+
+// logAttempt records the outcome of an attempt in the audit log
+func (c *Client) logAttempt(operation string, attempt int, retry bool, delay time.Duration, err error) {
+	status := "Success"
+	if err != nil {
+		status = "Failure"
+	}
+	if retry {
+		c.logger.Warn("Attempt %d/%d for model %s failed, retrying in %s: %v",
+			attempt, c.policy.MaxAttempts, c.modelName, delay.Round(time.Millisecond), err)
+	}
+
+	inputs := map[string]interface{}{
+		"model_name":   c.modelName,
+		"attempt":      attempt,
+		"max_attempts": c.policy.MaxAttempts,
+	}
+	outputs := map[string]interface{}{
+		"will_retry": retry,
+	}
+	if retry {
+		outputs["retry_delay_ms"] = delay.Milliseconds()
+	}
+	if logErr := c.auditLogger.LogOp(operation, status, inputs, outputs, err); logErr != nil {
+		c.logger.Error("Failed to write audit log: %v", logErr)
+	}
+}
+
+// sleepContext waits for the given duration unless the context is cancelled first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,232 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phrazzld/thinktank/internal/auditlog"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAuditLogger records the entries logged through LogOp
+type recordingAuditLogger struct {
+	mu      sync.Mutex
+	entries []auditlog.AuditEntry
+}
+
+func (l *recordingAuditLogger) Log(entry auditlog.AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func (l *recordingAuditLogger) LogOp(operation, status string, inputs map[string]interface{}, outputs map[string]interface{}, err error) error {
+	return l.Log(auditlog.AuditEntry{Operation: operation, Status: status, Inputs: inputs, Outputs: outputs})
+}
+
+func (l *recordingAuditLogger) Close() error {
+	return nil
+}
+
+// scriptedLLMClient returns the scripted errors in order, then succeeds
+type scriptedLLMClient struct {
+	calls int
+	errs  []error
+}
+
+func (c *scriptedLLMClient) GenerateContent(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+	c.calls++
+	if c.calls <= len(c.errs) {
+		return nil, c.errs[c.calls-1]
+	}
+	return &llm.ProviderResult{Content: "response"}, nil
+}
+
+func (c *scriptedLLMClient) GetModelName() string { return "model" }
+
+func (c *scriptedLLMClient) Close() error { return nil }
+
+// scriptedStreamingLLMClient streams the scripted chunk sequences in order
+type scriptedStreamingLLMClient struct {
+	scriptedLLMClient
+	streams [][]llm.StreamChunk
+}
+
+func (c *scriptedStreamingLLMClient) GenerateContentStream(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+	chunks := c.streams[c.calls]
+	c.calls++
+	stream := make(chan llm.StreamChunk, len(chunks))
+	for _, chunk := range chunks {
+		stream <- chunk
+	}
+	close(stream)
+	return stream, nil
+}
+
+// newTestClient creates a client that records its delays instead of sleeping
+func newTestClient(inner llm.LLMClient, policy Policy, auditLogger auditlog.AuditLogger) (*Client, *[]time.Duration) {
+	client := NewClient(inner, policy, "model", auditLogger, nil)
+	var delays []time.Duration
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return ctx.Err()
+	}
+	return client, &delays
+}
+
+var testPolicy = Policy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+func rateLimitError(retryAfter time.Duration) error {
+	err := llm.New("test", "", 429, "rate limited", "", nil, llm.CategoryRateLimit)
+	err.RetryAfter = retryAfter
+	return err
+}
+
+// TestClientRetriesTransientErrors tests that transient errors are retried with backoff
+func TestClientRetriesTransientErrors(t *testing.T) {
+	inner := &scriptedLLMClient{errs: []error{
+		llm.New("test", "", 503, "unavailable", "", nil, llm.CategoryServer),
+		llm.New("test", "", 0, "connection reset", "", nil, llm.CategoryNetwork),
+	}}
+	auditLogger := &recordingAuditLogger{}
+	client, delays := newTestClient(inner, testPolicy, auditLogger)
+
+	result, err := client.GenerateContent(context.Background(), "prompt", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "response", result.Content)
+	assert.Equal(t, 3, inner.calls)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, *delays)
+
+	require.Len(t, auditLogger.entries, 3)
+	for i, entry := range auditLogger.entries {
+		assert.Equal(t, "GenerateContentAttempt", entry.Operation)
+		assert.Equal(t, i+1, entry.Inputs["attempt"])
+		assert.Equal(t, 3, entry.Inputs["max_attempts"])
+	}
+	assert.Equal(t, "Failure", auditLogger.entries[0].Status)
+	assert.Equal(t, true, auditLogger.entries[0].Outputs["will_retry"])
+	assert.Equal(t, int64(1000), auditLogger.entries[0].Outputs["retry_delay_ms"])
+	assert.Equal(t, "Success", auditLogger.entries[2].Status)
+	assert.Equal(t, false, auditLogger.entries[2].Outputs["will_retry"])
+}
+
+// TestClientHonorsRetryAfter tests that a provider's Retry-After delay replaces the backoff
+func TestClientHonorsRetryAfter(t *testing.T) {
+	inner := &scriptedLLMClient{errs: []error{rateLimitError(30 * time.Second)}}
+	client, delays := newTestClient(inner, testPolicy, nil)
+
+	_, err := client.GenerateContent(context.Background(), "prompt", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{30 * time.Second}, *delays)
+}
+
+// TestClientGivesUp tests the cases in which a failed request is not retried
+func TestClientGivesUp(t *testing.T) {
+	t.Run("Attempts exhausted", func(t *testing.T) {
+		inner := &scriptedLLMClient{errs: []error{rateLimitError(0), rateLimitError(0), rateLimitError(0)}}
+		client, _ := newTestClient(inner, testPolicy, nil)
+
+		_, err := client.GenerateContent(context.Background(), "prompt", nil)
+		assert.True(t, llm.IsRateLimit(err))
+		assert.Equal(t, 3, inner.calls)
+	})
+
+	t.Run("Permanent error", func(t *testing.T) {
+		inner := &scriptedLLMClient{errs: []error{llm.New("test", "", 401, "unauthorized", "", nil, llm.CategoryAuth)}}
+		client, delays := newTestClient(inner, testPolicy, nil)
+
+		_, err := client.GenerateContent(context.Background(), "prompt", nil)
+		assert.True(t, llm.IsAuth(err))
+		assert.Equal(t, 1, inner.calls)
+		assert.Empty(t, *delays)
+	})
+
+	t.Run("Retries disabled", func(t *testing.T) {
+		inner := &scriptedLLMClient{errs: []error{rateLimitError(0)}}
+		client, _ := newTestClient(inner, Policy{MaxAttempts: 1}, nil)
+
+		_, err := client.GenerateContent(context.Background(), "prompt", nil)
+		assert.Error(t, err)
+		assert.Equal(t, 1, inner.calls)
+	})
+
+	t.Run("Delay exceeds deadline", func(t *testing.T) {
+		inner := &scriptedLLMClient{errs: []error{rateLimitError(time.Hour)}}
+		client, delays := newTestClient(inner, testPolicy, nil)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, err := client.GenerateContent(ctx, "prompt", nil)
+		assert.True(t, llm.IsRateLimit(err))
+		assert.Equal(t, 1, inner.calls)
+		assert.Empty(t, *delays)
+	})
+
+	t.Run("Context cancelled while waiting", func(t *testing.T) {
+		inner := &scriptedLLMClient{errs: []error{rateLimitError(0)}}
+		client := NewClient(inner, Policy{MaxAttempts: 3, InitialBackoff: time.Hour}, "model", nil, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		_, err := client.GenerateContent(ctx, "prompt", nil)
+		assert.True(t, llm.IsRateLimit(err), "Expected the provider error to be returned")
+		assert.Equal(t, 1, inner.calls)
+	})
+}
+
+// TestClientGenerateContentStream tests retrying streams that fail before producing output
+func TestClientGenerateContentStream(t *testing.T) {
+	inner := &scriptedStreamingLLMClient{streams: [][]llm.StreamChunk{
+		{{Err: rateLimitError(5 * time.Second)}},
+		{{Content: "Hello"}, {Content: " world"}, {FinishReason: "stop"}},
+	}}
+	auditLogger := &recordingAuditLogger{}
+	client, delays := newTestClient(inner, testPolicy, auditLogger)
+
+	stream, err := client.GenerateContentStream(context.Background(), "prompt", nil)
+	require.NoError(t, err)
+	result, err := llm.CollectStream(context.Background(), stream, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello world", result.Content)
+	assert.Equal(t, 2, inner.calls)
+	assert.Equal(t, []time.Duration{5 * time.Second}, *delays)
+
+	require.Len(t, auditLogger.entries, 2)
+	assert.Equal(t, "GenerateContentStreamAttempt", auditLogger.entries[0].Operation)
+	assert.Equal(t, "Failure", auditLogger.entries[0].Status)
+	assert.Equal(t, "Success", auditLogger.entries[1].Status)
+}
+
+// TestClientGenerateContentStreamPartialFailure tests that streams failing after output are not retried
+func TestClientGenerateContentStreamPartialFailure(t *testing.T) {
+	inner := &scriptedStreamingLLMClient{streams: [][]llm.StreamChunk{
+		{{Content: "Partial"}, {Err: rateLimitError(0)}},
+		{{Content: "Complete"}},
+	}}
+	client, _ := newTestClient(inner, testPolicy, nil)
+
+	stream, err := client.GenerateContentStream(context.Background(), "prompt", nil)
+	require.NoError(t, err)
+	result, err := llm.CollectStream(context.Background(), stream, nil)
+	assert.True(t, llm.IsRateLimit(err))
+	assert.Equal(t, "Partial", result.Content)
+	assert.Equal(t, 1, inner.calls)
+}
+
+// TestClientGenerateContentStreamWithoutStreaming tests streaming through a client that cannot stream
+func TestClientGenerateContentStreamWithoutStreaming(t *testing.T) {
+	inner := &scriptedLLMClient{errs: []error{errors.New("uncategorized")}}
+	client, _ := newTestClient(inner, testPolicy, nil)
+
+	stream, err := client.GenerateContentStream(context.Background(), "prompt", nil)
+	require.NoError(t, err)
+	_, err = llm.CollectStream(context.Background(), stream, nil)
+	assert.Error(t, err)
+	assert.Equal(t, 1, inner.calls)
+}
@@ -0,0 +1,60 @@
+// Package retry retries LLM requests that fail with transient errors, such as
+// rate limits, server errors and network failures, using exponential backoff
+package retry
+
+import (
+	"time"
+
+	"github.com/phrazzld/thinktank/internal/llm"
+)
+
+// Policy controls how often and how long a failed request is retried
+type Policy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values of 1 or less disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. It doubles after every attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the computed delay between attempts
+	MaxBackoff time.Duration
+	// Jitter randomizes each delay by up to this fraction in either direction (0-1),
+	// so that concurrent requests do not retry in lockstep
+	Jitter float64
+}
+
+// Enabled reports whether the policy allows any retries
+func (p Policy) Enabled() bool {
+	return p.MaxAttempts > 1
+}
+
+// Backoff returns the delay after the given failed attempt (starting at 1).
+// random must return a value in [0, 1) and is used to apply the jitter.
+func (p Policy) Backoff(attempt int, random func() float64) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 && random != nil {
+		delay += time.Duration(float64(delay) * p.Jitter * (2*random() - 1))
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	return delay
+}
+
+// IsRetryable reports whether an error is transient, so that the same request may succeed later
+func IsRetryable(err error) bool {
+	return llm.IsRateLimit(err) || llm.IsServer(err) || llm.IsNetwork(err)
+}
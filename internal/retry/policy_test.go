@@ -0,0 +1,67 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPolicyBackoff tests that delays grow exponentially up to the maximum
+func TestPolicyBackoff(t *testing.T) {
+	policy := Policy{MaxAttempts: 10, InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, want := range expected {
+		assert.Equal(t, want, policy.Backoff(i+1, nil), "attempt %d", i+1)
+	}
+
+	// Large attempt numbers do not overflow
+	assert.Equal(t, 10*time.Second, policy.Backoff(100, nil))
+}
+
+// TestPolicyBackoffJitter tests that jitter spreads delays around the computed backoff
+func TestPolicyBackoffJitter(t *testing.T) {
+	policy := Policy{MaxAttempts: 3, InitialBackoff: 10 * time.Second, MaxBackoff: time.Minute, Jitter: 0.2}
+
+	assert.Equal(t, 8*time.Second, policy.Backoff(1, func() float64 { return 0 }))
+	assert.Equal(t, 10*time.Second, policy.Backoff(1, func() float64 { return 0.5 }))
+	assert.Equal(t, 12*time.Second, policy.Backoff(1, func() float64 { return 1 }))
+}
+
+// TestPolicyEnabled tests that a single attempt disables retries
+func TestPolicyEnabled(t *testing.T) {
+	assert.True(t, Policy{MaxAttempts: 3}.Enabled())
+	assert.False(t, Policy{MaxAttempts: 1}.Enabled())
+	assert.False(t, Policy{}.Enabled())
+}
+
+// TestIsRetryable tests which error categories are retried
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		category  llm.ErrorCategory
+		retryable bool
+	}{
+		{llm.CategoryRateLimit, true},
+		{llm.CategoryServer, true},
+		{llm.CategoryNetwork, true},
+		{llm.CategoryAuth, false},
+		{llm.CategoryInvalidRequest, false},
+		{llm.CategoryInputLimit, false},
+		{llm.CategoryContentFiltered, false},
+		{llm.CategoryCancelled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.category.String(), func(t *testing.T) {
+			err := fmt.Errorf("wrapped: %w", llm.New("test", "", 0, "error", "", nil, tt.category))
+			assert.Equal(t, tt.retryable, IsRetryable(err))
+		})
+	}
+
+	assert.False(t, IsRetryable(errors.New("uncategorized")))
+	assert.False(t, IsRetryable(nil))
+}
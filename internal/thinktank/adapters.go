@@ -78,6 +78,12 @@ func (a *APIServiceAdapter) GetModelDefinition(modelName string) (*registry.Mode
 	return nil, fmt.Errorf("model definition not available")
 }
 
+// GetProviderDefinition delegates to the underlying APIService implementation
+// .nocover - pure wrapper method that simply delegates to underlying implementation
+func (a *APIServiceAdapter) GetProviderDefinition(providerName string) (*registry.ProviderDefinition, error) {
+	return a.APIService.GetProviderDefinition(providerName)
+}
+
 // GetModelTokenLimits delegates to the underlying APIService implementation
 func (a *APIServiceAdapter) GetModelTokenLimits(modelName string) (contextWindow, maxOutputTokens int32, err error) {
 	// Check if the underlying implementation supports this method
@@ -166,6 +172,18 @@ func internalToInterfacesGatherConfig(config interfaces.GatherConfig) GatherConf
 		Format:       config.Format,
 		Verbose:      config.Verbose,
 		LogLevel:     config.LogLevel,
+		TokenBudget:  config.TokenBudget,
+		PackStrategy: config.PackStrategy,
+		Instructions: config.Instructions,
+		Diff:         config.Diff,
+		Imports:      config.Imports,
+
+		PromptOverhead: config.PromptOverhead,
+		FileTokens:     config.FileTokens,
+
+		MaxFileSize:      config.MaxFileSize,
+		MaxTotalSize:     config.MaxTotalSize,
+		IncludeGenerated: config.IncludeGenerated,
 	}
 }
 
@@ -179,7 +197,16 @@ func internalToInterfacesContextStats(stats *ContextStats) *interfaces.ContextSt
 		CharCount:           stats.CharCount,
 		LineCount:           stats.LineCount,
 		// TokenCount field removed as part of T032F - token handling refactoring
-		ProcessedFiles: stats.ProcessedFiles,
+		ProcessedFiles:  stats.ProcessedFiles,
+		EstimatedTokens: stats.EstimatedTokens,
+		TokenBudget:     stats.TokenBudget,
+		OmittedFiles:    stats.OmittedFiles,
+		TruncatedFiles:  stats.TruncatedFiles,
+
+		SkippedFiles:       stats.SkippedFiles,
+		SizeTruncatedFiles: stats.SizeTruncatedFiles,
+
+		OmittedDirs: stats.OmittedDirs,
 	}
 }
 
@@ -193,7 +220,16 @@ func interfacesToInternalContextStats(stats *interfaces.ContextStats) *ContextSt
 		CharCount:           stats.CharCount,
 		LineCount:           stats.LineCount,
 		// TokenCount field removed as part of T032F - token handling refactoring
-		ProcessedFiles: stats.ProcessedFiles,
+		ProcessedFiles:  stats.ProcessedFiles,
+		EstimatedTokens: stats.EstimatedTokens,
+		TokenBudget:     stats.TokenBudget,
+		OmittedFiles:    stats.OmittedFiles,
+		TruncatedFiles:  stats.TruncatedFiles,
+
+		SkippedFiles:       stats.SkippedFiles,
+		SizeTruncatedFiles: stats.SizeTruncatedFiles,
+
+		OmittedDirs: stats.OmittedDirs,
 	}
 }
 
@@ -576,6 +576,18 @@ func TestInternalToInterfacesGatherConfig(t *testing.T) {
 		Format:       "json",
 		Verbose:      true,
 		LogLevel:     1, // Debug level
+		TokenBudget:  5000,
+		PackStrategy: "recency",
+		Instructions: "Refactor the parser",
+		Diff:         fileutil.DiffOptions{Range: "main", Neighbors: 2},
+		Imports:      fileutil.ImportOptions{Depth: 1, Importers: true},
+
+		PromptOverhead: func(files []fileutil.FileMeta, omittedDirs []string) int { return 100 },
+		FileTokens:     func(file fileutil.FileMeta) int { return 10 },
+
+		MaxFileSize:      1 << 20,
+		MaxTotalSize:     10 << 20,
+		IncludeGenerated: true,
 	}
 
 	result := internalToInterfacesGatherConfig(input)
@@ -602,6 +614,20 @@ func TestInternalToInterfacesGatherConfig(t *testing.T) {
 	if result.LogLevel != input.LogLevel {
 		t.Errorf("Expected LogLevel %v, got %v", input.LogLevel, result.LogLevel)
 	}
+	if result.TokenBudget != input.TokenBudget || result.PackStrategy != input.PackStrategy || result.Instructions != input.Instructions {
+		t.Errorf("Expected packing configuration to be copied, got budget=%d strategy=%s instructions=%q",
+			result.TokenBudget, result.PackStrategy, result.Instructions)
+	}
+	if result.PromptOverhead == nil || result.PromptOverhead(nil, nil) != 100 || result.FileTokens == nil || result.FileTokens(fileutil.FileMeta{}) != 10 {
+		t.Error("Expected the prompt estimators to be copied")
+	}
+	if result.Diff != input.Diff || result.Imports != input.Imports {
+		t.Errorf("Expected diff %+v and imports %+v, got %+v and %+v", input.Diff, input.Imports, result.Diff, result.Imports)
+	}
+	if result.MaxFileSize != input.MaxFileSize || result.MaxTotalSize != input.MaxTotalSize || result.IncludeGenerated != input.IncludeGenerated {
+		t.Errorf("Expected size limits to be copied, got file=%d total=%d generated=%v",
+			result.MaxFileSize, result.MaxTotalSize, result.IncludeGenerated)
+	}
 }
 
 // TestInternalToInterfacesContextStats verifies the conversion from internal ContextStats to interfaces.ContextStats
@@ -612,6 +638,13 @@ func TestInternalToInterfacesContextStats(t *testing.T) {
 		CharCount:           1000,
 		LineCount:           100,
 		ProcessedFiles:      []string{"file1.go", "file2.go"},
+		EstimatedTokens:     4000,
+		TokenBudget:         3000,
+		OmittedFiles:        []string{"file3.go"},
+		TruncatedFiles:      []string{"file2.go"},
+		SkippedFiles:        []string{"yarn.lock (lock file)"},
+		SizeTruncatedFiles:  []string{"fixture.json"},
+		OmittedDirs:         []string{"/project/node_modules"},
 	}
 
 	result := internalToInterfacesContextStats(input)
@@ -629,6 +662,18 @@ func TestInternalToInterfacesContextStats(t *testing.T) {
 	if !reflect.DeepEqual(result.ProcessedFiles, input.ProcessedFiles) {
 		t.Errorf("Expected ProcessedFiles %v, got %v", input.ProcessedFiles, result.ProcessedFiles)
 	}
+	if result.EstimatedTokens != input.EstimatedTokens || result.TokenBudget != input.TokenBudget {
+		t.Errorf("Expected token stats %d/%d, got %d/%d", input.EstimatedTokens, input.TokenBudget, result.EstimatedTokens, result.TokenBudget)
+	}
+	if !reflect.DeepEqual(result.OmittedFiles, input.OmittedFiles) || !reflect.DeepEqual(result.TruncatedFiles, input.TruncatedFiles) {
+		t.Errorf("Expected omitted %v and truncated %v, got %v and %v", input.OmittedFiles, input.TruncatedFiles, result.OmittedFiles, result.TruncatedFiles)
+	}
+	if !reflect.DeepEqual(result.SkippedFiles, input.SkippedFiles) || !reflect.DeepEqual(result.SizeTruncatedFiles, input.SizeTruncatedFiles) {
+		t.Errorf("Expected skipped %v and size-truncated %v, got %v and %v", input.SkippedFiles, input.SizeTruncatedFiles, result.SkippedFiles, result.SizeTruncatedFiles)
+	}
+	if !reflect.DeepEqual(result.OmittedDirs, input.OmittedDirs) {
+		t.Errorf("Expected omitted directories %v, got %v", input.OmittedDirs, result.OmittedDirs)
+	}
 
 	// Test with nil stats
 	nilResult := internalToInterfacesContextStats(nil)
@@ -645,6 +690,13 @@ func TestInterfacesToInternalContextStats(t *testing.T) {
 		CharCount:           1000,
 		LineCount:           100,
 		ProcessedFiles:      []string{"file1.go", "file2.go"},
+		EstimatedTokens:     4000,
+		TokenBudget:         3000,
+		OmittedFiles:        []string{"file3.go"},
+		TruncatedFiles:      []string{"file2.go"},
+		SkippedFiles:        []string{"yarn.lock (lock file)"},
+		SizeTruncatedFiles:  []string{"fixture.json"},
+		OmittedDirs:         []string{"/project/node_modules"},
 	}
 
 	result := interfacesToInternalContextStats(input)
@@ -662,6 +714,18 @@ func TestInterfacesToInternalContextStats(t *testing.T) {
 	if !reflect.DeepEqual(result.ProcessedFiles, input.ProcessedFiles) {
 		t.Errorf("Expected ProcessedFiles %v, got %v", input.ProcessedFiles, result.ProcessedFiles)
 	}
+	if result.EstimatedTokens != input.EstimatedTokens || result.TokenBudget != input.TokenBudget {
+		t.Errorf("Expected token stats %d/%d, got %d/%d", input.EstimatedTokens, input.TokenBudget, result.EstimatedTokens, result.TokenBudget)
+	}
+	if !reflect.DeepEqual(result.OmittedFiles, input.OmittedFiles) || !reflect.DeepEqual(result.TruncatedFiles, input.TruncatedFiles) {
+		t.Errorf("Expected omitted %v and truncated %v, got %v and %v", input.OmittedFiles, input.TruncatedFiles, result.OmittedFiles, result.TruncatedFiles)
+	}
+	if !reflect.DeepEqual(result.SkippedFiles, input.SkippedFiles) || !reflect.DeepEqual(result.SizeTruncatedFiles, input.SizeTruncatedFiles) {
+		t.Errorf("Expected skipped %v and size-truncated %v, got %v and %v", input.SkippedFiles, input.SizeTruncatedFiles, result.SkippedFiles, result.SizeTruncatedFiles)
+	}
+	if !reflect.DeepEqual(result.OmittedDirs, input.OmittedDirs) {
+		t.Errorf("Expected omitted directories %v, got %v", input.OmittedDirs, result.OmittedDirs)
+	}
 
 	// Test with nil stats
 	nilResult := interfacesToInternalContextStats(nil)
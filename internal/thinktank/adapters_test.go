@@ -22,6 +22,7 @@ type MockAPIServiceForAdapter struct {
 	GetModelParametersFunc     func(modelName string) (map[string]interface{}, error)
 	ValidateModelParameterFunc func(modelName, paramName string, value interface{}) (bool, error)
 	GetModelDefinitionFunc     func(modelName string) (*registry.ModelDefinition, error)
+	GetProviderDefinitionFunc  func(providerName string) (*registry.ProviderDefinition, error)
 	GetModelTokenLimitsFunc    func(modelName string) (contextWindow, maxOutputTokens int32, err error)
 
 	// Call tracking fields
@@ -163,6 +164,13 @@ func (m *MockAPIServiceForAdapter) GetModelDefinition(modelName string) (*regist
 	return nil, errors.New("GetModelDefinition not implemented")
 }
 
+func (m *MockAPIServiceForAdapter) GetProviderDefinition(providerName string) (*registry.ProviderDefinition, error) {
+	if m.GetProviderDefinitionFunc != nil {
+		return m.GetProviderDefinitionFunc(providerName)
+	}
+	return nil, errors.New("GetProviderDefinition not implemented")
+}
+
 func (m *MockAPIServiceForAdapter) GetModelTokenLimits(modelName string) (contextWindow, maxOutputTokens int32, err error) {
 	m.GetModelTokenLimitsCalls = append(m.GetModelTokenLimitsCalls, GetModelTokenLimitsCall{
 		ModelName: modelName,
@@ -272,6 +280,11 @@ func (m *MockAPIServiceWithoutExtensions) GetModelDefinition(modelName string) (
 	return nil, errors.New("model definition not available")
 }
 
+// GetProviderDefinition returns an error - this method should not be called by adapter tests
+func (m *MockAPIServiceWithoutExtensions) GetProviderDefinition(providerName string) (*registry.ProviderDefinition, error) {
+	return nil, errors.New("provider definition not available")
+}
+
 // GetModelTokenLimits returns fallback values that won't actually be used by the adapter
 // The adapter should use its own fallback logic based on the model name
 func (m *MockAPIServiceWithoutExtensions) GetModelTokenLimits(modelName string) (contextWindow, maxOutputTokens int32, err error) {
@@ -3,10 +3,13 @@ package thinktank
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/phrazzld/thinktank/internal/auditlog"
@@ -14,6 +17,7 @@ import (
 	"github.com/phrazzld/thinktank/internal/llm"
 	"github.com/phrazzld/thinktank/internal/logutil"
 	"github.com/phrazzld/thinktank/internal/ratelimit"
+	"github.com/phrazzld/thinktank/internal/retry"
 	"github.com/phrazzld/thinktank/internal/thinktank/interfaces"
 	"github.com/phrazzld/thinktank/internal/thinktank/orchestrator"
 )
@@ -43,6 +47,12 @@ func Execute(
 		}
 	}()
 
+	// Write the JSON report of the run when requested, even if the run failed early
+	var orch Orchestrator
+	if cliConfig.OutputFormat == config.OutputFormatJSON {
+		defer func() { writeRunReport(ctx, cliConfig, orch, err, logger) }()
+	}
+
 	// 1. Set up the output directory
 	if err := setupOutputDirectory(cliConfig, logger); err != nil {
 		return err
@@ -51,6 +61,7 @@ func Execute(
 	// 2. Log the start of the Execute operation
 	inputs := map[string]interface{}{
 		"instructions_file": cliConfig.InstructionsFile,
+		"instructions":      cliConfig.DescribeInstructions(),
 		"output_dir":        cliConfig.OutputDir,
 		"audit_log_file":    cliConfig.AuditLogFile,
 		"format":            cliConfig.Format,
@@ -61,6 +72,11 @@ func Execute(
 		"dry_run":           cliConfig.DryRun,
 		"verbose":           cliConfig.Verbose,
 		"model_names":       cliConfig.ModelNames,
+		"no_cache":          cliConfig.NoCache,
+		"retry_attempts":    cliConfig.RetryMaxAttempts,
+		"workflow_file":     cliConfig.WorkflowFile,
+		"output_format":     cliConfig.OutputFormat,
+		"resume_dir":        cliConfig.ResumeDir,
 		// "confirm_tokens" field removed as part of T032E - token management refactoring
 		"log_level": cliConfig.LogLevel,
 	}
@@ -69,44 +85,68 @@ func Execute(
 		logger.Error("Failed to write audit log: %v", logErr)
 	}
 
-	// 3. Read instructions from file
-	instructionsContent, err := os.ReadFile(cliConfig.InstructionsFile)
+	// 3. Read instructions from the instructions files, stdin and --prompt
+	sources := cliConfig.DescribeInstructions()
+	instructions, err := readInstructions(cliConfig)
 	if err != nil {
-		logger.Error("Failed to read instructions file %s: %v", cliConfig.InstructionsFile, err)
+		logger.Error("Failed to read instructions: %v", err)
 
-		// Log the failure to read the instructions file to the audit log
-		inputs := map[string]interface{}{"path": cliConfig.InstructionsFile}
+		// Log the failure to read the instructions to the audit log
+		inputs := map[string]interface{}{"sources": sources}
 		if logErr := auditLogger.LogOp("ReadInstructions", "Failure", inputs, nil, err); logErr != nil {
 			logger.Error("Failed to write audit log: %v", logErr)
 		}
 
-		return fmt.Errorf("%w: failed to read instructions file %s: %v", ErrInvalidInstructions, cliConfig.InstructionsFile, err)
+		return fmt.Errorf("%w: %v", ErrInvalidInstructions, err)
 	}
-	instructions := string(instructionsContent)
-	logger.Info("Successfully read instructions from %s", cliConfig.InstructionsFile)
+	logger.Info("Successfully read instructions from %s", strings.Join(sources, ", "))
 
-	// Log the successful reading of the instructions file to the audit log
+	// Log the successful reading of the instructions to the audit log
 	if logErr := auditLogger.Log(auditlog.AuditEntry{
 		Timestamp: time.Now().UTC(),
 		Operation: "ReadInstructions",
 		Status:    "Success",
 		Inputs: map[string]interface{}{
-			"path": cliConfig.InstructionsFile,
+			"sources": sources,
 		},
 		Outputs: map[string]interface{}{
 			"content_length": len(instructions),
 		},
-		Message: "Successfully read instructions file",
+		Message: "Successfully read instructions",
 	}); logErr != nil {
 		logger.Error("Failed to write audit log: %v", logErr)
 	}
 
-	// 4. Use the injected APIService
+	// 4. Use the injected APIService, retrying transient provider errors and
+	// serving repeated requests from the response cache
+	retryPolicy := retry.Policy{
+		MaxAttempts:    cliConfig.RetryMaxAttempts,
+		InitialBackoff: cliConfig.RetryInitialBackoff,
+		MaxBackoff:     cliConfig.RetryMaxBackoff,
+		Jitter:         cliConfig.RetryJitter,
+	}
+	if retryPolicy.Enabled() {
+		apiService = newRetryingAPIService(apiService, retryPolicy, auditLogger, logger)
+	}
+	if !cliConfig.NoCache && cliConfig.CacheDir != "" && !cliConfig.DryRun {
+		logger.Debug("Using response cache in %s (TTL %s)", cliConfig.CacheDir, cliConfig.CacheTTL)
+		apiService = newCachingAPIService(apiService, cliConfig.CacheDir, cliConfig.CacheTTL, auditLogger, logger)
+	}
 
 	// Create a reference client for token counting in context gathering
 	// Pass empty string instead of cliConfig.APIKey to force environment variable lookup
 	// This ensures each provider uses its own API key from the appropriate environment variable
 	referenceClientLLM, err := apiService.InitLLMClient(ctx, "", cliConfig.ModelNames[0], cliConfig.APIEndpoint)
+	if err != nil {
+		// Use the first fallback of the model that can be initialized instead
+		for _, fallback := range orchestrator.ModelChain(apiService, cliConfig, cliConfig.ModelNames[0])[1:] {
+			if client, fallbackErr := apiService.InitLLMClient(ctx, "", fallback, cliConfig.APIEndpoint); fallbackErr == nil {
+				logger.Warn("Using fallback model %s for context gathering: %v", fallback, err)
+				referenceClientLLM, err = client, nil
+				break
+			}
+		}
+	}
 	if err != nil {
 		// Check if this is a categorized error to provide better error messages
 		if catErr, ok := llm.IsCategorizedError(err); ok {
@@ -156,7 +196,7 @@ func Execute(
 	contextGathererAdapter := &ContextGathererAdapter{ContextGatherer: contextGatherer}
 	fileWriterAdapter := &FileWriterAdapter{FileWriter: fileWriter}
 
-	orch := orchestratorConstructor(
+	orch = orchestratorConstructor(
 		apiServiceAdapter,
 		contextGathererAdapter,
 		fileWriterAdapter,
@@ -169,6 +209,36 @@ func Execute(
 	return orch.Run(ctx, instructions)
 }
 
+// reportOutput is where the JSON report of a run is written.
+// This can be overridden in tests to capture the report.
+var reportOutput io.Writer = os.Stdout
+
+// writeRunReport writes the report of a run as a single JSON document to reportOutput.
+// The report comes from the orchestrator if it was created and can report on the run;
+// otherwise only the run's identity and outcome are reported.
+func writeRunReport(ctx context.Context, cliConfig *config.CliConfig, orch Orchestrator, runErr error, logger logutil.LoggerInterface) {
+	report := &orchestrator.RunReport{Models: []orchestrator.ModelReport{}}
+	if reporter, ok := orch.(interface {
+		Report() *orchestrator.RunReport
+	}); ok {
+		report = reporter.Report()
+	}
+	if report.CorrelationID == "" {
+		report.CorrelationID = logutil.GetCorrelationID(ctx)
+	}
+	report.OutputDir = cliConfig.OutputDir
+	if cliConfig.OutputDir != "" {
+		report.RunID = filepath.Base(cliConfig.OutputDir)
+	}
+	report.Finish(runErr)
+
+	encoder := json.NewEncoder(reportOutput)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		logger.Error("Failed to write JSON report: %v", err)
+	}
+}
+
 // Note: RunInternal has been removed as part of the refactoring.
 // The Execute function now properly handles dependency injection and can be
 // used directly for testing by providing appropriate mocks.
@@ -0,0 +1,116 @@
+package thinktank
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/auditlog"
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/logutil"
+	"github.com/phrazzld/thinktank/internal/ratelimit"
+	"github.com/phrazzld/thinktank/internal/thinktank/interfaces"
+	"github.com/phrazzld/thinktank/internal/thinktank/orchestrator"
+)
+
+// reportingOrchestrator is a MockOrchestrator that reports on its run
+type reportingOrchestrator struct {
+	MockOrchestrator
+	report *orchestrator.RunReport
+}
+
+func (m *reportingOrchestrator) Report() *orchestrator.RunReport {
+	return m.report
+}
+
+// captureRunReport runs Execute with the JSON output format and decodes the report it writes
+func captureRunReport(t *testing.T, cliConfig *config.CliConfig, orch Orchestrator) (map[string]interface{}, error) {
+	t.Helper()
+
+	originalConstructor := orchestratorConstructor
+	originalOutput := reportOutput
+	defer func() {
+		orchestratorConstructor = originalConstructor
+		reportOutput = originalOutput
+	}()
+	orchestratorConstructor = func(apiService interfaces.APIService, contextGatherer interfaces.ContextGatherer, fileWriter interfaces.FileWriter, auditLogger auditlog.AuditLogger, rateLimiter *ratelimit.RateLimiter, config *config.CliConfig, logger logutil.LoggerInterface) Orchestrator {
+		return orch
+	}
+	var output bytes.Buffer
+	reportOutput = &output
+
+	mockAPIService := NewMockAPIService()
+	mockAPIService.mockLLMClient = NewMockLLMClient("test-model")
+	ctx := logutil.WithCustomCorrelationID(context.Background(), "test-correlation-id")
+	runErr := Execute(ctx, cliConfig, NewMockLogger(), NewMockAuditLogger(), mockAPIService)
+
+	var report map[string]interface{}
+	if err := json.Unmarshal(output.Bytes(), &report); err != nil {
+		t.Fatalf("Expected a single JSON document on stdout, got %q: %v", output.String(), err)
+	}
+	return report, runErr
+}
+
+// TestExecuteJSONReport tests that the run report is written when the JSON output format is selected
+func TestExecuteJSONReport(t *testing.T) {
+	testDir := t.TempDir()
+	instructionsFile := createTestFile(t, filepath.Join(testDir, "instructions.md"), "Test instructions")
+	outputDir := filepath.Join(testDir, "thinktank_run")
+	newConfig := func() *config.CliConfig {
+		return &config.CliConfig{
+			InstructionsFile: instructionsFile,
+			OutputDir:        outputDir,
+			OutputFormat:     config.OutputFormatJSON,
+			ModelNames:       []string{"model-a", "model-b"},
+			Paths:            []string{testDir},
+		}
+	}
+
+	t.Run("partial failure", func(t *testing.T) {
+		orch := &reportingOrchestrator{report: &orchestrator.RunReport{
+			Models: []orchestrator.ModelReport{
+				{Model: "model-a", Status: orchestrator.ReportStatusSuccess, Content: "plan"},
+				{Model: "model-b", Status: orchestrator.ReportStatusFailure,
+					Error: &orchestrator.ErrorReport{Category: "RateLimit", Message: "rate limited"}},
+			},
+		}}
+		orch.runErr = errors.New("some models failed")
+
+		report, err := captureRunReport(t, newConfig(), orch)
+		if err == nil {
+			t.Fatal("Expected the run error to be returned")
+		}
+		if report["run_id"] != "thinktank_run" || report["output_dir"] != outputDir {
+			t.Errorf("Unexpected run identity: %v, %v", report["run_id"], report["output_dir"])
+		}
+		if report["correlation_id"] != "test-correlation-id" {
+			t.Errorf("Expected the correlation ID of the context, got %v", report["correlation_id"])
+		}
+		if report["status"] != orchestrator.ReportStatusPartialFailure || report["error"] != "some models failed" {
+			t.Errorf("Unexpected outcome: %v, %v", report["status"], report["error"])
+		}
+		byCategory, _ := report["errors_by_category"].(map[string]interface{})
+		if failed, _ := byCategory["RateLimit"].([]interface{}); len(failed) != 1 || failed[0] != "model-b" {
+			t.Errorf("Expected model-b to be listed as rate limited, got %v", report["errors_by_category"])
+		}
+	})
+
+	t.Run("failure before the orchestrator runs", func(t *testing.T) {
+		cliConfig := newConfig()
+		cliConfig.InstructionsFile = filepath.Join(testDir, "missing.md")
+
+		report, err := captureRunReport(t, cliConfig, NewMockOrchestrator())
+		if err == nil {
+			t.Fatal("Expected an error for the missing instructions file")
+		}
+		if report["status"] != orchestrator.ReportStatusFailure || report["error"] == "" {
+			t.Errorf("Expected a failed report, got %v", report)
+		}
+		if models, ok := report["models"].([]interface{}); !ok || len(models) != 0 {
+			t.Errorf("Expected an empty list of models, got %v", report["models"])
+		}
+	})
+}
@@ -242,6 +242,10 @@ func (m *MockAPIService) GetModelDefinition(modelName string) (*registry.ModelDe
 	return nil, errors.New("not implemented")
 }
 
+func (m *MockAPIService) GetProviderDefinition(providerName string) (*registry.ProviderDefinition, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (m *MockAPIService) GetModelTokenLimits(modelName string) (contextWindow, maxOutputTokens int32, err error) {
 	return 8192, 8192, nil
 }
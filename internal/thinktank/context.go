@@ -4,6 +4,7 @@ package thinktank
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,6 +14,9 @@ import (
 	"github.com/phrazzld/thinktank/internal/logutil"
 )
 
+// gatherProgressInterval is how often progress is logged while gathering the context
+const gatherProgressInterval = 2 * time.Second
+
 // ContextStats holds information about processed files and context size
 type ContextStats struct {
 	ProcessedFilesCount int
@@ -20,6 +24,19 @@ type ContextStats struct {
 	LineCount           int
 	// TokenCount field removed as part of T032F - token handling refactoring
 	ProcessedFiles []string
+
+	// Token budget packing results
+	EstimatedTokens int      // Estimated tokens of the gathered file content
+	TokenBudget     int      // Token budget the context was packed into (0 if unlimited)
+	OmittedFiles    []string // Files dropped to fit the token budget
+	TruncatedFiles  []string // Files truncated to fit the token budget
+
+	// Size limit and generated file results
+	SkippedFiles       []string // Files skipped as generated or over the total size limit, with the reason
+	SizeTruncatedFiles []string // Files truncated to the file or total size limit
+
+	// Directories skipped as excluded or ignored, for the directory tree of the prompt
+	OmittedDirs []string
 }
 
 // GatherConfig holds parameters needed for gathering context
@@ -31,6 +48,31 @@ type GatherConfig struct {
 	Format       string
 	Verbose      bool
 	LogLevel     logutil.LogLevel
+
+	// Token budget packing configuration
+	TokenBudget  int    // Maximum estimated tokens for the prompt (0 disables packing)
+	PackStrategy string // How to rank files that are not explicitly requested when over budget
+	Instructions string // Instructions used to rank files by relevance
+
+	// PromptOverhead estimates the tokens of the prompt around the gathered files, such as
+	// the instructions and the directory tree, which are taken from TokenBudget before the
+	// files are packed. FileTokens estimates the tokens of a file as rendered in the prompt.
+	// Either may be nil, for no overhead and the default estimate.
+	PromptOverhead func(files []fileutil.FileMeta, omittedDirs []string) int
+	FileTokens     func(file fileutil.FileMeta) int
+
+	// Diff selects changes to review: the context holds their diff and the changed files
+	// (within Paths) instead of every file under Paths
+	Diff fileutil.DiffOptions
+
+	// Imports adds the local Go packages imported by, or importing, the Go files and
+	// packages in Paths
+	Imports fileutil.ImportOptions
+
+	// Size limits in bytes (0 means no limit) and whether to keep generated files
+	MaxFileSize      int64
+	MaxTotalSize     int64
+	IncludeGenerated bool
 }
 
 // ContextGatherer defines the interface for gathering project context
@@ -73,6 +115,12 @@ func (cg *contextGatherer) GatherContext(ctx context.Context, config GatherConfi
 		"exclude_names": config.ExcludeNames,
 		"format":        config.Format,
 	}
+	if config.Diff.Enabled() {
+		inputs["diff"] = config.Diff
+	}
+	if config.Imports.Enabled() {
+		inputs["imports"] = config.Imports
+	}
 	if logErr := cg.auditLogger.LogOp("GatherContext", "InProgress", inputs, nil, nil); logErr != nil {
 		cg.logger.Error("Failed to write audit log: %v", logErr)
 	}
@@ -85,7 +133,11 @@ func (cg *contextGatherer) GatherContext(ctx context.Context, config GatherConfi
 		cg.logger.Debug("Processing files with exclude names: %v", config.ExcludeNames)
 		cg.logger.Debug("Paths being processed: %v", config.Paths)
 	} else {
-		cg.logger.Info("Gathering project context from %d paths...", len(config.Paths))
+		if config.Diff.Enabled() {
+			cg.logger.Info("Gathering the changes shown by %s...", config.Diff.Describe())
+		} else {
+			cg.logger.Info("Gathering project context from %d paths...", len(config.Paths))
+		}
 		cg.logger.Debug("Include filters: %v", config.Include)
 		cg.logger.Debug("Exclude filters: %v", config.Exclude)
 		cg.logger.Debug("Exclude names: %v", config.ExcludeNames)
@@ -94,12 +146,24 @@ func (cg *contextGatherer) GatherContext(ctx context.Context, config GatherConfi
 
 	// Setup file processing configuration
 	fileConfig := fileutil.NewConfig(config.Verbose, config.Include, config.Exclude, config.ExcludeNames, config.Format, cg.logger)
+	fileConfig.MaxFileSize = config.MaxFileSize
+	fileConfig.MaxTotalSize = config.MaxTotalSize
+	fileConfig.IncludeGenerated = config.IncludeGenerated
 
 	// Initialize ContextStats
 	stats := &ContextStats{
 		ProcessedFiles: make([]string, 0),
 	}
 
+	// Report progress on large trees, which take a while to read
+	lastProgress := time.Now()
+	fileConfig.SetProgressReporter(func(read, found int) {
+		if time.Since(lastProgress) >= gatherProgressInterval {
+			lastProgress = time.Now()
+			cg.logger.Info("Gathering context: checked %d of the %d files found so far...", read, found)
+		}
+	})
+
 	// Track processed files for dry run mode
 	if cg.dryRun {
 		collector := func(path string) {
@@ -108,8 +172,30 @@ func (cg *contextGatherer) GatherContext(ctx context.Context, config GatherConfi
 		fileConfig.SetFileCollector(collector)
 	}
 
-	// Gather project context
-	contextFiles, processedFilesCount, err := fileutil.GatherProjectContext(config.Paths, fileConfig)
+	// Gather the changes to review, or the project context
+	var contextFiles []fileutil.FileMeta
+	var processedFilesCount int
+	var err error
+	explicitPaths := config.Paths
+	if config.Diff.Enabled() {
+		var changes *fileutil.ChangeSet
+		if changes, err = fileutil.GatherChangedFiles(ctx, config.Paths, config.Diff, fileConfig); err == nil {
+			contextFiles, processedFilesCount = changes.Files, changes.Processed
+			explicitPaths = changes.ChangedPaths // Keep the diff and the changed files when packing
+		}
+	} else {
+		paths := config.Paths
+		if config.Imports.Enabled() {
+			var imported []string
+			if imported, err = fileutil.ExpandGoImports(ctx, config.Paths, config.Imports, fileConfig); err == nil {
+				cg.logger.Info("Adding %d Go files from connected packages", len(imported))
+				paths = append(slices.Clone(paths), imported...)
+			}
+		}
+		if err == nil {
+			contextFiles, processedFilesCount, err = fileutil.GatherProjectContext(ctx, paths, fileConfig)
+		}
+	}
 
 	// Calculate duration in milliseconds
 	gatherDurationMs := time.Since(gatherStartTime).Milliseconds()
@@ -134,6 +220,8 @@ func (cg *contextGatherer) GatherContext(ctx context.Context, config GatherConfi
 
 	// Set the processed files count in stats
 	stats.ProcessedFilesCount = processedFilesCount
+	cg.reportLimits(fileConfig, stats)
+	stats.OmittedDirs = fileConfig.OmittedDirs()
 
 	// Log warning if no files were processed
 	if processedFilesCount == 0 {
@@ -141,6 +229,29 @@ func (cg *contextGatherer) GatherContext(ctx context.Context, config GatherConfi
 		return contextFiles, stats, nil
 	}
 
+	// Fit the gathered files into the token budget, if one is set, less the rest of the
+	// prompt. The rest is estimated with every file gathered, so that it is not
+	// underestimated when some are left out.
+	budget := config.TokenBudget
+	if budget > 0 && config.PromptOverhead != nil {
+		budget -= config.PromptOverhead(contextFiles, stats.OmittedDirs)
+		if budget <= 0 {
+			cg.logger.Warn("The instructions alone exceed the input token budget of %d tokens; no files will fit", config.TokenBudget)
+			budget = 1
+		}
+	}
+	packed := fileutil.PackFiles(contextFiles, fileutil.PackOptions{
+		Budget:        budget,
+		Strategy:      config.PackStrategy,
+		ExplicitPaths: explicitPaths,
+		Instructions:  config.Instructions,
+		FileTokens:    config.FileTokens,
+	})
+	contextFiles = packed.Files
+	stats.EstimatedTokens = packed.EstimatedTokens
+	stats.TokenBudget = budget
+	cg.reportPacking(packed, stats)
+
 	// Create a combined string for calculating basic statistics
 	var combinedContent strings.Builder
 	for _, file := range contextFiles {
@@ -185,7 +296,19 @@ func (cg *contextGatherer) GatherContext(ctx context.Context, config GatherConfi
 		"char_count":            stats.CharCount,
 		"line_count":            stats.LineCount,
 		// token_count field removed as part of T032F - token handling refactoring
-		"files_count": len(contextFiles),
+		"files_count":      len(contextFiles),
+		"estimated_tokens": stats.EstimatedTokens,
+	}
+	if len(stats.OmittedFiles) > 0 || len(stats.TruncatedFiles) > 0 {
+		outputs["token_budget"] = stats.TokenBudget
+		outputs["omitted_files"] = stats.OmittedFiles
+		outputs["truncated_files"] = stats.TruncatedFiles
+	}
+	if len(stats.SkippedFiles) > 0 {
+		outputs["skipped_files"] = stats.SkippedFiles
+	}
+	if len(stats.SizeTruncatedFiles) > 0 {
+		outputs["size_truncated_files"] = stats.SizeTruncatedFiles
 	}
 	if logErr := cg.auditLogger.LogOp("GatherContext", "Success", inputs, outputs, nil); logErr != nil {
 		cg.logger.Error("Failed to write audit log: %v", logErr)
@@ -194,6 +317,45 @@ func (cg *contextGatherer) GatherContext(ctx context.Context, config GatherConfi
 	return contextFiles, stats, nil
 }
 
+// reportLimits records and logs the files that were skipped as generated or over the total
+// size limit, and those truncated to the size limits
+func (cg *contextGatherer) reportLimits(fileConfig *fileutil.Config, stats *ContextStats) {
+	skipped := fileConfig.SkippedFiles()
+	truncated := fileConfig.TruncatedFiles()
+	if len(skipped) == 0 && len(truncated) == 0 {
+		return
+	}
+
+	cg.logger.Info("Skipped %d generated or over-limit files, truncated %d files to the size limits",
+		len(skipped), len(truncated))
+	for _, file := range skipped {
+		stats.SkippedFiles = append(stats.SkippedFiles, file.String())
+		cg.logger.Debug("  Skipped: %s", file)
+	}
+	for _, path := range truncated {
+		stats.SizeTruncatedFiles = append(stats.SizeTruncatedFiles, path)
+		cg.logger.Debug("  Truncated: %s", path)
+	}
+}
+
+// reportPacking records and logs the files that were omitted or truncated to fit the token budget
+func (cg *contextGatherer) reportPacking(packed fileutil.PackResult, stats *ContextStats) {
+	if len(packed.Omitted) == 0 && len(packed.Truncated) == 0 {
+		return
+	}
+
+	cg.logger.Warn("Context exceeds the token budget of %d tokens: omitted %d files, truncated %d files",
+		stats.TokenBudget, len(packed.Omitted), len(packed.Truncated))
+	for _, omitted := range packed.Omitted {
+		stats.OmittedFiles = append(stats.OmittedFiles, omitted.Path)
+		cg.logger.Info("  Omitted: %s (~%d tokens)", omitted.Path, omitted.Tokens)
+	}
+	for _, path := range packed.Truncated {
+		stats.TruncatedFiles = append(stats.TruncatedFiles, path)
+		cg.logger.Info("  Truncated: %s", path)
+	}
+}
+
 // DisplayDryRunInfo shows detailed information for dry run mode
 func (cg *contextGatherer) DisplayDryRunInfo(ctx context.Context, stats *ContextStats) error {
 	cg.logger.Info("Files that would be included in context:")
@@ -209,6 +371,36 @@ func (cg *contextGatherer) DisplayDryRunInfo(ctx context.Context, stats *Context
 	cg.logger.Info("  Files: %d", stats.ProcessedFilesCount)
 	cg.logger.Info("  Lines: %d", stats.LineCount)
 	cg.logger.Info("  Characters: %d", stats.CharCount)
+	cg.logger.Info("  Estimated tokens: %d", stats.EstimatedTokens)
+
+	if stats.TokenBudget > 0 {
+		cg.logger.Info("Token budget: %d tokens", stats.TokenBudget)
+		if len(stats.OmittedFiles) > 0 {
+			cg.logger.Info("Files that would be omitted to fit the token budget:")
+			for i, file := range stats.OmittedFiles {
+				cg.logger.Info("  %d. %s", i+1, file)
+			}
+		}
+		if len(stats.TruncatedFiles) > 0 {
+			cg.logger.Info("Files that would be truncated to fit the token budget:")
+			for i, file := range stats.TruncatedFiles {
+				cg.logger.Info("  %d. %s", i+1, file)
+			}
+		}
+	}
+
+	if len(stats.SkippedFiles) > 0 {
+		cg.logger.Info("Files that would be skipped:")
+		for i, file := range stats.SkippedFiles {
+			cg.logger.Info("  %d. %s", i+1, file)
+		}
+	}
+	if len(stats.SizeTruncatedFiles) > 0 {
+		cg.logger.Info("Files that would be truncated to the size limits:")
+		for i, file := range stats.SizeTruncatedFiles {
+			cg.logger.Info("  %d. %s", i+1, file)
+		}
+	}
 
 	// Token counting and limit comparison code removed as part of T032F - token handling refactoring
 
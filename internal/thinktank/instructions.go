@@ -0,0 +1,47 @@
+package thinktank
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/phrazzld/thinktank/internal/config"
+)
+
+// instructionsInput is read for instructions given as --instructions -
+var instructionsInput io.Reader = os.Stdin
+
+// readInstructions reads the instructions from each instructions file (or stdin), in
+// order, followed by the inline --prompt. When there are several sources, they are
+// joined with a blank line between them.
+func readInstructions(cliConfig *config.CliConfig) (string, error) {
+	var parts []string
+	for _, path := range cliConfig.InstructionSources() {
+		var content []byte
+		var err error
+		if path == config.StdinInstructions {
+			content, err = io.ReadAll(instructionsInput)
+			if err != nil {
+				return "", fmt.Errorf("failed to read instructions from stdin: %v", err)
+			}
+		} else {
+			content, err = os.ReadFile(path)
+			if err != nil {
+				return "", fmt.Errorf("failed to read instructions file %s: %v", path, err)
+			}
+		}
+		parts = append(parts, string(content))
+	}
+	if cliConfig.Prompt != "" {
+		parts = append(parts, cliConfig.Prompt)
+	}
+
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	for i, part := range parts {
+		parts[i] = strings.TrimRight(part, "\n")
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
@@ -0,0 +1,80 @@
+package thinktank
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/config"
+)
+
+func TestReadInstructions(t *testing.T) {
+	testDir := t.TempDir()
+	styleFile := createTestFile(t, filepath.Join(testDir, "style.md"), "Be brief.\n")
+	taskFile := createTestFile(t, filepath.Join(testDir, "task.md"), "Add caching.\n")
+
+	originalInput := instructionsInput
+	defer func() { instructionsInput = originalInput }()
+
+	tests := []struct {
+		name     string
+		config   *config.CliConfig
+		stdin    string
+		expected string
+	}{
+		{
+			name:     "Single file is read unchanged",
+			config:   &config.CliConfig{InstructionsFile: taskFile},
+			expected: "Add caching.\n",
+		},
+		{
+			name:     "Files are concatenated in order",
+			config:   &config.CliConfig{InstructionsFiles: []string{styleFile, taskFile}},
+			expected: "Be brief.\n\nAdd caching.",
+		},
+		{
+			name:     "Stdin",
+			config:   &config.CliConfig{InstructionsFiles: []string{config.StdinInstructions}},
+			stdin:    "Generated task",
+			expected: "Generated task",
+		},
+		{
+			name:     "Inline prompt",
+			config:   &config.CliConfig{Prompt: "Review the code"},
+			expected: "Review the code",
+		},
+		{
+			name:     "Files, stdin and inline prompt",
+			config:   &config.CliConfig{InstructionsFiles: []string{styleFile, config.StdinInstructions}, Prompt: "Focus on errors"},
+			stdin:    "Generated task\n",
+			expected: "Be brief.\n\nGenerated task\n\nFocus on errors",
+		},
+		{
+			name:     "No instructions (dry run)",
+			config:   &config.CliConfig{DryRun: true},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instructionsInput = strings.NewReader(tt.stdin)
+
+			instructions, err := readInstructions(tt.config)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if instructions != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, instructions)
+			}
+		})
+	}
+
+	t.Run("Missing file", func(t *testing.T) {
+		missing := filepath.Join(testDir, "missing.md")
+		_, err := readInstructions(&config.CliConfig{InstructionsFiles: []string{styleFile, missing}})
+		if err == nil || !strings.Contains(err.Error(), "failed to read instructions file "+missing) {
+			t.Errorf("Expected an error naming the missing file, got: %v", err)
+		}
+	})
+}
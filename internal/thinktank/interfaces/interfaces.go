@@ -55,6 +55,14 @@ type APIService interface {
 	//   - An error if retrieval fails
 	GetModelDefinition(modelName string) (*registry.ModelDefinition, error)
 
+	// GetProviderDefinition retrieves a provider definition from the registry
+	// Parameters:
+	//   - providerName: The name of the provider to get the definition for
+	// Returns:
+	//   - The provider definition
+	//   - An error if retrieval fails
+	GetProviderDefinition(providerName string) (*registry.ProviderDefinition, error)
+
 	// GetModelTokenLimits retrieves token limits from the registry for a given model
 	// Parameters:
 	//   - modelName: The name of the model to get token limits for
@@ -101,6 +109,19 @@ type ContextStats struct {
 	LineCount           int
 	// TokenCount field removed as part of T032F - token handling refactoring
 	ProcessedFiles []string
+
+	// Token budget packing results
+	EstimatedTokens int      // Estimated tokens of the gathered file content
+	TokenBudget     int      // Token budget the context was packed into (0 if unlimited)
+	OmittedFiles    []string // Files dropped to fit the token budget
+	TruncatedFiles  []string // Files truncated to fit the token budget
+
+	// Size limit and generated file results
+	SkippedFiles       []string // Files skipped as generated or over the total size limit, with the reason
+	SizeTruncatedFiles []string // Files truncated to the file or total size limit
+
+	// Directories skipped as excluded or ignored, for the directory tree of the prompt
+	OmittedDirs []string
 }
 
 // GatherConfig holds parameters needed for gathering context
@@ -112,6 +133,31 @@ type GatherConfig struct {
 	Format       string
 	Verbose      bool
 	LogLevel     logutil.LogLevel
+
+	// Token budget packing configuration
+	TokenBudget  int    // Maximum estimated tokens for the prompt (0 disables packing)
+	PackStrategy string // How to rank files that are not explicitly requested when over budget
+	Instructions string // Instructions used to rank files by relevance
+
+	// PromptOverhead estimates the tokens of the prompt around the gathered files, such as
+	// the instructions and the directory tree, which are taken from TokenBudget before the
+	// files are packed. FileTokens estimates the tokens of a file as rendered in the prompt.
+	// Either may be nil, for no overhead and the default estimate.
+	PromptOverhead func(files []fileutil.FileMeta, omittedDirs []string) int
+	FileTokens     func(file fileutil.FileMeta) int
+
+	// Diff selects changes to review: the context holds their diff and the changed files
+	// (within Paths) instead of every file under Paths
+	Diff fileutil.DiffOptions
+
+	// Imports adds the local Go packages imported by, or importing, the Go files and
+	// packages in Paths
+	Imports fileutil.ImportOptions
+
+	// Size limits in bytes (0 means no limit) and whether to keep generated files
+	MaxFileSize      int64
+	MaxTotalSize     int64
+	IncludeGenerated bool
 }
 
 // ContextGatherer defines the interface for gathering project context
@@ -0,0 +1,20 @@
+package modelproc
+
+import (
+	"time"
+
+	"github.com/phrazzld/thinktank/internal/llm"
+)
+
+// Generation records the outcome of a single model call made by Process, so that it
+// can be reported once the run is complete
+type Generation struct {
+	ModelName    string        // Name of the model that was called
+	FinishReason string        // Why generation stopped, as reported by the provider
+	Truncated    bool          // Whether the response was truncated
+	SafetyInfo   []llm.Safety  // Safety ratings reported by the provider
+	Duration     time.Duration // Time spent generating the response
+	Usage        *ModelUsage   // Token usage and estimated cost (nil if not reported)
+	OutputFile   string        // File the output was saved to (empty if it was not saved)
+	Err          error         // Error that ended the call (nil if it succeeded)
+}
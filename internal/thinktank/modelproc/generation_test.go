@@ -0,0 +1,131 @@
+package modelproc_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/thinktank/modelproc"
+)
+
+func TestProcess_RecordsGeneration(t *testing.T) {
+	cfg := config.NewDefaultCliConfig()
+	cfg.APIKey = "test-api-key"
+	cfg.OutputDir = t.TempDir()
+
+	t.Run("successful call", func(t *testing.T) {
+		mockAPI := &mockAPIService{
+			initLLMClientFunc: func(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+				return &mockLLMClient{
+					generateContentFunc: func(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+						return &llm.ProviderResult{
+							Content:      "Generated content",
+							FinishReason: "length",
+							Truncated:    true,
+							SafetyInfo:   []llm.Safety{{Category: "HARASSMENT", Score: 0.1}},
+							Usage:        &llm.TokenUsage{PromptTokens: 20, CompletionTokens: 10, TotalTokens: 30},
+						}, nil
+					},
+				}, nil
+			},
+		}
+		processor := modelproc.NewProcessor(mockAPI, &mockFileWriter{}, &mockAuditLogger{}, newNoOpLogger(), cfg)
+		if processor.LastGeneration() != nil {
+			t.Fatal("Expected no generation before Process is called")
+		}
+
+		if _, err := processor.Process(context.Background(), "test/model", "Test prompt"); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		generation := processor.LastGeneration()
+		if generation == nil {
+			t.Fatal("Expected the generation to be recorded")
+		}
+		if generation.ModelName != "test/model" || generation.FinishReason != "length" || !generation.Truncated {
+			t.Errorf("Unexpected generation: %+v", generation)
+		}
+		if len(generation.SafetyInfo) != 1 || generation.SafetyInfo[0].Category != "HARASSMENT" {
+			t.Errorf("Expected the safety ratings to be recorded, got %+v", generation.SafetyInfo)
+		}
+		if generation.Usage == nil || generation.Usage.TotalTokens != 30 {
+			t.Errorf("Expected the token usage to be recorded, got %+v", generation.Usage)
+		}
+		if generation.OutputFile != filepath.Join(cfg.OutputDir, "test-model.md") {
+			t.Errorf("Unexpected output file: %s", generation.OutputFile)
+		}
+		if generation.Err != nil {
+			t.Errorf("Expected no error, got: %v", generation.Err)
+		}
+	})
+
+	t.Run("failed call", func(t *testing.T) {
+		mockAPI := &mockAPIService{
+			initLLMClientFunc: func(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+				return &mockLLMClient{
+					generateContentFunc: func(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+						return nil, llm.New("test", "", 429, "rate limited", "", nil, llm.CategoryRateLimit)
+					},
+				}, nil
+			},
+		}
+		processor := modelproc.NewProcessor(mockAPI, &mockFileWriter{}, &mockAuditLogger{}, newNoOpLogger(), cfg)
+
+		_, err := processor.Process(context.Background(), "test-model", "Test prompt")
+		if err == nil {
+			t.Fatal("Expected an error")
+		}
+
+		generation := processor.LastGeneration()
+		if generation == nil || !errors.Is(generation.Err, modelproc.ErrModelProcessingFailed) || !llm.IsRateLimit(generation.Err) {
+			t.Errorf("Expected the failure to be recorded, got %+v", generation)
+		}
+		if generation != nil && generation.OutputFile != "" {
+			t.Errorf("Expected no output file for a failed call, got %s", generation.OutputFile)
+		}
+	})
+}
+
+func TestProcess_SystemPrompt(t *testing.T) {
+	// newProcessor returns a processor for a model whose definition has a system prompt,
+	// and records the system prompt sent with the request
+	newProcessor := func(cfg *config.CliConfig, sent *string) *modelproc.ModelProcessor {
+		mockAPI := &mockAPIService{
+			getModelParametersFunc: func(modelName string) (map[string]interface{}, error) {
+				return map[string]interface{}{llm.SystemPromptParam: "You are a model-specific reviewer."}, nil
+			},
+			initLLMClientFunc: func(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+				return &mockLLMClient{
+					generateContentFunc: func(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+						*sent = llm.SystemPrompt(params)
+						return &llm.ProviderResult{Content: "Generated content"}, nil
+					},
+				}, nil
+			},
+		}
+		return modelproc.NewProcessor(mockAPI, &mockFileWriter{}, &mockAuditLogger{}, newNoOpLogger(), cfg)
+	}
+
+	cfg := config.NewDefaultCliConfig()
+	cfg.APIKey = "test-api-key"
+	cfg.OutputDir = t.TempDir()
+
+	var sent string
+	if _, err := newProcessor(cfg, &sent).Process(context.Background(), "test-model", "Test prompt"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if sent != "You are a model-specific reviewer." {
+		t.Errorf("Expected the system prompt of the model, got %q", sent)
+	}
+
+	cfg.SystemPrompt = "You are a careful reviewer."
+	if _, err := newProcessor(cfg, &sent).Process(context.Background(), "test-model", "Test prompt"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if sent != "You are a careful reviewer." {
+		t.Errorf("Expected --system-prompt to override the system prompt of the model, got %q", sent)
+	}
+}
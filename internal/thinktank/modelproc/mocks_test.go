@@ -157,3 +157,26 @@ func (m *mockAuditLogger) Close() error {
 	}
 	return nil
 }
+
+// mockStreamingLLMClient extends mockLLMClient with streaming support
+type mockStreamingLLMClient struct {
+	mockLLMClient
+	generateContentStreamFunc func(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error)
+}
+
+func (m *mockStreamingLLMClient) GenerateContentStream(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+	if m.generateContentStreamFunc != nil {
+		return m.generateContentStreamFunc(ctx, prompt, params)
+	}
+	return llm.StreamFromResult(&llm.ProviderResult{Content: "mock streamed content"}, nil), nil
+}
+
+// chunkStream returns a closed, buffered stream containing the given chunks
+func chunkStream(chunks ...llm.StreamChunk) <-chan llm.StreamChunk {
+	stream := make(chan llm.StreamChunk, len(chunks))
+	for _, chunk := range chunks {
+		stream <- chunk
+	}
+	close(stream)
+	return stream
+}
@@ -6,6 +6,8 @@ package modelproc
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -17,6 +19,9 @@ import (
 	"github.com/phrazzld/thinktank/internal/registry"
 )
 
+// partialOutputSuffix is appended to the output file name while a response is being streamed
+const partialOutputSuffix = ".partial"
+
 // APIService defines the interface for API-related operations
 type APIService interface {
 	// InitLLMClient initializes and returns a provider-agnostic LLM client
@@ -59,6 +64,15 @@ type ModelProcessor struct {
 	auditLogger auditlog.AuditLogger
 	logger      logutil.LoggerInterface
 	config      *config.CliConfig
+
+	// streamOutput receives streamed content deltas as they arrive (optional)
+	streamOutput io.Writer
+
+	// usageTracker accumulates token usage across processors in a run (optional)
+	usageTracker *UsageTracker
+
+	// lastGeneration is the outcome of the most recent call made by Process
+	lastGeneration *Generation
 }
 
 // NewProcessor creates a new ModelProcessor with all required dependencies.
@@ -80,6 +94,24 @@ func NewProcessor(
 	}
 }
 
+// SetStreamOutput sets a writer that receives response text as it is streamed from the model.
+// It only has an effect when streaming is enabled in the configuration.
+func (p *ModelProcessor) SetStreamOutput(w io.Writer) {
+	p.streamOutput = w
+}
+
+// SetUsageTracker sets a tracker that records the token usage of each successful generation,
+// so that usage and cost can be summarized across all models in a run.
+func (p *ModelProcessor) SetUsageTracker(t *UsageTracker) {
+	p.usageTracker = t
+}
+
+// LastGeneration returns the outcome of the most recent call made by Process, including
+// calls that failed, or nil if Process has not been called.
+func (p *ModelProcessor) LastGeneration() *Generation {
+	return p.lastGeneration
+}
+
 // Process handles the entire model processing workflow for a single model.
 // It implements the logic from the previous processModel/processModelConcurrently functions,
 // including initialization, token checking, generation, response processing, and output saving.
@@ -89,16 +121,21 @@ func NewProcessor(
 // Returns:
 //   - The generated content as a string, which can be used for synthesis
 //   - Any error encountered during processing
-func (p *ModelProcessor) Process(ctx context.Context, modelName string, stitchedPrompt string) (string, error) {
+func (p *ModelProcessor) Process(ctx context.Context, modelName string, stitchedPrompt string) (output string, err error) {
 	p.logger.Info("Processing model: %s", modelName)
 
+	// Record the outcome of the call, whether or not it succeeds
+	generation := &Generation{ModelName: modelName}
+	p.lastGeneration = generation
+	defer func() { generation.Err = err }()
+
 	// 1. Initialize model-specific LLM client
 	llmClient, err := p.apiService.InitLLMClient(ctx, p.config.APIKey, modelName, p.config.APIEndpoint)
 	if err != nil {
 		// Use the APIService interface for consistent error detail extraction
 		errorDetails := p.apiService.GetErrorDetails(err)
 		p.logger.Error("Error creating LLM client for model %s: %s", modelName, errorDetails)
-		return "", fmt.Errorf("%w: failed to initialize API client for model %s: %v", ErrModelInitializationFailed, modelName, err)
+		return "", fmt.Errorf("%w: failed to initialize API client for model %s: %w", ErrModelInitializationFailed, modelName, err)
 	}
 
 	// BUGFIX: Ensure llmClient is not nil before attempting to close it
@@ -135,6 +172,14 @@ func (p *ModelProcessor) Process(ctx context.Context, modelName string, stitched
 		params = make(map[string]interface{})
 	}
 
+	// The --system-prompt overrides the system prompt of the model
+	if p.config.SystemPrompt != "" {
+		if params == nil {
+			params = make(map[string]interface{})
+		}
+		params[llm.SystemPromptParam] = p.config.SystemPrompt
+	}
+
 	// Log parameters being used (at debug level)
 	if len(params) > 0 {
 		p.logger.Debug("Using model parameters for %s:", modelName)
@@ -143,11 +188,19 @@ func (p *ModelProcessor) Process(ctx context.Context, modelName string, stitched
 		}
 	}
 
-	// Generate content with parameters
-	result, err := llmClient.GenerateContent(ctx, stitchedPrompt, params)
+	// Sanitize model name for use in filename and construct output file path
+	outputFilePath := filepath.Join(p.config.OutputDir, SanitizeFilename(modelName)+".md")
+
+	// Generate content with parameters. Streamed text goes to a separate file that only
+	// becomes the output once the response has been accepted.
+	streamFilePath := outputFilePath + partialOutputSuffix
+	defer p.discardStreamedOutput(streamFilePath)
+	result, err := p.generateContent(ctx, llmClient, modelName, stitchedPrompt, params, streamFilePath)
 
 	// Calculate duration in milliseconds
-	generateDurationMs := time.Since(generateStartTime).Milliseconds()
+	generateDuration := time.Since(generateStartTime)
+	generateDurationMs := generateDuration.Milliseconds()
+	generation.Duration = generateDuration
 
 	if err != nil {
 		p.logger.Error("Generation failed for model %s", modelName)
@@ -164,16 +217,40 @@ func (p *ModelProcessor) Process(ctx context.Context, modelName string, stitched
 			p.logger.Error("Failed to write audit log: %v", logErr)
 		}
 
-		return "", fmt.Errorf("%w: output generation failed for model %s: %v", ErrModelProcessingFailed, modelName, err)
+		return "", fmt.Errorf("%w: output generation failed for model %s: %w", ErrModelProcessingFailed, modelName, err)
 	}
 
 	// Log successful content generation
+	generation.FinishReason = result.FinishReason
+	generation.Truncated = result.Truncated
+	generation.SafetyInfo = result.SafetyInfo
 	inputs["duration_ms"] = generateDurationMs
 	outputs := map[string]interface{}{
 		"finish_reason":      result.FinishReason,
 		"has_safety_ratings": len(result.SafetyInfo) > 0,
 	}
-	if logErr := p.auditLogger.LogOp("GenerateContent", "Success", inputs, outputs, nil); logErr != nil {
+	entry := auditlog.AuditEntry{
+		Operation:  "GenerateContent",
+		Status:     "Success",
+		DurationMs: &generateDurationMs,
+		Inputs:     inputs,
+		Outputs:    outputs,
+		Message:    "GenerateContent completed successfully",
+	}
+	if result.Usage != nil {
+		usage := NewModelUsage(modelName, "GenerateContent", result.Usage, p.modelDefinition(modelName))
+		generation.Usage = &usage
+		entry.TokenCounts = usage.TokenCounts()
+		if usage.CostKnown {
+			outputs["estimated_cost_usd"] = usage.Cost
+		}
+		if p.usageTracker != nil {
+			p.usageTracker.Record(usage)
+		}
+		p.logger.Debug("Model %s used %d prompt tokens and %d completion tokens",
+			modelName, usage.PromptTokens, usage.CompletionTokens)
+	}
+	if logErr := p.auditLogger.Log(entry); logErr != nil {
 		p.logger.Error("Failed to write audit log: %v", logErr)
 	}
 
@@ -222,21 +299,92 @@ func (p *ModelProcessor) Process(ctx context.Context, modelName string, stitched
 	p.logger.Info("Output generated successfully with model %s (content length: %d characters)",
 		modelName, contentLength)
 
-	// 5. Sanitize model name for use in filename
-	sanitizedModelName := SanitizeFilename(modelName)
-
-	// 6. Construct output file path
-	outputFilePath := filepath.Join(p.config.OutputDir, sanitizedModelName+".md")
-
-	// 7. Save the output to file
+	// 5. Save the output to file
+	if err := p.acceptStreamedOutput(streamFilePath, outputFilePath); err != nil {
+		return "", fmt.Errorf("%w: failed to save output for model %s: %v", ErrOutputWriteFailed, modelName, err)
+	}
 	if err := p.saveOutputToFile(outputFilePath, generatedOutput); err != nil {
 		return "", fmt.Errorf("%w: failed to save output for model %s: %v", ErrOutputWriteFailed, modelName, err)
 	}
+	generation.OutputFile = outputFilePath
 
 	p.logger.Info("Successfully processed model: %s", modelName)
 	return generatedOutput, nil
 }
 
+// generateContent calls the model, streaming the response when streaming is enabled and the
+// client supports it. Streamed text is appended to streamFilePath as it arrives (and echoed to
+// the stream output, if set), so partial results can be inspected while generation is running.
+// The complete response is still returned so that it can be validated and saved as usual.
+func (p *ModelProcessor) generateContent(ctx context.Context, client llm.LLMClient, modelName, prompt string, params map[string]interface{}, streamFilePath string) (*llm.ProviderResult, error) {
+	streamingClient, ok := client.(llm.StreamingLLMClient)
+	if !p.config.Stream || !ok {
+		return client.GenerateContent(ctx, prompt, params)
+	}
+
+	p.logger.Debug("Streaming output from model %s to %s", modelName, streamFilePath)
+	stream, err := streamingClient.GenerateContentStream(ctx, prompt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	partialFile, err := os.OpenFile(streamFilePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, p.config.FilePermissions)
+	if err != nil {
+		p.logger.Warn("Cannot write streamed output for model %s to %s: %v", modelName, streamFilePath, err)
+		partialFile = nil
+	}
+
+	result, err := llm.CollectStream(ctx, stream, func(delta string) {
+		if partialFile != nil {
+			if _, writeErr := partialFile.WriteString(delta); writeErr != nil {
+				p.logger.Warn("Failed to write streamed output for model %s: %v", modelName, writeErr)
+				_ = partialFile.Close()
+				partialFile = nil
+			}
+		}
+		if p.streamOutput != nil {
+			_, _ = io.WriteString(p.streamOutput, delta)
+		}
+	})
+
+	if partialFile != nil {
+		if closeErr := partialFile.Close(); closeErr != nil {
+			p.logger.Warn("Failed to close streamed output file %s: %v", streamFilePath, closeErr)
+		}
+	}
+
+	return result, err
+}
+
+// acceptStreamedOutput moves the streamed output of an accepted response to the output file,
+// which is then overwritten with the processed response. It does nothing when nothing was streamed.
+func (p *ModelProcessor) acceptStreamedOutput(streamFilePath, outputFilePath string) error {
+	err := os.Rename(streamFilePath, outputFilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// discardStreamedOutput removes the streamed output of a response that failed or was rejected,
+// so that an interrupted or blocked response is never mistaken for the model's output.
+func (p *ModelProcessor) discardStreamedOutput(streamFilePath string) {
+	if err := os.Remove(streamFilePath); err != nil && !os.IsNotExist(err) {
+		p.logger.Warn("Failed to remove streamed output file %s: %v", streamFilePath, err)
+	}
+}
+
+// modelDefinition looks up the registry definition of a model for cost estimation.
+// It returns nil if the definition is unavailable.
+func (p *ModelProcessor) modelDefinition(modelName string) *registry.ModelDefinition {
+	modelDef, err := p.apiService.GetModelDefinition(modelName)
+	if err != nil {
+		p.logger.Debug("No model definition for %s, cost will not be estimated: %v", modelName, err)
+		return nil
+	}
+	return modelDef
+}
+
 // SanitizeFilename replaces characters that are not valid in filenames
 // with safe alternatives to ensure filenames are valid across different operating systems.
 func SanitizeFilename(filename string) string {
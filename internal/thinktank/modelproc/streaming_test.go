@@ -0,0 +1,242 @@
+package modelproc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/thinktank/modelproc"
+)
+
+// newStreamingConfig returns a config with streaming enabled and a temporary output directory
+func newStreamingConfig(t *testing.T, stream bool) *config.CliConfig {
+	cfg := config.NewDefaultCliConfig()
+	cfg.APIKey = "test-api-key"
+	cfg.OutputDir = t.TempDir()
+	cfg.Stream = stream
+	return cfg
+}
+
+func TestProcess_StreamingWritesIncrementally(t *testing.T) {
+	client := &mockStreamingLLMClient{
+		generateContentStreamFunc: func(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+			return chunkStream(
+				llm.StreamChunk{Content: "# Plan\n"},
+				llm.StreamChunk{Content: "Step one"},
+				llm.StreamChunk{FinishReason: "stop"},
+			), nil
+		},
+	}
+	client.generateContentFunc = func(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+		t.Error("GenerateContent should not be called when streaming is enabled")
+		return nil, errors.New("unexpected call")
+	}
+
+	mockAPI := &mockAPIService{
+		initLLMClientFunc: func(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+			return client, nil
+		},
+	}
+
+	cfg := newStreamingConfig(t, true)
+	expectedPath := filepath.Join(cfg.OutputDir, "test-model.md")
+
+	var savedContent string
+	mockWriter := &mockFileWriter{
+		saveToFileFunc: func(content, outputFile string) error {
+			// By the time the final save happens, the streamed text must have been moved to the output file
+			partial, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Errorf("Expected streamed output file to exist: %v", err)
+			}
+			if string(partial) != "# Plan\nStep one" {
+				t.Errorf("Expected streamed file content %q, got %q", "# Plan\nStep one", string(partial))
+			}
+			if outputFile != expectedPath {
+				t.Errorf("Expected output path %s, got %s", expectedPath, outputFile)
+			}
+			savedContent = content
+			return nil
+		},
+	}
+
+	var terminal bytes.Buffer
+	processor := modelproc.NewProcessor(mockAPI, mockWriter, &mockAuditLogger{}, newNoOpLogger(), cfg)
+	processor.SetStreamOutput(&terminal)
+
+	output, err := processor.Process(context.Background(), "test-model", "Test prompt")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if output != "# Plan\nStep one" {
+		t.Errorf("Expected output %q, got %q", "# Plan\nStep one", output)
+	}
+	if savedContent != output {
+		t.Errorf("Expected final save to contain the full output, got %q", savedContent)
+	}
+	if terminal.String() != "# Plan\nStep one" {
+		t.Errorf("Expected streamed terminal output %q, got %q", "# Plan\nStep one", terminal.String())
+	}
+	if _, statErr := os.Stat(expectedPath + ".partial"); !os.IsNotExist(statErr) {
+		t.Errorf("Expected no partial output file after success, got stat error: %v", statErr)
+	}
+}
+
+func TestProcess_StreamingDisabledUsesGenerateContent(t *testing.T) {
+	client := &mockStreamingLLMClient{
+		generateContentStreamFunc: func(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+			t.Error("GenerateContentStream should not be called when streaming is disabled")
+			return nil, errors.New("unexpected call")
+		},
+	}
+	client.generateContentFunc = func(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+		return &llm.ProviderResult{Content: "Blocking content"}, nil
+	}
+
+	mockAPI := &mockAPIService{
+		initLLMClientFunc: func(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+			return client, nil
+		},
+	}
+
+	processor := modelproc.NewProcessor(mockAPI, &mockFileWriter{}, &mockAuditLogger{}, newNoOpLogger(), newStreamingConfig(t, false))
+
+	output, err := processor.Process(context.Background(), "test-model", "Test prompt")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if output != "Blocking content" {
+		t.Errorf("Expected output %q, got %q", "Blocking content", output)
+	}
+}
+
+func TestProcess_StreamingErrorRemovesPartialOutput(t *testing.T) {
+	streamErr := errors.New("connection reset")
+	client := &mockStreamingLLMClient{
+		generateContentStreamFunc: func(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+			return chunkStream(
+				llm.StreamChunk{Content: "Partial answer"},
+				llm.StreamChunk{Err: streamErr},
+			), nil
+		},
+	}
+
+	mockAPI := &mockAPIService{
+		initLLMClientFunc: func(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+			return client, nil
+		},
+	}
+
+	var auditStatuses []string
+	mockAudit := &mockAuditLogger{
+		logOpFunc: func(operation, status string, inputs map[string]interface{}, outputs map[string]interface{}, err error) error {
+			if operation == "GenerateContent" {
+				auditStatuses = append(auditStatuses, status)
+			}
+			return nil
+		},
+	}
+
+	cfg := newStreamingConfig(t, true)
+	processor := modelproc.NewProcessor(mockAPI, &mockFileWriter{}, mockAudit, newNoOpLogger(), cfg)
+
+	output, err := processor.Process(context.Background(), "test-model", "Test prompt")
+	if err == nil {
+		t.Fatal("Expected error from interrupted stream, got nil")
+	}
+	if !errors.Is(err, modelproc.ErrModelProcessingFailed) {
+		t.Errorf("Expected error to be ErrModelProcessingFailed, got '%v'", err)
+	}
+	if output != "" {
+		t.Errorf("Expected empty output on error, got: %s", output)
+	}
+
+	// An interrupted response must not be left where --resume would take it for output
+	assertNoOutputFiles(t, cfg.OutputDir)
+
+	if len(auditStatuses) != 2 || auditStatuses[1] != "Failure" {
+		t.Errorf("Expected GenerateContent audit statuses [InProgress Failure], got %v", auditStatuses)
+	}
+}
+
+func TestProcess_StreamingStartErrorLeavesNoFile(t *testing.T) {
+	client := &mockStreamingLLMClient{
+		generateContentStreamFunc: func(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+			return nil, errors.New("authentication failed")
+		},
+	}
+
+	mockAPI := &mockAPIService{
+		initLLMClientFunc: func(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+			return client, nil
+		},
+	}
+
+	cfg := newStreamingConfig(t, true)
+	processor := modelproc.NewProcessor(mockAPI, &mockFileWriter{}, &mockAuditLogger{}, newNoOpLogger(), cfg)
+
+	_, err := processor.Process(context.Background(), "test-model", "Test prompt")
+	if !errors.Is(err, modelproc.ErrModelProcessingFailed) {
+		t.Errorf("Expected error to be ErrModelProcessingFailed, got '%v'", err)
+	}
+
+	assertNoOutputFiles(t, cfg.OutputDir)
+}
+
+func TestProcess_StreamingSafetyBlockRemovesPartialOutput(t *testing.T) {
+	client := &mockStreamingLLMClient{
+		generateContentStreamFunc: func(ctx context.Context, prompt string, params map[string]interface{}) (<-chan llm.StreamChunk, error) {
+			return chunkStream(
+				llm.StreamChunk{Content: "Blocked answer"},
+				llm.StreamChunk{FinishReason: "safety"},
+			), nil
+		},
+	}
+
+	mockAPI := &mockAPIService{
+		initLLMClientFunc: func(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+			return client, nil
+		},
+		processLLMResponseFunc: func(result *llm.ProviderResult) (string, error) {
+			return "", errors.New("content blocked by safety filters")
+		},
+		isSafetyBlockedErrorFunc: func(err error) bool {
+			return true
+		},
+	}
+
+	mockWriter := &mockFileWriter{
+		saveToFileFunc: func(content, outputFile string) error {
+			t.Error("SaveToFile should not be called for a blocked response")
+			return nil
+		},
+	}
+
+	cfg := newStreamingConfig(t, true)
+	processor := modelproc.NewProcessor(mockAPI, mockWriter, &mockAuditLogger{}, newNoOpLogger(), cfg)
+
+	_, err := processor.Process(context.Background(), "test-model", "Test prompt")
+	if !errors.Is(err, modelproc.ErrContentFiltered) {
+		t.Errorf("Expected error to be ErrContentFiltered, got '%v'", err)
+	}
+
+	assertNoOutputFiles(t, cfg.OutputDir)
+}
+
+// assertNoOutputFiles fails the test if anything was written to the output directory
+func assertNoOutputFiles(t *testing.T, outputDir string) {
+	t.Helper()
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		t.Fatalf("Failed to read output directory: %v", err)
+	}
+	for _, entry := range entries {
+		t.Errorf("Expected no output files, found %s", filepath.Join(outputDir, entry.Name()))
+	}
+}
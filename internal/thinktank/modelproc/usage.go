@@ -0,0 +1,98 @@
+package modelproc
+
+import (
+	"sync"
+
+	"github.com/phrazzld/thinktank/internal/auditlog"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/registry"
+)
+
+// ModelUsage records the token usage and estimated cost of a single model call
+type ModelUsage struct {
+	ModelName        string  // Name of the model that was called
+	Operation        string  // Audit operation that made the call, e.g., "GenerateContent"
+	PromptTokens     int32   // Tokens sent to the model
+	CompletionTokens int32   // Tokens generated by the model
+	TotalTokens      int32   // Total tokens reported by the provider
+	Cost             float64 // Estimated cost in US dollars (only meaningful if CostKnown)
+	CostKnown        bool    // Whether pricing was available for the model
+}
+
+// NewModelUsage builds a ModelUsage from provider-reported token counts.
+// The cost is estimated from the model definition's pricing when available;
+// modelDef may be nil, in which case the cost is reported as unknown.
+func NewModelUsage(modelName, operation string, usage *llm.TokenUsage, modelDef *registry.ModelDefinition) ModelUsage {
+	record := ModelUsage{
+		ModelName: modelName,
+		Operation: operation,
+	}
+	if usage == nil {
+		return record
+	}
+
+	record.PromptTokens = usage.PromptTokens
+	record.CompletionTokens = usage.CompletionTokens
+	record.TotalTokens = usage.TotalTokens
+	if record.TotalTokens == 0 {
+		record.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	if modelDef != nil {
+		record.Cost, record.CostKnown = modelDef.EstimateCost(usage.PromptTokens, usage.CompletionTokens)
+	}
+	return record
+}
+
+// TokenCounts converts the usage into the token count structure used by audit entries
+func (u ModelUsage) TokenCounts() *auditlog.TokenCountInfo {
+	return &auditlog.TokenCountInfo{
+		PromptTokens: u.PromptTokens,
+		OutputTokens: u.CompletionTokens,
+		TotalTokens:  u.TotalTokens,
+	}
+}
+
+// UsageTracker accumulates token usage across all model calls in a run.
+// It is safe for concurrent use by multiple processors.
+type UsageTracker struct {
+	mu      sync.Mutex
+	records []ModelUsage
+}
+
+// NewUsageTracker creates an empty UsageTracker
+func NewUsageTracker() *UsageTracker {
+	return &UsageTracker{}
+}
+
+// Record adds the usage of a single model call to the tracker
+func (t *UsageTracker) Record(usage ModelUsage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.records = append(t.records, usage)
+}
+
+// Records returns a copy of all recorded usage, in the order it was recorded
+func (t *UsageTracker) Records() []ModelUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	records := make([]ModelUsage, len(t.records))
+	copy(records, t.records)
+	return records
+}
+
+// Totals sums the recorded usage. The total cost is only marked as known
+// if pricing was available for every recorded call.
+func (t *UsageTracker) Totals() ModelUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	totals := ModelUsage{CostKnown: len(t.records) > 0}
+	for _, record := range t.records {
+		totals.PromptTokens += record.PromptTokens
+		totals.CompletionTokens += record.CompletionTokens
+		totals.TotalTokens += record.TotalTokens
+		totals.Cost += record.Cost
+		totals.CostKnown = totals.CostKnown && record.CostKnown
+	}
+	return totals
+}
@@ -0,0 +1,200 @@
+package modelproc_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/auditlog"
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/registry"
+	"github.com/phrazzld/thinktank/internal/thinktank/modelproc"
+)
+
+func TestNewModelUsage(t *testing.T) {
+	usage := &llm.TokenUsage{PromptTokens: 1_000_000, CompletionTokens: 500_000, TotalTokens: 1_500_000}
+
+	t.Run("with pricing", func(t *testing.T) {
+		modelDef := &registry.ModelDefinition{
+			Name:    "priced-model",
+			Pricing: &registry.PricingDefinition{InputPerMillion: 2.0, OutputPerMillion: 8.0},
+		}
+		record := modelproc.NewModelUsage("priced-model", "GenerateContent", usage, modelDef)
+		if !record.CostKnown {
+			t.Fatal("Expected cost to be known")
+		}
+		if record.Cost != 6.0 {
+			t.Errorf("Expected cost 6.0, got %f", record.Cost)
+		}
+		if record.TotalTokens != 1_500_000 {
+			t.Errorf("Expected total tokens 1500000, got %d", record.TotalTokens)
+		}
+	})
+
+	t.Run("without pricing", func(t *testing.T) {
+		record := modelproc.NewModelUsage("unpriced-model", "GenerateContent", usage, &registry.ModelDefinition{})
+		if record.CostKnown {
+			t.Error("Expected cost to be unknown for a model without pricing")
+		}
+	})
+
+	t.Run("missing total is derived", func(t *testing.T) {
+		record := modelproc.NewModelUsage("model", "GenerateContent", &llm.TokenUsage{PromptTokens: 10, CompletionTokens: 5}, nil)
+		if record.TotalTokens != 15 {
+			t.Errorf("Expected derived total of 15, got %d", record.TotalTokens)
+		}
+		if record.CostKnown {
+			t.Error("Expected cost to be unknown without a model definition")
+		}
+	})
+}
+
+func TestUsageTracker(t *testing.T) {
+	tracker := modelproc.NewUsageTracker()
+
+	if totals := tracker.Totals(); totals.CostKnown || totals.TotalTokens != 0 {
+		t.Errorf("Expected empty totals, got %+v", totals)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tracker.Record(modelproc.ModelUsage{ModelName: "model", PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12, Cost: 0.5, CostKnown: true})
+		}()
+	}
+	wg.Wait()
+
+	totals := tracker.Totals()
+	if len(tracker.Records()) != 10 {
+		t.Errorf("Expected 10 records, got %d", len(tracker.Records()))
+	}
+	if totals.PromptTokens != 100 || totals.CompletionTokens != 20 || totals.TotalTokens != 120 {
+		t.Errorf("Unexpected token totals: %+v", totals)
+	}
+	if !totals.CostKnown || totals.Cost != 5.0 {
+		t.Errorf("Expected known total cost of 5.0, got %+v", totals)
+	}
+
+	// A single call without pricing makes the total cost unknown
+	tracker.Record(modelproc.ModelUsage{ModelName: "unpriced", PromptTokens: 1, TotalTokens: 1})
+	if tracker.Totals().CostKnown {
+		t.Error("Expected total cost to be unknown once an unpriced call is recorded")
+	}
+}
+
+func TestProcess_RecordsUsage(t *testing.T) {
+	mockAPI := &mockAPIService{
+		initLLMClientFunc: func(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+			return &mockLLMClient{
+				generateContentFunc: func(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+					return &llm.ProviderResult{
+						Content:      "Generated content",
+						FinishReason: "stop",
+						Usage:        &llm.TokenUsage{PromptTokens: 2000, CompletionTokens: 1000, TotalTokens: 3000},
+					}, nil
+				},
+			}, nil
+		},
+		getModelDefinitionFunc: func(modelName string) (*registry.ModelDefinition, error) {
+			return &registry.ModelDefinition{
+				Name:    modelName,
+				Pricing: &registry.PricingDefinition{InputPerMillion: 1.0, OutputPerMillion: 4.0},
+			}, nil
+		},
+	}
+
+	var successEntry *auditlog.AuditEntry
+	mockAudit := &mockAuditLogger{
+		logFunc: func(entry auditlog.AuditEntry) error {
+			if entry.Operation == "GenerateContent" && entry.Status == "Success" {
+				successEntry = &entry
+			}
+			return nil
+		},
+	}
+
+	cfg := config.NewDefaultCliConfig()
+	cfg.APIKey = "test-api-key"
+	cfg.OutputDir = t.TempDir()
+
+	tracker := modelproc.NewUsageTracker()
+	processor := modelproc.NewProcessor(mockAPI, &mockFileWriter{}, mockAudit, newNoOpLogger(), cfg)
+	processor.SetUsageTracker(tracker)
+
+	if _, err := processor.Process(context.Background(), "test-model", "Test prompt"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if successEntry == nil {
+		t.Fatal("Expected a GenerateContent success audit entry")
+	}
+	if successEntry.TokenCounts == nil {
+		t.Fatal("Expected token counts in the audit entry")
+	}
+	if successEntry.TokenCounts.PromptTokens != 2000 || successEntry.TokenCounts.OutputTokens != 1000 || successEntry.TokenCounts.TotalTokens != 3000 {
+		t.Errorf("Unexpected token counts: %+v", successEntry.TokenCounts)
+	}
+	cost, ok := successEntry.Outputs["estimated_cost_usd"].(float64)
+	if !ok {
+		t.Fatalf("Expected estimated_cost_usd in outputs, got %v", successEntry.Outputs)
+	}
+	if cost != 0.006 {
+		t.Errorf("Expected estimated cost 0.006, got %f", cost)
+	}
+	if successEntry.DurationMs == nil {
+		t.Error("Expected duration in the audit entry")
+	}
+
+	records := tracker.Records()
+	if len(records) != 1 || records[0].ModelName != "test-model" || records[0].TotalTokens != 3000 {
+		t.Errorf("Expected one tracked usage record for test-model, got %+v", records)
+	}
+}
+
+func TestProcess_UsageWithoutDefinition(t *testing.T) {
+	mockAPI := &mockAPIService{
+		initLLMClientFunc: func(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+			return &mockLLMClient{
+				generateContentFunc: func(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+					return &llm.ProviderResult{
+						Content: "Generated content",
+						Usage:   &llm.TokenUsage{PromptTokens: 20, CompletionTokens: 10, TotalTokens: 30},
+					}, nil
+				},
+			}, nil
+		},
+		getModelDefinitionFunc: func(modelName string) (*registry.ModelDefinition, error) {
+			return nil, errors.New("model not found")
+		},
+	}
+
+	var successEntry *auditlog.AuditEntry
+	mockAudit := &mockAuditLogger{
+		logFunc: func(entry auditlog.AuditEntry) error {
+			if entry.Operation == "GenerateContent" && entry.Status == "Success" {
+				successEntry = &entry
+			}
+			return nil
+		},
+	}
+
+	cfg := config.NewDefaultCliConfig()
+	cfg.APIKey = "test-api-key"
+	cfg.OutputDir = t.TempDir()
+
+	processor := modelproc.NewProcessor(mockAPI, &mockFileWriter{}, mockAudit, newNoOpLogger(), cfg)
+	if _, err := processor.Process(context.Background(), "test-model", "Test prompt"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if successEntry == nil || successEntry.TokenCounts == nil {
+		t.Fatal("Expected token counts even when the model definition is unavailable")
+	}
+	if _, ok := successEntry.Outputs["estimated_cost_usd"]; ok {
+		t.Error("Expected no estimated cost when pricing is unavailable")
+	}
+}
@@ -0,0 +1,160 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/phrazzld/thinktank/internal/auditlog"
+	"github.com/phrazzld/thinktank/internal/logutil"
+	"github.com/phrazzld/thinktank/internal/thinktank/interfaces"
+	"github.com/phrazzld/thinktank/internal/thinktank/modelproc"
+	"github.com/phrazzld/thinktank/internal/thinktank/prompt"
+)
+
+// CritiqueRefineService reviews model outputs with a critic model and revises them
+// to address the critique
+type CritiqueRefineService interface {
+	// CritiqueAndRefine critiques a model's output against the original instructions and
+	// revises it to address the critique, for up to the configured number of rounds.
+	// It returns the completed rounds, including those completed before an error.
+	CritiqueAndRefine(ctx context.Context, instructions string, modelName string, output string) ([]CritiqueRound, error)
+}
+
+// CritiqueRound holds the artifacts of one critique-and-refine round
+type CritiqueRound struct {
+	CriticModel string // Model that wrote the critique
+	Critique    string // Review of the output from the previous round
+	RefineModel string // Model that revised the output (empty if it was not revised)
+	Refined     string // Revised output (empty if the critic found nothing to change)
+}
+
+// DefaultCritiqueRefineService implements the CritiqueRefineService interface.
+// Each critique and revision is a call through a synthesis service with its own
+// prompt, so both share the synthesis error handling, usage tracking and audit logging.
+type DefaultCritiqueRefineService struct {
+	apiService  interfaces.APIService
+	auditLogger auditlog.AuditLogger
+	logger      logutil.LoggerInterface
+	criticModel string // Model that critiques outputs; empty for the model that produced the output
+	refineModel string // Model that revises outputs; empty for the model that produced the output
+	rounds      int    // Maximum number of rounds
+
+	// usageTracker records the token usage of the critique and refine calls (optional)
+	usageTracker *modelproc.UsageTracker
+}
+
+// NewCritiqueRefineService creates a new CritiqueRefineService instance with the specified dependencies
+func NewCritiqueRefineService(
+	apiService interfaces.APIService,
+	auditLogger auditlog.AuditLogger,
+	logger logutil.LoggerInterface,
+	criticModel string,
+	refineModel string,
+	rounds int,
+) CritiqueRefineService {
+	return &DefaultCritiqueRefineService{
+		apiService:  apiService,
+		auditLogger: auditLogger,
+		logger:      logger,
+		criticModel: criticModel,
+		refineModel: refineModel,
+		rounds:      rounds,
+	}
+}
+
+// SetUsageTracker sets a tracker that records the token usage of the critique and
+// refine calls, so that they are included in the run's usage summary.
+func (s *DefaultCritiqueRefineService) SetUsageTracker(t *modelproc.UsageTracker) {
+	s.usageTracker = t
+}
+
+// CritiqueAndRefine critiques and revises a model's output. Each round critiques the
+// output of the previous round, and the rounds stop early when the critic replies
+// that no changes are needed.
+func (s *DefaultCritiqueRefineService) CritiqueAndRefine(
+	ctx context.Context,
+	instructions string,
+	modelName string,
+	output string,
+) ([]CritiqueRound, error) {
+	contextLogger := s.logger.WithContext(ctx)
+
+	criticModel := s.criticModel
+	if criticModel == "" {
+		criticModel = modelName
+	}
+	refineModel := s.refineModel
+	if refineModel == "" {
+		refineModel = modelName
+	}
+
+	var rounds []CritiqueRound
+	current := output
+	for round := 1; round <= s.rounds; round++ {
+		// Both prompts label the output with the model that produced the original
+		outputs := map[string]string{modelName: current}
+
+		contextLogger.InfoContext(ctx, "Critiquing output of model %s with model %s (round %d of %d)",
+			modelName, criticModel, round, s.rounds)
+		critic := s.newStage(criticModel, "Critique", prompt.StitchCritiquePrompt)
+		critique, err := critic.SynthesizeResults(ctx, instructions, outputs)
+		if err != nil {
+			return rounds, fmt.Errorf("critique of model %s output by %s (round %d): %w", modelName, criticModel, round, err)
+		}
+		result := CritiqueRound{CriticModel: criticModel, Critique: critique}
+
+		if noChangesNeeded(critique) {
+			contextLogger.InfoContext(ctx, "Model %s found no changes needed in the output of model %s", criticModel, modelName)
+			return append(rounds, result), nil
+		}
+
+		contextLogger.InfoContext(ctx, "Revising output of model %s with model %s (round %d of %d)",
+			modelName, refineModel, round, s.rounds)
+		refiner := s.newStage(refineModel, "Refine", func(instructions string, modelOutputs map[string]string) string {
+			return prompt.StitchRefinePrompt(instructions, modelOutputs, critique)
+		})
+		refined, err := refiner.SynthesizeResults(ctx, instructions, outputs)
+		if err != nil {
+			// Keep the critique, so that it is saved even though the revision failed
+			return append(rounds, result), fmt.Errorf("revision of model %s output by %s (round %d): %w", modelName, refineModel, round, err)
+		}
+
+		result.RefineModel = refineModel
+		result.Refined = refined
+		rounds = append(rounds, result)
+		current = refined
+	}
+
+	return rounds, nil
+}
+
+// newStage creates a synthesis service that sends the prompt built by buildPrompt to
+// the given model, recording its audit log entries under the given operation prefix
+func (s *DefaultCritiqueRefineService) newStage(
+	modelName string,
+	operation string,
+	buildPrompt func(instructions string, modelOutputs map[string]string) string,
+) *DefaultSynthesisService {
+	stage := NewSynthesisService(s.apiService, s.auditLogger, s.logger, modelName).(*DefaultSynthesisService)
+	stage.operation = operation
+	stage.buildPrompt = buildPrompt
+	stage.usageTracker = s.usageTracker
+	return stage
+}
+
+// noChangesNeeded reports whether a critique says the output needs no revision
+func noChangesNeeded(critique string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(critique)), prompt.NoChangesNeeded)
+}
+
+// finalOutput returns the output after the critique-and-refine rounds: the last
+// revision, or the original output if it was never revised
+func finalOutput(output string, rounds []CritiqueRound) string {
+	for i := len(rounds) - 1; i >= 0; i-- {
+		if rounds[i].Refined != "" {
+			return rounds[i].Refined
+		}
+	}
+	return output
+}
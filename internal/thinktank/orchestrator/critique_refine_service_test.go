@@ -0,0 +1,191 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/thinktank/modelproc"
+	"github.com/phrazzld/thinktank/internal/thinktank/prompt"
+)
+
+// critiqueRefineResponder answers critique prompts with the critiques in order, and
+// refine prompts with a revision numbered by the round
+func critiqueRefineResponder(critiques ...string) func(modelName, promptText string) (string, error) {
+	critiqueCount := 0
+	refineCount := 0
+	return func(modelName, promptText string) (string, error) {
+		if strings.Contains(promptText, "<critique>") {
+			refineCount++
+			return strings.Repeat("revised ", refineCount) + "by " + modelName, nil
+		}
+		critique := critiques[critiqueCount]
+		critiqueCount++
+		return critique, nil
+	}
+}
+
+func TestCritiqueAndRefine(t *testing.T) {
+	t.Run("critic and refiner default to the producing model", func(t *testing.T) {
+		apiService := &scriptedAPIService{respond: critiqueRefineResponder("Missing error handling")}
+		service := NewCritiqueRefineService(apiService, NewMockAuditLogger(), &MockLogger{}, "", "", 1)
+
+		rounds, err := service.CritiqueAndRefine(context.Background(), "Design it", "model-a", "first draft")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(rounds) != 1 {
+			t.Fatalf("Expected one round, got %d", len(rounds))
+		}
+		round := rounds[0]
+		if round.CriticModel != "model-a" || round.RefineModel != "model-a" {
+			t.Errorf("Expected model-a to critique and refine, got %s and %s", round.CriticModel, round.RefineModel)
+		}
+		if round.Critique != "Missing error handling" || round.Refined != "revised by model-a" {
+			t.Errorf("Unexpected round: %+v", round)
+		}
+
+		prompts := apiService.promptsFor("model-a")
+		if len(prompts) != 2 {
+			t.Fatalf("Expected a critique and a refine prompt, got %d", len(prompts))
+		}
+		if !strings.Contains(prompts[0], "Please critique the output above") || !strings.Contains(prompts[0], "first draft") {
+			t.Errorf("Unexpected critique prompt:\n%s", prompts[0])
+		}
+		if !strings.Contains(prompts[1], "<critique>\nMissing error handling\n</critique>") || !strings.Contains(prompts[1], "first draft") {
+			t.Errorf("Unexpected refine prompt:\n%s", prompts[1])
+		}
+	})
+
+	t.Run("designated models and multiple rounds", func(t *testing.T) {
+		apiService := &scriptedAPIService{respond: critiqueRefineResponder("Too vague", "Still missing tests", "unused")}
+		auditLogger := NewMockAuditLogger()
+		service := NewCritiqueRefineService(apiService, auditLogger, &MockLogger{}, "critic", "editor", 2)
+
+		rounds, err := service.CritiqueAndRefine(context.Background(), "Design it", "model-a", "first draft")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(rounds) != 2 {
+			t.Fatalf("Expected two rounds, got %d", len(rounds))
+		}
+		if rounds[1].Critique != "Still missing tests" || rounds[1].Refined != "revised revised by editor" {
+			t.Errorf("Unexpected second round: %+v", rounds[1])
+		}
+		if finalOutput("first draft", rounds) != "revised revised by editor" {
+			t.Errorf("Expected the last revision to be the final output")
+		}
+
+		// The second critique reviews the first revision
+		criticPrompts := apiService.promptsFor("critic")
+		if len(criticPrompts) != 2 || !strings.Contains(criticPrompts[1], "revised by editor") {
+			t.Errorf("Expected the second critique to review the first revision, got %v", criticPrompts)
+		}
+		if len(apiService.promptsFor("model-a")) != 0 {
+			t.Error("Expected the producing model not to be called")
+		}
+
+		var operations []string
+		for _, call := range auditLogger.LogCalls {
+			if strings.HasSuffix(call.Operation, "Start") {
+				operations = append(operations, call.Operation)
+			}
+		}
+		if strings.Join(operations, " ") != "CritiqueStart RefineStart CritiqueStart RefineStart" {
+			t.Errorf("Unexpected audit operations: %v", operations)
+		}
+	})
+
+	t.Run("stops when no changes are needed", func(t *testing.T) {
+		apiService := &scriptedAPIService{respond: critiqueRefineResponder("Looks good", "No changes needed.")}
+		service := NewCritiqueRefineService(apiService, NewMockAuditLogger(), &MockLogger{}, "", "", 3)
+
+		rounds, err := service.CritiqueAndRefine(context.Background(), "Design it", "model-a", "first draft")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(rounds) != 2 || rounds[1].Refined != "" {
+			t.Fatalf("Expected the second round to stop without a revision, got %+v", rounds)
+		}
+		if got := len(apiService.promptsFor("model-a")); got != 3 {
+			t.Errorf("Expected 3 calls (critique, refine, critique), got %d", got)
+		}
+		if finalOutput("first draft", rounds) != "revised by model-a" {
+			t.Error("Expected the first revision to be the final output")
+		}
+		if finalOutput("first draft", rounds[1:]) != "first draft" {
+			t.Error("Expected the original output when nothing was revised")
+		}
+	})
+
+	t.Run("failed revision keeps the critique", func(t *testing.T) {
+		apiService := &scriptedAPIService{respond: func(modelName, promptText string) (string, error) {
+			if strings.Contains(promptText, "<critique>") {
+				return "", llm.New("test", "", 500, "server error", "", nil, llm.CategoryServer)
+			}
+			return "Too vague", nil
+		}}
+		service := NewCritiqueRefineService(apiService, NewMockAuditLogger(), &MockLogger{}, "", "", 1)
+
+		rounds, err := service.CritiqueAndRefine(context.Background(), "Design it", "model-a", "first draft")
+		if !errors.Is(err, ErrSynthesisFailed) || !strings.Contains(err.Error(), "revision of model model-a output") {
+			t.Errorf("Expected a revision error, got: %v", err)
+		}
+		if len(rounds) != 1 || rounds[0].Critique != "Too vague" || rounds[0].Refined != "" {
+			t.Errorf("Expected the critique to be kept, got %+v", rounds)
+		}
+	})
+}
+
+// TestCritiqueAndRefineUsage tests that critique and refine calls are tracked
+func TestCritiqueAndRefineUsage(t *testing.T) {
+	apiService := &usageReportingAPIService{scriptedAPIService: scriptedAPIService{respond: critiqueRefineResponder("Too vague")}}
+	service := NewCritiqueRefineService(apiService, NewMockAuditLogger(), &MockLogger{}, "", "", 1).(*DefaultCritiqueRefineService)
+	tracker := modelproc.NewUsageTracker()
+	service.SetUsageTracker(tracker)
+
+	if _, err := service.CritiqueAndRefine(context.Background(), "Design it", "model-a", "draft"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var operations []string
+	for _, record := range tracker.Records() {
+		operations = append(operations, record.Operation)
+	}
+	if strings.Join(operations, " ") != "CritiqueAPICall RefineAPICall" {
+		t.Errorf("Expected usage for the critique and refine calls, got %v", operations)
+	}
+}
+
+// usageReportingAPIService is a scriptedAPIService whose responses report token usage
+type usageReportingAPIService struct {
+	scriptedAPIService
+}
+
+func (m *usageReportingAPIService) InitLLMClient(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+	return &usageReportingLLMClient{scriptedLLMClient{service: &m.scriptedAPIService, modelName: modelName}}, nil
+}
+
+type usageReportingLLMClient struct {
+	scriptedLLMClient
+}
+
+func (c *usageReportingLLMClient) GenerateContent(ctx context.Context, promptText string, params map[string]interface{}) (*llm.ProviderResult, error) {
+	result, err := c.scriptedLLMClient.GenerateContent(ctx, promptText, params)
+	if result != nil {
+		result.Usage = &llm.TokenUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}
+	}
+	return result, err
+}
+
+// TestNoChangesNeeded tests recognizing critiques that ask for no revision
+func TestNoChangesNeeded(t *testing.T) {
+	if !noChangesNeeded(prompt.NoChangesNeeded) || !noChangesNeeded("  no changes needed - the plan is complete") {
+		t.Error("Expected replies starting with the marker to need no changes")
+	}
+	if noChangesNeeded("Some changes needed. " + prompt.NoChangesNeeded) {
+		t.Error("Expected the marker to count only at the start of the reply")
+	}
+}
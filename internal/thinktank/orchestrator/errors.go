@@ -34,4 +34,28 @@ var (
 
 	// ErrModelProcessingCancelled is returned when model processing is cancelled by context.
 	ErrModelProcessingCancelled = errors.New("model processing cancelled")
+
+	// ErrPromptExceedsContextWindow is returned when the prompt is too large for a model's
+	// context window, so the model is skipped instead of making a request that cannot succeed.
+	ErrPromptExceedsContextWindow = errors.New("prompt exceeds model context window")
+
+	// ErrWorkflowStepFailed is returned when one or more steps of a workflow produced
+	// no output, because all of their models failed or a step they need failed.
+	ErrWorkflowStepFailed = errors.New("workflow step failed")
+
+	// ErrCritiqueRefineFailed is returned when the critique or revision of a model's
+	// output fails in critique-and-refine mode. The model's earlier output is kept.
+	ErrCritiqueRefineFailed = errors.New("critique and refine of model output failed")
+
+	// ErrInvalidInstructionsTemplate is returned when the instructions are a template
+	// that cannot be parsed or executed, e.g. because it uses an undefined variable.
+	ErrInvalidInstructionsTemplate = errors.New("invalid instructions template")
+
+	// ErrResumeFailed is returned when the output directory of a previous run cannot be
+	// resumed, because its manifest cannot be read.
+	ErrResumeFailed = errors.New("failed to resume previous run")
+
+	// ErrInvalidContextFormat is returned when the format of the context files is neither
+	// a built-in format nor a template using {path} and {content}.
+	ErrInvalidContextFormat = errors.New("invalid context format")
 )
@@ -0,0 +1,50 @@
+package orchestrator
+
+import (
+	"errors"
+
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/thinktank/interfaces"
+	"github.com/phrazzld/thinktank/internal/thinktank/modelproc"
+)
+
+// ModelChain returns the models to try, in order, for a requested model: the model
+// itself followed by its fallbacks. Fallbacks given in the configuration (from the
+// --model flag) take precedence over those declared for the model in models.yaml.
+func ModelChain(apiService interfaces.APIService, cfg *config.CliConfig, modelName string) []string {
+	chain := []string{modelName}
+
+	fallbacks := cfg.ModelFallbacks[modelName]
+	if len(fallbacks) == 0 {
+		if modelDef, err := apiService.GetModelDefinition(modelName); err == nil && modelDef != nil {
+			fallbacks = modelDef.Fallbacks
+		}
+	}
+
+	// Skip repeated models, so that a misconfigured chain cannot try a model twice
+	seen := map[string]bool{modelName: true}
+	for _, fallback := range fallbacks {
+		if !seen[fallback] {
+			seen[fallback] = true
+			chain = append(chain, fallback)
+		}
+	}
+	return chain
+}
+
+// shouldFallback reports whether a model failed in a way that another model may not:
+// the client could not be created (e.g., a missing API key or a model that no longer
+// exists), or the provider rejected the request for authentication, an unknown model,
+// exhausted quota or the input size. Other failures, such as content filtering or
+// cancellation, would most likely repeat with the next model.
+func shouldFallback(err error) bool {
+	if errors.Is(err, modelproc.ErrModelInitializationFailed) || errors.Is(err, ErrPromptExceedsContextWindow) {
+		return true
+	}
+	return llm.IsAuth(err) ||
+		llm.IsNotFound(err) ||
+		llm.IsRateLimit(err) ||
+		llm.IsInsufficientCredits(err) ||
+		llm.IsInputLimit(err)
+}
@@ -0,0 +1,205 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/fileutil"
+)
+
+// ManifestFileName is the name of the manifest written to the output directory of a run
+const ManifestFileName = "manifest.json"
+
+// Manifest describes how the outputs in an output directory were produced, so that the
+// directory is self-describing: the configuration of the run (without secrets), hashes
+// of the instructions and context files, and the parameters and outcome of each model.
+type Manifest struct {
+	RunID          string                 `json:"run_id"`
+	CorrelationID  string                 `json:"correlation_id"`
+	StartedAt      time.Time              `json:"started_at"`
+	CompletedAt    time.Time              `json:"completed_at"`
+	Status         string                 `json:"status"`
+	Error          string                 `json:"error,omitempty"`
+	Config         map[string]interface{} `json:"config"`
+	Instructions   ManifestFile           `json:"instructions"`
+	ContextFiles   []ManifestFile         `json:"context_files"`
+	Models         []ManifestModel        `json:"models"`
+	SynthesisModel string                 `json:"synthesis_model,omitempty"`
+	Synthesis      *SynthesisReport       `json:"synthesis,omitempty"`
+	Usage          *TokenReport           `json:"usage,omitempty"`
+}
+
+// ManifestFile identifies an input file by its path and a hash of its content
+type ManifestFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int    `json:"size"` // Size of the content in bytes
+}
+
+// ManifestModel describes the parameters and outcome of a model. The model's output is
+// not repeated in the manifest; it is in the model's output file.
+type ManifestModel struct {
+	ModelReport
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// LoadManifest reads the manifest of a previous run from its output directory
+func LoadManifest(outputDir string) (*Manifest, error) {
+	path := filepath.Join(outputDir, ManifestFileName)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// ModelNames returns the models requested for the run, in the order they were requested.
+// Models run by workflow steps are not included.
+func (m *Manifest) ModelNames() []string {
+	var modelNames []string
+	for _, model := range m.Models {
+		if model.Step == "" {
+			modelNames = append(modelNames, model.Model)
+		}
+	}
+	return modelNames
+}
+
+// ModelFallbacks returns the fallback models configured for the run
+func (m *Manifest) ModelFallbacks() map[string][]string {
+	// The configuration is decoded generically, so the fallbacks are lists of interface{}
+	configured, ok := m.Config["model_fallbacks"].(map[string]interface{})
+	if !ok || len(configured) == 0 {
+		return nil
+	}
+
+	fallbacks := make(map[string][]string, len(configured))
+	for modelName, chain := range configured {
+		models, _ := chain.([]interface{})
+		for _, model := range models {
+			if name, ok := model.(string); ok {
+				fallbacks[modelName] = append(fallbacks[modelName], name)
+			}
+		}
+	}
+	return fallbacks
+}
+
+// buildManifest describes the run from its report, which is finished with the outcome
+// of the run
+func (o *Orchestrator) buildManifest(ctx context.Context, instructions string, contextFiles []fileutil.FileMeta, runErr error) *Manifest {
+	report := o.Report()
+	report.Finish(runErr)
+
+	manifest := &Manifest{
+		RunID:          filepath.Base(o.config.OutputDir),
+		CorrelationID:  report.CorrelationID,
+		StartedAt:      o.startedAt,
+		CompletedAt:    time.Now().UTC(),
+		Status:         report.Status,
+		Error:          report.Error,
+		Config:         manifestConfig(o.config),
+		Instructions:   newManifestFile(strings.Join(o.config.DescribeInstructions(), ", "), instructions),
+		ContextFiles:   make([]ManifestFile, 0, len(contextFiles)),
+		Models:         make([]ManifestModel, 0, len(report.Models)),
+		SynthesisModel: o.config.SynthesisModel,
+		Synthesis:      report.Synthesis,
+		Usage:          report.Usage,
+	}
+	if manifest.Synthesis != nil {
+		synthesis := *manifest.Synthesis
+		synthesis.Content = ""
+		manifest.Synthesis = &synthesis
+	}
+
+	for _, file := range contextFiles {
+		manifest.ContextFiles = append(manifest.ContextFiles, newManifestFile(file.Path, file.Content))
+	}
+
+	for _, model := range report.Models {
+		// The parameters are those of the model that answered, which may be a fallback
+		modelName := model.Model
+		if model.AnsweredBy != "" {
+			modelName = model.AnsweredBy
+		}
+		parameters, err := o.apiService.GetModelParameters(modelName)
+		if err != nil {
+			o.logger.WithContext(ctx).DebugContext(ctx, "No parameters recorded in the manifest for model %s: %v", modelName, err)
+		}
+
+		model.Content = ""
+		manifest.Models = append(manifest.Models, ManifestModel{ModelReport: model, Parameters: parameters})
+	}
+
+	return manifest
+}
+
+// saveManifest writes the manifest of the run to the output directory
+func (o *Orchestrator) saveManifest(ctx context.Context, instructions string, contextFiles []fileutil.FileMeta, runErr error) error {
+	manifest := o.buildManifest(ctx, instructions, contextFiles, runErr)
+	return o.outputWriter.SaveManifest(ctx, manifest, o.config.OutputDir)
+}
+
+// newManifestFile identifies a file by its path and the SHA-256 hash of its content
+func newManifestFile(path, content string) ManifestFile {
+	sum := sha256.Sum256([]byte(content))
+	return ManifestFile{Path: path, SHA256: hex.EncodeToString(sum[:]), Size: len(content)}
+}
+
+// manifestConfig returns the settings of a run that affect its outputs. Secrets such as
+// the API key are left out, so that the manifest can be shared with the outputs.
+func manifestConfig(cfg *config.CliConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"instructions_file":       cfg.InstructionsFile,
+		"system_prompt_file":      cfg.SystemPromptFile,
+		"output_dir":              cfg.OutputDir,
+		"resume_dir":              cfg.ResumeDir,
+		"paths":                   cfg.Paths,
+		"include":                 cfg.Include,
+		"exclude":                 cfg.Exclude,
+		"exclude_names":           cfg.ExcludeNames,
+		"format":                  cfg.Format,
+		"tree":                    cfg.Tree,
+		"pack_strategy":           cfg.PackStrategy,
+		"diff":                    cfg.DiffRange,
+		"staged":                  cfg.DiffStaged,
+		"since":                   cfg.DiffSince,
+		"diff_neighbors":          cfg.DiffNeighbors,
+		"import_depth":            cfg.ImportDepth,
+		"importers":               cfg.Importers,
+		"max_file_size":           cfg.MaxFileSize,
+		"max_total_size":          cfg.MaxTotalSize,
+		"include_generated":       cfg.IncludeGenerated,
+		"model_names":             cfg.ModelNames,
+		"model_fallbacks":         cfg.ModelFallbacks,
+		"synthesis_model":         cfg.SynthesisModel,
+		"workflow_file":           cfg.WorkflowFile,
+		"critique_refine":         cfg.CritiqueRefine,
+		"critic_model":            cfg.CriticModel,
+		"refine_model":            cfg.RefineModel,
+		"critique_rounds":         cfg.CritiqueRounds,
+		"stream":                  cfg.Stream,
+		"no_cache":                cfg.NoCache,
+		"cache_ttl":               cfg.CacheTTL.String(),
+		"retry_attempts":          cfg.RetryMaxAttempts,
+		"retry_backoff":           cfg.RetryInitialBackoff.String(),
+		"retry_max_backoff":       cfg.RetryMaxBackoff.String(),
+		"retry_jitter":            cfg.RetryJitter,
+		"max_concurrent_requests": cfg.MaxConcurrentRequests,
+		"rate_limit_rpm":          cfg.RateLimitRequestsPerMinute,
+		"timeout":                 cfg.Timeout.String(),
+	}
+}
@@ -63,8 +63,9 @@ type LogCall struct {
 // MockAuditLogger provides a mock implementation for testing
 type MockAuditLogger struct {
 	LogCalls []LogCall
-	LogError error      // To simulate logging errors for testing error handling
-	mutex    sync.Mutex // Mutex for thread-safe access to LogCalls
+	Entries  []auditlog.AuditEntry // Entries written directly via Log
+	LogError error                 // To simulate logging errors for testing error handling
+	mutex    sync.Mutex            // Mutex for thread-safe access to LogCalls and Entries
 }
 
 // NewMockAuditLogger creates a new instance of MockAuditLogger
@@ -77,6 +78,10 @@ func NewMockAuditLogger() *MockAuditLogger {
 
 // Log is a mock implementation
 func (m *MockAuditLogger) Log(entry auditlog.AuditEntry) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.Entries = append(m.Entries, entry)
 	return m.LogError
 }
 
@@ -147,6 +152,11 @@ func (m *MockAPIService) GetModelDefinition(modelName string) (*registry.ModelDe
 	return &registry.ModelDefinition{}, nil
 }
 
+// GetProviderDefinition is a mock implementation
+func (m *MockAPIService) GetProviderDefinition(providerName string) (*registry.ProviderDefinition, error) {
+	return &registry.ProviderDefinition{Name: providerName}, nil
+}
+
 // GetModelTokenLimits is a mock implementation
 func (m *MockAPIService) GetModelTokenLimits(modelName string) (int32, int32, error) {
 	return 8000, 1000, nil
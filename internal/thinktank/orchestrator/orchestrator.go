@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -37,6 +38,23 @@ type Orchestrator struct {
 	logger           logutil.LoggerInterface
 	synthesisService SynthesisService
 	outputWriter     OutputWriter
+	usageTracker     *modelproc.UsageTracker
+
+	// critiqueRefineService critiques and revises model outputs (nil unless enabled)
+	critiqueRefineService CritiqueRefineService
+
+	// report summarizes the outcome of the run (see Report)
+	report RunReport
+	// startedAt is when the run started, for the manifest
+	startedAt time.Time
+	// resumed holds the reused results of the run being resumed, by requested model
+	resumed map[string]modelResult
+	// resumedCritiques holds the critique rounds of the reused outputs, by the model that
+	// answered
+	resumedCritiques map[string][]CritiqueRound
+	// stitchOptions controls how the context files are rendered in prompts (see
+	// config.Format and config.Tree)
+	stitchOptions prompt.StitchOptions
 }
 
 // NewOrchestrator creates a new instance of the Orchestrator.
@@ -54,10 +72,30 @@ func NewOrchestrator(
 	// Create the output writer
 	outputWriter := NewOutputWriter(fileWriter, auditLogger, logger)
 
+	// Create a usage tracker shared by all model calls, including synthesis
+	usageTracker := modelproc.NewUsageTracker()
+
 	// Create a synthesis service only if synthesis model is specified
 	var synthesisService SynthesisService
 	if config.SynthesisModel != "" {
 		synthesisService = NewSynthesisService(apiService, auditLogger, logger, config.SynthesisModel)
+		if tracked, ok := synthesisService.(interface {
+			SetUsageTracker(*modelproc.UsageTracker)
+		}); ok {
+			tracked.SetUsageTracker(usageTracker)
+		}
+	}
+
+	// Create a critique-and-refine service only if the mode is enabled
+	var critiqueRefineService CritiqueRefineService
+	if config.CritiqueRefine {
+		critiqueRefineService = NewCritiqueRefineService(apiService, auditLogger, logger,
+			config.CriticModel, config.RefineModel, config.CritiqueRounds)
+		if tracked, ok := critiqueRefineService.(interface {
+			SetUsageTracker(*modelproc.UsageTracker)
+		}); ok {
+			tracked.SetUsageTracker(usageTracker)
+		}
 	}
 
 	return &Orchestrator{
@@ -70,6 +108,9 @@ func NewOrchestrator(
 		logger:           logger,
 		synthesisService: synthesisService,
 		outputWriter:     outputWriter,
+		usageTracker:     usageTracker,
+
+		critiqueRefineService: critiqueRefineService,
 	}
 }
 
@@ -78,54 +119,98 @@ func NewOrchestrator(
 //
 // Workflow:
 // 1. Setup context with correlation ID and validate configuration
-// 2. Gather context from project files
+// 2. Gather context from project files, then execute the instructions as a template (if they are one)
 // 3. Handle dry run mode (if enabled)
 // 4. Build the complete prompt
 // 5. Process models concurrently with error handling
-// 6. Save outputs (either individually or via synthesis)
-// 7. Handle and report any errors
+// 6. Critique and refine each output (if enabled)
+// 7. Save outputs (either individually or via synthesis)
+// 8. Report token usage and estimated cost
+// 9. Write the run manifest (manifest.json) to the output directory
+// 10. Handle and report any errors
+//
+// When a workflow file is configured, steps 4-7 are replaced by running the
+// workflow's steps and saving the outputs of its final steps.
 //
 // Each step is delegated to a specialized helper method, making the workflow
 // clear and maintainable.
 func (o *Orchestrator) Run(ctx context.Context, instructions string) error {
 	// Setup: initialize context and validate configuration
+	o.startedAt = time.Now().UTC()
 	ctx, contextLogger, err := o.setupContext(ctx)
+	o.report.CorrelationID = logutil.GetCorrelationID(ctx)
 	if err != nil {
 		return err
 	}
 
 	// Step 1: Gather file context for the prompt
-	contextFiles, contextStats, err := o.gatherProjectContext(ctx)
+	contextFiles, contextStats, err := o.gatherProjectContext(ctx, instructions)
 	if err != nil {
 		contextLogger.ErrorContext(ctx, "Failed to gather project context: %v", err)
 		return err
 	}
 
+	// Execute the instructions as a template, now that the context files are known
+	if instructions, err = o.renderInstructions(ctx, instructions, contextFiles); err != nil {
+		contextLogger.ErrorContext(ctx, "Failed to render instructions: %v", err)
+		return err
+	}
+
 	// Step 2: Handle dry run mode (short-circuit if enabled)
-	if dryRunExecuted, err := o.runDryRunFlow(ctx, contextStats); err != nil {
+	if dryRunExecuted, err := o.runDryRunFlow(ctx, instructions, contextStats); err != nil {
 		return err
 	} else if dryRunExecuted {
 		return nil
 	}
 
+	// Run the configured workflow instead of the default fan-out and synthesis
+	if o.config.WorkflowFile != "" {
+		processingErr, fileSaveErr := o.runWorkflowFlow(ctx, instructions, contextFiles)
+		o.logUsageSummary(ctx)
+		fileSaveErr = combineErrors(fileSaveErr, o.saveManifest(ctx, instructions, contextFiles, combineErrors(processingErr, fileSaveErr)))
+		return o.handleProcessingOutcome(ctx, processingErr, fileSaveErr, contextLogger)
+	}
+
 	// Step 3: Build the complete prompt
 	stitchedPrompt := o.buildPrompt(instructions, contextFiles)
 
+	// Reuse the outputs of models that completed in the run being resumed, if any
+	if err := o.loadResumedResults(ctx, instructions); err != nil {
+		contextLogger.ErrorContext(ctx, "Failed to resume run: %v", err)
+		return err
+	}
+
 	// Step 4: Process all models and handle errors
 	modelOutputs, processingErr, criticalErr := o.processModelsWithErrorHandling(ctx, stitchedPrompt, contextLogger)
 	if criticalErr != nil {
+		// Describe the failed run, so that the output directory records why it is empty
+		if err := o.saveManifest(ctx, instructions, contextFiles, criticalErr); err != nil {
+			contextLogger.WarnContext(ctx, "Failed to save run manifest: %v", err)
+		}
 		return criticalErr
 	}
 
-	// Step 5: Save outputs (via synthesis or individually)
-	fileSaveErr := o.handleOutputFlow(ctx, instructions, modelOutputs)
+	// Step 5: Critique and refine each output, if enabled
+	critique := o.runCritiqueRefineFlow(ctx, instructions, modelOutputs)
+	processingErr = combineErrors(processingErr, critique.err)
+
+	// Step 6: Save outputs (via synthesis of the final outputs or individually)
+	fileSaveErr := combineErrors(critique.saveErr, o.handleOutputFlow(ctx, instructions, modelOutputs, critique.outputs))
+
+	// Step 7: Report token usage and estimated cost for the run
+	o.logUsageSummary(ctx)
 
-	// Step 6: Final error processing and return
+	// Step 8: Describe the run in a manifest next to the outputs
+	fileSaveErr = combineErrors(fileSaveErr, o.saveManifest(ctx, instructions, contextFiles, combineErrors(processingErr, fileSaveErr)))
+
+	// Step 9: Final error processing and return
 	return o.handleProcessingOutcome(ctx, processingErr, fileSaveErr, contextLogger)
 }
 
 // gatherProjectContext collects relevant files from the project based on configuration.
-func (o *Orchestrator) gatherProjectContext(ctx context.Context) ([]fileutil.FileMeta, *interfaces.ContextStats, error) {
+// The files are packed into the token budget of the selected models, so that the prompt
+// fits within every model's context window.
+func (o *Orchestrator) gatherProjectContext(ctx context.Context, instructions string) ([]fileutil.FileMeta, *interfaces.ContextStats, error) {
 	gatherConfig := interfaces.GatherConfig{
 		Paths:        o.config.Paths,
 		Include:      o.config.Include,
@@ -134,6 +219,22 @@ func (o *Orchestrator) gatherProjectContext(ctx context.Context) ([]fileutil.Fil
 		Format:       o.config.Format,
 		Verbose:      o.config.Verbose,
 		LogLevel:     o.config.LogLevel,
+		TokenBudget:  o.contextTokenBudget(ctx),
+		PackStrategy: o.config.PackStrategy,
+		Instructions: instructions,
+		Diff:         o.config.Diff(),
+		Imports:      o.config.Imports(),
+
+		PromptOverhead: func(files []fileutil.FileMeta, omittedDirs []string) int {
+			return o.promptOverhead(instructions, files, omittedDirs)
+		},
+		FileTokens: func(file fileutil.FileMeta) int {
+			return fileutil.EstimateTokens(o.stitchOptions.FormatFile(file))
+		},
+
+		MaxFileSize:      o.config.MaxFileSize,
+		MaxTotalSize:     o.config.MaxTotalSize,
+		IncludeGenerated: o.config.IncludeGenerated,
 	}
 
 	contextFiles, contextStats, err := o.contextGatherer.GatherContext(ctx, gatherConfig)
@@ -141,15 +242,80 @@ func (o *Orchestrator) gatherProjectContext(ctx context.Context) ([]fileutil.Fil
 		return nil, nil, fmt.Errorf("failed during project context gathering: %w", fmt.Errorf("%w: %v", ErrModelProcessingCancelled, err))
 	}
 
+	// Show the directories left out of the context in the directory tree
+	if contextStats != nil {
+		o.stitchOptions.OmittedDirs = contextStats.OmittedDirs
+	}
+
 	return contextFiles, contextStats, nil
 }
 
-// runDryRunFlow handles the dry run mode by displaying statistics without performing API calls.
+// contextTokenBudget returns the number of tokens available for the prompt: the smallest
+// input budget among the selected models. The rest of the prompt (see promptOverhead) is
+// taken from it to pack the context files. Models without known token limits don't
+// constrain the budget; if no model's limits are known, 0 is returned and the context is
+// not packed.
+func (o *Orchestrator) contextTokenBudget(ctx context.Context) int {
+	// Get logger with context
+	contextLogger := o.logger.WithContext(ctx)
+
+	budget := 0
+	limitingModel := ""
+	for _, modelName := range o.config.ModelNames {
+		contextWindow, maxOutputTokens, err := o.apiService.GetModelTokenLimits(modelName)
+		if err != nil {
+			contextLogger.DebugContext(ctx, "Token limits unavailable for model %s, not constraining context: %v", modelName, err)
+			continue
+		}
+
+		modelBudget := inputTokenBudget(contextWindow, maxOutputTokens)
+		contextLogger.DebugContext(ctx, "Model %s accepts up to %d input tokens", modelName, modelBudget)
+		if modelBudget > 0 && (budget == 0 || modelBudget < budget) {
+			budget = modelBudget
+			limitingModel = modelName
+		}
+	}
+	if budget == 0 {
+		return 0
+	}
+
+	contextLogger.DebugContext(ctx, "Prompt token budget: %d tokens (limited by model %s)", budget, limitingModel)
+	return budget
+}
+
+// promptOverhead estimates the tokens of the prompt around the context files, as it is
+// built from the files: the instructions, rendered with the files when they are a
+// template, the prompt framing and the directory tree of the files
+func (o *Orchestrator) promptOverhead(instructions string, contextFiles []fileutil.FileMeta, omittedDirs []string) int {
+	if o.config.TemplateInstructions {
+		// A template that fails to render is reported once the files are gathered
+		if rendered, err := prompt.RenderInstructions(instructions, o.templateData(contextFiles), o.config.TemplateDir); err == nil {
+			instructions = rendered
+		}
+	}
+
+	overhead := fileutil.EstimateTokens(prompt.StitchPrompt(instructions, nil, o.stitchOptions))
+	if o.stitchOptions.Tree {
+		overhead += fileutil.EstimateTokens(prompt.StitchTree(contextFiles, omittedDirs))
+	}
+	return overhead
+}
+
+// inputTokenBudget returns the number of tokens a model accepts as input: its context window
+// less the tokens reserved for output. The reservation is capped at half the context window,
+// since some models advertise a maximum output as large as their whole context window.
+func inputTokenBudget(contextWindow, maxOutputTokens int32) int {
+	reserved := min(maxOutputTokens, contextWindow/2)
+	return int(contextWindow - reserved)
+}
+
+// runDryRunFlow handles the dry run mode by displaying the instructions and context
+// statistics without performing API calls.
 // It short-circuits the execution flow when in dry run mode.
 // Returns:
 // - bool: true if dry run was executed (to stop normal flow), false if normal processing should continue
 // - error: any error that occurred during dry run handling
-func (o *Orchestrator) runDryRunFlow(ctx context.Context, contextStats *interfaces.ContextStats) (bool, error) {
+func (o *Orchestrator) runDryRunFlow(ctx context.Context, instructions string, contextStats *interfaces.ContextStats) (bool, error) {
 	// Early return if not in dry run mode
 	if !o.config.DryRun {
 		return false, nil
@@ -161,6 +327,15 @@ func (o *Orchestrator) runDryRunFlow(ctx context.Context, contextStats *interfac
 	// Log that we're in dry run mode
 	contextLogger.InfoContext(ctx, "Running in dry-run mode")
 
+	// Show the instructions as they would be sent, combined from all of their sources
+	if instructions == "" {
+		contextLogger.InfoContext(ctx, "No instructions given")
+	} else {
+		contextLogger.InfoContext(ctx, "Instructions from %s (%d characters, ~%d tokens):",
+			strings.Join(o.config.DescribeInstructions(), ", "), len(instructions), fileutil.EstimateTokens(instructions))
+		contextLogger.InfoContext(ctx, "%s", instructions)
+	}
+
 	// Call the existing handleDryRun method
 	err := o.handleDryRun(ctx, contextStats)
 	if err != nil {
@@ -217,6 +392,7 @@ func (o *Orchestrator) runSynthesisFlow(ctx context.Context, instructions string
 	if err != nil {
 		// Process the error with specialized handling
 		contextLogger.ErrorContext(ctx, "Synthesis failed: %v", err)
+		o.recordSynthesis("", "", err)
 		return err
 	}
 
@@ -227,13 +403,191 @@ func (o *Orchestrator) runSynthesisFlow(ctx context.Context, instructions string
 	// Save the synthesis output using the OutputWriter
 	if err := o.outputWriter.SaveSynthesisOutput(ctx, synthesisContent, o.config.SynthesisModel, o.config.OutputDir); err != nil {
 		contextLogger.ErrorContext(ctx, "Failed to save synthesis output: %v", err)
+		o.recordSynthesis(synthesisContent, "", err)
 		return err
 	}
+	o.recordSynthesis(synthesisContent, synthesisOutputPath(o.config.OutputDir, o.config.SynthesisModel), nil)
 
 	contextLogger.InfoContext(ctx, "Successfully saved synthesis output")
 	return nil
 }
 
+// critiqueRefineResult is the outcome of critiquing and revising the model outputs
+type critiqueRefineResult struct {
+	// outputs holds the final output of each model: its last revision, or its original
+	// output if it was not revised
+	outputs map[string]string
+	// err wraps ErrCritiqueRefineFailed for the models whose critique or revision failed,
+	// whose earlier output is kept
+	err error
+	// saveErr is the error saving the critiques and revisions, if any
+	saveErr error
+}
+
+// critiqueArtifact names the files a round of critique of a model's output is saved to,
+// without their extension
+type critiqueArtifact struct {
+	round    CritiqueRound
+	critique string // <model>-critique, with the round number appended after the first round
+	refined  string // <model>-refined likewise, or empty if the output was not revised
+}
+
+// critiqueArtifacts names the files of the critique rounds of a model's output
+func critiqueArtifacts(modelName string, rounds []CritiqueRound) []critiqueArtifact {
+	artifacts := make([]critiqueArtifact, 0, len(rounds))
+	for i, round := range rounds {
+		suffix := ""
+		if i > 0 {
+			suffix = fmt.Sprintf("-%d", i+1)
+		}
+		artifact := critiqueArtifact{round: round, critique: modelName + "-critique" + suffix}
+		if round.Refined != "" {
+			artifact.refined = modelName + "-refined" + suffix
+		}
+		artifacts = append(artifacts, artifact)
+	}
+	return artifacts
+}
+
+// runCritiqueRefineFlow critiques and revises each model output concurrently when
+// critique-and-refine mode is enabled, and saves the critique and revised output of every
+// round next to the model's output (see critiqueArtifacts). The rounds and their files are
+// recorded in the reports of the models. Outputs reused from the run being resumed keep
+// the rounds recorded for them, instead of being critiqued again. When the mode is
+// disabled, the outputs are returned unchanged.
+func (o *Orchestrator) runCritiqueRefineFlow(ctx context.Context, instructions string, modelOutputs map[string]string) critiqueRefineResult {
+	if o.critiqueRefineService == nil || len(modelOutputs) == 0 {
+		return critiqueRefineResult{outputs: modelOutputs}
+	}
+
+	// Get logger with context
+	contextLogger := o.logger.WithContext(ctx)
+	contextLogger.InfoContext(ctx, "Critiquing and refining %d model outputs", len(modelOutputs))
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		errs      []error
+		result    = critiqueRefineResult{outputs: make(map[string]string, len(modelOutputs))}
+		rounds    = make(map[string][]CritiqueRound, len(modelOutputs))
+		modelErrs = make(map[string]error)
+	)
+	for modelName, output := range modelOutputs {
+		if resumed, ok := o.resumedCritiques[modelName]; ok {
+			contextLogger.InfoContext(ctx, "Reusing the critique of model %s output from the run being resumed", modelName)
+			rounds[modelName] = resumed
+			result.outputs[modelName] = finalOutput(output, resumed)
+			continue
+		}
+
+		wg.Add(1)
+		go func(modelName, output string) {
+			defer wg.Done()
+
+			modelRounds, err := o.critiqueRefineService.CritiqueAndRefine(ctx, instructions, modelName, output)
+
+			mu.Lock()
+			defer mu.Unlock()
+			rounds[modelName] = modelRounds
+			result.outputs[modelName] = finalOutput(output, modelRounds)
+
+			status := "Success"
+			if err != nil {
+				status = "Failure"
+				contextLogger.ErrorContext(ctx, "Critique and refine of model %s output failed: %v", modelName, err)
+				errs = append(errs, err)
+				modelErrs[modelName] = err
+			}
+			o.logAuditEvent(ctx, "CritiqueRefine", status,
+				map[string]interface{}{"model_name": modelName, "max_rounds": o.config.CritiqueRounds},
+				map[string]interface{}{"rounds": len(modelRounds), "refined": result.outputs[modelName] != output}, err)
+		}(modelName, output)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		result.err = fmt.Errorf("%w: %s", ErrCritiqueRefineFailed, aggregateErrorMessages(errs))
+	}
+
+	artifacts := make(map[string]string)
+	for modelName, modelRounds := range rounds {
+		for _, artifact := range critiqueArtifacts(modelName, modelRounds) {
+			artifacts[artifact.critique] = artifact.round.Critique
+			if artifact.refined != "" {
+				artifacts[artifact.refined] = artifact.round.Refined
+			}
+		}
+	}
+	if len(artifacts) > 0 {
+		_, result.saveErr = o.outputWriter.SaveIndividualOutputs(ctx, artifacts, o.config.OutputDir)
+	}
+	o.recordCritiques(rounds, modelErrs, result.saveErr == nil)
+
+	return result
+}
+
+// logUsageSummary reports the token usage and estimated cost of every model call made
+// during the run, both to the log and as a "RunSummary" audit entry.
+// Nothing is reported if no provider returned usage information.
+func (o *Orchestrator) logUsageSummary(ctx context.Context) {
+	if o.usageTracker == nil {
+		return
+	}
+	records := o.usageTracker.Records()
+	if len(records) == 0 {
+		return
+	}
+
+	// Get logger with context
+	contextLogger := o.logger.WithContext(ctx)
+
+	models := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		contextLogger.InfoContext(ctx, "Usage for %s (%s): %d prompt + %d completion = %d tokens, estimated cost: %s",
+			record.ModelName, record.Operation, record.PromptTokens, record.CompletionTokens, record.TotalTokens, formatCost(record))
+
+		model := map[string]interface{}{
+			"model_name":        record.ModelName,
+			"operation":         record.Operation,
+			"prompt_tokens":     record.PromptTokens,
+			"completion_tokens": record.CompletionTokens,
+			"total_tokens":      record.TotalTokens,
+		}
+		if record.CostKnown {
+			model["estimated_cost_usd"] = record.Cost
+		}
+		models = append(models, model)
+	}
+
+	totals := o.usageTracker.Totals()
+	contextLogger.InfoContext(ctx, "Total usage: %d tokens across %d model calls, estimated cost: %s",
+		totals.TotalTokens, len(records), formatCost(totals))
+
+	outputs := map[string]interface{}{
+		"models": models,
+	}
+	if totals.CostKnown {
+		outputs["estimated_cost_usd"] = totals.Cost
+	}
+	if logErr := o.auditLogger.Log(auditlog.AuditEntry{
+		Operation:   "RunSummary",
+		Status:      "Success",
+		Outputs:     outputs,
+		TokenCounts: totals.TokenCounts(),
+		Message:     fmt.Sprintf("Run used %d tokens across %d model calls", totals.TotalTokens, len(records)),
+	}); logErr != nil {
+		contextLogger.WarnContext(ctx, "Failed to write audit log: %v", logErr)
+	}
+}
+
+// formatCost renders an estimated cost for display, noting when pricing is unavailable.
+func formatCost(usage modelproc.ModelUsage) string {
+	if !usage.CostKnown {
+		return "unknown (no pricing configured)"
+	}
+	return fmt.Sprintf("$%.4f", usage.Cost)
+}
+
 // handleDryRun displays context statistics without performing API calls.
 func (o *Orchestrator) handleDryRun(ctx context.Context, stats *interfaces.ContextStats) error {
 	err := o.contextGatherer.DisplayDryRunInfo(ctx, stats)
@@ -246,12 +600,56 @@ func (o *Orchestrator) handleDryRun(ctx context.Context, stats *interfaces.Conte
 
 // buildPrompt creates the complete prompt by combining instructions with context files.
 func (o *Orchestrator) buildPrompt(instructions string, contextFiles []fileutil.FileMeta) string {
-	stitchedPrompt := prompt.StitchPrompt(instructions, contextFiles)
+	stitchedPrompt := prompt.StitchPrompt(instructions, contextFiles, o.stitchOptions)
 	o.logger.Info("Prompt constructed successfully")
 	o.logger.Debug("Stitched prompt length: %d characters", len(stitchedPrompt))
 	return stitchedPrompt
 }
 
+// renderInstructions executes the instructions as a Go text/template when templates are
+// enabled, with the configured variables and built-in variables describing the run.
+// Otherwise the instructions are returned unchanged.
+func (o *Orchestrator) renderInstructions(ctx context.Context, instructions string, contextFiles []fileutil.FileMeta) (string, error) {
+	if !o.config.TemplateInstructions {
+		return instructions, nil
+	}
+
+	data := o.templateData(contextFiles)
+	rendered, err := prompt.RenderInstructions(instructions, data, o.config.TemplateDir)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrInvalidInstructionsTemplate, err)
+		o.logAuditEvent(ctx, "RenderInstructions", "Failure",
+			map[string]interface{}{"template_dir": o.config.TemplateDir}, nil, err)
+		return "", err
+	}
+
+	o.logger.WithContext(ctx).DebugContext(ctx, "Rendered instructions template: %d characters", len(rendered))
+	o.logAuditEvent(ctx, "RenderInstructions", "Success",
+		map[string]interface{}{"template_dir": o.config.TemplateDir, "variables": len(data.Vars)},
+		map[string]interface{}{"content_length": len(rendered)}, nil)
+	return rendered, nil
+}
+
+// templateData returns the built-in and configured variables of an instructions template
+func (o *Orchestrator) templateData(contextFiles []fileutil.FileMeta) prompt.TemplateData {
+	data := prompt.TemplateData{
+		Vars:   o.config.TemplateVars,
+		Files:  make([]string, 0, len(contextFiles)),
+		Models: o.config.ModelNames,
+		Date:   time.Now().Format("2006-01-02"),
+	}
+	if data.Vars == nil {
+		data.Vars = map[string]string{}
+	}
+	for _, file := range contextFiles {
+		data.Files = append(data.Files, file.Path)
+	}
+	if len(o.config.Paths) > 0 {
+		data.Branch = fileutil.GitBranch(o.config.Paths[0])
+	}
+	return data
+}
+
 // logRateLimitingConfiguration logs information about concurrency and rate limits.
 func (o *Orchestrator) logRateLimitingConfiguration(ctx context.Context) {
 	// Get logger with context
@@ -290,16 +688,24 @@ func (o *Orchestrator) logRateLimitingConfiguration(ctx context.Context) {
 // in synthesis prompts.
 //
 // Returns:
-// - A map of model names to their generated content (contains only successful models)
+// - A map of answering model names to their generated content (contains only successful models)
 // - A slice of errors encountered during processing (empty if all models were successful)
 func (o *Orchestrator) processModels(ctx context.Context, stitchedPrompt string) (map[string]string, []error) {
 	var wg sync.WaitGroup
 	resultChan := make(chan modelResult, len(o.config.ModelNames))
 
+	// Estimate the prompt size once for the per-model preflight checks
+	promptTokens := fileutil.EstimateTokens(stitchedPrompt)
+
 	// Launch a goroutine for each model
 	for _, modelName := range o.config.ModelNames {
+		// A model that completed in the run being resumed is not processed again
+		if result, ok := o.resumed[modelName]; ok {
+			resultChan <- result
+			continue
+		}
 		wg.Add(1)
-		go o.processModelWithRateLimit(ctx, modelName, stitchedPrompt, &wg, resultChan)
+		go o.processModelWithRateLimit(ctx, modelName, stitchedPrompt, promptTokens, &wg, resultChan)
 	}
 
 	// Wait for all goroutines to complete
@@ -309,17 +715,26 @@ func (o *Orchestrator) processModels(ctx context.Context, stitchedPrompt string)
 	// Collect outputs and errors from the channel
 	modelOutputs := make(map[string]string)
 	var modelErrors []error
+	var results []modelResult
 
 	// We're processing a channel that's already closed, so there's no race condition here
 	for result := range resultChan {
+		results = append(results, result)
+
 		// Only store output for successful models
 		if result.err == nil {
+			// Two requested models may have fallen back to the same model; keep one output
+			if _, exists := modelOutputs[result.modelName]; exists {
+				o.logger.WarnContext(ctx, "Model %s answered for more than one requested model; keeping one output", result.modelName)
+				continue
+			}
 			modelOutputs[result.modelName] = result.content
 		} else {
 			// Collect errors
 			modelErrors = append(modelErrors, result.err)
 		}
 	}
+	o.recordModelResults("", o.config.ModelNames, results)
 
 	return modelOutputs, modelErrors
 }
@@ -329,38 +744,97 @@ func (o *Orchestrator) processModels(ctx context.Context, stitchedPrompt string)
 // This struct is crucial for the synthesis feature as it captures outputs
 // from multiple models so they can be combined by a synthesis model.
 type modelResult struct {
-	modelName string // Name of the processed model
-	content   string // Generated content from the model, which may be used for synthesis
-	err       error  // Any error encountered during processing
+	requestedModel string                // Name of the model that was requested
+	modelName      string                // Name of the model that answered (or was tried last), which may be a fallback
+	content        string                // Generated content from the model, which may be used for synthesis
+	generation     *modelproc.Generation // Details of the call to modelName (nil if the model was not called)
+	err            error                 // Any error encountered during processing
+	resumed        bool                  // Whether the content was reused from the run being resumed
 }
 
-// processModelWithRateLimit processes a single model with rate limiting.
-// It acquires a rate limiting token, processes the model, and sends the result
-// (containing model name, content, and any error) to the result channel.
+// processModelWithRateLimit processes a single model with rate limiting and sends the
+// result (containing the name of the model that answered, its content, and any error)
+// to the result channel.
 func (o *Orchestrator) processModelWithRateLimit(
 	ctx context.Context,
 	modelName string,
 	stitchedPrompt string,
+	promptTokens int,
 	wg *sync.WaitGroup,
 	resultChan chan<- modelResult,
 ) {
 	defer wg.Done()
+	resultChan <- o.processModelChain(ctx, o.config, modelName, stitchedPrompt, promptTokens)
+}
 
+// processModelChain processes a model using the given configuration.
+// It tries the model and then each of its fallbacks in turn, until one succeeds or a
+// model fails in a way that another model would not fix, and returns the result of
+// the last model tried.
+func (o *Orchestrator) processModelChain(
+	ctx context.Context,
+	cfg *config.CliConfig,
+	modelName string,
+	stitchedPrompt string,
+	promptTokens int,
+) modelResult {
 	// Get logger with context
 	contextLogger := o.logger.WithContext(ctx)
 
-	// Create a local variable to store the result to avoid accessing it from multiple goroutines
-	var result modelResult
-	result.modelName = modelName
+	result := modelResult{requestedModel: modelName, modelName: modelName}
+
+	chain := ModelChain(o.apiService, cfg, modelName)
+	for i, candidate := range chain {
+		content, generation, err := o.processSingleModel(ctx, cfg, candidate, stitchedPrompt, promptTokens)
+		if err == nil {
+			if candidate != modelName {
+				contextLogger.InfoContext(ctx, "Fallback model %s answered for model %s", candidate, modelName)
+				o.logAuditEvent(ctx, "ModelFallback", "Success",
+					map[string]interface{}{"model_name": modelName, "fallback_chain": chain},
+					map[string]interface{}{"answered_by": candidate, "attempted_models": chain[:i+1]}, nil)
+			}
+			return modelResult{requestedModel: modelName, modelName: candidate, content: content, generation: generation}
+		}
+
+		result.modelName, result.generation, result.err = candidate, generation, err
+		if i == len(chain)-1 || !shouldFallback(err) || ctx.Err() != nil {
+			break
+		}
+
+		next := chain[i+1]
+		contextLogger.WarnContext(ctx, "Model %s failed, falling back to %s: %v", candidate, next, err)
+		o.logAuditEvent(ctx, "ModelFallback", "InProgress",
+			map[string]interface{}{"model_name": modelName, "failed_model": candidate, "fallback_model": next},
+			nil, err)
+	}
+
+	if len(chain) > 1 {
+		o.logAuditEvent(ctx, "ModelFallback", "Failure",
+			map[string]interface{}{"model_name": modelName, "fallback_chain": chain}, nil, result.err)
+	}
+	return result
+}
+
+// processSingleModel processes one model with rate limiting.
+// It checks the prompt against the model's token limits, acquires a rate limiting token,
+// and processes the model with the given configuration, returning its content or an
+// error naming the model, along with the details of the call if the model was called.
+func (o *Orchestrator) processSingleModel(ctx context.Context, cfg *config.CliConfig, modelName string, stitchedPrompt string, promptTokens int) (string, *modelproc.Generation, error) {
+	// Get logger with context
+	contextLogger := o.logger.WithContext(ctx)
+
+	// Skip models whose context window cannot fit the prompt before spending rate limit quota
+	if err := o.preflightModel(ctx, modelName, promptTokens); err != nil {
+		contextLogger.ErrorContext(ctx, "Skipping model %s: %v", modelName, err)
+		return "", nil, fmt.Errorf("model %s: %w", modelName, err)
+	}
 
 	// Acquire rate limiting permission
 	contextLogger.DebugContext(ctx, "Attempting to acquire rate limiter for model %s...", modelName)
 	acquireStart := time.Now()
 	if err := o.rateLimiter.Acquire(ctx, modelName); err != nil {
 		contextLogger.ErrorContext(ctx, "Rate limiting error for model %s: %v", modelName, err)
-		result.err = fmt.Errorf("model %s rate limit: %w", modelName, err)
-		resultChan <- result
-		return
+		return "", nil, fmt.Errorf("model %s rate limit: %w", modelName, err)
 	}
 	acquireDuration := time.Since(acquireStart)
 	contextLogger.DebugContext(ctx, "Rate limiter acquired for model %s (waited %v)", modelName, acquireDuration)
@@ -378,23 +852,69 @@ func (o *Orchestrator) processModelWithRateLimit(
 		o.fileWriter,
 		o.auditLogger,
 		o.logger,
-		o.config,
+		cfg,
 	)
 
+	// Echo streamed output to the terminal only for single-model runs,
+	// since concurrent streams would interleave, and not when stdout carries the JSON report
+	if o.config.Stream && len(o.config.ModelNames) == 1 && o.config.OutputFormat != config.OutputFormatJSON {
+		processor.SetStreamOutput(os.Stdout)
+	}
+	if o.usageTracker != nil {
+		processor.SetUsageTracker(o.usageTracker)
+	}
+
 	// Process the model
 	content, err := processor.Process(ctx, modelName, stitchedPrompt)
 	if err != nil {
 		contextLogger.ErrorContext(ctx, "Processing model %s failed: %v", modelName, err)
-		result.err = fmt.Errorf("model %s: %w", modelName, err)
-		resultChan <- result
-		return
+		return "", processor.LastGeneration(), fmt.Errorf("model %s: %w", modelName, err)
 	}
 
-	// Store the successful result in local variable before sending
 	contextLogger.DebugContext(ctx, "Processing model %s completed successfully", modelName)
-	result.content = content
-	result.err = nil
-	resultChan <- result
+	return content, processor.LastGeneration(), nil
+}
+
+// preflightModel checks the estimated prompt size against the model's token limits.
+// It returns ErrPromptExceedsContextWindow if the prompt alone exceeds the context window,
+// and warns if the prompt leaves less room than the model's maximum output. Models without
+// known token limits are not checked.
+func (o *Orchestrator) preflightModel(ctx context.Context, modelName string, promptTokens int) error {
+	// Get logger with context
+	contextLogger := o.logger.WithContext(ctx)
+
+	contextWindow, maxOutputTokens, err := o.apiService.GetModelTokenLimits(modelName)
+	if err != nil {
+		contextLogger.DebugContext(ctx, "Skipping preflight check for model %s: %v", modelName, err)
+		return nil
+	}
+	if contextWindow <= 0 {
+		contextLogger.DebugContext(ctx, "Skipping preflight check for model %s: no context window configured", modelName)
+		return nil
+	}
+
+	inputs := map[string]interface{}{
+		"model_name":        modelName,
+		"prompt_tokens":     promptTokens,
+		"context_window":    contextWindow,
+		"max_output_tokens": maxOutputTokens,
+	}
+
+	if promptTokens > int(contextWindow) {
+		err := fmt.Errorf("%w: estimated %d prompt tokens, context window is %d tokens",
+			ErrPromptExceedsContextWindow, promptTokens, contextWindow)
+		o.logAuditEvent(ctx, "PreflightCheck", "Failure", inputs, nil, err)
+		return err
+	}
+
+	if promptTokens+int(maxOutputTokens) > int(contextWindow) {
+		contextLogger.WarnContext(ctx, "Prompt for model %s (~%d tokens) leaves only %d of %d output tokens; the response may be cut short",
+			modelName, promptTokens, int(contextWindow)-promptTokens, maxOutputTokens)
+	}
+
+	contextLogger.DebugContext(ctx, "Preflight check passed for model %s: ~%d prompt tokens, context window %d",
+		modelName, promptTokens, contextWindow)
+	return nil
 }
 
 // APIServiceAdapter adapts interfaces.APIService to modelproc.APIService.
@@ -521,6 +1041,13 @@ func (o *Orchestrator) setupContext(ctx context.Context) (context.Context, logut
 		return ctx, contextLogger, fmt.Errorf("%w: at least one model is required", ErrNoValidModels)
 	}
 
+	// Resolve how context files are rendered in prompts
+	contextFormat, err := prompt.ParseContextFormat(o.config.Format)
+	if err != nil {
+		return ctx, contextLogger, fmt.Errorf("%w: %v", ErrInvalidContextFormat, err)
+	}
+	o.stitchOptions = prompt.StitchOptions{Format: contextFormat, Tree: o.config.Tree}
+
 	// Log the start of processing
 	contextLogger.InfoContext(ctx, "Starting processing")
 
@@ -572,14 +1099,14 @@ func (o *Orchestrator) processModelsWithErrorHandling(ctx context.Context, stitc
 
 // handleOutputFlow decides whether to use synthesis or individual output flow
 // based on configuration and handles the saving of outputs accordingly.
-func (o *Orchestrator) handleOutputFlow(ctx context.Context, instructions string, modelOutputs map[string]string) error {
+func (o *Orchestrator) handleOutputFlow(ctx context.Context, instructions string, modelOutputs, finalOutputs map[string]string) error {
 	if o.config.SynthesisModel == "" {
 		// No synthesis model specified - save individual model outputs
 		return o.runIndividualOutputFlow(ctx, modelOutputs)
 	}
 
-	// Synthesis model specified - process all outputs with synthesis model
-	return o.runSynthesisFlow(ctx, instructions, modelOutputs)
+	// Synthesis model specified - process the final (possibly refined) outputs with synthesis model
+	return o.runSynthesisFlow(ctx, instructions, finalOutputs)
 }
 
 // handleProcessingOutcome combines and reports any errors from model processing and file saving.
@@ -648,6 +1175,17 @@ func aggregateErrorMessages(errs []error) string {
 	return strings.Join(messages, "; ")
 }
 
+// combineErrors combines two errors, either of which may be nil, keeping both for errors.Is
+func combineErrors(first, second error) error {
+	if first == nil {
+		return second
+	}
+	if second == nil {
+		return first
+	}
+	return fmt.Errorf("%w; %w", first, second)
+}
+
 // logAuditEvent is a helper method that constructs an AuditEntry and logs it via the audit logger.
 // It simplifies and standardizes audit event logging across the orchestrator.
 //
@@ -0,0 +1,166 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/fileutil"
+	"github.com/phrazzld/thinktank/internal/thinktank/interfaces"
+	"github.com/phrazzld/thinktank/internal/thinktank/prompt"
+)
+
+// limitsAPIService returns per-model token limits; models missing from limits report an error
+type limitsAPIService struct {
+	MockAPIService
+	limits map[string][2]int32
+}
+
+func (m *limitsAPIService) GetModelTokenLimits(modelName string) (int32, int32, error) {
+	limits, ok := m.limits[modelName]
+	if !ok {
+		return 0, 0, errors.New("no token limits")
+	}
+	return limits[0], limits[1], nil
+}
+
+// budgetContextGatherer records the gather configuration it was called with
+type budgetContextGatherer struct {
+	MockContextGatherer
+	config interfaces.GatherConfig
+}
+
+func (m *budgetContextGatherer) GatherContext(ctx context.Context, config interfaces.GatherConfig) ([]fileutil.FileMeta, *interfaces.ContextStats, error) {
+	m.config = config
+	return m.MockContextGatherer.GatherContext(ctx, config)
+}
+
+func TestInputTokenBudget(t *testing.T) {
+	tests := []struct {
+		name            string
+		contextWindow   int32
+		maxOutputTokens int32
+		expected        int
+	}{
+		{"Output reserved", 1000000, 65000, 935000},
+		{"Reservation capped at half the window", 200000, 200000, 100000},
+		{"No output limit", 8192, 0, 8192},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inputTokenBudget(tt.contextWindow, tt.maxOutputTokens); got != tt.expected {
+				t.Errorf("inputTokenBudget(%d, %d) = %d, want %d", tt.contextWindow, tt.maxOutputTokens, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestContextTokenBudget(t *testing.T) {
+	tests := []struct {
+		name     string
+		models   []string
+		limits   map[string][2]int32
+		expected int
+	}{
+		{
+			name:     "Smallest model limits the budget",
+			models:   []string{"large", "small"},
+			limits:   map[string][2]int32{"large": {1000000, 65000}, "small": {32768, 4096}},
+			expected: 32768 - 4096,
+		},
+		{
+			name:     "Models without limits are skipped",
+			models:   []string{"unknown", "small"},
+			limits:   map[string][2]int32{"small": {32768, 4096}},
+			expected: 32768 - 4096,
+		},
+		{
+			name:     "No known limits disables packing",
+			models:   []string{"unknown"},
+			limits:   map[string][2]int32{},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orch := &Orchestrator{
+				apiService: &limitsAPIService{limits: tt.limits},
+				config:     &config.CliConfig{ModelNames: tt.models},
+				logger:     &MockLogger{},
+			}
+			if got := orch.contextTokenBudget(context.Background()); got != tt.expected {
+				t.Errorf("contextTokenBudget() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGatherProjectContextPassesPackingConfig(t *testing.T) {
+	gatherer := &budgetContextGatherer{}
+	orch := &Orchestrator{
+		apiService:      &limitsAPIService{limits: map[string][2]int32{"model": {32768, 4096}}},
+		contextGatherer: gatherer,
+		config: &config.CliConfig{
+			ModelNames:   []string{"model"},
+			PackStrategy: fileutil.PackByRecency,
+			Paths:        []string{"."},
+		},
+		logger: &MockLogger{},
+	}
+
+	if _, _, err := orch.gatherProjectContext(context.Background(), "Fix the parser"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if gatherer.config.TokenBudget <= 0 {
+		t.Errorf("Expected a positive token budget, got %d", gatherer.config.TokenBudget)
+	}
+	if gatherer.config.PackStrategy != fileutil.PackByRecency {
+		t.Errorf("Expected pack strategy %q, got %q", fileutil.PackByRecency, gatherer.config.PackStrategy)
+	}
+	if gatherer.config.Instructions != "Fix the parser" {
+		t.Errorf("Expected instructions to be passed through, got %q", gatherer.config.Instructions)
+	}
+
+	// Files are estimated as rendered in the selected format
+	orch.stitchOptions.Format, _ = prompt.ParseContextFormat(prompt.FormatNumbered)
+	file := fileutil.FileMeta{Path: "/project/main.go", Content: strings.Repeat("x\n", 100)}
+	if got, expected := gatherer.config.FileTokens(file), fileutil.EstimateTokens(orch.stitchOptions.FormatFile(file)); got != expected {
+		t.Errorf("Expected %d tokens for a numbered file, got %d", expected, got)
+	}
+	if gatherer.config.PromptOverhead(nil, nil) <= 0 {
+		t.Error("Expected the instructions to count towards the prompt overhead")
+	}
+}
+
+// TestPromptOverhead tests that the prompt overhead is estimated from the prompt as it is
+// built from the files: rendered instructions, framing and directory tree
+func TestPromptOverhead(t *testing.T) {
+	files := []fileutil.FileMeta{
+		{Path: "/project/src/main.go", Content: "package main\n", Size: 13},
+		{Path: "/project/src/util/strings.go", Content: "package util\n", Size: 13},
+	}
+	instructions := "Review {{range .Files}}{{.}} {{end}}"
+	rendered := "Review /project/src/main.go /project/src/util/strings.go "
+
+	orch := &Orchestrator{
+		config:        &config.CliConfig{TemplateInstructions: true},
+		stitchOptions: prompt.StitchOptions{Tree: true},
+	}
+	expected := fileutil.EstimateTokens(prompt.StitchPrompt(rendered, nil, orch.stitchOptions)) +
+		fileutil.EstimateTokens(prompt.StitchTree(files, []string{"/project/vendor"}))
+	if got := orch.promptOverhead(instructions, files, []string{"/project/vendor"}); got != expected {
+		t.Errorf("promptOverhead() = %d, want %d", got, expected)
+	}
+
+	// Without a template or tree, only the instructions and framing count
+	orch = &Orchestrator{config: &config.CliConfig{}}
+	expected = fileutil.EstimateTokens(prompt.StitchPrompt(instructions, nil, prompt.StitchOptions{}))
+	if got := orch.promptOverhead(instructions, files, nil); got != expected {
+		t.Errorf("promptOverhead() = %d, want %d", got, expected)
+	}
+}
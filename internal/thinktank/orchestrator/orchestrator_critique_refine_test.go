@@ -0,0 +1,187 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/ratelimit"
+)
+
+// newCritiqueRefineOrchestrator creates an orchestrator with critique-and-refine mode enabled
+func newCritiqueRefineOrchestrator(t *testing.T, apiService *scriptedAPIService, synthesisModel string) (*Orchestrator, *syncFileWriter, *MockAuditLogger, string) {
+	t.Helper()
+
+	outputDir := filepath.Join(t.TempDir(), "output")
+	cfg := &config.CliConfig{
+		ModelNames:     []string{"model-a", "model-b"},
+		SynthesisModel: synthesisModel,
+		CritiqueRefine: true,
+		CriticModel:    "critic",
+		CritiqueRounds: 1,
+		OutputDir:      outputDir,
+	}
+	fileWriter := &syncFileWriter{}
+	auditLogger := NewMockAuditLogger()
+
+	orch := NewOrchestrator(apiService, &MockContextGatherer{}, fileWriter, auditLogger,
+		ratelimit.NewRateLimiter(0, 0), cfg, &MockLogger{})
+	return orch, fileWriter, auditLogger, outputDir
+}
+
+// TestRunCritiqueRefine tests that each model output is critiqued and revised, that the
+// artifacts are saved next to the original outputs, and that synthesis combines the revisions
+func TestRunCritiqueRefine(t *testing.T) {
+	apiService := &scriptedAPIService{respond: func(modelName, promptText string) (string, error) {
+		switch {
+		case modelName == "synthesizer":
+			return "synthesis", nil
+		case modelName == "critic":
+			return "critique of output", nil
+		case strings.Contains(promptText, "<critique>"):
+			return "refined by " + modelName, nil
+		default:
+			return "draft from " + modelName, nil
+		}
+	}}
+	orch, fileWriter, auditLogger, outputDir := newCritiqueRefineOrchestrator(t, apiService, "synthesizer")
+
+	if err := orch.Run(context.Background(), "Design it"); err != nil {
+		t.Fatalf("Expected critique and refine to succeed, got: %v", err)
+	}
+
+	expectedFiles := map[string]string{
+		filepath.Join(outputDir, "model-a.md"):          "draft from model-a",
+		filepath.Join(outputDir, "model-a-critique.md"): "critique of output",
+		filepath.Join(outputDir, "model-a-refined.md"):  "refined by model-a",
+		filepath.Join(outputDir, "model-b-refined.md"):  "refined by model-b",
+	}
+	for path, content := range expectedFiles {
+		if fileWriter.files[path] != content {
+			t.Errorf("Expected %s to contain %q, got %q", path, content, fileWriter.files[path])
+		}
+	}
+
+	synthesisPrompts := apiService.promptsFor("synthesizer")
+	if len(synthesisPrompts) != 1 {
+		t.Fatalf("Expected one synthesis prompt, got %d", len(synthesisPrompts))
+	}
+	if !strings.Contains(synthesisPrompts[0], "refined by model-b") || strings.Contains(synthesisPrompts[0], "draft from model-b") {
+		t.Errorf("Expected synthesis to combine the refined outputs, got:\n%s", synthesisPrompts[0])
+	}
+
+	if statuses := auditStatuses(auditLogger, "CritiqueRefine"); len(statuses) != 2 || statuses[0] != "Success" {
+		t.Errorf("Expected a successful CritiqueRefine entry per model, got %v", statuses)
+	}
+
+	// The rounds and their files are recorded in the report and the manifest
+	expectedRound := CritiqueReport{
+		CriticModel:  "critic",
+		CritiqueFile: filepath.Join(outputDir, "model-a-critique.md"),
+		RefineModel:  "model-a",
+		RefinedFile:  filepath.Join(outputDir, "model-a-refined.md"),
+	}
+	report := orch.Report()
+	if len(report.Models) != 2 || len(report.Models[0].Critique) != 1 || report.Models[0].Critique[0] != expectedRound {
+		t.Errorf("Expected the critique round %+v in the report, got %+v", expectedRound, report.Models)
+	}
+	if manifest := fileWriter.files[filepath.Join(outputDir, ManifestFileName)]; !strings.Contains(manifest, `"refined_file"`) ||
+		!strings.Contains(manifest, "model-b-critique.md") {
+		t.Errorf("Expected the critique files in the manifest, got:\n%s", manifest)
+	}
+}
+
+// TestRunCritiqueRefineResume tests that a resumed run reuses the critique rounds of the
+// reused outputs instead of critiquing them again
+func TestRunCritiqueRefineResume(t *testing.T) {
+	manifest := &Manifest{
+		Instructions: newManifestFile("instructions.md", "Design it"),
+		Models: []ManifestModel{
+			{ModelReport: ModelReport{Model: "model-a", Status: ReportStatusSuccess, Critique: []CritiqueReport{{
+				CriticModel:  "critic",
+				CritiqueFile: "old-run/model-a-critique.md",
+				RefineModel:  "model-a",
+				RefinedFile:  "old-run/model-a-refined.md",
+			}}}},
+			{ModelReport: ModelReport{Model: "model-b", Status: ReportStatusFailure}},
+		},
+	}
+	resumeDir := writeResumeDir(t, manifest, map[string]string{
+		"model-a.md":          "old draft from model-a",
+		"model-a-critique.md": "old critique",
+		"model-a-refined.md":  "old revision from model-a",
+	})
+
+	apiService := &scriptedAPIService{respond: func(modelName, promptText string) (string, error) {
+		switch {
+		case modelName == "synthesizer":
+			return "synthesis", nil
+		case modelName == "critic":
+			return "critique of output", nil
+		case strings.Contains(promptText, "<critique>"):
+			return "refined by " + modelName, nil
+		default:
+			return "draft from " + modelName, nil
+		}
+	}}
+	orch, fileWriter, _, outputDir := newCritiqueRefineOrchestrator(t, apiService, "synthesizer")
+	orch.config.ResumeDir = resumeDir
+
+	if err := orch.Run(context.Background(), "Design it"); err != nil {
+		t.Fatalf("Expected the resumed run to succeed, got: %v", err)
+	}
+
+	// Only the output of model-b is critiqued
+	if prompts := apiService.promptsFor("critic"); len(prompts) != 1 || !strings.Contains(prompts[0], "draft from model-b") {
+		t.Errorf("Expected only the new output to be critiqued, got %d critiques", len(prompts))
+	}
+	synthesisPrompts := apiService.promptsFor("synthesizer")
+	if len(synthesisPrompts) != 1 || !strings.Contains(synthesisPrompts[0], "old revision from model-a") {
+		t.Errorf("Expected synthesis to combine the reused revision, got %v", synthesisPrompts)
+	}
+	if got := fileWriter.files[filepath.Join(outputDir, "model-a-refined.md")]; got != "old revision from model-a" {
+		t.Errorf("Expected the reused revision to be saved with the new outputs, got %q", got)
+	}
+	if report := orch.Report(); len(report.Models[0].Critique) != 1 {
+		t.Errorf("Expected the reused critique round in the report, got %+v", report.Models[0])
+	}
+}
+
+// TestRunCritiqueRefineFailure tests that a failed critique keeps the original output
+// and is reported as a critique-and-refine failure
+func TestRunCritiqueRefineFailure(t *testing.T) {
+	apiService := &scriptedAPIService{respond: func(modelName, promptText string) (string, error) {
+		switch {
+		case modelName == "synthesizer":
+			return "synthesis", nil
+		case modelName == "critic":
+			return "", llm.New("test", "", 500, "server error", "", nil, llm.CategoryServer)
+		default:
+			return "draft from " + modelName, nil
+		}
+	}}
+	orch, fileWriter, auditLogger, outputDir := newCritiqueRefineOrchestrator(t, apiService, "synthesizer")
+
+	err := orch.Run(context.Background(), "Design it")
+	if !errors.Is(err, ErrCritiqueRefineFailed) {
+		t.Fatalf("Expected ErrCritiqueRefineFailed, got: %v", err)
+	}
+
+	if _, ok := fileWriter.files[filepath.Join(outputDir, "model-a-refined.md")]; ok {
+		t.Error("Expected no refined output when the critique failed")
+	}
+	synthesisPrompts := apiService.promptsFor("synthesizer")
+	if len(synthesisPrompts) != 1 || !strings.Contains(synthesisPrompts[0], "draft from model-a") {
+		t.Errorf("Expected synthesis to combine the original outputs, got %v", synthesisPrompts)
+	}
+	if statuses := auditStatuses(auditLogger, "CritiqueRefine"); len(statuses) != 2 || statuses[0] != "Failure" {
+		t.Errorf("Expected a failed CritiqueRefine entry per model, got %v", statuses)
+	}
+	if report := orch.Report(); len(report.Models) != 2 || report.Models[0].CritiqueError == nil {
+		t.Errorf("Expected the critique error in the report, got %+v", report.Models)
+	}
+}
@@ -3,6 +3,8 @@ package orchestrator
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/phrazzld/thinktank/internal/config"
@@ -30,11 +32,13 @@ func (m *MockContextGathererWithConfigurableError) DisplayDryRunInfo(ctx context
 type MockLoggerWithRecorder struct {
 	MockLogger
 	infoMessages []string
+	infoLines    []string // Formatted messages
 }
 
 // InfoContext records messages
 func (m *MockLoggerWithRecorder) InfoContext(ctx context.Context, format string, args ...interface{}) {
 	m.infoMessages = append(m.infoMessages, format)
+	m.infoLines = append(m.infoLines, fmt.Sprintf(format, args...))
 }
 
 // WithContext returns self (maintains recording)
@@ -104,7 +108,7 @@ func TestRunDryRunFlow(t *testing.T) {
 			}
 
 			// Call the method under test
-			executed, err := orch.runDryRunFlow(context.Background(), stats)
+			executed, err := orch.runDryRunFlow(context.Background(), "Review the code", stats)
 
 			// Verify execution flag is correctly set
 			if executed != tt.expectedExecution {
@@ -131,3 +135,29 @@ func TestRunDryRunFlow(t *testing.T) {
 		})
 	}
 }
+
+// TestRunDryRunFlowInstructions tests that a dry run shows the combined instructions and their sources
+func TestRunDryRunFlowInstructions(t *testing.T) {
+	mockLogger := &MockLoggerWithRecorder{}
+	orch := &Orchestrator{
+		contextGatherer: &MockContextGathererWithConfigurableError{},
+		logger:          mockLogger,
+		config: &config.CliConfig{
+			DryRun:            true,
+			InstructionsFiles: []string{"style.md", "-"},
+			Prompt:            "Review the code",
+		},
+	}
+
+	if _, err := orch.runDryRunFlow(context.Background(), "Be brief\n\nTask\n\nReview the code", &interfaces.ContextStats{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	output := strings.Join(mockLogger.infoLines, "\n")
+	if !strings.Contains(output, "Instructions from style.md, stdin, --prompt (31 characters") {
+		t.Errorf("Expected the sources of the instructions, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Be brief\n\nTask\n\nReview the code") {
+		t.Errorf("Expected the combined instructions, got:\n%s", output)
+	}
+}
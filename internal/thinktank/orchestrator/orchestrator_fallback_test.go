@@ -0,0 +1,194 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/ratelimit"
+	"github.com/phrazzld/thinktank/internal/registry"
+	"github.com/phrazzld/thinktank/internal/thinktank/modelproc"
+)
+
+// fallbackAPIService fails the models in failures and declares registry fallbacks
+type fallbackAPIService struct {
+	MockAPIService
+	mu          sync.Mutex
+	failures    map[string]error
+	fallbacks   map[string][]string
+	initialized []string
+}
+
+func (m *fallbackAPIService) InitLLMClient(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+	m.mu.Lock()
+	m.initialized = append(m.initialized, modelName)
+	m.mu.Unlock()
+	if err, ok := m.failures[modelName]; ok {
+		return nil, err
+	}
+	return &MockLLMClient{}, nil
+}
+
+func (m *fallbackAPIService) GetModelDefinition(modelName string) (*registry.ModelDefinition, error) {
+	return &registry.ModelDefinition{Name: modelName, Fallbacks: m.fallbacks[modelName]}, nil
+}
+
+func TestModelChain(t *testing.T) {
+	apiService := &fallbackAPIService{fallbacks: map[string][]string{
+		"preview-model": {"stable-model", "other-model"},
+		"looping-model": {"looping-model", "stable-model", "stable-model"},
+	}}
+
+	tests := []struct {
+		name      string
+		fallbacks map[string][]string
+		model     string
+		expected  []string
+	}{
+		{"No fallbacks", nil, "stable-model", []string{"stable-model"}},
+		{"Registry fallbacks", nil, "preview-model", []string{"preview-model", "stable-model", "other-model"}},
+		{"Configured fallbacks take precedence", map[string][]string{"preview-model": {"cli-model"}}, "preview-model", []string{"preview-model", "cli-model"}},
+		{"Repeated models are skipped", nil, "looping-model", []string{"looping-model", "stable-model"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := ModelChain(apiService, &config.CliConfig{ModelFallbacks: tt.fallbacks}, tt.model)
+			if !reflect.DeepEqual(chain, tt.expected) {
+				t.Errorf("Expected chain %v, got %v", tt.expected, chain)
+			}
+		})
+	}
+}
+
+func TestShouldFallback(t *testing.T) {
+	categorized := func(category llm.ErrorCategory) error {
+		return fmt.Errorf("model x: %w", llm.New("test", "", 0, "error", "", nil, category))
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Initialization failure", fmt.Errorf("%w: no API key", modelproc.ErrModelInitializationFailed), true},
+		{"Prompt too large", fmt.Errorf("model x: %w", ErrPromptExceedsContextWindow), true},
+		{"Authentication", categorized(llm.CategoryAuth), true},
+		{"Model not found", categorized(llm.CategoryNotFound), true},
+		{"Quota", categorized(llm.CategoryRateLimit), true},
+		{"Insufficient credits", categorized(llm.CategoryInsufficientCredits), true},
+		{"Input limit", categorized(llm.CategoryInputLimit), true},
+		{"Content filtered", categorized(llm.CategoryContentFiltered), false},
+		{"Server error", categorized(llm.CategoryServer), false},
+		{"Uncategorized", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldFallback(tt.err); got != tt.expected {
+				t.Errorf("Expected shouldFallback = %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestRunFallsBackToNextModel tests that a model that cannot be used is replaced by its
+// fallback, and that the output and audit log name the model that answered
+func TestRunFallsBackToNextModel(t *testing.T) {
+	notFound := llm.New("test", "", 404, "model retired", "", nil, llm.CategoryNotFound)
+	apiService := &fallbackAPIService{
+		failures: map[string]error{
+			"preview-model": notFound,
+			"backup-model":  errors.New("missing API key"),
+		},
+	}
+	cfg := &config.CliConfig{
+		ModelNames:     []string{"preview-model"},
+		ModelFallbacks: map[string][]string{"preview-model": {"backup-model", "stable-model", "unused-model"}},
+		OutputDir:      t.TempDir(),
+	}
+	auditLogger := NewMockAuditLogger()
+	fileWriter := &MockFileWriter{}
+
+	orch := NewOrchestrator(apiService, &MockContextGatherer{}, fileWriter, auditLogger,
+		ratelimit.NewRateLimiter(0, 0), cfg, &MockLogger{})
+
+	outputs, errs := orch.processModels(context.Background(), "prompt")
+	if len(errs) != 0 {
+		t.Fatalf("Expected no errors, got: %v", errs)
+	}
+	if _, ok := outputs["stable-model"]; !ok || len(outputs) != 1 {
+		t.Errorf("Expected a single output from stable-model, got %v", outputs)
+	}
+	if expected := []string{"preview-model", "backup-model", "stable-model"}; !reflect.DeepEqual(apiService.initialized, expected) {
+		t.Errorf("Expected models %v to be tried, got %v", expected, apiService.initialized)
+	}
+
+	var fallbackStatuses []string
+	for _, call := range auditLogger.LogCalls {
+		if call.Operation == "ModelFallback" {
+			fallbackStatuses = append(fallbackStatuses, call.Status)
+		}
+	}
+	if expected := []string{"InProgress", "InProgress", "Success"}; !reflect.DeepEqual(fallbackStatuses, expected) {
+		t.Errorf("Expected ModelFallback entries %v, got %v", expected, fallbackStatuses)
+	}
+	last := auditLogger.LogCalls[len(auditLogger.LogCalls)-1]
+	if last.Outputs["answered_by"] != "stable-model" {
+		t.Errorf("Expected the audit log to record stable-model as answering, got %v", last.Outputs["answered_by"])
+	}
+}
+
+// TestRunDoesNotFallBackOnOtherErrors tests that failures another model would not fix
+// are reported without trying the fallbacks
+func TestRunDoesNotFallBackOnOtherErrors(t *testing.T) {
+	filtered := llm.New("test", "", 400, "blocked", "", nil, llm.CategoryContentFiltered)
+	apiService := &fallbackAPIService{
+		fallbacks: map[string][]string{"preview-model": {"stable-model"}},
+	}
+	// Initialization failures always fall back, so fail during generation instead
+	client := &failingLLMClient{err: filtered}
+
+	cfg := &config.CliConfig{ModelNames: []string{"preview-model"}, OutputDir: t.TempDir()}
+	orch := NewOrchestrator(&clientAPIService{fallbackAPIService: apiService, client: client},
+		&MockContextGatherer{}, &MockFileWriter{}, NewMockAuditLogger(), ratelimit.NewRateLimiter(0, 0), cfg, &MockLogger{})
+
+	outputs, errs := orch.processModels(context.Background(), "prompt")
+	if len(outputs) != 0 || len(errs) != 1 {
+		t.Fatalf("Expected a single error, got outputs %v and errors %v", outputs, errs)
+	}
+	if !llm.IsContentFiltered(errs[0]) {
+		t.Errorf("Expected the content filtering error, got: %v", errs[0])
+	}
+	if !reflect.DeepEqual(apiService.initialized, []string{"preview-model"}) {
+		t.Errorf("Expected only preview-model to be tried, got %v", apiService.initialized)
+	}
+}
+
+// failingLLMClient fails every generation with err
+type failingLLMClient struct {
+	MockLLMClient
+	err error
+}
+
+func (c *failingLLMClient) GenerateContent(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+	return nil, c.err
+}
+
+// clientAPIService returns client for every model
+type clientAPIService struct {
+	*fallbackAPIService
+	client llm.LLMClient
+}
+
+func (m *clientAPIService) InitLLMClient(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+	if _, err := m.fallbackAPIService.InitLLMClient(ctx, apiKey, modelName, apiEndpoint); err != nil {
+		return nil, err
+	}
+	return m.client, nil
+}
@@ -0,0 +1,62 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/fileutil"
+	"github.com/phrazzld/thinktank/internal/ratelimit"
+)
+
+// newFormatOrchestrator creates an orchestrator that renders its context files with format
+func newFormatOrchestrator(t *testing.T, apiService *scriptedAPIService, format string) *Orchestrator {
+	t.Helper()
+
+	gatherer := &manifestContextGatherer{files: []fileutil.FileMeta{
+		{Path: "main.go", Content: "package main"},
+	}}
+	cfg := &config.CliConfig{
+		ModelNames: []string{"model-a"},
+		OutputDir:  filepath.Join(t.TempDir(), "output"),
+		Format:     format,
+	}
+	return NewOrchestrator(apiService, gatherer, &syncFileWriter{}, NewMockAuditLogger(),
+		ratelimit.NewRateLimiter(0, 0), cfg, &MockLogger{})
+}
+
+// TestRunContextFormat tests that the context files are rendered with the configured format
+func TestRunContextFormat(t *testing.T) {
+	apiService := &scriptedAPIService{respond: func(modelName, promptText string) (string, error) {
+		return "output", nil
+	}}
+	orch := newFormatOrchestrator(t, apiService, "markdown")
+
+	if err := orch.Run(context.Background(), "Review it"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	prompts := apiService.promptsFor("model-a")
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "<context>\n## main.go\n\n```go\npackage main\n```\n\n</context>") {
+		t.Errorf("Expected the context files as Markdown, got %v", prompts)
+	}
+}
+
+// TestRunInvalidContextFormat tests that an unknown format fails the run before any model
+// is called
+func TestRunInvalidContextFormat(t *testing.T) {
+	apiService := &scriptedAPIService{respond: func(modelName, promptText string) (string, error) {
+		return "output", nil
+	}}
+	orch := newFormatOrchestrator(t, apiService, "html")
+
+	if err := orch.Run(context.Background(), "Review it"); !errors.Is(err, ErrInvalidContextFormat) {
+		t.Fatalf("Expected ErrInvalidContextFormat, got: %v", err)
+	}
+	if len(apiService.promptsFor("model-a")) != 0 {
+		t.Error("Expected no model to be called")
+	}
+}
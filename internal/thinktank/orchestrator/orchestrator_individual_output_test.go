@@ -30,6 +30,11 @@ func (m *MockOutputWriter) SaveSynthesisOutput(ctx context.Context, content stri
 	return nil
 }
 
+// SaveManifest is a mock implementation (not used in these tests)
+func (m *MockOutputWriter) SaveManifest(ctx context.Context, manifest *Manifest, outputDir string) error {
+	return nil
+}
+
 // MockLoggerWithOutputRecorder records log calls for verification
 type MockLoggerWithOutputRecorder struct {
 	MockLogger
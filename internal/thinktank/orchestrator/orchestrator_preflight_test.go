@@ -0,0 +1,102 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/ratelimit"
+)
+
+// countingAPIService records the models for which a client was initialized
+type countingAPIService struct {
+	limitsAPIService
+	mu          sync.Mutex
+	initialized []string
+}
+
+func (m *countingAPIService) InitLLMClient(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+	m.mu.Lock()
+	m.initialized = append(m.initialized, modelName)
+	m.mu.Unlock()
+	return m.limitsAPIService.InitLLMClient(ctx, apiKey, modelName, apiEndpoint)
+}
+
+func TestPreflightModel(t *testing.T) {
+	tests := []struct {
+		name         string
+		limits       map[string][2]int32
+		promptTokens int
+		wantErr      bool
+	}{
+		{"Prompt fits", map[string][2]int32{"model": {8192, 1024}}, 1000, false},
+		{"Prompt leaves little room for output", map[string][2]int32{"model": {8192, 4096}}, 6000, false},
+		{"Prompt exceeds context window", map[string][2]int32{"model": {8192, 1024}}, 9000, true},
+		{"Unknown limits are not checked", map[string][2]int32{}, 1000000, false},
+		{"Zero context window is not checked", map[string][2]int32{"model": {0, 0}}, 1000, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditLogger := NewMockAuditLogger()
+			orch := &Orchestrator{
+				apiService:  &limitsAPIService{limits: tt.limits},
+				auditLogger: auditLogger,
+				logger:      &MockLogger{},
+			}
+
+			err := orch.preflightModel(context.Background(), "model", tt.promptTokens)
+			if tt.wantErr {
+				if !errors.Is(err, ErrPromptExceedsContextWindow) {
+					t.Fatalf("Expected ErrPromptExceedsContextWindow, got: %v", err)
+				}
+				if len(auditLogger.LogCalls) != 1 || auditLogger.LogCalls[0].Operation != "PreflightCheck" {
+					t.Errorf("Expected a PreflightCheck audit entry, got %+v", auditLogger.LogCalls)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestRunSkipsModelsThatCannotFitPrompt tests that a model whose context window is too small
+// is skipped without calling its API, while the other models still run
+func TestRunSkipsModelsThatCannotFitPrompt(t *testing.T) {
+	apiService := &countingAPIService{
+		limitsAPIService: limitsAPIService{limits: map[string][2]int32{
+			"tiny-model":  {4, 0},
+			"large-model": {1000000, 65000},
+		}},
+	}
+	cfg := &config.CliConfig{
+		ModelNames: []string{"tiny-model", "large-model"},
+		OutputDir:  t.TempDir(),
+	}
+
+	orch := NewOrchestrator(
+		apiService,
+		&MockContextGatherer{},
+		&MockFileWriter{},
+		NewMockAuditLogger(),
+		ratelimit.NewRateLimiter(0, 0),
+		cfg,
+		&MockLogger{},
+	)
+
+	err := orch.Run(context.Background(), "Review this code for bugs")
+	if !errors.Is(err, ErrPartialProcessingFailure) {
+		t.Fatalf("Expected ErrPartialProcessingFailure, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), ErrPromptExceedsContextWindow.Error()) {
+		t.Errorf("Expected error to mention the context window, got: %v", err)
+	}
+
+	if len(apiService.initialized) != 1 || apiService.initialized[0] != "large-model" {
+		t.Errorf("Expected only large-model to be called, got %v", apiService.initialized)
+	}
+}
@@ -0,0 +1,161 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/ratelimit"
+)
+
+// writeResumeDir creates the output directory of a previous run with the given output
+// files and, if it is not nil, manifest
+func writeResumeDir(t *testing.T, manifest *Manifest, outputs map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if manifest != nil {
+		content, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatalf("Failed to encode manifest: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, ManifestFileName), content, 0644); err != nil {
+			t.Fatalf("Failed to write manifest: %v", err)
+		}
+	}
+	for name, content := range outputs {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write output: %v", err)
+		}
+	}
+	return dir
+}
+
+// newResumeOrchestrator creates an orchestrator that resumes the run in resumeDir
+func newResumeOrchestrator(apiService *scriptedAPIService, resumeDir string, modelNames ...string) (*Orchestrator, *syncFileWriter, *MockAuditLogger) {
+	cfg := &config.CliConfig{
+		ModelNames:     modelNames,
+		ModelFallbacks: map[string][]string{"model-c": {"model-d"}},
+		SynthesisModel: "synthesizer",
+		OutputDir:      resumeDir,
+		ResumeDir:      resumeDir,
+	}
+	fileWriter := &syncFileWriter{}
+	auditLogger := NewMockAuditLogger()
+	orch := NewOrchestrator(apiService, &MockContextGatherer{}, fileWriter, auditLogger,
+		ratelimit.NewRateLimiter(0, 0), cfg, &MockLogger{})
+	return orch, fileWriter, auditLogger
+}
+
+// resumeResponder answers each model with a new output naming the model
+func resumeResponder(modelName, promptText string) (string, error) {
+	if modelName == "synthesizer" {
+		return "synthesis", nil
+	}
+	return "new output from " + modelName, nil
+}
+
+// TestRunResume tests that only the models that failed in the resumed run are processed
+// again, and that synthesis combines the reused and new outputs
+func TestRunResume(t *testing.T) {
+	manifest := &Manifest{
+		Instructions: newManifestFile("instructions.md", "Plan it"),
+		Models: []ManifestModel{
+			{ModelReport: ModelReport{Model: "model-a", Status: ReportStatusSuccess}},
+			{ModelReport: ModelReport{Model: "model-b", Status: ReportStatusFailure}},
+			{ModelReport: ModelReport{Model: "model-c", AnsweredBy: "model-d", Status: ReportStatusSuccess}},
+		},
+	}
+	resumeDir := writeResumeDir(t, manifest, map[string]string{
+		"model-a.md": "old output from model-a",
+		"model-d.md": "old output from model-d",
+	})
+	apiService := &scriptedAPIService{respond: resumeResponder}
+	orch, _, auditLogger := newResumeOrchestrator(apiService, resumeDir, "model-a", "model-b", "model-c")
+
+	if err := orch.Run(context.Background(), "Plan it"); err != nil {
+		t.Fatalf("Expected the resumed run to succeed, got: %v", err)
+	}
+
+	for _, modelName := range []string{"model-a", "model-c", "model-d"} {
+		if prompts := apiService.promptsFor(modelName); len(prompts) != 0 {
+			t.Errorf("Expected the output of %s to be reused, got %d calls", modelName, len(prompts))
+		}
+	}
+	if prompts := apiService.promptsFor("model-b"); len(prompts) != 1 {
+		t.Errorf("Expected model-b to be processed again, got %d calls", len(prompts))
+	}
+
+	synthesisPrompts := apiService.promptsFor("synthesizer")
+	if len(synthesisPrompts) != 1 {
+		t.Fatalf("Expected one synthesis prompt, got %d", len(synthesisPrompts))
+	}
+	for _, output := range []string{"old output from model-a", "new output from model-b", "old output from model-d"} {
+		if !strings.Contains(synthesisPrompts[0], output) {
+			t.Errorf("Expected synthesis to combine %q, got:\n%s", output, synthesisPrompts[0])
+		}
+	}
+
+	report := orch.Report()
+	if len(report.Models) != 3 || !report.Models[0].Resumed || report.Models[1].Resumed ||
+		!report.Models[2].Resumed || report.Models[2].AnsweredBy != "model-d" {
+		t.Errorf("Expected model-a and model-c to be reported as resumed, got %+v", report.Models)
+	}
+	if statuses := auditStatuses(auditLogger, "ResumeRun"); len(statuses) != 1 || statuses[0] != "Success" {
+		t.Errorf("Expected a successful ResumeRun entry, got %v", statuses)
+	}
+}
+
+// TestRunResumeWithoutManifest tests that a run without a manifest is not resumed, even
+// when it left output files
+func TestRunResumeWithoutManifest(t *testing.T) {
+	resumeDir := writeResumeDir(t, nil, map[string]string{"model-a.md": "old output from model-a"})
+	apiService := &scriptedAPIService{respond: resumeResponder}
+	orch, _, _ := newResumeOrchestrator(apiService, resumeDir, "model-a", "model-b")
+
+	err := orch.Run(context.Background(), "Plan it")
+	if !errors.Is(err, ErrResumeFailed) {
+		t.Fatalf("Expected ErrResumeFailed, got: %v", err)
+	}
+	if len(apiService.promptsFor("model-a")) != 0 || len(apiService.promptsFor("model-b")) != 0 {
+		t.Error("Expected no model to be processed")
+	}
+}
+
+// TestRunResumeMissingOutput tests that a model recorded as successful is processed again
+// when its output file is missing
+func TestRunResumeMissingOutput(t *testing.T) {
+	manifest := &Manifest{Models: []ManifestModel{
+		{ModelReport: ModelReport{Model: "model-a", Status: ReportStatusSuccess}},
+	}}
+	resumeDir := writeResumeDir(t, manifest, nil)
+	apiService := &scriptedAPIService{respond: resumeResponder}
+	orch, _, _ := newResumeOrchestrator(apiService, resumeDir, "model-a")
+
+	if err := orch.Run(context.Background(), "Plan it"); err != nil {
+		t.Fatalf("Expected the resumed run to succeed, got: %v", err)
+	}
+	if len(apiService.promptsFor("model-a")) != 1 {
+		t.Errorf("Expected model-a to be processed again, got %d calls", len(apiService.promptsFor("model-a")))
+	}
+}
+
+// TestRunResumeInvalidManifest tests that an unreadable manifest fails the run
+func TestRunResumeInvalidManifest(t *testing.T) {
+	resumeDir := writeResumeDir(t, nil, map[string]string{ManifestFileName: "{"})
+	apiService := &scriptedAPIService{respond: resumeResponder}
+	orch, _, _ := newResumeOrchestrator(apiService, resumeDir, "model-a")
+
+	err := orch.Run(context.Background(), "Plan it")
+	if !errors.Is(err, ErrResumeFailed) {
+		t.Fatalf("Expected ErrResumeFailed, got: %v", err)
+	}
+	if len(apiService.promptsFor("model-a")) != 0 {
+		t.Error("Expected no model to be processed")
+	}
+}
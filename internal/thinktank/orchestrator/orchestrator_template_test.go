@@ -0,0 +1,65 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/fileutil"
+	"github.com/phrazzld/thinktank/internal/ratelimit"
+)
+
+// newTemplateOrchestrator creates an orchestrator that executes the instructions as a template
+func newTemplateOrchestrator(t *testing.T, apiService *scriptedAPIService) *Orchestrator {
+	t.Helper()
+
+	gatherer := &manifestContextGatherer{files: []fileutil.FileMeta{
+		{Path: "main.go", Content: "package main"},
+	}}
+	cfg := &config.CliConfig{
+		ModelNames:           []string{"model-a"},
+		OutputDir:            filepath.Join(t.TempDir(), "output"),
+		TemplateInstructions: true,
+		TemplateVars:         map[string]string{"focus": "error handling"},
+	}
+	return NewOrchestrator(apiService, gatherer, &syncFileWriter{}, NewMockAuditLogger(),
+		ratelimit.NewRateLimiter(0, 0), cfg, &MockLogger{})
+}
+
+// TestRunInstructionsTemplate tests that the instructions sent to the models are the
+// executed template
+func TestRunInstructionsTemplate(t *testing.T) {
+	apiService := &scriptedAPIService{respond: func(modelName, promptText string) (string, error) {
+		return "output", nil
+	}}
+	orch := newTemplateOrchestrator(t, apiService)
+
+	if err := orch.Run(context.Background(), "Review the {{.Vars.focus}} in {{join .Files \", \"}}."); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	prompts := apiService.promptsFor("model-a")
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "<instructions>\nReview the error handling in main.go.\n</instructions>") {
+		t.Errorf("Expected the rendered instructions, got %v", prompts)
+	}
+}
+
+// TestRunInstructionsTemplateError tests that an invalid template fails the run before
+// any model is called
+func TestRunInstructionsTemplateError(t *testing.T) {
+	apiService := &scriptedAPIService{respond: func(modelName, promptText string) (string, error) {
+		return "output", nil
+	}}
+	orch := newTemplateOrchestrator(t, apiService)
+
+	err := orch.Run(context.Background(), "Review the {{.Vars.undefined}}.")
+	if !errors.Is(err, ErrInvalidInstructionsTemplate) {
+		t.Fatalf("Expected ErrInvalidInstructionsTemplate, got: %v", err)
+	}
+	if len(apiService.promptsFor("model-a")) != 0 {
+		t.Error("Expected no model to be called")
+	}
+}
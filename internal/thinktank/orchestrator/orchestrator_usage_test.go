@@ -0,0 +1,196 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/auditlog"
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/ratelimit"
+	"github.com/phrazzld/thinktank/internal/registry"
+	"github.com/phrazzld/thinktank/internal/thinktank/modelproc"
+)
+
+// usageAPIService returns clients that report token usage and models with pricing
+type usageAPIService struct {
+	MockAPIService
+	usage   *llm.TokenUsage
+	pricing *registry.PricingDefinition
+}
+
+func (m *usageAPIService) InitLLMClient(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+	return &llm.MockLLMClient{
+		GenerateContentFunc: func(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+			return &llm.ProviderResult{
+				Content:      "Output from " + modelName,
+				FinishReason: "stop",
+				Usage:        m.usage,
+			}, nil
+		},
+	}, nil
+}
+
+func (m *usageAPIService) GetModelDefinition(modelName string) (*registry.ModelDefinition, error) {
+	return &registry.ModelDefinition{Name: modelName, Pricing: m.pricing}, nil
+}
+
+// findEntry returns the first audit entry with the given operation and status, or nil
+func findEntry(entries []auditlog.AuditEntry, operation, status string) *auditlog.AuditEntry {
+	for i := range entries {
+		if entries[i].Operation == operation && entries[i].Status == status {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+// TestRunReportsUsageSummary tests that a run records per-model usage and a run summary
+func TestRunReportsUsageSummary(t *testing.T) {
+	apiService := &usageAPIService{
+		usage:   &llm.TokenUsage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500},
+		pricing: &registry.PricingDefinition{InputPerMillion: 2.0, OutputPerMillion: 8.0},
+	}
+	auditLogger := NewMockAuditLogger()
+	cfg := &config.CliConfig{
+		ModelNames: []string{"model-a", "model-b"},
+		OutputDir:  t.TempDir(),
+	}
+
+	orch := NewOrchestrator(
+		apiService,
+		&MockContextGatherer{},
+		&MockFileWriter{},
+		auditLogger,
+		ratelimit.NewRateLimiter(0, 0),
+		cfg,
+		&MockLogger{},
+	)
+
+	if err := orch.Run(context.Background(), "Test instructions"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	generateEntries := 0
+	for _, entry := range auditLogger.Entries {
+		if entry.Operation == "GenerateContent" && entry.Status == "Success" {
+			generateEntries++
+			if entry.TokenCounts == nil || entry.TokenCounts.TotalTokens != 1500 {
+				t.Errorf("Expected GenerateContent token counts with 1500 total tokens, got %+v", entry.TokenCounts)
+			}
+		}
+	}
+	if generateEntries != 2 {
+		t.Errorf("Expected 2 GenerateContent success entries, got %d", generateEntries)
+	}
+
+	summary := findEntry(auditLogger.Entries, "RunSummary", "Success")
+	if summary == nil {
+		t.Fatal("Expected a RunSummary audit entry")
+	}
+	if summary.TokenCounts == nil {
+		t.Fatal("Expected token counts in the run summary")
+	}
+	if summary.TokenCounts.PromptTokens != 2000 || summary.TokenCounts.OutputTokens != 1000 || summary.TokenCounts.TotalTokens != 3000 {
+		t.Errorf("Unexpected run summary token counts: %+v", summary.TokenCounts)
+	}
+
+	// Each model costs 1000*2/1M + 500*8/1M = $0.006
+	cost, ok := summary.Outputs["estimated_cost_usd"].(float64)
+	if !ok {
+		t.Fatalf("Expected estimated_cost_usd in run summary outputs, got %v", summary.Outputs)
+	}
+	if cost < 0.01199 || cost > 0.01201 {
+		t.Errorf("Expected total cost of 0.012, got %f", cost)
+	}
+	models, ok := summary.Outputs["models"].([]map[string]interface{})
+	if !ok || len(models) != 2 {
+		t.Errorf("Expected per-model usage for 2 models, got %v", summary.Outputs["models"])
+	}
+}
+
+// TestRunWithoutUsageSkipsSummary tests that no summary is written when providers report no usage
+func TestRunWithoutUsageSkipsSummary(t *testing.T) {
+	auditLogger := NewMockAuditLogger()
+	cfg := &config.CliConfig{
+		ModelNames: []string{"model-a"},
+		OutputDir:  t.TempDir(),
+	}
+
+	orch := NewOrchestrator(
+		&usageAPIService{},
+		&MockContextGatherer{},
+		&MockFileWriter{},
+		auditLogger,
+		ratelimit.NewRateLimiter(0, 0),
+		cfg,
+		&MockLogger{},
+	)
+
+	if err := orch.Run(context.Background(), "Test instructions"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if summary := findEntry(auditLogger.Entries, "RunSummary", "Success"); summary != nil {
+		t.Errorf("Expected no RunSummary entry without usage, got %+v", summary)
+	}
+}
+
+// TestLogUsageSummaryUnknownCost tests that the total cost is omitted when a model has no pricing
+func TestLogUsageSummaryUnknownCost(t *testing.T) {
+	auditLogger := NewMockAuditLogger()
+	tracker := modelproc.NewUsageTracker()
+	tracker.Record(modelproc.ModelUsage{ModelName: "priced", Operation: "GenerateContent", PromptTokens: 10, TotalTokens: 10, Cost: 0.1, CostKnown: true})
+	tracker.Record(modelproc.ModelUsage{ModelName: "unpriced", Operation: "GenerateContent", PromptTokens: 5, TotalTokens: 5})
+
+	orch := &Orchestrator{
+		auditLogger:  auditLogger,
+		logger:       &MockLogger{},
+		usageTracker: tracker,
+	}
+	orch.logUsageSummary(context.Background())
+
+	summary := findEntry(auditLogger.Entries, "RunSummary", "Success")
+	if summary == nil {
+		t.Fatal("Expected a RunSummary audit entry")
+	}
+	if summary.TokenCounts.TotalTokens != 15 {
+		t.Errorf("Expected 15 total tokens, got %d", summary.TokenCounts.TotalTokens)
+	}
+	if _, ok := summary.Outputs["estimated_cost_usd"]; ok {
+		t.Error("Expected no total cost when some models have no pricing")
+	}
+}
+
+// TestSynthesizeResultsRecordsUsage tests that the synthesis call reports its token usage
+func TestSynthesizeResultsRecordsUsage(t *testing.T) {
+	mockAPI := &MockSynthesisAPIService{
+		ClientGenerateResult: &llm.ProviderResult{
+			Content:      "Synthesized",
+			FinishReason: "stop",
+			Usage:        &llm.TokenUsage{PromptTokens: 300, CompletionTokens: 100, TotalTokens: 400},
+		},
+	}
+	auditLogger := NewMockAuditLogger()
+	tracker := modelproc.NewUsageTracker()
+
+	service := NewSynthesisService(mockAPI, auditLogger, &MockLogger{}, "synthesis-model").(*DefaultSynthesisService)
+	service.SetUsageTracker(tracker)
+
+	if _, err := service.SynthesizeResults(context.Background(), "instructions", map[string]string{"model1": "output"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	entry := findEntry(auditLogger.Entries, "SynthesisAPICall", "Success")
+	if entry == nil {
+		t.Fatal("Expected a SynthesisAPICall success entry with token counts")
+	}
+	if entry.TokenCounts == nil || entry.TokenCounts.PromptTokens != 300 || entry.TokenCounts.OutputTokens != 100 {
+		t.Errorf("Unexpected synthesis token counts: %+v", entry.TokenCounts)
+	}
+
+	records := tracker.Records()
+	if len(records) != 1 || records[0].ModelName != "synthesis-model" || records[0].Operation != "SynthesisAPICall" {
+		t.Errorf("Expected one synthesis usage record, got %+v", records)
+	}
+}
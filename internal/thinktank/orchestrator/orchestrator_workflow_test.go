@@ -0,0 +1,305 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/ratelimit"
+	"github.com/phrazzld/thinktank/internal/workflow"
+)
+
+// scriptedAPIService answers each prompt with respond and records the prompts sent to each model
+type scriptedAPIService struct {
+	MockAPIService
+	respond func(modelName, prompt string) (string, error)
+
+	mu      sync.Mutex
+	prompts map[string][]string
+}
+
+func (m *scriptedAPIService) InitLLMClient(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+	return &scriptedLLMClient{service: m, modelName: modelName}, nil
+}
+
+// promptsFor returns the prompts sent to a model
+func (m *scriptedAPIService) promptsFor(modelName string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.prompts[modelName]
+}
+
+// scriptedLLMClient generates content for one model of a scriptedAPIService
+type scriptedLLMClient struct {
+	MockLLMClient
+	service   *scriptedAPIService
+	modelName string
+}
+
+func (c *scriptedLLMClient) GenerateContent(ctx context.Context, prompt string, params map[string]interface{}) (*llm.ProviderResult, error) {
+	c.service.mu.Lock()
+	if c.service.prompts == nil {
+		c.service.prompts = make(map[string][]string)
+	}
+	c.service.prompts[c.modelName] = append(c.service.prompts[c.modelName], prompt)
+	c.service.mu.Unlock()
+
+	content, err := c.service.respond(c.modelName, prompt)
+	if err != nil {
+		return nil, err
+	}
+	return &llm.ProviderResult{Content: content}, nil
+}
+
+// syncFileWriter records saved files and is safe for concurrent use
+type syncFileWriter struct {
+	mu    sync.Mutex
+	files map[string]string
+}
+
+func (w *syncFileWriter) SaveToFile(content, outputFile string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.files == nil {
+		w.files = make(map[string]string)
+	}
+	w.files[outputFile] = content
+	return nil
+}
+
+// newWorkflowOrchestrator writes a workflow file and creates an orchestrator that runs it
+func newWorkflowOrchestrator(t *testing.T, definition string, apiService *scriptedAPIService) (*Orchestrator, *syncFileWriter, *MockAuditLogger, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	workflowFile := filepath.Join(dir, "workflow.yaml")
+	if err := os.WriteFile(workflowFile, []byte(definition), 0644); err != nil {
+		t.Fatalf("Failed to write workflow file: %v", err)
+	}
+	def, err := workflow.Load(workflowFile)
+	if err != nil {
+		t.Fatalf("Invalid workflow: %v", err)
+	}
+
+	outputDir := filepath.Join(dir, "output")
+	cfg := &config.CliConfig{
+		ModelNames:   def.ModelNames(),
+		WorkflowFile: workflowFile,
+		OutputDir:    outputDir,
+	}
+	fileWriter := &syncFileWriter{}
+	auditLogger := NewMockAuditLogger()
+
+	orch := NewOrchestrator(apiService, &MockContextGatherer{}, fileWriter, auditLogger,
+		ratelimit.NewRateLimiter(0, 0), cfg, &MockLogger{})
+	return orch, fileWriter, auditLogger, outputDir
+}
+
+// auditStatuses returns the statuses of the audit entries for an operation
+func auditStatuses(auditLogger *MockAuditLogger, operation string) []string {
+	var statuses []string
+	for _, call := range auditLogger.LogCalls {
+		if call.Operation == operation {
+			statuses = append(statuses, call.Status)
+		}
+	}
+	return statuses
+}
+
+// TestRunWorkflowPlanCritiqueRevise tests that steps run in dependency order, receive
+// the outputs of the steps they need, and that the final step's output is saved
+func TestRunWorkflowPlanCritiqueRevise(t *testing.T) {
+	const definition = `
+name: plan-critique-revise
+steps:
+  - id: plan
+    type: generate
+    models: [planner-a, planner-b]
+  - id: critique
+    type: critique
+    models: [critic]
+    needs: [plan]
+  - id: revise
+    type: refine
+    models: [planner-a]
+    needs: [plan, critique]
+`
+	apiService := &scriptedAPIService{respond: func(modelName, prompt string) (string, error) {
+		switch {
+		case strings.Contains(prompt, "Produce a single improved response"):
+			return "revised plan", nil
+		case strings.Contains(prompt, "Critique the responses above"):
+			return "critique of plans", nil
+		default:
+			return "plan from " + modelName, nil
+		}
+	}}
+	orch, fileWriter, auditLogger, outputDir := newWorkflowOrchestrator(t, definition, apiService)
+
+	if err := orch.Run(context.Background(), "Plan the feature"); err != nil {
+		t.Fatalf("Expected the workflow to succeed, got: %v", err)
+	}
+
+	critiquePrompts := apiService.promptsFor("critic")
+	if len(critiquePrompts) != 1 {
+		t.Fatalf("Expected one critique prompt, got %d", len(critiquePrompts))
+	}
+	for _, expected := range []string{"Plan the feature", "plan from planner-a", "plan from planner-b"} {
+		if !strings.Contains(critiquePrompts[0], expected) {
+			t.Errorf("Expected the critique prompt to contain %q", expected)
+		}
+	}
+
+	plannerPrompts := apiService.promptsFor("planner-a")
+	if len(plannerPrompts) != 2 {
+		t.Fatalf("Expected planner-a to run the plan and revise steps, got %d prompts", len(plannerPrompts))
+	}
+	revisePrompt := plannerPrompts[1]
+	if !strings.Contains(revisePrompt, "critique of plans") || !strings.Contains(revisePrompt, "plan from planner-b") {
+		t.Errorf("Expected the revise prompt to contain the plans and the critique, got:\n%s", revisePrompt)
+	}
+
+	expectedFiles := map[string]string{
+		filepath.Join(outputDir, "plan", "planner-a.md"):   "plan from planner-a",
+		filepath.Join(outputDir, "plan", "planner-b.md"):   "plan from planner-b",
+		filepath.Join(outputDir, "critique", "critic.md"):  "critique of plans",
+		filepath.Join(outputDir, "revise", "planner-a.md"): "revised plan",
+		filepath.Join(outputDir, "planner-a.md"):           "revised plan",
+	}
+	for path, content := range expectedFiles {
+		if fileWriter.files[path] != content {
+			t.Errorf("Expected %s to contain %q, got %q", path, content, fileWriter.files[path])
+		}
+	}
+	// The remaining file is the run manifest
+	if len(fileWriter.files) != len(expectedFiles)+1 {
+		t.Errorf("Expected %d files and the manifest, got %v", len(expectedFiles), fileWriter.files)
+	}
+
+	if statuses := auditStatuses(auditLogger, "WorkflowStep"); len(statuses) != 6 {
+		t.Errorf("Expected InProgress and Success entries for each step, got %v", statuses)
+	}
+	if statuses := auditStatuses(auditLogger, "WorkflowComplete"); len(statuses) != 1 || statuses[0] != "Success" {
+		t.Errorf("Expected a successful WorkflowComplete entry, got %v", statuses)
+	}
+}
+
+// TestRunWorkflowVote tests that a vote step chooses the candidate with the most votes
+func TestRunWorkflowVote(t *testing.T) {
+	const definition = `
+name: best-of
+steps:
+  - id: draft
+    type: generate
+    models: [writer-a, writer-b]
+  - id: vote
+    type: vote
+    models: [judge-a, judge-b, judge-c]
+    needs: [draft]
+`
+	ballots := map[string]string{"judge-a": "2", "judge-b": "Candidate 1", "judge-c": "2\nMore thorough."}
+	apiService := &scriptedAPIService{respond: func(modelName, prompt string) (string, error) {
+		if ballot, ok := ballots[modelName]; ok {
+			return ballot, nil
+		}
+		return "draft from " + modelName, nil
+	}}
+	orch, fileWriter, auditLogger, outputDir := newWorkflowOrchestrator(t, definition, apiService)
+
+	if err := orch.Run(context.Background(), "Write the docs"); err != nil {
+		t.Fatalf("Expected the workflow to succeed, got: %v", err)
+	}
+
+	judgePrompt := apiService.promptsFor("judge-a")[0]
+	if !strings.Contains(judgePrompt, "<candidate number=\"2\">\ndraft from writer-b\n</candidate>") {
+		t.Errorf("Expected numbered candidates in the vote prompt, got:\n%s", judgePrompt)
+	}
+
+	if got := fileWriter.files[filepath.Join(outputDir, "vote.md")]; got != "draft from writer-b" {
+		t.Errorf("Expected the winning draft to be saved as vote.md, got %q", got)
+	}
+	if got := fileWriter.files[filepath.Join(outputDir, "vote", "judge-c.md")]; got != "2\nMore thorough." {
+		t.Errorf("Expected the ballots to be saved in the step directory, got %q", got)
+	}
+
+	var voteCall *LogCall
+	for i, call := range auditLogger.LogCalls {
+		if call.Operation == "WorkflowVote" {
+			voteCall = &auditLogger.LogCalls[i]
+		}
+	}
+	if voteCall == nil {
+		t.Fatal("Expected a WorkflowVote audit entry")
+	}
+	if voteCall.Outputs["winner"] != "draft/writer-b" {
+		t.Errorf("Expected draft/writer-b to win, got %v", voteCall.Outputs["winner"])
+	}
+}
+
+// TestRunWorkflowFailures tests how failed models and steps are reported
+func TestRunWorkflowFailures(t *testing.T) {
+	const definition = `
+name: review
+steps:
+  - id: plan
+    type: generate
+    models: [planner-a, planner-b]
+  - id: critique
+    type: critique
+    models: [critic]
+    needs: [plan]
+`
+	filtered := llm.New("test", "", 400, "blocked", "", nil, llm.CategoryContentFiltered)
+
+	t.Run("some models fail", func(t *testing.T) {
+		apiService := &scriptedAPIService{respond: func(modelName, prompt string) (string, error) {
+			if modelName == "planner-a" {
+				return "", filtered
+			}
+			return "output from " + modelName, nil
+		}}
+		orch, fileWriter, _, outputDir := newWorkflowOrchestrator(t, definition, apiService)
+
+		err := orch.Run(context.Background(), "Plan it")
+		if !errors.Is(err, ErrPartialProcessingFailure) {
+			t.Fatalf("Expected ErrPartialProcessingFailure, got: %v", err)
+		}
+		if !strings.Contains(err.Error(), "step plan") {
+			t.Errorf("Expected the error to name the step, got: %v", err)
+		}
+		if fileWriter.files[filepath.Join(outputDir, "critic.md")] != "output from critic" {
+			t.Error("Expected the critique to run with the remaining plan")
+		}
+	})
+
+	t.Run("all models of a step fail", func(t *testing.T) {
+		apiService := &scriptedAPIService{respond: func(modelName, prompt string) (string, error) {
+			if strings.HasPrefix(modelName, "planner") {
+				return "", filtered
+			}
+			return "output from " + modelName, nil
+		}}
+		orch, _, auditLogger, _ := newWorkflowOrchestrator(t, definition, apiService)
+
+		err := orch.Run(context.Background(), "Plan it")
+		if !errors.Is(err, ErrWorkflowStepFailed) {
+			t.Fatalf("Expected ErrWorkflowStepFailed, got: %v", err)
+		}
+		if !strings.Contains(err.Error(), "step critique skipped") {
+			t.Errorf("Expected the dependent step to be skipped, got: %v", err)
+		}
+		if prompts := apiService.promptsFor("critic"); len(prompts) != 0 {
+			t.Errorf("Expected the critique step not to run, got %d prompts", len(prompts))
+		}
+		if statuses := auditStatuses(auditLogger, "WorkflowComplete"); fmt.Sprint(statuses) != "[Failure]" {
+			t.Errorf("Expected a failed WorkflowComplete entry, got %v", statuses)
+		}
+	})
+}
@@ -6,6 +6,7 @@ package orchestrator
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 
@@ -23,6 +24,9 @@ type OutputWriter interface {
 
 	// SaveSynthesisOutput saves the synthesis result to a file
 	SaveSynthesisOutput(ctx context.Context, content string, modelName string, outputDir string) error
+
+	// SaveManifest saves the manifest describing the run to manifest.json
+	SaveManifest(ctx context.Context, manifest *Manifest, outputDir string) error
 }
 
 // DefaultOutputWriter implements the OutputWriter interface
@@ -68,11 +72,8 @@ func (w *DefaultOutputWriter) SaveIndividualOutputs(
 
 	// Iterate over the model outputs and save each to a file
 	for modelName, content := range modelOutputs {
-		// Sanitize model name for use in filename
-		sanitizedModelName := modelproc.SanitizeFilename(modelName)
-
-		// Construct output file path
-		outputFilePath := filepath.Join(outputDir, sanitizedModelName+".md")
+		// Construct output file path, with the model name sanitized for use in the filename
+		outputFilePath := individualOutputPath(outputDir, modelName)
 
 		// Save the output to file
 		contextLogger.DebugContext(ctx, "Saving output for model %s to %s", modelName, outputFilePath)
@@ -110,11 +111,8 @@ func (w *DefaultOutputWriter) SaveSynthesisOutput(
 	// Get logger with context
 	contextLogger := w.logger.WithContext(ctx)
 
-	// Sanitize model name for use in filename
-	sanitizedModelName := modelproc.SanitizeFilename(modelName)
-
 	// Construct output file path with -synthesis suffix
-	outputFilePath := filepath.Join(outputDir, sanitizedModelName+"-synthesis.md")
+	outputFilePath := synthesisOutputPath(outputDir, modelName)
 
 	// Save the synthesis output to file
 	contextLogger.DebugContext(ctx, "Saving synthesis output to %s", outputFilePath)
@@ -126,3 +124,43 @@ func (w *DefaultOutputWriter) SaveSynthesisOutput(
 	contextLogger.InfoContext(ctx, "Successfully saved synthesis output to %s", outputFilePath)
 	return nil
 }
+
+// SaveManifest saves the manifest describing the run to manifest.json in the output
+// directory, as indented JSON so that it is readable alongside the outputs.
+func (w *DefaultOutputWriter) SaveManifest(
+	ctx context.Context,
+	manifest *Manifest,
+	outputDir string,
+) error {
+	// Get logger with context
+	contextLogger := w.logger.WithContext(ctx)
+
+	outputFilePath := filepath.Join(outputDir, ManifestFileName)
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		contextLogger.ErrorContext(ctx, "Failed to encode run manifest: %v", err)
+		return fmt.Errorf("%w: failed to encode manifest: %v", ErrOutputFileSaveFailed, err)
+	}
+
+	contextLogger.DebugContext(ctx, "Saving run manifest to %s", outputFilePath)
+	if err := w.fileWriter.SaveToFile(string(content)+"\n", outputFilePath); err != nil {
+		contextLogger.ErrorContext(ctx, "Failed to save run manifest: %v", err)
+		return fmt.Errorf("%w: failed to save manifest to %s: %v", ErrOutputFileSaveFailed, outputFilePath, err)
+	}
+
+	contextLogger.InfoContext(ctx, "Successfully saved run manifest to %s", outputFilePath)
+	return nil
+}
+
+// individualOutputPath returns the path of the output of a model, named after the
+// sanitized model name
+func individualOutputPath(outputDir, modelName string) string {
+	return filepath.Join(outputDir, modelproc.SanitizeFilename(modelName)+".md")
+}
+
+// synthesisOutputPath returns the path of the synthesis output of a model: the sanitized
+// model name with a -synthesis suffix
+func synthesisOutputPath(outputDir, modelName string) string {
+	return filepath.Join(outputDir, modelproc.SanitizeFilename(modelName)+"-synthesis.md")
+}
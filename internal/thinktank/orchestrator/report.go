@@ -0,0 +1,280 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/thinktank/modelproc"
+)
+
+// Statuses of a run, and of the models and synthesis within it, in a RunReport
+const (
+	ReportStatusSuccess        = "success"
+	ReportStatusPartialFailure = "partial_failure"
+	ReportStatusFailure        = "failure"
+)
+
+// RunReport is a machine-readable summary of a run, written to stdout as JSON with
+// --output-format json so that scripts don't have to parse the logs. It describes the
+// outcome of each model, the synthesis result, token usage and the files written.
+type RunReport struct {
+	RunID            string              `json:"run_id"`         // Name of the run's output directory
+	CorrelationID    string              `json:"correlation_id"` // Correlation ID of the run's logs and audit entries
+	Status           string              `json:"status"`         // ReportStatusSuccess, ReportStatusPartialFailure or ReportStatusFailure
+	OutputDir        string              `json:"output_dir"`
+	Models           []ModelReport       `json:"models"`
+	Synthesis        *SynthesisReport    `json:"synthesis,omitempty"`
+	Usage            *TokenReport        `json:"usage,omitempty"`              // Totals across every model call, including synthesis
+	ErrorsByCategory map[string][]string `json:"errors_by_category,omitempty"` // Failed models by llm.ErrorCategory
+	Error            string              `json:"error,omitempty"`              // Error the run failed with
+}
+
+// ModelReport describes the outcome of one requested model
+type ModelReport struct {
+	Step         string         `json:"step,omitempty"` // Workflow step the model ran in (empty without a workflow)
+	Model        string         `json:"model"`
+	AnsweredBy   string         `json:"answered_by,omitempty"` // Fallback model that answered instead of Model
+	Status       string         `json:"status"`
+	Content      string         `json:"content,omitempty"`
+	FinishReason string         `json:"finish_reason,omitempty"`
+	Truncated    bool           `json:"truncated"`
+	Safety       []SafetyReport `json:"safety,omitempty"`
+	DurationMs   int64          `json:"duration_ms"`
+	Tokens       *TokenReport   `json:"tokens,omitempty"`
+	OutputFile   string         `json:"output_file,omitempty"`
+	Resumed      bool           `json:"resumed,omitempty"` // Output was reused from the run being resumed (--resume)
+	Error        *ErrorReport   `json:"error,omitempty"`
+
+	// Critique holds the rounds of critique and revision of the output (--critique-refine),
+	// and CritiqueError the error that stopped them early, if any
+	Critique      []CritiqueReport `json:"critique,omitempty"`
+	CritiqueError *ErrorReport     `json:"critique_error,omitempty"`
+}
+
+// CritiqueReport describes a round of critique and revision of a model's output
+type CritiqueReport struct {
+	CriticModel  string `json:"critic_model"`
+	CritiqueFile string `json:"critique_file,omitempty"`
+	RefineModel  string `json:"refine_model,omitempty"` // Empty if the critic found nothing to change
+	RefinedFile  string `json:"refined_file,omitempty"`
+}
+
+// SynthesisReport describes the outcome of synthesizing the model outputs
+type SynthesisReport struct {
+	Model      string       `json:"model"`
+	Status     string       `json:"status"`
+	Content    string       `json:"content,omitempty"`
+	OutputFile string       `json:"output_file,omitempty"`
+	Error      *ErrorReport `json:"error,omitempty"`
+}
+
+// TokenReport holds token counts and the estimated cost, if pricing is known
+type TokenReport struct {
+	PromptTokens     int32    `json:"prompt_tokens"`
+	CompletionTokens int32    `json:"completion_tokens"`
+	TotalTokens      int32    `json:"total_tokens"`
+	EstimatedCostUSD *float64 `json:"estimated_cost_usd,omitempty"`
+}
+
+// SafetyReport holds a safety rating reported by a provider
+type SafetyReport struct {
+	Category string  `json:"category"`
+	Blocked  bool    `json:"blocked"`
+	Score    float32 `json:"score"`
+}
+
+// ErrorReport describes an error and its llm.ErrorCategory
+type ErrorReport struct {
+	Category string `json:"category"`
+	Message  string `json:"message"`
+}
+
+// Report returns the report of the run so far. The run's status and error are set by
+// Finish once the run has completed; the run ID and output directory are left to the caller.
+func (o *Orchestrator) Report() *RunReport {
+	report := o.report
+	report.Models = append([]ModelReport(nil), o.report.Models...)
+	if report.Models == nil {
+		report.Models = []ModelReport{}
+	}
+	if o.usageTracker != nil && len(o.usageTracker.Records()) > 0 {
+		report.Usage = newTokenReport(o.usageTracker.Totals())
+	}
+	return &report
+}
+
+// Finish records the outcome of the run: its status, the error it failed with, and the
+// failed models grouped by error category.
+func (r *RunReport) Finish(err error) {
+	succeeded := false
+	for _, model := range r.Models {
+		if model.Status == ReportStatusSuccess {
+			succeeded = true
+		}
+		if model.Error != nil {
+			name := model.Model
+			if model.Step != "" {
+				name = model.Step + "/" + model.Model
+			}
+			r.addError(model.Error.Category, name)
+		}
+	}
+	if r.Synthesis != nil && r.Synthesis.Error != nil {
+		r.addError(r.Synthesis.Error.Category, r.Synthesis.Model)
+	}
+
+	switch {
+	case err == nil:
+		r.Status = ReportStatusSuccess
+	case succeeded:
+		r.Status = ReportStatusPartialFailure
+		r.Error = err.Error()
+	default:
+		r.Status = ReportStatusFailure
+		r.Error = err.Error()
+	}
+}
+
+// addError records a failed model under an error category
+func (r *RunReport) addError(category, modelName string) {
+	if r.ErrorsByCategory == nil {
+		r.ErrorsByCategory = make(map[string][]string)
+	}
+	r.ErrorsByCategory[category] = append(r.ErrorsByCategory[category], modelName)
+}
+
+// recordModelResults adds the results of processing models to the report, in the
+// order the models were requested. The step is empty for runs without a workflow.
+func (o *Orchestrator) recordModelResults(step string, requested []string, results []modelResult) {
+	position := make(map[string]int, len(requested))
+	for i, modelName := range requested {
+		position[modelName] = i
+	}
+	ordered := append([]modelResult(nil), results...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return position[ordered[i].requestedModel] < position[ordered[j].requestedModel]
+	})
+
+	for _, result := range ordered {
+		o.report.Models = append(o.report.Models, newModelReport(step, result))
+	}
+}
+
+// recordSynthesis adds the outcome of synthesis to the report
+func (o *Orchestrator) recordSynthesis(content, outputFile string, err error) {
+	synthesis := &SynthesisReport{
+		Model:      o.config.SynthesisModel,
+		Status:     ReportStatusSuccess,
+		Content:    content,
+		OutputFile: outputFile,
+	}
+	if err != nil {
+		synthesis.Status = ReportStatusFailure
+		synthesis.Error = newErrorReport(err)
+	}
+	o.report.Synthesis = synthesis
+}
+
+// recordCritiques adds the critique rounds of the model outputs to the reports of the
+// models that answered, with the files they were saved to (if saved is set), and the
+// errors that stopped the critique of some outputs. Rounds and errors are keyed by the
+// model that answered.
+func (o *Orchestrator) recordCritiques(rounds map[string][]CritiqueRound, errs map[string]error, saved bool) {
+	for i, model := range o.report.Models {
+		if model.Step != "" || model.Status != ReportStatusSuccess {
+			continue
+		}
+		answeredBy := model.Model
+		if model.AnsweredBy != "" {
+			answeredBy = model.AnsweredBy
+		}
+
+		for _, artifact := range critiqueArtifacts(answeredBy, rounds[answeredBy]) {
+			report := CritiqueReport{CriticModel: artifact.round.CriticModel, RefineModel: artifact.round.RefineModel}
+			if saved {
+				report.CritiqueFile = individualOutputPath(o.config.OutputDir, artifact.critique)
+				if artifact.refined != "" {
+					report.RefinedFile = individualOutputPath(o.config.OutputDir, artifact.refined)
+				}
+			}
+			model.Critique = append(model.Critique, report)
+		}
+		if err := errs[answeredBy]; err != nil {
+			model.CritiqueError = newErrorReport(err)
+		}
+		o.report.Models[i] = model
+	}
+}
+
+// newModelReport describes the result of processing a model
+func newModelReport(step string, result modelResult) ModelReport {
+	report := ModelReport{
+		Step:    step,
+		Model:   result.requestedModel,
+		Status:  ReportStatusSuccess,
+		Content: result.content,
+		Resumed: result.resumed,
+	}
+	if result.modelName != result.requestedModel {
+		report.AnsweredBy = result.modelName
+	}
+	if result.err != nil {
+		report.Status = ReportStatusFailure
+		report.Error = newErrorReport(result.err)
+	}
+
+	if generation := result.generation; generation != nil {
+		report.FinishReason = generation.FinishReason
+		report.Truncated = generation.Truncated
+		report.DurationMs = generation.Duration.Milliseconds()
+		report.OutputFile = generation.OutputFile
+		for _, safety := range generation.SafetyInfo {
+			report.Safety = append(report.Safety, SafetyReport{Category: safety.Category, Blocked: safety.Blocked, Score: safety.Score})
+		}
+		if generation.Usage != nil {
+			report.Tokens = newTokenReport(*generation.Usage)
+		}
+	}
+	return report
+}
+
+// newTokenReport converts recorded usage into a TokenReport
+func newTokenReport(usage modelproc.ModelUsage) *TokenReport {
+	report := &TokenReport{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+	}
+	if usage.CostKnown {
+		cost := usage.Cost
+		report.EstimatedCostUSD = &cost
+	}
+	return report
+}
+
+// newErrorReport describes an error and its category
+func newErrorReport(err error) *ErrorReport {
+	return &ErrorReport{Category: errorCategory(err).String(), Message: err.Error()}
+}
+
+// errorCategory returns the llm.ErrorCategory of an error. Provider errors carry their
+// category; errors raised while processing a model are mapped to the closest category.
+func errorCategory(err error) llm.ErrorCategory {
+	if catErr, ok := llm.IsCategorizedError(err); ok {
+		return catErr.Category()
+	}
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return llm.CategoryCancelled
+	case errors.Is(err, ErrPromptExceedsContextWindow), errors.Is(err, modelproc.ErrModelTokenLimitExceeded):
+		return llm.CategoryInputLimit
+	case errors.Is(err, modelproc.ErrContentFiltered):
+		return llm.CategoryContentFiltered
+	case errors.Is(err, modelproc.ErrModelRateLimited):
+		return llm.CategoryRateLimit
+	default:
+		return llm.CategoryUnknown
+	}
+}
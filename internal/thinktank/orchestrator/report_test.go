@@ -0,0 +1,147 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/ratelimit"
+	"github.com/phrazzld/thinktank/internal/thinktank/modelproc"
+)
+
+// TestRunReport tests that the report describes the outcome of each model and of synthesis
+func TestRunReport(t *testing.T) {
+	apiService := &usageReportingAPIService{scriptedAPIService: scriptedAPIService{respond: func(modelName, promptText string) (string, error) {
+		switch modelName {
+		case "model-b":
+			return "", llm.New("test", "", 429, "rate limited", "", nil, llm.CategoryRateLimit)
+		case "model-c":
+			return "", llm.New("test", "", 401, "invalid key", "", nil, llm.CategoryAuth)
+		case "synthesizer":
+			return "synthesis", nil
+		default:
+			return "output from " + modelName, nil
+		}
+	}}}
+	outputDir := filepath.Join(t.TempDir(), "output")
+	cfg := &config.CliConfig{
+		ModelNames:     []string{"model-a", "model-b", "model-c"},
+		ModelFallbacks: map[string][]string{"model-c": {"model-d"}},
+		SynthesisModel: "synthesizer",
+		OutputDir:      outputDir,
+	}
+	orch := NewOrchestrator(apiService, &MockContextGatherer{}, &syncFileWriter{}, NewMockAuditLogger(),
+		ratelimit.NewRateLimiter(0, 0), cfg, &MockLogger{})
+
+	err := orch.Run(context.Background(), "Plan it")
+	if !errors.Is(err, ErrPartialProcessingFailure) {
+		t.Fatalf("Expected ErrPartialProcessingFailure, got: %v", err)
+	}
+
+	report := orch.Report()
+	report.Finish(err)
+
+	if report.CorrelationID == "" {
+		t.Error("Expected the correlation ID of the run")
+	}
+	if report.Status != ReportStatusPartialFailure || report.Error != err.Error() {
+		t.Errorf("Unexpected outcome: %s, %q", report.Status, report.Error)
+	}
+	if len(report.Models) != 3 {
+		t.Fatalf("Expected a report for each requested model, got %+v", report.Models)
+	}
+
+	modelA := report.Models[0]
+	if modelA.Model != "model-a" || modelA.Status != ReportStatusSuccess || modelA.Content != "output from model-a" {
+		t.Errorf("Unexpected report for model-a: %+v", modelA)
+	}
+	if modelA.OutputFile != filepath.Join(outputDir, "model-a.md") {
+		t.Errorf("Expected the output file of model-a, got %q", modelA.OutputFile)
+	}
+	if modelA.Tokens == nil || modelA.Tokens.TotalTokens != 15 {
+		t.Errorf("Expected the token usage of model-a, got %+v", modelA.Tokens)
+	}
+
+	modelB := report.Models[1]
+	if modelB.Status != ReportStatusFailure || modelB.Error == nil || modelB.Error.Category != "RateLimit" {
+		t.Errorf("Expected model-b to fail with a rate limit error, got %+v", modelB)
+	}
+
+	modelC := report.Models[2]
+	if modelC.Model != "model-c" || modelC.AnsweredBy != "model-d" || modelC.Content != "output from model-d" {
+		t.Errorf("Expected model-d to answer for model-c, got %+v", modelC)
+	}
+
+	if report.Synthesis == nil || report.Synthesis.Content != "synthesis" ||
+		report.Synthesis.OutputFile != filepath.Join(outputDir, "synthesizer-synthesis.md") {
+		t.Errorf("Unexpected synthesis report: %+v", report.Synthesis)
+	}
+	if report.Usage == nil || report.Usage.TotalTokens != 45 {
+		t.Errorf("Expected the usage of the three successful calls, got %+v", report.Usage)
+	}
+	if fmt.Sprint(report.ErrorsByCategory) != "map[RateLimit:[model-b]]" {
+		t.Errorf("Unexpected errors by category: %v", report.ErrorsByCategory)
+	}
+}
+
+// TestRunReportWorkflow tests that the models of each workflow step are reported
+func TestRunReportWorkflow(t *testing.T) {
+	const definition = `
+name: review
+steps:
+  - id: plan
+    type: generate
+    models: [planner]
+  - id: critique
+    type: critique
+    models: [critic]
+    needs: [plan]
+`
+	apiService := &scriptedAPIService{respond: func(modelName, promptText string) (string, error) {
+		return "output from " + modelName, nil
+	}}
+	orch, _, _, _ := newWorkflowOrchestrator(t, definition, apiService)
+
+	if err := orch.Run(context.Background(), "Plan it"); err != nil {
+		t.Fatalf("Expected the workflow to succeed, got: %v", err)
+	}
+
+	report := orch.Report()
+	report.Finish(nil)
+
+	var steps []string
+	for _, model := range report.Models {
+		steps = append(steps, model.Step+"/"+model.Model)
+	}
+	if strings.Join(steps, " ") != "plan/planner critique/critic" {
+		t.Errorf("Expected the models of each step in order, got %v", steps)
+	}
+	if report.Status != ReportStatusSuccess {
+		t.Errorf("Expected a successful report, got %s", report.Status)
+	}
+}
+
+// TestErrorCategory tests mapping errors to error categories
+func TestErrorCategory(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected llm.ErrorCategory
+	}{
+		{fmt.Errorf("model a: %w", llm.New("test", "", 500, "down", "", nil, llm.CategoryServer)), llm.CategoryServer},
+		{fmt.Errorf("model a: %w", context.DeadlineExceeded), llm.CategoryCancelled},
+		{fmt.Errorf("model a: %w", ErrPromptExceedsContextWindow), llm.CategoryInputLimit},
+		{fmt.Errorf("%w: blocked", modelproc.ErrContentFiltered), llm.CategoryContentFiltered},
+		{errors.New("unexpected"), llm.CategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := errorCategory(tt.err); got != tt.expected {
+			t.Errorf("errorCategory(%v) = %s, expected %s", tt.err, got, tt.expected)
+		}
+	}
+}
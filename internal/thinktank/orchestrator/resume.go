@@ -0,0 +1,125 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/phrazzld/thinktank/internal/thinktank/modelproc"
+)
+
+// loadResumedResults finds the models of the run being resumed (config.ResumeDir) whose
+// outputs can be reused, so that only the models that failed are processed again.
+// A model's output is reused when the run's manifest records the model as successful and
+// its output file still exists, along with the rounds of its critique if they completed.
+// A run without a manifest cannot be resumed, since an output file alone does not tell
+// whether its model succeeded.
+func (o *Orchestrator) loadResumedResults(ctx context.Context, instructions string) error {
+	resumeDir := o.config.ResumeDir
+	if resumeDir == "" {
+		return nil
+	}
+	contextLogger := o.logger.WithContext(ctx)
+
+	manifest, err := LoadManifest(resumeDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = fmt.Errorf("%w: no %s in %s to tell which models succeeded", ErrResumeFailed, ManifestFileName, resumeDir)
+	} else if err != nil {
+		err = fmt.Errorf("%w: %v", ErrResumeFailed, err)
+	}
+	if err != nil {
+		o.logAuditEvent(ctx, "ResumeRun", "Failure", map[string]interface{}{"resume_dir": resumeDir}, nil, err)
+		return err
+	}
+
+	if manifest.Instructions.SHA256 != newManifestFile("", instructions).SHA256 {
+		contextLogger.WarnContext(ctx, "The instructions differ from those of the run being resumed; reused outputs answer the previous instructions")
+	}
+	previous := make(map[string]ModelReport)
+	for _, model := range manifest.Models {
+		if model.Step == "" {
+			previous[model.Model] = model.ModelReport
+		}
+	}
+
+	o.resumed = make(map[string]modelResult)
+	o.resumedCritiques = make(map[string][]CritiqueRound)
+	var reused, pending []string
+	for _, modelName := range o.config.ModelNames {
+		model, ok := previous[modelName]
+		if !ok || model.Status != ReportStatusSuccess {
+			pending = append(pending, modelName)
+			continue
+		}
+		answeredBy := modelName
+		if model.AnsweredBy != "" {
+			answeredBy = model.AnsweredBy
+		}
+
+		// Output files are named after the model that answered
+		outputFile := filepath.Join(resumeDir, modelproc.SanitizeFilename(answeredBy)+".md")
+		content, err := os.ReadFile(outputFile)
+		if err != nil || len(content) == 0 {
+			contextLogger.DebugContext(ctx, "No output of model %s to reuse in %s", modelName, outputFile)
+			pending = append(pending, modelName)
+			continue
+		}
+
+		o.resumed[modelName] = modelResult{
+			requestedModel: modelName,
+			modelName:      answeredBy,
+			content:        string(content),
+			generation:     &modelproc.Generation{ModelName: answeredBy, OutputFile: outputFile},
+			resumed:        true,
+		}
+		reused = append(reused, modelName)
+
+		// The critique of the output is reused too, if it completed
+		if o.config.CritiqueRefine && len(model.Critique) > 0 && model.CritiqueError == nil {
+			if rounds, ok := loadCritiqueRounds(resumeDir, model.Critique); ok {
+				o.resumedCritiques[answeredBy] = rounds
+			} else {
+				contextLogger.DebugContext(ctx, "No critique of model %s output to reuse in %s", modelName, resumeDir)
+			}
+		}
+	}
+
+	contextLogger.InfoContext(ctx, "Resuming run in %s: reusing the outputs of %d models %v, processing %d models %v",
+		resumeDir, len(reused), reused, len(pending), pending)
+	o.logAuditEvent(ctx, "ResumeRun", "Success",
+		map[string]interface{}{"resume_dir": resumeDir, "model_names": o.config.ModelNames},
+		map[string]interface{}{"reused_models": reused, "pending_models": pending}, nil)
+	return nil
+}
+
+// loadCritiqueRounds reads the critique rounds of a reused output from the files the run
+// being resumed recorded for them, which are looked for in resumeDir. It reports false if
+// a file is missing or was not recorded.
+func loadCritiqueRounds(resumeDir string, reports []CritiqueReport) ([]CritiqueRound, bool) {
+	read := func(path string) (string, bool) {
+		if path == "" {
+			return "", false
+		}
+		content, err := os.ReadFile(filepath.Join(resumeDir, filepath.Base(path)))
+		return string(content), err == nil && len(content) > 0
+	}
+
+	rounds := make([]CritiqueRound, 0, len(reports))
+	for _, report := range reports {
+		round := CritiqueRound{CriticModel: report.CriticModel, RefineModel: report.RefineModel}
+		var ok bool
+		if round.Critique, ok = read(report.CritiqueFile); !ok {
+			return nil, false
+		}
+		if report.RefineModel != "" {
+			if round.Refined, ok = read(report.RefinedFile); !ok {
+				return nil, false
+			}
+		}
+		rounds = append(rounds, round)
+	}
+	return rounds, true
+}
@@ -12,7 +12,9 @@ import (
 
 	"github.com/phrazzld/thinktank/internal/auditlog"
 	"github.com/phrazzld/thinktank/internal/logutil"
+	"github.com/phrazzld/thinktank/internal/registry"
 	"github.com/phrazzld/thinktank/internal/thinktank/interfaces"
+	"github.com/phrazzld/thinktank/internal/thinktank/modelproc"
 	"github.com/phrazzld/thinktank/internal/thinktank/prompt"
 )
 
@@ -29,6 +31,14 @@ type DefaultSynthesisService struct {
 	auditLogger auditlog.AuditLogger
 	logger      logutil.LoggerInterface
 	modelName   string // The name of the synthesis model to use
+
+	// usageTracker records the token usage of the synthesis call (optional)
+	usageTracker *modelproc.UsageTracker
+
+	// operation prefixes the operations recorded in the audit log (e.g., "SynthesisAPICall")
+	operation string
+	// buildPrompt builds the prompt from the original instructions and the model outputs
+	buildPrompt func(instructions string, modelOutputs map[string]string) string
 }
 
 // NewSynthesisService creates a new SynthesisService instance with the specified dependencies
@@ -43,9 +53,17 @@ func NewSynthesisService(
 		auditLogger: auditLogger,
 		logger:      logger,
 		modelName:   modelName,
+		operation:   "Synthesis",
+		buildPrompt: prompt.StitchSynthesisPrompt,
 	}
 }
 
+// SetUsageTracker sets a tracker that records the token usage of the synthesis call,
+// so that it is included in the run's usage summary.
+func (s *DefaultSynthesisService) SetUsageTracker(t *modelproc.UsageTracker) {
+	s.usageTracker = t
+}
+
 // SynthesizeResults processes multiple model outputs through a synthesis model.
 // It builds a prompt that includes the original instructions and all model outputs,
 // then sends this to the synthesis model to generate a consolidated result.
@@ -80,7 +98,7 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 
 	// Log synthesis process start with audit logger
 	s.logAuditEvent(auditlog.AuditEntry{
-		Operation: "SynthesisStart",
+		Operation: s.operation + "Start",
 		Status:    "InProgress",
 		Inputs: map[string]interface{}{
 			"synthesis_model": s.modelName,
@@ -93,12 +111,12 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 
 	// Build synthesis prompt using the dedicated prompt function
 	contextLogger.DebugContext(ctx, "Building synthesis prompt")
-	synthesisPrompt := prompt.StitchSynthesisPrompt(originalInstructions, modelOutputs)
+	synthesisPrompt := s.buildPrompt(originalInstructions, modelOutputs)
 	contextLogger.DebugContext(ctx, "Synthesis prompt built, length: %d characters", len(synthesisPrompt))
 
 	// Log prompt building completed
 	s.logAuditEvent(auditlog.AuditEntry{
-		Operation: "SynthesisPromptCreated",
+		Operation: s.operation + "PromptCreated",
 		Status:    "Success",
 		Inputs: map[string]interface{}{
 			"synthesis_model": s.modelName,
@@ -116,7 +134,7 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 
 		// Log error with audit logger
 		s.logAuditEvent(auditlog.AuditEntry{
-			Operation:  "SynthesisModelParameters",
+			Operation:  s.operation + "ModelParameters",
 			Status:     "Failure",
 			DurationMs: &durationMs,
 			Inputs: map[string]interface{}{
@@ -134,7 +152,7 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 
 	// Log successful parameter retrieval
 	s.logAuditEvent(auditlog.AuditEntry{
-		Operation: "SynthesisModelParameters",
+		Operation: s.operation + "ModelParameters",
 		Status:    "Success",
 		Inputs: map[string]interface{}{
 			"synthesis_model": s.modelName,
@@ -152,7 +170,7 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 
 		// Log error with audit logger
 		s.logAuditEvent(auditlog.AuditEntry{
-			Operation:  "SynthesisClientInit",
+			Operation:  s.operation + "ClientInit",
 			Status:     "Failure",
 			DurationMs: &durationMs,
 			Inputs: map[string]interface{}{
@@ -170,7 +188,7 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 
 	// Log successful client initialization
 	s.logAuditEvent(auditlog.AuditEntry{
-		Operation: "SynthesisClientInit",
+		Operation: s.operation + "ClientInit",
 		Status:    "Success",
 		Inputs: map[string]interface{}{
 			"synthesis_model": s.modelName,
@@ -184,7 +202,7 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 
 			// Log client close error
 			s.logAuditEvent(auditlog.AuditEntry{
-				Operation: "SynthesisClientClose",
+				Operation: s.operation + "ClientClose",
 				Status:    "Failure",
 				Inputs: map[string]interface{}{
 					"synthesis_model": s.modelName,
@@ -198,7 +216,7 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 		} else {
 			// Log successful client close
 			s.logAuditEvent(auditlog.AuditEntry{
-				Operation: "SynthesisClientClose",
+				Operation: s.operation + "ClientClose",
 				Status:    "Success",
 				Inputs: map[string]interface{}{
 					"synthesis_model": s.modelName,
@@ -211,7 +229,7 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 	// Log API call start
 	apiCallStartTime := time.Now()
 	s.logAuditEvent(auditlog.AuditEntry{
-		Operation: "SynthesisAPICall",
+		Operation: s.operation + "APICall",
 		Status:    "InProgress",
 		Inputs: map[string]interface{}{
 			"synthesis_model": s.modelName,
@@ -233,7 +251,7 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 
 		// Log API call failure
 		s.logAuditEvent(auditlog.AuditEntry{
-			Operation:  "SynthesisAPICall",
+			Operation:  s.operation + "APICall",
 			Status:     "Failure",
 			DurationMs: &apiCallDurationMs,
 			Inputs: map[string]interface{}{
@@ -249,7 +267,7 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 
 		// Log overall synthesis failure
 		s.logAuditEvent(auditlog.AuditEntry{
-			Operation:  "SynthesisEnd",
+			Operation:  s.operation + "End",
 			Status:     "Failure",
 			DurationMs: &durationMs,
 			Inputs: map[string]interface{}{
@@ -265,9 +283,9 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 		return "", s.handleSynthesisError(ctx, err)
 	}
 
-	// Log successful API call
-	s.logAuditEvent(auditlog.AuditEntry{
-		Operation:  "SynthesisAPICall",
+	// Log successful API call, including token usage when the provider reports it
+	apiCallEntry := auditlog.AuditEntry{
+		Operation:  s.operation + "APICall",
 		Status:     "Success",
 		DurationMs: &apiCallDurationMs,
 		Inputs: map[string]interface{}{
@@ -277,7 +295,22 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 			"result_received": result != nil,
 		},
 		Message: "Synthesis model API call completed successfully",
-	})
+	}
+	if result != nil && result.Usage != nil {
+		var modelDef *registry.ModelDefinition
+		if def, defErr := s.apiService.GetModelDefinition(s.modelName); defErr == nil {
+			modelDef = def
+		}
+		usage := modelproc.NewModelUsage(s.modelName, s.operation+"APICall", result.Usage, modelDef)
+		apiCallEntry.TokenCounts = usage.TokenCounts()
+		if usage.CostKnown {
+			apiCallEntry.Outputs["estimated_cost_usd"] = usage.Cost
+		}
+		if s.usageTracker != nil {
+			s.usageTracker.Record(usage)
+		}
+	}
+	s.logAuditEvent(apiCallEntry)
 
 	// Process response
 	responseStartTime := time.Now()
@@ -294,7 +327,7 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 
 		// Log response processing failure
 		s.logAuditEvent(auditlog.AuditEntry{
-			Operation:  "SynthesisResponseProcessing",
+			Operation:  s.operation + "ResponseProcessing",
 			Status:     "Failure",
 			DurationMs: &responseDurationMs,
 			Inputs: map[string]interface{}{
@@ -309,7 +342,7 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 
 		// Log overall synthesis failure
 		s.logAuditEvent(auditlog.AuditEntry{
-			Operation:  "SynthesisEnd",
+			Operation:  s.operation + "End",
 			Status:     "Failure",
 			DurationMs: &durationMs,
 			Inputs: map[string]interface{}{
@@ -327,7 +360,7 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 
 	// Log successful response processing
 	s.logAuditEvent(auditlog.AuditEntry{
-		Operation:  "SynthesisResponseProcessing",
+		Operation:  s.operation + "ResponseProcessing",
 		Status:     "Success",
 		DurationMs: &responseDurationMs,
 		Inputs: map[string]interface{}{
@@ -344,7 +377,7 @@ func (s *DefaultSynthesisService) SynthesizeResults(
 	durationMs := duration
 
 	s.logAuditEvent(auditlog.AuditEntry{
-		Operation:  "SynthesisEnd",
+		Operation:  s.operation + "End",
 		Status:     "Success",
 		DurationMs: &durationMs,
 		Inputs: map[string]interface{}{
@@ -434,8 +467,16 @@ func (s *DefaultSynthesisService) handleSynthesisError(ctx context.Context, err
 
 // logAuditEvent writes an audit log entry and logs any errors that occur.
 // This helper ensures proper error handling for all audit log operations.
-// It converts an AuditEntry to LogOp parameters for consistent logging.
+// It converts an AuditEntry to LogOp parameters for consistent logging, except
+// for entries carrying token counts, which LogOp cannot represent.
 func (s *DefaultSynthesisService) logAuditEvent(entry auditlog.AuditEntry) {
+	if entry.TokenCounts != nil {
+		if logErr := s.auditLogger.Log(entry); logErr != nil {
+			s.logger.Warn("Failed to write audit log: %v", logErr)
+		}
+		return
+	}
+
 	// Create an error from entry.Error if present
 	var err error
 	if entry.Error != nil {
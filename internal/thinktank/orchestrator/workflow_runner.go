@@ -0,0 +1,331 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/phrazzld/thinktank/internal/fileutil"
+	"github.com/phrazzld/thinktank/internal/thinktank/prompt"
+	"github.com/phrazzld/thinktank/internal/workflow"
+)
+
+// workflowRunner executes a workflow as a directed acyclic graph of steps. Each step
+// starts as soon as the steps it needs have finished, and the models of a step run
+// concurrently through the orchestrator's model processing, so that every step shares
+// its rate limiter, fallbacks, audit logger and usage tracking.
+type workflowRunner struct {
+	orchestrator *Orchestrator
+	definition   *workflow.Definition
+	instructions string
+	context      string // the <context> block with the gathered files
+	prompt       string // the standard prompt stitched from the instructions and files
+
+	mu      sync.Mutex
+	results map[string]*stepResult
+}
+
+// stepResult is the result of running a workflow step
+type stepResult struct {
+	outputs []workflow.Output // outputs of the models that succeeded, in the order of the step's models
+	models  []modelResult     // results of the step's models, in the order of the step's models
+	errs    []error           // errors of the models that failed
+	err     error             // set if the step produced no output
+}
+
+// newWorkflowRunner creates a runner for a workflow with the given instructions and files
+func newWorkflowRunner(o *Orchestrator, definition *workflow.Definition, instructions string, contextFiles []fileutil.FileMeta) *workflowRunner {
+	return &workflowRunner{
+		orchestrator: o,
+		definition:   definition,
+		instructions: instructions,
+		context:      prompt.StitchContext(contextFiles, o.stitchOptions),
+		prompt:       prompt.StitchPrompt(instructions, contextFiles, o.stitchOptions),
+		results:      make(map[string]*stepResult, len(definition.Steps)),
+	}
+}
+
+// run executes every step of the workflow and returns the results by step ID.
+// Steps whose needs produced no output are skipped and recorded as failed.
+func (r *workflowRunner) run(ctx context.Context) map[string]*stepResult {
+	done := make(map[string]chan struct{}, len(r.definition.Steps))
+	for _, step := range r.definition.Steps {
+		done[step.ID] = make(chan struct{})
+	}
+
+	// Every step finishes, even when cancelled, so waiting for needs cannot block forever
+	// (the definition was validated to contain no cycles)
+	var wg sync.WaitGroup
+	for _, step := range r.definition.Steps {
+		wg.Add(1)
+		go func(step workflow.Step) {
+			defer wg.Done()
+			defer close(done[step.ID])
+
+			for _, need := range step.Needs {
+				<-done[need]
+			}
+
+			result := r.runStep(ctx, step)
+
+			r.mu.Lock()
+			r.results[step.ID] = result
+			r.mu.Unlock()
+		}(step)
+	}
+	wg.Wait()
+
+	return r.results
+}
+
+// finishedOutputs returns the outputs of the steps that have finished so far
+func (r *workflowRunner) finishedOutputs() map[string][]workflow.Output {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	outputs := make(map[string][]workflow.Output, len(r.results))
+	for id, result := range r.results {
+		outputs[id] = result.outputs
+	}
+	return outputs
+}
+
+// runStep renders the prompt of a step and processes it with each of the step's models.
+// The models' outputs are written to a directory named after the step.
+func (r *workflowRunner) runStep(ctx context.Context, step workflow.Step) *stepResult {
+	o := r.orchestrator
+	contextLogger := o.logger.WithContext(ctx)
+
+	inputs := map[string]interface{}{
+		"workflow": r.definition.Name,
+		"step":     step.ID,
+		"type":     string(step.Type),
+		"models":   step.Models,
+		"needs":    step.Needs,
+	}
+	fail := func(result *stepResult, err error) *stepResult {
+		result.err = err
+		contextLogger.ErrorContext(ctx, "Workflow step %s failed: %v", step.ID, err)
+		o.logAuditEvent(ctx, "WorkflowStep", "Failure", inputs, nil, err)
+		return result
+	}
+
+	// Skip the step if a step it needs produced no output
+	outputs := r.finishedOutputs()
+	for _, need := range step.Needs {
+		if len(outputs[need]) == 0 {
+			return fail(&stepResult{}, fmt.Errorf("step %s skipped: needed step %s produced no output", step.ID, need))
+		}
+	}
+
+	data := r.definition.PromptData(step, r.instructions, r.context, r.prompt, outputs)
+	stepPrompt, err := step.RenderPrompt(data)
+	if err != nil {
+		return fail(&stepResult{}, err)
+	}
+
+	contextLogger.InfoContext(ctx, "Running workflow step %s (%s) with %d model(s)", step.ID, step.Type, len(step.Models))
+	o.logAuditEvent(ctx, "WorkflowStep", "InProgress", inputs, nil, nil)
+
+	// Write the outputs of each step to their own directory
+	stepConfig := *o.config
+	stepConfig.OutputDir = filepath.Join(o.config.OutputDir, step.ID)
+
+	promptTokens := fileutil.EstimateTokens(stepPrompt)
+	modelResults := make([]modelResult, len(step.Models))
+	var wg sync.WaitGroup
+	for i, modelName := range step.Models {
+		wg.Add(1)
+		go func(i int, modelName string) {
+			defer wg.Done()
+			modelResults[i] = o.processModelChain(ctx, &stepConfig, modelName, stepPrompt, promptTokens)
+		}(i, modelName)
+	}
+	wg.Wait()
+
+	result := &stepResult{models: modelResults}
+	answered := make(map[string]bool)
+	for _, modelResult := range modelResults {
+		if modelResult.err != nil {
+			result.errs = append(result.errs, fmt.Errorf("step %s: %w", step.ID, modelResult.err))
+			continue
+		}
+		// Two models of the step may have fallen back to the same model; keep one output
+		if answered[modelResult.modelName] {
+			contextLogger.WarnContext(ctx, "Model %s answered for more than one model of step %s; keeping one output",
+				modelResult.modelName, step.ID)
+			continue
+		}
+		answered[modelResult.modelName] = true
+		result.outputs = append(result.outputs, workflow.Output{
+			Step:    step.ID,
+			Model:   modelResult.modelName,
+			Content: modelResult.content,
+		})
+	}
+
+	if len(result.outputs) == 0 {
+		return fail(result, fmt.Errorf("step %s: all models failed: %s", step.ID, aggregateErrorMessages(result.errs)))
+	}
+
+	if step.Type == workflow.StepVote {
+		if err := r.countVotes(ctx, step, data.Inputs, result); err != nil {
+			return fail(result, err)
+		}
+	}
+
+	answeredBy := make([]string, 0, len(result.outputs))
+	for _, output := range result.outputs {
+		answeredBy = append(answeredBy, output.Model)
+	}
+	contextLogger.InfoContext(ctx, "Workflow step %s completed (%d of %d models succeeded)",
+		step.ID, len(modelResults)-len(result.errs), len(modelResults))
+	o.logAuditEvent(ctx, "WorkflowStep", "Success", inputs,
+		map[string]interface{}{"answered_by": answeredBy, "failed_models": len(result.errs)}, nil)
+
+	return result
+}
+
+// countVotes tallies the ballots of a vote step and replaces its outputs with the
+// winning candidate, so that later steps and the final output use the chosen response.
+// The ballots themselves remain in the step's output directory.
+func (r *workflowRunner) countVotes(ctx context.Context, step workflow.Step, candidates []workflow.Output, result *stepResult) error {
+	o := r.orchestrator
+
+	ballots := make([]string, 0, len(result.outputs))
+	for _, output := range result.outputs {
+		ballots = append(ballots, output.Content)
+	}
+
+	number, votes, ok := workflow.Tally(ballots, len(candidates))
+	if !ok {
+		result.outputs = nil
+		return fmt.Errorf("step %s: no ballot named one of the %d candidates", step.ID, len(candidates))
+	}
+
+	tally := make(map[string]interface{}, len(candidates))
+	for i, candidate := range candidates {
+		tally[fmt.Sprintf("%d:%s/%s", i+1, candidate.Step, candidate.Model)] = votes[i]
+	}
+
+	winner := candidates[number-1]
+	o.logger.WithContext(ctx).InfoContext(ctx, "Workflow step %s chose the output of model %s from step %s (%d of %d votes)",
+		step.ID, winner.Model, winner.Step, votes[number-1], len(ballots))
+	o.logAuditEvent(ctx, "WorkflowVote", "Success",
+		map[string]interface{}{"workflow": r.definition.Name, "step": step.ID},
+		map[string]interface{}{"winner": fmt.Sprintf("%s/%s", winner.Step, winner.Model), "votes": tally}, nil)
+
+	result.outputs = []workflow.Output{{Step: step.ID, Model: winner.Model, Content: winner.Content}}
+	return nil
+}
+
+// saveFinalOutputs saves the outputs of the steps that no other step needs to the
+// output directory, named like the outputs of a run without a workflow: one file per
+// model, or <model>-synthesis.md for synthesize steps. A vote step's chosen output is
+// named after the step. When the workflow has several final steps, the file names are
+// prefixed with the step ID so they cannot collide.
+func (r *workflowRunner) saveFinalOutputs(ctx context.Context, results map[string]*stepResult) error {
+	o := r.orchestrator
+	outputDir := o.config.OutputDir
+
+	finalSteps := r.definition.FinalSteps()
+	name := func(step workflow.Step, modelName string) string {
+		if len(finalSteps) > 1 {
+			return step.ID + "-" + modelName
+		}
+		return modelName
+	}
+
+	var errs []error
+	for _, step := range finalSteps {
+		result := results[step.ID]
+		if result == nil || len(result.outputs) == 0 {
+			continue
+		}
+
+		switch step.Type {
+		case workflow.StepSynthesize:
+			for _, output := range result.outputs {
+				if err := o.outputWriter.SaveSynthesisOutput(ctx, output.Content, name(step, output.Model), outputDir); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		case workflow.StepVote:
+			outputs := map[string]string{step.ID: result.outputs[0].Content}
+			if _, err := o.outputWriter.SaveIndividualOutputs(ctx, outputs, outputDir); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			outputs := make(map[string]string, len(result.outputs))
+			for _, output := range result.outputs {
+				outputs[name(step, output.Model)] = output.Content
+			}
+			if _, err := o.outputWriter.SaveIndividualOutputs(ctx, outputs, outputDir); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %s", ErrOutputFileSaveFailed, aggregateErrorMessages(errs))
+	}
+	return nil
+}
+
+// runWorkflowFlow runs the workflow named in the configuration instead of the default
+// fan-out and synthesis, and saves the outputs of its final steps. It returns an error
+// wrapping ErrWorkflowStepFailed if any step produced no output, or
+// ErrPartialProcessingFailure if only some models of a step failed.
+func (o *Orchestrator) runWorkflowFlow(ctx context.Context, instructions string, contextFiles []fileutil.FileMeta) (error, error) {
+	contextLogger := o.logger.WithContext(ctx)
+
+	definition, err := workflow.Load(o.config.WorkflowFile)
+	if err != nil {
+		return err, nil
+	}
+
+	ordered, err := definition.Order()
+	if err != nil {
+		return err, nil
+	}
+	stepIDs := make([]string, 0, len(ordered))
+	for _, step := range ordered {
+		stepIDs = append(stepIDs, step.ID)
+	}
+
+	contextLogger.InfoContext(ctx, "Running workflow %s with %d steps", definition.Name, len(ordered))
+	o.logRateLimitingConfiguration(ctx)
+	o.logAuditEvent(ctx, "WorkflowStart", "InProgress",
+		map[string]interface{}{"workflow": definition.Name, "file": o.config.WorkflowFile, "steps": stepIDs}, nil, nil)
+
+	runner := newWorkflowRunner(o, definition, instructions, contextFiles)
+	results := runner.run(ctx)
+
+	var stepErrs, modelErrs []error
+	for _, step := range ordered {
+		result := results[step.ID]
+		if result.err != nil {
+			stepErrs = append(stepErrs, result.err)
+		}
+		modelErrs = append(modelErrs, result.errs...)
+		o.recordModelResults(step.ID, step.Models, result.models)
+	}
+
+	var processingErr error
+	if len(stepErrs) > 0 {
+		processingErr = fmt.Errorf("%w: %d of %d steps produced no output: %s",
+			ErrWorkflowStepFailed, len(stepErrs), len(ordered), aggregateErrorMessages(stepErrs))
+		o.logAuditEvent(ctx, "WorkflowComplete", "Failure",
+			map[string]interface{}{"workflow": definition.Name}, nil, processingErr)
+	} else {
+		if len(modelErrs) > 0 {
+			processingErr = fmt.Errorf("%w: %s", ErrPartialProcessingFailure, aggregateErrorMessages(modelErrs))
+		}
+		o.logAuditEvent(ctx, "WorkflowComplete", "Success",
+			map[string]interface{}{"workflow": definition.Name},
+			map[string]interface{}{"steps_completed": len(ordered), "failed_models": len(modelErrs)}, nil)
+	}
+
+	return processingErr, runner.saveFinalOutputs(ctx, results)
+}
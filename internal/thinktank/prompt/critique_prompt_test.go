@@ -0,0 +1,46 @@
+package prompt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/thinktank/prompt"
+)
+
+// TestStitchCritiquePrompt tests the prompt asking a critic model to review an output
+func TestStitchCritiquePrompt(t *testing.T) {
+	result := prompt.StitchCritiquePrompt("Design the cache", map[string]string{"model1": "Use an LRU cache"})
+
+	expectedParts := []string{
+		"<instructions>\nDesign the cache\n</instructions>\n\n",
+		"<model_outputs>\n<model_result model=\"model1\">\nUse an LRU cache\n</model_result>\n\n</model_outputs>\n\n",
+		"Please critique the output above",
+		"reply with exactly: " + prompt.NoChangesNeeded,
+	}
+	for _, part := range expectedParts {
+		if !strings.Contains(result, part) {
+			t.Errorf("Expected critique prompt to contain %q, got:\n%s", part, result)
+		}
+	}
+	if strings.Contains(result, "Please synthesize") {
+		t.Error("Critique prompt should not contain synthesis instructions")
+	}
+}
+
+// TestStitchRefinePrompt tests the prompt asking a model to revise an output using a critique
+func TestStitchRefinePrompt(t *testing.T) {
+	result := prompt.StitchRefinePrompt("Design the cache", map[string]string{"model1": "Use an LRU cache"},
+		"Eviction under memory pressure is not covered")
+
+	expectedParts := []string{
+		"<instructions>\nDesign the cache\n</instructions>\n\n",
+		"<model_result model=\"model1\">\nUse an LRU cache\n</model_result>",
+		"</model_outputs>\n\n<critique>\nEviction under memory pressure is not covered\n</critique>\n\n",
+		"Please revise the output above",
+	}
+	for _, part := range expectedParts {
+		if !strings.Contains(result, part) {
+			t.Errorf("Expected refine prompt to contain %q, got:\n%s", part, result)
+		}
+	}
+}
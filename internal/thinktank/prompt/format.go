@@ -0,0 +1,143 @@
+package prompt
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/phrazzld/thinktank/internal/fileutil"
+)
+
+// Built-in context formats, selected by name with --format
+const (
+	// FormatXML wraps each file's path in <path> tags, followed by its content
+	FormatXML = "xml"
+	// FormatMarkdown gives each file a heading and a fenced code block tagged with its language
+	FormatMarkdown = "markdown"
+	// FormatNumbered is FormatXML with line numbers, so that models can cite lines
+	FormatNumbered = "numbered"
+)
+
+// ContextFormat renders a single context file of a prompt
+type ContextFormat func(file fileutil.FileMeta) string
+
+// contextFormats are the built-in context formats by name
+var contextFormats = map[string]ContextFormat{
+	FormatXML:      formatXML,
+	FormatMarkdown: formatMarkdown,
+	FormatNumbered: formatNumbered,
+}
+
+// ParseContextFormat returns the context format for the value of --format: the name of a
+// built-in format, or a template in which {path} and {content} are replaced by each
+// file's path and content. Escaped newlines and tabs (\n, \t) in a template are expanded,
+// so that templates can be given on the command line. An empty value is FormatXML.
+func ParseContextFormat(format string) (ContextFormat, error) {
+	if format == "" {
+		return formatXML, nil
+	}
+	if contextFormat, ok := contextFormats[format]; ok {
+		return contextFormat, nil
+	}
+	if !strings.Contains(format, "{path}") && !strings.Contains(format, "{content}") {
+		return nil, fmt.Errorf("unknown format %q: expected %s, %s, %s or a template using {path} and {content}",
+			format, FormatXML, FormatMarkdown, FormatNumbered)
+	}
+
+	template := strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(format)
+	return func(file fileutil.FileMeta) string {
+		// A single pass, so that placeholders in the content itself are left alone
+		return strings.NewReplacer("{path}", file.Path, "{content}", file.Content).Replace(template)
+	}, nil
+}
+
+// formatXML renders a file as its path in <path> tags followed by its content
+func formatXML(file fileutil.FileMeta) string {
+	return "<path>" + file.Path + "</path>\n" + EscapeContent(file.Content) + "\n\n"
+}
+
+// formatMarkdown renders a file as a heading with its path followed by its content in a
+// fenced code block. The fence is longer than any run of backticks in the content, so
+// that Markdown files with their own code blocks stay intact.
+func formatMarkdown(file fileutil.FileMeta) string {
+	fence := strings.Repeat("`", max(3, longestRun(file.Content, '`')+1))
+	return "## " + file.Path + "\n\n" + fence + languageTag(file.Path) + "\n" +
+		strings.TrimSuffix(file.Content, "\n") + "\n" + fence + "\n\n"
+}
+
+// formatNumbered renders a file as formatXML does, with each line prefixed by its number
+func formatNumbered(file fileutil.FileMeta) string {
+	lines := strings.Split(strings.TrimSuffix(file.Content, "\n"), "\n")
+	width := len(strconv.Itoa(len(lines)))
+
+	var sb strings.Builder
+	sb.WriteString("<path>" + file.Path + "</path>\n")
+	for i, line := range lines {
+		fmt.Fprintf(&sb, "%*d| %s\n", width, i+1, line)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// longestRun returns the length of the longest run of c in s
+func longestRun(s string, c byte) int {
+	longest, current := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != c {
+			current = 0
+			continue
+		}
+		current++
+		longest = max(longest, current)
+	}
+	return longest
+}
+
+// languageTags maps file extensions to the language tags of Markdown code blocks
+var languageTags = map[string]string{
+	".go":    "go",
+	".py":    "python",
+	".js":    "javascript",
+	".jsx":   "jsx",
+	".ts":    "typescript",
+	".tsx":   "tsx",
+	".java":  "java",
+	".kt":    "kotlin",
+	".rs":    "rust",
+	".rb":    "ruby",
+	".php":   "php",
+	".c":     "c",
+	".h":     "c",
+	".cpp":   "cpp",
+	".hpp":   "cpp",
+	".cs":    "csharp",
+	".swift": "swift",
+	".scala": "scala",
+	".sh":    "bash",
+	".bash":  "bash",
+	".sql":   "sql",
+	".html":  "html",
+	".css":   "css",
+	".scss":  "scss",
+	".json":  "json",
+	".yaml":  "yaml",
+	".yml":   "yaml",
+	".toml":  "toml",
+	".xml":   "xml",
+	".md":    "markdown",
+	".proto": "protobuf",
+	".tf":    "hcl",
+}
+
+// languageTag returns the language tag of a Markdown code block for a file, or "" when
+// the language is unknown
+func languageTag(path string) string {
+	switch filepath.Base(path) {
+	case "Dockerfile":
+		return "dockerfile"
+	case "Makefile":
+		return "makefile"
+	}
+	return languageTags[strings.ToLower(filepath.Ext(path))]
+}
@@ -0,0 +1,88 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/fileutil"
+)
+
+func TestParseContextFormat(t *testing.T) {
+	goFile := fileutil.FileMeta{Path: "cmd/main.go", Content: "package main\n\nfunc main() {}\n"}
+
+	tests := []struct {
+		name     string
+		format   string
+		file     fileutil.FileMeta
+		expected string
+	}{
+		{
+			name:     "default is xml",
+			format:   "",
+			file:     goFile,
+			expected: "<path>cmd/main.go</path>\npackage main\n\nfunc main() {}\n\n\n",
+		},
+		{
+			name:     "xml",
+			format:   FormatXML,
+			file:     goFile,
+			expected: "<path>cmd/main.go</path>\npackage main\n\nfunc main() {}\n\n\n",
+		},
+		{
+			name:     "markdown with a language tag",
+			format:   FormatMarkdown,
+			file:     goFile,
+			expected: "## cmd/main.go\n\n```go\npackage main\n\nfunc main() {}\n```\n\n",
+		},
+		{
+			name:     "markdown with a longer fence than the content's",
+			format:   FormatMarkdown,
+			file:     fileutil.FileMeta{Path: "README", Content: "Run:\n```\nmake\n```"},
+			expected: "## README\n\n````\nRun:\n```\nmake\n```\n````\n\n",
+		},
+		{
+			name:   "numbered",
+			format: FormatNumbered,
+			file:   fileutil.FileMeta{Path: "a.txt", Content: strings.Repeat("line\n", 9) + "tenth\n"},
+			expected: "<path>a.txt</path>\n" +
+				" 1| line\n 2| line\n 3| line\n 4| line\n 5| line\n 6| line\n 7| line\n 8| line\n 9| line\n10| tenth\n\n",
+		},
+		{
+			name:     "template with escaped newlines",
+			format:   `### {path}\n{content}\n\n`,
+			file:     fileutil.FileMeta{Path: "a.txt", Content: "uses {path} literally"},
+			expected: "### a.txt\nuses {path} literally\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := ParseContextFormat(tt.format)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := format(tt.file); got != tt.expected {
+				t.Errorf("Expected:\n%q\nGot:\n%q", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		if _, err := ParseContextFormat("html"); err == nil || !strings.Contains(err.Error(), "unknown format") {
+			t.Errorf("Expected an unknown format error, got: %v", err)
+		}
+	})
+}
+
+func TestStitchContextWithFormat(t *testing.T) {
+	format, err := ParseContextFormat(FormatMarkdown)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	context := StitchContext([]fileutil.FileMeta{{Path: "app.py", Content: "print(1)"}}, StitchOptions{Format: format})
+	expected := "<context>\n## app.py\n\n```python\nprint(1)\n```\n\n</context>"
+	if context != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, context)
+	}
+}
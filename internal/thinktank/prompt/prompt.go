@@ -18,8 +18,19 @@ func EscapeContent(content string) string {
 	return content
 }
 
-// StitchPrompt combines instructions and file context into the final prompt string with XML-like tags
-func StitchPrompt(instructions string, contextFiles []fileutil.FileMeta) string {
+// StitchOptions controls how the context files are rendered in a prompt
+type StitchOptions struct {
+	// Format renders each context file (FormatXML when nil)
+	Format ContextFormat
+	// Tree prepends a directory tree of the context files, with their sizes, to the files
+	Tree bool
+	// OmittedDirs are directories left out of the context, marked as omitted in the tree
+	OmittedDirs []string
+}
+
+// StitchPrompt combines instructions and file context into the final prompt string with
+// XML-like tags, rendering the context files as set by opts
+func StitchPrompt(instructions string, contextFiles []fileutil.FileMeta, opts StitchOptions) string {
 	var sb strings.Builder
 
 	// Add instructions block
@@ -31,22 +42,46 @@ func StitchPrompt(instructions string, contextFiles []fileutil.FileMeta) string
 	sb.WriteString("</instructions>\n")
 
 	// Add context block
+	sb.WriteString(StitchContext(contextFiles, opts))
+
+	return sb.String()
+}
+
+// StitchContext formats the context files as the <context> block of a prompt, rendering
+// them as set by opts
+func StitchContext(contextFiles []fileutil.FileMeta, opts StitchOptions) string {
+	var sb strings.Builder
+
 	sb.WriteString("<context>\n")
+	if opts.Tree {
+		sb.WriteString(StitchTree(contextFiles, opts.OmittedDirs))
+	}
 	for _, file := range contextFiles {
-		// Add file path tag
-		sb.WriteString("<path>")
-		sb.WriteString(file.Path)
-		sb.WriteString("</path>\n")
-
-		// Add file content with escaping
-		sb.WriteString(EscapeContent(file.Content))
-		sb.WriteString("\n\n")
+		sb.WriteString(opts.FormatFile(file))
 	}
 	sb.WriteString("</context>")
 
 	return sb.String()
 }
 
+// FormatFile renders a single context file in the format set by opts
+func (opts StitchOptions) FormatFile(file fileutil.FileMeta) string {
+	if opts.Format == nil {
+		return formatXML(file)
+	}
+	return opts.Format(file)
+}
+
+// StitchTree formats the directory tree of the context files as the <tree> block that
+// StitchContext puts before the files, or returns an empty string if there is no tree
+func StitchTree(contextFiles []fileutil.FileMeta, omittedDirs []string) string {
+	tree := DirectoryTree(contextFiles, omittedDirs)
+	if tree == "" {
+		return ""
+	}
+	return "<tree>\n" + tree + "</tree>\n\n"
+}
+
 // StitchSynthesisPrompt combines original instructions and multiple model outputs
 // into a single prompt for a synthesis model. Each model output is clearly labeled
 // with the model name for reference.
@@ -62,6 +97,61 @@ func StitchPrompt(instructions string, contextFiles []fileutil.FileMeta) string
 func StitchSynthesisPrompt(originalInstructions string, modelOutputs map[string]string) string {
 	var builder strings.Builder
 
+	writeInstructionsAndOutputs(&builder, originalInstructions, modelOutputs)
+
+	// Add synthesis instructions
+	builder.WriteString("Please synthesize these outputs into a single, comprehensive response that addresses " +
+		"the original instructions. Your synthesis should incorporate the strongest insights and information " +
+		"from each model's output, resolving any contradictions and presenting a cohesive, well-structured result.")
+
+	return builder.String()
+}
+
+// NoChangesNeeded is the reply a critic model gives when an output needs no revision
+const NoChangesNeeded = "NO CHANGES NEEDED"
+
+// StitchCritiquePrompt combines original instructions and model outputs into a prompt
+// asking a critic model to review the outputs against the instructions. It uses the same
+// structure as StitchSynthesisPrompt. The critic replies with NoChangesNeeded if the
+// outputs need no revision.
+func StitchCritiquePrompt(originalInstructions string, modelOutputs map[string]string) string {
+	var builder strings.Builder
+
+	writeInstructionsAndOutputs(&builder, originalInstructions, modelOutputs)
+
+	// Add critique instructions
+	builder.WriteString("Please critique the output above against the original instructions. Identify errors, " +
+		"omissions, unsupported claims, risks and unclear reasoning, and give specific, actionable suggestions " +
+		"for improving it. Do not rewrite the output yourself. If the output fully addresses the instructions " +
+		"and needs no changes, reply with exactly: " + NoChangesNeeded)
+
+	return builder.String()
+}
+
+// StitchRefinePrompt combines original instructions, model outputs and a critique of them
+// into a prompt asking a model to produce a revised output that addresses the critique.
+// It uses the same structure as StitchSynthesisPrompt, with the critique in a <critique> section.
+func StitchRefinePrompt(originalInstructions string, modelOutputs map[string]string, critique string) string {
+	var builder strings.Builder
+
+	writeInstructionsAndOutputs(&builder, originalInstructions, modelOutputs)
+
+	// Format the critique with clear delimiters
+	builder.WriteString("<critique>\n")
+	builder.WriteString(critique)
+	builder.WriteString("\n</critique>\n\n")
+
+	// Add refinement instructions
+	builder.WriteString("Please revise the output above so that it addresses every valid point in the critique " +
+		"while keeping its strengths. Respond with the complete revised output only, as a full replacement " +
+		"for the original, without commenting on the critique.")
+
+	return builder.String()
+}
+
+// writeInstructionsAndOutputs writes the <instructions> and <model_outputs> sections
+// shared by the prompts that work on model outputs
+func writeInstructionsAndOutputs(builder *strings.Builder, originalInstructions string, modelOutputs map[string]string) {
 	// Format original instructions with clear delimiters
 	builder.WriteString("<instructions>\n")
 	builder.WriteString(originalInstructions)
@@ -75,11 +165,4 @@ func StitchSynthesisPrompt(originalInstructions string, modelOutputs map[string]
 		builder.WriteString("\n</model_result>\n\n")
 	}
 	builder.WriteString("</model_outputs>\n\n")
-
-	// Add synthesis instructions
-	builder.WriteString("Please synthesize these outputs into a single, comprehensive response that addresses " +
-		"the original instructions. Your synthesis should incorporate the strongest insights and information " +
-		"from each model's output, resolving any contradictions and presenting a cohesive, well-structured result.")
-
-	return builder.String()
 }
@@ -237,7 +237,7 @@ func TestStitchPrompt(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Get the stitched prompt result
-			result := prompt.StitchPrompt(tt.instructions, tt.contextFiles)
+			result := prompt.StitchPrompt(tt.instructions, tt.contextFiles, prompt.StitchOptions{})
 
 			// Run all checks for this test case
 			for _, check := range tt.checks {
@@ -246,3 +246,25 @@ func TestStitchPrompt(t *testing.T) {
 		})
 	}
 }
+
+// TestStitchContext verifies that the context block matches the one in the stitched prompt
+func TestStitchContext(t *testing.T) {
+	files := []fileutil.FileMeta{
+		{Path: "a.go", Content: "package a"},
+		{Path: "b.go", Content: "package b"},
+	}
+
+	context := prompt.StitchContext(files, prompt.StitchOptions{})
+	expected := "<context>\n<path>a.go</path>\npackage a\n\n<path>b.go</path>\npackage b\n\n</context>"
+	if context != expected {
+		t.Errorf("Unexpected context block:\n%s", context)
+	}
+
+	if !strings.HasSuffix(prompt.StitchPrompt("Do it", files, prompt.StitchOptions{}), "</instructions>\n"+expected) {
+		t.Error("Stitched prompt does not end with the context block")
+	}
+
+	if got := prompt.StitchContext(nil, prompt.StitchOptions{}); got != "<context>\n</context>" {
+		t.Errorf("Unexpected empty context block: %q", got)
+	}
+}
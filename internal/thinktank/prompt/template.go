@@ -0,0 +1,92 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// TemplateDirName is the directory, relative to the user's home directory, holding the
+// snippets that instructions templates can include
+const TemplateDirName = ".config/thinktank/templates"
+
+// TemplateData holds the variables instructions templates are executed with
+type TemplateData struct {
+	Vars   map[string]string // Variables given with --var and --vars-file, e.g. {{.Vars.language}}
+	Files  []string          // Paths of the context files
+	Models []string          // Models the instructions are sent to
+	Branch string            // Current git branch (empty outside a git repository)
+	Date   string            // Date of the run (YYYY-MM-DD)
+}
+
+// templateEnvPrefix is the prefix of the environment variables instructions templates
+// can read
+const templateEnvPrefix = "THINKTANK_"
+
+// templateFuncs are the functions available to instructions templates
+var templateFuncs = template.FuncMap{
+	"env":  templateEnv,
+	"join": strings.Join,
+}
+
+// templateEnv returns the value of an environment variable for the env function of
+// templates. Only variables starting with templateEnvPrefix can be read, since the
+// rendered instructions are sent to the providers and must not carry secrets such as
+// API keys.
+func templateEnv(name string) (string, error) {
+	if !strings.HasPrefix(name, templateEnvPrefix) {
+		return "", fmt.Errorf("env %q: templates can only read variables starting with %s", name, templateEnvPrefix)
+	}
+	return os.Getenv(name), nil
+}
+
+// DefaultTemplateDir returns the default snippet directory, ~/.config/thinktank/templates
+func DefaultTemplateDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, TemplateDirName), nil
+}
+
+// RenderInstructions executes instructions as a Go text/template with the given data.
+// Each file in snippetDir is available to the template by its file name, as in
+// {{template "review-checklist.md" .}}; a missing snippetDir provides no snippets.
+// Referring to a variable that was not given is an error, so that typos are not
+// silently rendered as empty text.
+func RenderInstructions(instructions string, data TemplateData, snippetDir string) (string, error) {
+	tmpl := template.New("instructions").Option("missingkey=error").Funcs(templateFuncs)
+
+	if snippetDir != "" {
+		entries, err := os.ReadDir(snippetDir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("failed to read template snippets in %s: %w", snippetDir, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			content, err := os.ReadFile(filepath.Join(snippetDir, entry.Name()))
+			if err != nil {
+				return "", fmt.Errorf("failed to read template snippet %s: %w", entry.Name(), err)
+			}
+			if _, err := tmpl.New(entry.Name()).Parse(string(content)); err != nil {
+				return "", fmt.Errorf("invalid template snippet %s: %w", entry.Name(), err)
+			}
+		}
+	}
+
+	if _, err := tmpl.Parse(instructions); err != nil {
+		return "", fmt.Errorf("invalid instructions template: %w", err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to execute instructions template: %w", err)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,78 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderInstructions(t *testing.T) {
+	snippetDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(snippetDir, "checklist.md"), []byte("Check {{.Vars.focus}}."), 0644); err != nil {
+		t.Fatalf("Failed to write snippet: %v", err)
+	}
+	t.Setenv("THINKTANK_TEST_TEAM", "platform")
+
+	data := TemplateData{
+		Vars:   map[string]string{"focus": "error handling"},
+		Files:  []string{"main.go", "util.go"},
+		Models: []string{"gpt-4.1"},
+		Branch: "feature/cache",
+		Date:   "2025-04-24",
+	}
+
+	tests := []struct {
+		name         string
+		instructions string
+		expected     string
+	}{
+		{"Variables", "Review the {{.Vars.focus}}.", "Review the error handling."},
+		{"Built-in variables", "{{.Branch}} on {{.Date}} with {{join .Models \", \"}}: {{join .Files \" \"}}",
+			"feature/cache on 2025-04-24 with gpt-4.1: main.go util.go"},
+		{"Environment", "Team {{env \"THINKTANK_TEST_TEAM\"}}", "Team platform"},
+		{"Snippets", "Review.\n{{template \"checklist.md\" .}}", "Review.\nCheck error handling."},
+		{"Plain text", "No template actions here.", "No template actions here."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := RenderInstructions(tt.instructions, data, snippetDir)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if rendered != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, rendered)
+			}
+		})
+	}
+
+	t.Run("Undefined variable", func(t *testing.T) {
+		_, err := RenderInstructions("Review the {{.Vars.fokus}}.", data, "")
+		if err == nil || !strings.Contains(err.Error(), "fokus") {
+			t.Errorf("Expected an error naming the undefined variable, got: %v", err)
+		}
+	})
+
+	t.Run("Environment outside the prefix", func(t *testing.T) {
+		t.Setenv("OPENAI_API_KEY", "secret-key")
+		rendered, err := RenderInstructions("Key {{env \"OPENAI_API_KEY\"}}", data, "")
+		if err == nil || !strings.Contains(err.Error(), "THINKTANK_") || strings.Contains(rendered, "secret-key") {
+			t.Errorf("Expected an error refusing to read the variable, got %q and error %v", rendered, err)
+		}
+	})
+
+	t.Run("Invalid template", func(t *testing.T) {
+		_, err := RenderInstructions("Review the {{.Vars.focus", data, "")
+		if err == nil || !strings.Contains(err.Error(), "invalid instructions template") {
+			t.Errorf("Expected a parse error, got: %v", err)
+		}
+	})
+
+	t.Run("Missing snippet directory", func(t *testing.T) {
+		rendered, err := RenderInstructions("{{.Date}}", data, filepath.Join(snippetDir, "missing"))
+		if err != nil || rendered != "2025-04-24" {
+			t.Errorf("Expected no snippets without the directory, got %q, %v", rendered, err)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package prompt
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/phrazzld/thinktank/internal/fileutil"
+)
+
+// treeNode is a file or directory of a directory tree
+type treeNode struct {
+	name     string
+	size     int64
+	isDir    bool
+	omitted  bool
+	children map[string]*treeNode
+}
+
+// DirectoryTree renders the directories and files of the context as a compact tree with
+// the size of each file, rooted at the deepest directory containing them all. Directories
+// in omittedDirs are listed as omitted, and chains of directories holding a single
+// directory are shown on one line. Files that were not read from disk, such as a diff,
+// are left out. An empty string is returned when no file is on disk.
+func DirectoryTree(contextFiles []fileutil.FileMeta, omittedDirs []string) string {
+	var files []fileutil.FileMeta
+	for _, file := range contextFiles {
+		if filepath.IsAbs(file.Path) {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return ""
+	}
+
+	root := filepath.Dir(files[0].Path)
+	for _, file := range files {
+		root = commonDir(root, filepath.Dir(file.Path))
+	}
+	var dirs []string
+	for _, dir := range omittedDirs {
+		if filepath.IsAbs(dir) && dir != root && isWithin(root, filepath.Dir(dir)) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	tree := &treeNode{isDir: true, children: map[string]*treeNode{}}
+	for _, file := range files {
+		size := file.Size
+		if size == 0 {
+			size = int64(len(file.Content))
+		}
+		node := tree.add(root, file.Path, false)
+		node.size = size
+	}
+	for _, dir := range dirs {
+		tree.add(root, dir, true).omitted = true
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.TrimSuffix(root, string(filepath.Separator)) + "/\n")
+	tree.render(&sb, "  ")
+	return sb.String()
+}
+
+// add returns the node of a path under root, creating it and the directories above it
+func (n *treeNode) add(root, path string, isDir bool) *treeNode {
+	relPath, _ := filepath.Rel(root, path)
+	parts := strings.Split(filepath.ToSlash(relPath), "/")
+	node := n
+	for i, part := range parts {
+		child, ok := node.children[part]
+		if !ok {
+			child = &treeNode{name: part, isDir: isDir || i < len(parts)-1, children: map[string]*treeNode{}}
+			node.children[part] = child
+		}
+		node = child
+	}
+	return node
+}
+
+// render writes the children of a directory node in name order, one per line
+func (n *treeNode) render(sb *strings.Builder, indent string) {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		node := n.children[name]
+		if !node.isDir {
+			sb.WriteString(indent + node.name + " (" + fileutil.FormatSize(node.size) + ")\n")
+			continue
+		}
+
+		// Collapse directories holding nothing but a single directory
+		label := node.name + "/"
+		for !node.omitted && len(node.children) == 1 {
+			var only *treeNode
+			for _, child := range node.children {
+				only = child
+			}
+			if !only.isDir {
+				break
+			}
+			node = only
+			label += node.name + "/"
+		}
+		if node.omitted {
+			label += " (omitted)"
+		}
+		sb.WriteString(indent + label + "\n")
+		node.render(sb, indent+"  ")
+	}
+}
+
+// commonDir returns the deepest directory containing both directories a and b
+func commonDir(a, b string) string {
+	for !isWithin(a, b) {
+		parent := filepath.Dir(a)
+		if parent == a {
+			return a
+		}
+		a = parent
+	}
+	return a
+}
+
+// isWithin reports whether path is dir or a path under it
+func isWithin(dir, path string) bool {
+	relPath, err := filepath.Rel(dir, path)
+	return err == nil && relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,58 @@
+package prompt
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phrazzld/thinktank/internal/fileutil"
+)
+
+func TestDirectoryTree(t *testing.T) {
+	root := filepath.FromSlash("/project")
+	path := func(p string) string { return filepath.Join(root, filepath.FromSlash(p)) }
+
+	files := []fileutil.FileMeta{
+		{Path: "git diff main...HEAD", Content: "diff --git a/main.go b/main.go"},
+		{Path: path("main.go"), Content: "package main", Size: 2048},
+		{Path: path("internal/server/server.go"), Content: "package server"},
+		{Path: path("internal/server/routes.go"), Content: "package server", Size: 300},
+		{Path: path("cmd/tool/v2/tool.go"), Content: "package main", Size: 1536},
+	}
+	omitted := []string{path("node_modules"), path("internal/server/testdata"), filepath.FromSlash("/elsewhere/vendor")}
+
+	expected := strings.Join([]string{
+		filepath.ToSlash(root) + "/",
+		"  cmd/tool/v2/",
+		"    tool.go (1.5 KB)",
+		"  internal/server/",
+		"    routes.go (300 B)",
+		"    server.go (14 B)",
+		"    testdata/ (omitted)",
+		"  main.go (2.0 KB)",
+		"  node_modules/ (omitted)",
+		"",
+	}, "\n")
+	if got := DirectoryTree(files, omitted); filepath.ToSlash(got) != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+
+	if got := DirectoryTree(files[:1], omitted); got != "" {
+		t.Errorf("Expected no tree without files on disk, got:\n%s", got)
+	}
+}
+
+func TestStitchContextWithTree(t *testing.T) {
+	file := fileutil.FileMeta{Path: filepath.FromSlash("/project/app.py"), Content: "print(1)"}
+
+	context := StitchContext([]fileutil.FileMeta{file}, StitchOptions{Tree: true})
+	expected := "<context>\n<tree>\n" + filepath.FromSlash("/project") + "/\n  app.py (8 B)\n</tree>\n\n" +
+		"<path>" + file.Path + "</path>\nprint(1)\n\n</context>"
+	if context != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, context)
+	}
+
+	if got := StitchContext(nil, StitchOptions{Tree: true}); got != "<context>\n</context>" {
+		t.Errorf("Expected an empty context without a tree, got %q", got)
+	}
+}
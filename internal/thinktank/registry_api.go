@@ -13,6 +13,7 @@ import (
 	"github.com/phrazzld/thinktank/internal/logutil"
 	"github.com/phrazzld/thinktank/internal/openai"
 	"github.com/phrazzld/thinktank/internal/providers"
+	"github.com/phrazzld/thinktank/internal/providers/anthropic"
 	"github.com/phrazzld/thinktank/internal/registry"
 	"github.com/phrazzld/thinktank/internal/thinktank/interfaces"
 )
@@ -115,6 +116,7 @@ func (s *registryAPIService) InitLLMClient(ctx context.Context, apiKey, modelNam
 	//    - For OpenAI: OPENAI_API_KEY
 	//    - For Gemini: GEMINI_API_KEY
 	//    - For OpenRouter: OPENROUTER_API_KEY
+	//    - For Anthropic: ANTHROPIC_API_KEY
 	//    These mappings are defined in ~/.config/thinktank/models.yaml
 	// 2. Explicitly provided API key parameter (fallback only)
 	//
@@ -149,8 +151,9 @@ func (s *registryAPIService) InitLLMClient(ctx context.Context, apiKey, modelNam
 	}
 
 	// STEP 3: If no API key is available from either source, reject the request
-	// API keys are required for all providers
-	if effectiveApiKey == "" {
+	// API keys are required for all providers except local servers such as Ollama
+	requiresAPIKey := providers.RequiresAPIKey(providerImpl)
+	if effectiveApiKey == "" && requiresAPIKey {
 		envVarName := getEnvVarNameForProvider(modelDef.Provider, modelConfig)
 		return nil, fmt.Errorf("%w: API key is required for model '%s' with provider '%s'. Please set the %s environment variable",
 			llm.ErrClientInitialization, modelName, modelDef.Provider, envVarName)
@@ -161,7 +164,9 @@ func (s *registryAPIService) InitLLMClient(ctx context.Context, apiKey, modelNam
 		modelName, modelDef.Provider)
 
 	// Verify the API key is non-empty before passing it to the provider
-	if effectiveApiKey == "" {
+	if effectiveApiKey == "" && !requiresAPIKey {
+		s.logger.Debug("No API key configured for provider '%s', which does not require one", modelDef.Provider)
+	} else if effectiveApiKey == "" {
 		s.logger.Error("Empty API key for provider '%s' - this will cause authentication failures", modelDef.Provider)
 	} else {
 		// Log API key metadata only (NEVER log any portion of the key itself)
@@ -222,6 +227,10 @@ func (s *registryAPIService) GetModelParameters(modelName string) (map[string]in
 		}
 	}
 
+	if modelDef.SystemPrompt != "" {
+		params[llm.SystemPromptParam] = modelDef.SystemPrompt
+	}
+
 	return params, nil
 }
 
@@ -352,8 +361,20 @@ func (s *registryAPIService) GetModelDefinition(modelName string) (*registry.Mod
 	return modelDef, nil
 }
 
-// GetModelTokenLimits retrieves token limits from the registry for a given model
-// This method now returns default values instead of actual model token limits
+// GetProviderDefinition retrieves a provider definition from the registry
+func (s *registryAPIService) GetProviderDefinition(providerName string) (*registry.ProviderDefinition, error) {
+	regImpl, ok := s.registry.(interface {
+		GetProvider(name string) (*registry.ProviderDefinition, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("registry does not implement GetProvider method")
+	}
+
+	return regImpl.GetProvider(providerName)
+}
+
+// GetModelTokenLimits retrieves token limits from the registry for a given model.
+// It returns an error if the model does not define a context window.
 func (s *registryAPIService) GetModelTokenLimits(modelName string) (contextWindow, maxOutputTokens int32, err error) {
 	// Look up the model in the registry to verify it exists
 	regImpl, ok := s.registry.(interface {
@@ -363,15 +384,17 @@ func (s *registryAPIService) GetModelTokenLimits(modelName string) (contextWindo
 		return 0, 0, fmt.Errorf("registry does not implement GetModel method")
 	}
 
-	_, err = regImpl.GetModel(modelName)
+	modelDef, err := regImpl.GetModel(modelName)
 	if err != nil {
 		s.logger.Debug("Model '%s' not found in registry: %v", modelName, err)
 		return 0, 0, fmt.Errorf("%w: %s", llm.ErrModelNotFound, modelName)
 	}
 
-	// Return default values instead of actual model values
-	// Token handling is now the responsibility of each provider
-	return 8192, 2048, nil
+	if modelDef.ContextWindow <= 0 {
+		return 0, 0, fmt.Errorf("model '%s' has no context_window configured", modelName)
+	}
+
+	return modelDef.ContextWindow, modelDef.MaxOutputTokens, nil
 }
 
 // ProcessLLMResponse processes a provider-agnostic API response and extracts content
@@ -508,6 +531,8 @@ func getEnvVarNameForProvider(providerName string, modelConfig *registry.ModelsC
 		return "GEMINI_API_KEY"
 	case "openrouter":
 		return "OPENROUTER_API_KEY"
+	case "anthropic":
+		return "ANTHROPIC_API_KEY"
 	default:
 		// Use a generic format for unknown providers
 		return strings.ToUpper(providerName) + "_API_KEY"
@@ -531,6 +556,11 @@ func (s *registryAPIService) GetErrorDetails(err error) string {
 		return apiErr.UserFacingError()
 	}
 
+	// Check if it's an Anthropic API error with enhanced details
+	if apiErr, ok := anthropic.IsAnthropicError(err); ok {
+		return apiErr.UserFacingError()
+	}
+
 	// Return the error string for other error types
 	return err.Error()
 }
@@ -771,6 +771,10 @@ func (m *mockAPIService) GetModelDefinition(modelName string) (*registry.ModelDe
 	return nil, errors.New("not implemented")
 }
 
+func (m *mockAPIService) GetProviderDefinition(providerName string) (*registry.ProviderDefinition, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (m *mockAPIService) GetModelTokenLimits(modelName string) (contextWindow, maxOutputTokens int32, err error) {
 	// Implement token limit fallbacks based on model name
 	switch modelName {
@@ -28,9 +28,11 @@ func TestFullAdapterRegistryInteraction(t *testing.T) {
 	mockRegistry := &BoundaryMockRegistry{
 		models: map[string]*registry.ModelDefinition{
 			"standard-model": {
-				Name:       "standard-model",
-				Provider:   "test-provider",
-				APIModelID: "test-model-id",
+				Name:            "standard-model",
+				Provider:        "test-provider",
+				APIModelID:      "test-model-id",
+				ContextWindow:   32768,
+				MaxOutputTokens: 4096,
 				Parameters: map[string]registry.ParameterDefinition{
 					"temperature": {
 						Type:    "float",
@@ -53,9 +55,11 @@ func TestFullAdapterRegistryInteraction(t *testing.T) {
 				},
 			},
 			"large-model": {
-				Name:       "large-model",
-				Provider:   "premium-provider",
-				APIModelID: "premium-model-id",
+				Name:            "large-model",
+				Provider:        "premium-provider",
+				APIModelID:      "premium-model-id",
+				ContextWindow:   1000000,
+				MaxOutputTokens: 65000,
 				Parameters: map[string]registry.ParameterDefinition{
 					"temperature": {
 						Type:    "float",
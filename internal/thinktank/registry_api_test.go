@@ -29,6 +29,7 @@ type MockRegistryAPI struct {
 type MockProviderAPI struct {
 	createClientErr error
 	client          llm.LLMClient
+	keyless         bool
 }
 
 // Use the built-in MockLLMClient from the llm package instead of creating our own
@@ -55,6 +56,11 @@ func (m MockProviderAPI) CreateClient(ctx context.Context, apiKey, modelID, apiE
 	return m.client, nil
 }
 
+// RequiresAPIKey reports whether the mock provider needs an API key
+func (m MockProviderAPI) RequiresAPIKey() bool {
+	return !m.keyless
+}
+
 // GetModel implements the registry.Registry method
 func (m *MockRegistryAPI) GetModel(name string) (*registry.ModelDefinition, error) {
 	if m.getModelErr != nil {
@@ -103,9 +109,11 @@ func setupTest(t *testing.T) (*registryAPIService, *MockRegistryAPI, *testutil.M
 
 	// Add a test model
 	mockRegistry.models["test-model"] = &registry.ModelDefinition{
-		Name:       "test-model",
-		Provider:   "test-provider",
-		APIModelID: "test-model-id",
+		Name:            "test-model",
+		Provider:        "test-provider",
+		APIModelID:      "test-model-id",
+		ContextWindow:   8192,
+		MaxOutputTokens: 2048,
 		Parameters: map[string]registry.ParameterDefinition{
 			"temperature": {
 				Type:    "float",
@@ -348,6 +356,21 @@ func TestGetModelParameters(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name:      "model with a system prompt",
+			modelName: "system-prompt-model",
+			modelDefinition: &registry.ModelDefinition{
+				Name:         "system-prompt-model",
+				Provider:     "test-provider",
+				APIModelID:   "system-prompt-model-id",
+				SystemPrompt: "You are a careful reviewer.",
+			},
+			registryImpl: nil, // Use the default mock registry
+			expectedParams: map[string]interface{}{
+				"system": "You are a careful reviewer.",
+			},
+			expectError: false,
+		},
 		{
 			name:      "model with nil default value",
 			modelName: "nil-default-model",
@@ -558,6 +581,36 @@ func TestInitLLMClient(t *testing.T) {
 		}
 	})
 
+	t.Run("missing API key is rejected", func(t *testing.T) {
+		service, _, _ := setupTest(t)
+		t.Setenv("TEST_PROVIDER_API_KEY", "")
+
+		_, err := service.InitLLMClient(ctx, "", "test-model", "")
+		if err == nil {
+			t.Fatal("Expected error for missing API key, got nil")
+		}
+		if !errors.Is(err, llm.ErrClientInitialization) {
+			t.Errorf("Expected ErrClientInitialization, got: %v", err)
+		}
+	})
+
+	t.Run("keyless provider without API key", func(t *testing.T) {
+		service, mockRegistry, _ := setupTest(t)
+		t.Setenv("TEST_PROVIDER_API_KEY", "")
+
+		providerImpl := mockRegistry.implementations["test-provider"]
+		providerImpl.keyless = true
+		mockRegistry.implementations["test-provider"] = providerImpl
+
+		client, err := service.InitLLMClient(ctx, "", "test-model", "")
+		if err != nil {
+			t.Fatalf("Expected no error for provider that does not require an API key, got: %v", err)
+		}
+		if client == nil {
+			t.Error("Expected a client, got nil")
+		}
+	})
+
 	t.Run("custom endpoint is logged", func(t *testing.T) {
 		service, _, logger := setupTest(t)
 
@@ -839,8 +892,21 @@ func TestGetModelTokenLimits(t *testing.T) {
 					},
 				},
 			},
-			expectedContextWindow: 8192, // Should still return default values
-			expectedMaxTokens:     2048, // Should still return default values
+			expectError:    true,
+			errorSubstring: "no context_window configured",
+		},
+		{
+			name:      "model with custom token limits",
+			modelName: "large-model",
+			modelDef: &registry.ModelDefinition{
+				Name:            "large-model",
+				Provider:        "test-provider",
+				APIModelID:      "large-model-id",
+				ContextWindow:   1000000,
+				MaxOutputTokens: 65000,
+			},
+			expectedContextWindow: 1000000,
+			expectedMaxTokens:     65000,
 			expectError:           false,
 		},
 	}
@@ -0,0 +1,63 @@
+// Package thinktank contains the core application logic for the thinktank tool
+package thinktank
+
+import (
+	"context"
+	"time"
+
+	"github.com/phrazzld/thinktank/internal/auditlog"
+	"github.com/phrazzld/thinktank/internal/cache"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/logutil"
+	"github.com/phrazzld/thinktank/internal/thinktank/interfaces"
+)
+
+// cachingAPIService wraps the LLM clients created by an APIService so that
+// repeated requests are served from the response cache. All other methods
+// are delegated to the underlying APIService.
+type cachingAPIService struct {
+	interfaces.APIService
+	cache       *cache.ResponseCache
+	auditLogger auditlog.AuditLogger
+	logger      logutil.LoggerInterface
+}
+
+// newCachingAPIService wraps an APIService with a response cache stored in cacheDir
+func newCachingAPIService(
+	apiService interfaces.APIService,
+	cacheDir string,
+	ttl time.Duration,
+	auditLogger auditlog.AuditLogger,
+	logger logutil.LoggerInterface,
+) interfaces.APIService {
+	return &cachingAPIService{
+		APIService:  apiService,
+		cache:       cache.NewResponseCache(cacheDir, ttl),
+		auditLogger: auditLogger,
+		logger:      logger,
+	}
+}
+
+// InitLLMClient initializes a client with the underlying APIService and wraps it with the cache
+func (s *cachingAPIService) InitLLMClient(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+	client, err := s.APIService.InitLLMClient(ctx, apiKey, modelName, apiEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	// Include the provider's model ID and the endpoint requests are sent to in the
+	// cache key when they are known. Without a custom endpoint, requests go to the
+	// provider's base URL, or to its built-in one, which the provider name stands for.
+	apiModelID, endpoint := "", apiEndpoint
+	if modelDef, err := s.GetModelDefinition(modelName); err == nil && modelDef != nil {
+		apiModelID = modelDef.APIModelID
+		if endpoint == "" {
+			endpoint = modelDef.Provider
+			if providerDef, err := s.GetProviderDefinition(modelDef.Provider); err == nil && providerDef != nil && providerDef.BaseURL != "" {
+				endpoint = providerDef.BaseURL
+			}
+		}
+	}
+
+	return cache.NewClient(client, s.cache, modelName, apiModelID, endpoint, s.auditLogger, s.logger), nil
+}
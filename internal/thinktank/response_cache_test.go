@@ -0,0 +1,126 @@
+package thinktank
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/phrazzld/thinktank/internal/auditlog"
+	"github.com/phrazzld/thinktank/internal/config"
+	"github.com/phrazzld/thinktank/internal/logutil"
+	"github.com/phrazzld/thinktank/internal/ratelimit"
+	"github.com/phrazzld/thinktank/internal/thinktank/interfaces"
+)
+
+// TestCachingAPIServiceInitLLMClient tests that clients created through the caching
+// service reuse responses across runs, as long as they talk to the same endpoint
+func TestCachingAPIServiceInitLLMClient(t *testing.T) {
+	cacheDir := t.TempDir()
+	auditLogger := NewMockAuditLogger()
+	mockClient := NewMockLLMClient("test-model")
+	apiService := NewMockAPIService()
+	apiService.mockLLMClient = mockClient
+
+	generate := func(endpoint string) string {
+		service := newCachingAPIService(apiService, cacheDir, time.Hour, auditLogger, NewMockLogger())
+		client, err := service.InitLLMClient(context.Background(), "", "test-model", endpoint)
+		if err != nil {
+			t.Fatalf("InitLLMClient failed: %v", err)
+		}
+		defer func() { _ = client.Close() }()
+
+		result, err := client.GenerateContent(context.Background(), "prompt", nil)
+		if err != nil {
+			t.Fatalf("GenerateContent failed: %v", err)
+		}
+		return result.Content
+	}
+
+	if got := generate(""); got != "Test Generated Plan" {
+		t.Errorf("Expected the provider response, got %q", got)
+	}
+
+	// Change the provider's response; the second run should not see it
+	mockClient.generatedOutput = "Changed plan"
+	if got := generate(""); got != "Test Generated Plan" {
+		t.Errorf("Expected the cached response, got %q", got)
+	}
+
+	// Responses from another endpoint are not reused
+	if got := generate("http://localhost:8080/v1"); got != "Changed plan" {
+		t.Errorf("Expected the response from the other endpoint, got %q", got)
+	}
+
+	hits := 0
+	for _, entry := range auditLogger.GetEntries() {
+		if entry.Operation == "CacheHit" {
+			hits++
+		}
+	}
+	if hits != 1 {
+		t.Errorf("Expected 1 CacheHit audit entry, got %d", hits)
+	}
+}
+
+// TestExecuteResponseCache tests that Execute enables the response cache unless it is disabled
+func TestExecuteResponseCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		noCache     bool
+		cacheDir    string
+		expectCache bool
+	}{
+		{name: "Cache enabled", cacheDir: "cache", expectCache: true},
+		{name: "Cache disabled with --no-cache", noCache: true, cacheDir: "cache", expectCache: false},
+		{name: "No cache directory", cacheDir: "", expectCache: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testDir := t.TempDir()
+			instructionsFile := createTestFile(t, filepath.Join(testDir, "instructions.md"), "Test instructions")
+
+			cliConfig := &config.CliConfig{
+				InstructionsFile: instructionsFile,
+				OutputDir:        filepath.Join(testDir, "output"),
+				ModelNames:       []string{"test-model"},
+				Paths:            []string{testDir},
+				LogLevel:         logutil.InfoLevel,
+				NoCache:          tt.noCache,
+				CacheTTL:         time.Hour,
+				DirPermissions:   0750,
+				FilePermissions:  0640,
+			}
+			if tt.cacheDir != "" {
+				cliConfig.CacheDir = filepath.Join(testDir, tt.cacheDir)
+			}
+
+			var usedCache bool
+			origConstructor := GetOrchestratorConstructor()
+			defer SetOrchestratorConstructor(origConstructor)
+			SetOrchestratorConstructor(func(
+				apiService interfaces.APIService,
+				contextGatherer interfaces.ContextGatherer,
+				fileWriter interfaces.FileWriter,
+				auditLogger auditlog.AuditLogger,
+				rateLimiter *ratelimit.RateLimiter,
+				config *config.CliConfig,
+				logger logutil.LoggerInterface,
+			) Orchestrator {
+				if adapter, ok := apiService.(*APIServiceAdapter); ok {
+					_, usedCache = adapter.APIService.(*cachingAPIService)
+				}
+				return NewMockOrchestrator()
+			})
+
+			err := Execute(context.Background(), cliConfig, NewMockLogger(), NewMockAuditLogger(), NewMockAPIService())
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if usedCache != tt.expectCache {
+				t.Errorf("Expected response cache enabled: %v, got: %v", tt.expectCache, usedCache)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Package thinktank contains the core application logic for the thinktank tool
+package thinktank
+
+import (
+	"context"
+
+	"github.com/phrazzld/thinktank/internal/auditlog"
+	"github.com/phrazzld/thinktank/internal/llm"
+	"github.com/phrazzld/thinktank/internal/logutil"
+	"github.com/phrazzld/thinktank/internal/retry"
+	"github.com/phrazzld/thinktank/internal/thinktank/interfaces"
+)
+
+// retryingAPIService wraps the LLM clients created by an APIService so that
+// transient provider errors are retried. All other methods are delegated to the
+// underlying APIService.
+type retryingAPIService struct {
+	interfaces.APIService
+	policy      retry.Policy
+	auditLogger auditlog.AuditLogger
+	logger      logutil.LoggerInterface
+}
+
+// newRetryingAPIService wraps an APIService so that its clients retry transient errors
+// according to policy, recording every attempt with the audit logger
+func newRetryingAPIService(
+	apiService interfaces.APIService,
+	policy retry.Policy,
+	auditLogger auditlog.AuditLogger,
+	logger logutil.LoggerInterface,
+) interfaces.APIService {
+	return &retryingAPIService{
+		APIService:  apiService,
+		policy:      policy,
+		auditLogger: auditLogger,
+		logger:      logger,
+	}
+}
+
+// InitLLMClient initializes a client with the underlying APIService and wraps it with the retry policy
+func (s *retryingAPIService) InitLLMClient(ctx context.Context, apiKey, modelName, apiEndpoint string) (llm.LLMClient, error) {
+	client, err := s.APIService.InitLLMClient(ctx, apiKey, modelName, apiEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	return retry.NewClient(client, s.policy, modelName, s.auditLogger, s.logger), nil
+}
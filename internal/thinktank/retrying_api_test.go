@@ -0,0 +1,33 @@
+package thinktank
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/phrazzld/thinktank/internal/retry"
+)
+
+// TestRetryingAPIServiceInitLLMClient tests that clients created through the retrying
+// service retry with the policy it was constructed with
+func TestRetryingAPIServiceInitLLMClient(t *testing.T) {
+	apiService := NewMockAPIService()
+	apiService.mockLLMClient = NewMockLLMClient("test-model")
+	service := newRetryingAPIService(apiService, retry.Policy{MaxAttempts: 3, InitialBackoff: time.Second}, NewMockAuditLogger(), NewMockLogger())
+
+	client, err := service.InitLLMClient(context.Background(), "", "test-model", "")
+	if err != nil {
+		t.Fatalf("InitLLMClient failed: %v", err)
+	}
+	if _, ok := client.(*retry.Client); !ok {
+		t.Errorf("Expected a retry client, got %T", client)
+	}
+
+	// Errors initializing the client are returned as they are
+	initErr := errors.New("init failed")
+	apiService.initLLMClientErr = initErr
+	if _, err := service.InitLLMClient(context.Background(), "", "test-model", ""); !errors.Is(err, initErr) {
+		t.Errorf("Expected the initialization error, got %v", err)
+	}
+}
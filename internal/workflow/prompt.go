@@ -0,0 +1,165 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Output is the response of one model in a step
+type Output struct {
+	Step    string
+	Model   string
+	Content string
+}
+
+// PromptData is the data available to the prompt template of a step
+type PromptData struct {
+	// Instructions are the user's instructions
+	Instructions string
+	// Context is the <context> block with the gathered project files
+	Context string
+	// Prompt is the standard prompt: the instructions followed by the context
+	Prompt string
+	// Inputs are the outputs of the steps this step needs, in the order they are needed
+	Inputs []Output
+
+	// results holds the outputs of every step this step needs, directly or indirectly
+	results map[string][]Output
+}
+
+// PromptData returns the template data for a step, given the outputs of the steps
+// that have finished so far. Only the outputs of steps the step needs, directly or
+// indirectly, are made available, since other steps may not have finished yet.
+func (d *Definition) PromptData(step Step, instructions, context, prompt string, results map[string][]Output) PromptData {
+	data := PromptData{
+		Instructions: instructions,
+		Context:      context,
+		Prompt:       prompt,
+		results:      make(map[string][]Output),
+	}
+
+	for id := range d.Ancestors(step.ID) {
+		data.results[id] = results[id]
+	}
+	for _, need := range step.Needs {
+		data.Inputs = append(data.Inputs, results[need]...)
+	}
+	return data
+}
+
+// Responses formats the inputs of the step, each labeled with its step and model
+func (p PromptData) Responses() string {
+	return formatOutputs(p.Inputs)
+}
+
+// Candidates formats the inputs of the step as numbered candidates for a vote
+func (p PromptData) Candidates() string {
+	var sb strings.Builder
+	for i, input := range p.Inputs {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "<candidate number=\"%d\">\n%s\n</candidate>", i+1, input.Content)
+	}
+	return sb.String()
+}
+
+// Output formats the outputs of an earlier step, each labeled with its model.
+// The step must be one that the current step needs, directly or indirectly.
+func (p PromptData) Output(step string) (string, error) {
+	outputs, ok := p.results[step]
+	if !ok {
+		return "", fmt.Errorf("step %q is not needed by this step", step)
+	}
+	return formatOutputs(outputs), nil
+}
+
+// formatOutputs formats step outputs with XML-like tags, like the other prompts
+func formatOutputs(outputs []Output) string {
+	var sb strings.Builder
+	for i, output := range outputs {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "<step_output step=\"%s\" model=\"%s\">\n%s\n</step_output>", output.Step, output.Model, output.Content)
+	}
+	return sb.String()
+}
+
+// defaultPrompts are the prompt templates used for steps that do not define their own
+var defaultPrompts = map[StepType]string{
+	StepGenerate: `{{.Prompt}}`,
+
+	StepCritique: `{{.Prompt}}
+
+<previous_outputs>
+{{.Responses}}
+</previous_outputs>
+
+Critique the responses above. Identify errors, omissions, risks and weak reasoning in how they ` +
+		`address the instructions and the context, and give specific, actionable suggestions for ` +
+		`improving them. Do not rewrite the responses yourself.`,
+
+	StepRefine: `{{.Prompt}}
+
+<previous_outputs>
+{{.Responses}}
+</previous_outputs>
+
+Above are earlier responses to the instructions, along with any critiques of them. Produce a ` +
+		`single improved response to the instructions that addresses every valid point raised in the ` +
+		`critiques and keeps the strengths of the earlier responses.`,
+
+	StepSynthesize: `<instructions>
+{{.Instructions}}
+</instructions>
+
+<previous_outputs>
+{{.Responses}}
+</previous_outputs>
+
+Please synthesize these outputs into a single, comprehensive response that addresses the original ` +
+		`instructions. Your synthesis should incorporate the strongest insights and information from each ` +
+		`output, resolving any contradictions and presenting a cohesive, well-structured result.`,
+
+	StepVote: `<instructions>
+{{.Instructions}}
+</instructions>
+
+<candidates>
+{{.Candidates}}
+</candidates>
+
+Which candidate best addresses the instructions? Answer with the number of the best candidate on ` +
+		`the first line, followed by a brief justification.`,
+}
+
+// DefaultPrompt returns the prompt template used for steps of the given type that
+// do not define their own
+func DefaultPrompt(stepType StepType) string {
+	return defaultPrompts[stepType]
+}
+
+// template parses the prompt template of the step
+func (s Step) template() (*template.Template, error) {
+	text := s.Prompt
+	if text == "" {
+		text = DefaultPrompt(s.Type)
+	}
+	return template.New(s.ID).Option("missingkey=error").Parse(text)
+}
+
+// RenderPrompt renders the prompt template of the step with the given data
+func (s Step) RenderPrompt(data PromptData) (string, error) {
+	tmpl, err := s.template()
+	if err != nil {
+		return "", fmt.Errorf("failed to parse prompt template of step %q: %w", s.ID, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt of step %q: %w", s.ID, err)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,83 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRenderDefaultPrompts tests the default templates of each step type
+func TestRenderDefaultPrompts(t *testing.T) {
+	def, err := Parse([]byte(planCritiqueRevise))
+	require.NoError(t, err)
+
+	results := map[string][]Output{
+		"plan": {
+			{Step: "plan", Model: "model-a", Content: "plan A"},
+			{Step: "plan", Model: "model-b", Content: "plan B"},
+		},
+		"critique": {
+			{Step: "critique", Model: "model-c", Content: "too vague"},
+		},
+	}
+
+	plan, _ := def.Step("plan")
+	data := def.PromptData(plan, "Do it", "<context>\n</context>", "PROMPT", results)
+	prompt, err := plan.RenderPrompt(data)
+	require.NoError(t, err)
+	assert.Equal(t, "PROMPT", prompt)
+
+	revise, _ := def.Step("revise")
+	data = def.PromptData(revise, "Do it", "<context>\n</context>", "PROMPT", results)
+	prompt, err = revise.RenderPrompt(data)
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "PROMPT\n\n<previous_outputs>\n")
+	assert.Contains(t, prompt, "<step_output step=\"plan\" model=\"model-a\">\nplan A\n</step_output>\n\n"+
+		"<step_output step=\"plan\" model=\"model-b\">\nplan B\n</step_output>\n\n"+
+		"<step_output step=\"critique\" model=\"model-c\">\ntoo vague\n</step_output>")
+	assert.Contains(t, prompt, "addresses every valid point")
+
+	vote := Step{ID: "vote", Type: StepVote, Models: []string{"model-a"}, Needs: []string{"plan"}}
+	data = (&Definition{Steps: append(def.Steps, vote)}).PromptData(vote, "Do it", "", "PROMPT", results)
+	prompt, err = vote.RenderPrompt(data)
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "<instructions>\nDo it\n</instructions>")
+	assert.Contains(t, prompt, "<candidate number=\"1\">\nplan A\n</candidate>\n\n<candidate number=\"2\">\nplan B\n</candidate>")
+	assert.NotContains(t, prompt, "PROMPT")
+
+	for _, stepType := range []StepType{StepGenerate, StepCritique, StepRefine, StepSynthesize, StepVote} {
+		assert.NotEmpty(t, DefaultPrompt(stepType), "default prompt for %s", stepType)
+	}
+}
+
+// TestRenderCustomPrompt tests custom templates that refer to earlier steps
+func TestRenderCustomPrompt(t *testing.T) {
+	def := &Definition{Steps: []Step{
+		{ID: "plan", Type: StepGenerate, Models: []string{"a"}},
+		{ID: "other", Type: StepGenerate, Models: []string{"a"}},
+		{ID: "critique", Type: StepCritique, Models: []string{"a"}, Needs: []string{"plan"}},
+		{ID: "revise", Type: StepRefine, Models: []string{"a"}, Needs: []string{"critique"},
+			Prompt: "{{.Instructions}}|{{.Output \"plan\"}}|{{range .Inputs}}{{.Model}}={{.Content}}{{end}}"},
+		{ID: "bad", Type: StepRefine, Models: []string{"a"}, Needs: []string{"critique"},
+			Prompt: "{{.Output \"other\"}}"},
+	}}
+	require.NoError(t, def.Validate())
+
+	results := map[string][]Output{
+		"plan":     {{Step: "plan", Model: "a", Content: "the plan"}},
+		"other":    {{Step: "other", Model: "a", Content: "unrelated"}},
+		"critique": {{Step: "critique", Model: "a", Content: "the critique"}},
+	}
+
+	revise, _ := def.Step("revise")
+	prompt, err := revise.RenderPrompt(def.PromptData(revise, "Do it", "", "", results))
+	require.NoError(t, err)
+	assert.Equal(t, "Do it|<step_output step=\"plan\" model=\"a\">\nthe plan\n</step_output>|a=the critique", prompt)
+
+	// Steps that are not needed may not have finished, so they cannot be referenced
+	bad, _ := def.Step("bad")
+	_, err = bad.RenderPrompt(def.PromptData(bad, "Do it", "", "", results))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `step "other" is not needed by this step`)
+}
@@ -0,0 +1,47 @@
+package workflow
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// numberPattern matches the candidate numbers in a ballot
+var numberPattern = regexp.MustCompile(`\d+`)
+
+// ParseVote returns the candidate (numbered from 1) chosen in a ballot: the first
+// number in the ballot that names one of the candidates. It returns false if the
+// ballot does not name a candidate.
+func ParseVote(ballot string, candidates int) (int, bool) {
+	for _, match := range numberPattern.FindAllString(ballot, -1) {
+		number, err := strconv.Atoi(match)
+		if err == nil && number >= 1 && number <= candidates {
+			return number, true
+		}
+	}
+	return 0, false
+}
+
+// Tally counts the votes in the ballots and returns the winning candidate (numbered
+// from 1) together with the votes for each candidate. Ties go to the lower-numbered
+// candidate. It returns false if no ballot names a candidate.
+func Tally(ballots []string, candidates int) (int, []int, bool) {
+	votes := make([]int, candidates)
+	valid := 0
+	for _, ballot := range ballots {
+		if number, ok := ParseVote(ballot, candidates); ok {
+			votes[number-1]++
+			valid++
+		}
+	}
+	if valid == 0 {
+		return 0, votes, false
+	}
+
+	winner := 0
+	for i, count := range votes {
+		if count > votes[winner] {
+			winner = i
+		}
+	}
+	return winner + 1, votes, true
+}
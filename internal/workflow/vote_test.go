@@ -0,0 +1,46 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseVote tests reading the chosen candidate from a ballot
+func TestParseVote(t *testing.T) {
+	tests := []struct {
+		ballot string
+		want   int
+		ok     bool
+	}{
+		{"2", 2, true},
+		{"Candidate 3\nIt covers the edge cases.", 3, true},
+		{"7 is not a candidate, but 1 is the best", 1, true},
+		{"0", 0, false},
+		{"None of them", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseVote(tt.ballot, 3)
+		assert.Equal(t, tt.ok, ok, "ballot %q", tt.ballot)
+		assert.Equal(t, tt.want, got, "ballot %q", tt.ballot)
+	}
+}
+
+// TestTally tests counting ballots and breaking ties
+func TestTally(t *testing.T) {
+	winner, votes, ok := Tally([]string{"2", "Candidate 3", "2 because", "abstain"}, 3)
+	assert.True(t, ok)
+	assert.Equal(t, 2, winner)
+	assert.Equal(t, []int{0, 2, 1}, votes)
+
+	// Ties go to the lower-numbered candidate
+	winner, _, ok = Tally([]string{"3", "1"}, 3)
+	assert.True(t, ok)
+	assert.Equal(t, 1, winner)
+
+	_, votes, ok = Tally([]string{"no idea"}, 2)
+	assert.False(t, ok)
+	assert.Equal(t, []int{0, 0}, votes)
+}
@@ -0,0 +1,263 @@
+// Package workflow defines multi-step model pipelines, such as plan -> critique -> revise.
+// A workflow is a set of steps described in YAML. Each step sends a prompt to one or more
+// models and may use the outputs of the steps it needs, so that the steps form a directed
+// acyclic graph that can be executed in dependency order.
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StepType identifies what a step does with its inputs
+type StepType string
+
+const (
+	// StepGenerate answers the instructions using the project context
+	StepGenerate StepType = "generate"
+	// StepCritique reviews the outputs of the steps it needs
+	StepCritique StepType = "critique"
+	// StepRefine revises the outputs of the steps it needs, typically using critiques
+	StepRefine StepType = "refine"
+	// StepSynthesize combines the outputs of the steps it needs into a single response
+	StepSynthesize StepType = "synthesize"
+	// StepVote asks each model to choose the best of the outputs of the steps it needs.
+	// The output of a vote step is the candidate with the most votes.
+	StepVote StepType = "vote"
+)
+
+// ErrInvalidWorkflow is returned when a workflow definition cannot be used
+var ErrInvalidWorkflow = errors.New("invalid workflow")
+
+// stepIDPattern restricts step IDs to names that are safe to use as directory names
+var stepIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// Definition describes a workflow
+type Definition struct {
+	// Name identifies the workflow in logs and the audit log
+	Name string `yaml:"name"`
+	// Description explains what the workflow is for
+	Description string `yaml:"description,omitempty"`
+	// Steps are the steps of the workflow, in any order that respects their needs
+	Steps []Step `yaml:"steps"`
+}
+
+// Step describes a single step of a workflow
+type Step struct {
+	// ID names the step. Other steps refer to it in needs and templates, and its
+	// outputs are written to a directory of the same name.
+	ID string `yaml:"id"`
+	// Type is what the step does
+	Type StepType `yaml:"type"`
+	// Models are the models that run the step concurrently
+	Models []string `yaml:"models"`
+	// Prompt is a text/template for the prompt sent to each model. When it is empty,
+	// the default template for the step type is used (see DefaultPrompt).
+	Prompt string `yaml:"prompt,omitempty"`
+	// Needs lists the steps whose outputs this step uses. The step runs once they finish.
+	Needs []string `yaml:"needs,omitempty"`
+}
+
+// Load reads and validates a workflow definition from a YAML file
+func Load(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow file %s: %w", path, err)
+	}
+
+	def, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("workflow file %s: %w", path, err)
+	}
+	return def, nil
+}
+
+// Parse parses and validates a workflow definition from YAML
+func Parse(data []byte) (*Definition, error) {
+	var def Definition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWorkflow, err)
+	}
+
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// Validate checks that the steps are well formed and that their needs form a
+// directed acyclic graph
+func (d *Definition) Validate() error {
+	if len(d.Steps) == 0 {
+		return fmt.Errorf("%w: no steps defined", ErrInvalidWorkflow)
+	}
+
+	steps := make(map[string]bool, len(d.Steps))
+	for i, step := range d.Steps {
+		if step.ID == "" {
+			return fmt.Errorf("%w: step %d has no id", ErrInvalidWorkflow, i+1)
+		}
+		if !stepIDPattern.MatchString(step.ID) {
+			return fmt.Errorf("%w: step id %q may only contain letters, digits, '-' and '_'", ErrInvalidWorkflow, step.ID)
+		}
+		if steps[step.ID] {
+			return fmt.Errorf("%w: duplicate step id %q", ErrInvalidWorkflow, step.ID)
+		}
+		steps[step.ID] = true
+
+		switch step.Type {
+		case StepGenerate, StepCritique, StepRefine, StepSynthesize, StepVote:
+		case "":
+			return fmt.Errorf("%w: step %q has no type", ErrInvalidWorkflow, step.ID)
+		default:
+			return fmt.Errorf("%w: step %q has unknown type %q (expected generate, critique, refine, synthesize or vote)",
+				ErrInvalidWorkflow, step.ID, step.Type)
+		}
+
+		if len(step.Models) == 0 {
+			return fmt.Errorf("%w: step %q has no models", ErrInvalidWorkflow, step.ID)
+		}
+		for _, model := range step.Models {
+			if strings.TrimSpace(model) == "" {
+				return fmt.Errorf("%w: step %q has an empty model name", ErrInvalidWorkflow, step.ID)
+			}
+		}
+
+		if step.Type != StepGenerate && len(step.Needs) == 0 {
+			return fmt.Errorf("%w: %s step %q must need at least one other step", ErrInvalidWorkflow, step.Type, step.ID)
+		}
+
+		if _, err := step.template(); err != nil {
+			return fmt.Errorf("%w: step %q has an invalid prompt template: %v", ErrInvalidWorkflow, step.ID, err)
+		}
+	}
+
+	for _, step := range d.Steps {
+		for _, need := range step.Needs {
+			if need == step.ID {
+				return fmt.Errorf("%w: step %q needs itself", ErrInvalidWorkflow, step.ID)
+			}
+			if !steps[need] {
+				return fmt.Errorf("%w: step %q needs unknown step %q", ErrInvalidWorkflow, step.ID, need)
+			}
+		}
+	}
+
+	if _, err := d.Order(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Order returns the steps in an order in which every step comes after the steps it
+// needs. Steps that do not depend on each other keep their order in the definition.
+// It returns an error if the needs contain a cycle.
+func (d *Definition) Order() ([]Step, error) {
+	done := make(map[string]bool, len(d.Steps))
+	ordered := make([]Step, 0, len(d.Steps))
+
+	for len(ordered) < len(d.Steps) {
+		progressed := false
+		for _, step := range d.Steps {
+			if done[step.ID] || !allDone(step.Needs, done) {
+				continue
+			}
+			done[step.ID] = true
+			ordered = append(ordered, step)
+			progressed = true
+		}
+
+		if !progressed {
+			var cyclic []string
+			for _, step := range d.Steps {
+				if !done[step.ID] {
+					cyclic = append(cyclic, step.ID)
+				}
+			}
+			return nil, fmt.Errorf("%w: steps %s depend on each other in a cycle",
+				ErrInvalidWorkflow, strings.Join(cyclic, ", "))
+		}
+	}
+	return ordered, nil
+}
+
+// allDone reports whether every step ID is marked as done
+func allDone(ids []string, done map[string]bool) bool {
+	for _, id := range ids {
+		if !done[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// Step returns the step with the given ID
+func (d *Definition) Step(id string) (Step, bool) {
+	for _, step := range d.Steps {
+		if step.ID == id {
+			return step, true
+		}
+	}
+	return Step{}, false
+}
+
+// Ancestors returns the IDs of all steps that the given step needs, directly or indirectly
+func (d *Definition) Ancestors(id string) map[string]bool {
+	ancestors := make(map[string]bool)
+
+	var visit func(string)
+	visit = func(id string) {
+		step, ok := d.Step(id)
+		if !ok {
+			return
+		}
+		for _, need := range step.Needs {
+			if !ancestors[need] {
+				ancestors[need] = true
+				visit(need)
+			}
+		}
+	}
+	visit(id)
+
+	return ancestors
+}
+
+// FinalSteps returns the steps that no other step needs. Their outputs are the
+// results of the workflow.
+func (d *Definition) FinalSteps() []Step {
+	needed := make(map[string]bool)
+	for _, step := range d.Steps {
+		for _, need := range step.Needs {
+			needed[need] = true
+		}
+	}
+
+	var final []Step
+	for _, step := range d.Steps {
+		if !needed[step.ID] {
+			final = append(final, step)
+		}
+	}
+	return final
+}
+
+// ModelNames returns every model used by the workflow, in the order they first appear
+func (d *Definition) ModelNames() []string {
+	seen := make(map[string]bool)
+	var models []string
+	for _, step := range d.Steps {
+		for _, model := range step.Models {
+			if !seen[model] {
+				seen[model] = true
+				models = append(models, model)
+			}
+		}
+	}
+	return models
+}
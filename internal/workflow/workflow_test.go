@@ -0,0 +1,198 @@
+package workflow
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const planCritiqueRevise = `
+name: plan-critique-revise
+description: Draft a plan, critique it and revise it
+steps:
+  - id: revise
+    type: refine
+    models: [model-a]
+    needs: [plan, critique]
+  - id: plan
+    type: generate
+    models: [model-a, model-b]
+  - id: critique
+    type: critique
+    models: [model-c]
+    needs: [plan]
+`
+
+// TestParse tests that a valid workflow is parsed
+func TestParse(t *testing.T) {
+	def, err := Parse([]byte(planCritiqueRevise))
+	require.NoError(t, err)
+
+	assert.Equal(t, "plan-critique-revise", def.Name)
+	require.Len(t, def.Steps, 3)
+	assert.Equal(t, StepRefine, def.Steps[0].Type)
+	assert.Equal(t, []string{"plan", "critique"}, def.Steps[0].Needs)
+	assert.Equal(t, []string{"model-a", "model-b"}, def.Steps[1].Models)
+	assert.Equal(t, []string{"model-a", "model-b", "model-c"}, def.ModelNames())
+}
+
+// TestLoad tests loading a workflow from a file
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "workflow.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(planCritiqueRevise), 0644))
+
+	def, err := Load(path)
+	require.NoError(t, err)
+	assert.Len(t, def.Steps, 3)
+
+	_, err = Load(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("steps: []"), 0644))
+	_, err = Load(path)
+	assert.True(t, errors.Is(err, ErrInvalidWorkflow))
+	assert.Contains(t, err.Error(), path)
+}
+
+// TestValidate tests that invalid workflows are rejected with a helpful message
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name:    "malformed YAML",
+			yaml:    "steps: [",
+			wantErr: "invalid workflow",
+		},
+		{
+			name:    "no steps",
+			yaml:    "name: empty",
+			wantErr: "no steps defined",
+		},
+		{
+			name:    "missing id",
+			yaml:    "steps:\n  - type: generate\n    models: [a]",
+			wantErr: "step 1 has no id",
+		},
+		{
+			name:    "unsafe id",
+			yaml:    "steps:\n  - id: ../plan\n    type: generate\n    models: [a]",
+			wantErr: "may only contain",
+		},
+		{
+			name:    "duplicate id",
+			yaml:    "steps:\n  - id: plan\n    type: generate\n    models: [a]\n  - id: plan\n    type: generate\n    models: [b]",
+			wantErr: `duplicate step id "plan"`,
+		},
+		{
+			name:    "missing type",
+			yaml:    "steps:\n  - id: plan\n    models: [a]",
+			wantErr: `step "plan" has no type`,
+		},
+		{
+			name:    "unknown type",
+			yaml:    "steps:\n  - id: plan\n    type: brainstorm\n    models: [a]",
+			wantErr: `unknown type "brainstorm"`,
+		},
+		{
+			name:    "no models",
+			yaml:    "steps:\n  - id: plan\n    type: generate",
+			wantErr: `step "plan" has no models`,
+		},
+		{
+			name:    "empty model name",
+			yaml:    "steps:\n  - id: plan\n    type: generate\n    models: ['']",
+			wantErr: "empty model name",
+		},
+		{
+			name:    "critique without needs",
+			yaml:    "steps:\n  - id: review\n    type: critique\n    models: [a]",
+			wantErr: `critique step "review" must need at least one other step`,
+		},
+		{
+			name:    "invalid template",
+			yaml:    "steps:\n  - id: plan\n    type: generate\n    models: [a]\n    prompt: '{{.Prompt'",
+			wantErr: "invalid prompt template",
+		},
+		{
+			name:    "needs itself",
+			yaml:    "steps:\n  - id: plan\n    type: generate\n    models: [a]\n    needs: [plan]",
+			wantErr: `step "plan" needs itself`,
+		},
+		{
+			name:    "unknown need",
+			yaml:    "steps:\n  - id: review\n    type: critique\n    models: [a]\n    needs: [plan]",
+			wantErr: `needs unknown step "plan"`,
+		},
+		{
+			name: "cycle",
+			yaml: "steps:\n  - id: plan\n    type: generate\n    models: [a]\n" +
+				"  - id: a\n    type: critique\n    models: [a]\n    needs: [plan, b]\n" +
+				"  - id: b\n    type: refine\n    models: [a]\n    needs: [a]",
+			wantErr: "steps a, b depend on each other in a cycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrInvalidWorkflow))
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// TestOrder tests that steps are ordered after the steps they need
+func TestOrder(t *testing.T) {
+	def, err := Parse([]byte(planCritiqueRevise))
+	require.NoError(t, err)
+
+	ordered, err := def.Order()
+	require.NoError(t, err)
+
+	var ids []string
+	for _, step := range ordered {
+		ids = append(ids, step.ID)
+	}
+	assert.Equal(t, []string{"plan", "critique", "revise"}, ids)
+}
+
+// TestGraphHelpers tests looking up steps, their ancestors and the final steps
+func TestGraphHelpers(t *testing.T) {
+	def, err := Parse([]byte(planCritiqueRevise))
+	require.NoError(t, err)
+
+	step, ok := def.Step("critique")
+	assert.True(t, ok)
+	assert.Equal(t, StepCritique, step.Type)
+	_, ok = def.Step("missing")
+	assert.False(t, ok)
+
+	assert.Equal(t, map[string]bool{"plan": true, "critique": true}, def.Ancestors("revise"))
+	assert.Equal(t, map[string]bool{"plan": true}, def.Ancestors("critique"))
+	assert.Empty(t, def.Ancestors("plan"))
+
+	final := def.FinalSteps()
+	require.Len(t, final, 1)
+	assert.Equal(t, "revise", final[0].ID)
+}
+
+// TestExampleWorkflows tests that the example workflows in the docs are valid
+func TestExampleWorkflows(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "docs", "workflows", "*.yaml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		_, err := Load(path)
+		assert.NoError(t, err, path)
+	}
+}